@@ -35,3 +35,7 @@
 #include "cgotorch/byte_buffer.h"
 #include "cgotorch/jit.h"
 #include "cgotorch/functional.h"
+#include "cgotorch/memory.h"
+#include "cgotorch/scope.h"
+#include "cgotorch/object.h"
+#include "cgotorch/future.h"
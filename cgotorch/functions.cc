@@ -329,6 +329,16 @@ const char* Torch_ClampMin_(Tensor input, Tensor minimum) {
 
 // MARK: Data layout
 
+const char* Torch_AsStrided(Tensor* result, Tensor a, int64_t* size, int64_t size_len, int64_t* stride, int64_t stride_len, int64_t storage_offset) {
+    return try_catch_return_error_string([&] () {
+        *result = new at::Tensor(torch::as_strided(*a,
+            torch::IntArrayRef(size, size_len),
+            torch::IntArrayRef(stride, stride_len),
+            storage_offset
+        ));
+    });
+}
+
 const char* Torch_Permute(Tensor a, int64_t* dims, int64_t dims_size, Tensor* result) {
     return try_catch_return_error_string([&] () {
         c10::ArrayRef<int64_t> d(dims, dims_size);
@@ -75,6 +75,7 @@ const char* Torch_RandNLike(Tensor* result, Tensor reference);
 
 // TODO: adjoint
 // TODO: argwhere
+const char* Torch_AsStrided(Tensor* result, Tensor a, int64_t* size, int64_t size_len, int64_t* stride, int64_t stride_len, int64_t storage_offset);
 const char* Torch_Cat(Tensor* result, Tensor* tensors, int64_t tensors_size, int64_t dim);
 const char* Torch_Stack(Tensor* result, Tensor* tensors, int64_t tensors_size, int64_t dim);
 // TODO: Conj
@@ -0,0 +1,46 @@
+// C bindings for TorchScript futures held by c10::IValue.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+#include "cgotorch/future.h"
+#include "cgotorch/try_catch_return_error_string.hpp"
+
+/// @brief Return the future held by an IValue.
+static c10::intrusive_ptr<c10::ivalue::Future> to_future(IValue ivalue) {
+    if (!ivalue->isFuture())
+        throw std::runtime_error("Expected IValue to hold a future but received " + ivalue->tagKind());
+    return ivalue->toFuture();
+}
+
+const char* Torch_Future_IsCompleted(bool* output, IValue future) {
+    return try_catch_return_error_string([&]() { *output = to_future(future)->completed(); });
+}
+
+const char* Torch_Future_Wait(IValue* output, IValue future) {
+    return try_catch_return_error_string([&]() {
+        auto ptr = to_future(future);
+        ptr->wait();
+        if (ptr->hasError())
+            throw std::runtime_error(ptr->tryRetrieveErrorMessage());
+        *output = new torch::IValue(ptr->value());
+    });
+}
@@ -25,6 +25,7 @@
 #include <complex>
 #include <vector>
 #include "cgotorch/try_catch_return_error_string.hpp"
+#include "cgotorch/output_buffers.hpp"
 #include "cgotorch/ivalue.h"
 
 // MARK: Constructors
@@ -32,15 +33,28 @@
 const char* Torch_IValue_FromNone(IValue* output) { return try_catch_return_error_string([&]() { *output = new torch::IValue(); }); }
 // const char* Torch_IValue_FromScalar(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
 const char* Torch_IValue_FromBool         (IValue* output, bool            data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(data);  }); }
-const char* Torch_IValue_FromInt          (IValue* output, int             data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(data);  }); }
+const char* Torch_IValue_FromInt          (IValue* output, int64_t         data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(data);  }); }
 const char* Torch_IValue_FromDouble       (IValue* output, double          data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(data);  }); }
 const char* Torch_IValue_FromComplexDouble(IValue* output, double _Complex data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(reinterpret_cast<c10::complex<double>(&)>(data)); }); }
 const char* Torch_IValue_FromString       (IValue* output, const char* data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(data);  }); }
 const char* Torch_IValue_FromTensor       (IValue* output, Tensor      data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
-const char* Torch_IValue_FromBoolList         (IValue* output, bool*            data, int num_datums) { return try_catch_return_error_string([&]() { *output = new torch::IValue(std::vector<bool>(data, data + num_datums));            }); }
-const char* Torch_IValue_FromIntList          (IValue* output, int*             data, int num_datums) { return try_catch_return_error_string([&]() { *output = new torch::IValue(std::vector<int>(data, data + num_datums));             }); }
-const char* Torch_IValue_FromDoubleList       (IValue* output, double*          data, int num_datums) { return try_catch_return_error_string([&]() { *output = new torch::IValue(std::vector<double>(data, data + num_datums));          }); }
-const char* Torch_IValue_FromComplexDoubleList(IValue* output, double _Complex* data, int num_datums) { return try_catch_return_error_string([&]() { *output = new torch::IValue(std::vector<double _Complex>(data, data + num_datums)); }); }
+
+/// @brief Copy an array of C data into a typed list.
+/// @param data The array of data to copy. May be nullptr if num_datums is 0.
+/// @param num_datums The number of elements in the array.
+/// @returns A new IValue holding the list.
+template<typename T, typename U>
+torch::IValue* new_typed_list(U* data, int num_datums) {
+    c10::List<T> list;
+    list.reserve(num_datums);
+    for (int i = 0; i < num_datums; i++) list.push_back(reinterpret_cast<T&>(data[i]));
+    return new torch::IValue(list);
+}
+
+const char* Torch_IValue_FromBoolList         (IValue* output, bool*            data, int num_datums) { return try_catch_return_error_string([&]() { *output = new_typed_list<bool>(data, num_datums);                 }); }
+const char* Torch_IValue_FromIntList          (IValue* output, int64_t*         data, int num_datums) { return try_catch_return_error_string([&]() { *output = new_typed_list<int64_t>(data, num_datums);              }); }
+const char* Torch_IValue_FromDoubleList       (IValue* output, double*          data, int num_datums) { return try_catch_return_error_string([&]() { *output = new_typed_list<double>(data, num_datums);               }); }
+const char* Torch_IValue_FromComplexDoubleList(IValue* output, double _Complex* data, int num_datums) { return try_catch_return_error_string([&]() { *output = new_typed_list<c10::complex<double>>(data, num_datums); }); }
 const char* Torch_IValue_FromTensorList       (IValue* output, Tensor*          data, int num_datums) {
     return try_catch_return_error_string([&]() {
         std::vector<torch::Tensor> inputs;
@@ -48,15 +62,59 @@ const char* Torch_IValue_FromTensorList       (IValue* output, Tensor*
         *output = new torch::IValue(inputs);
     });
 }
-// const char* Torch_IValue_FromOptionalTensorList(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
-// const char* Torch_IValue_FromList(IValue* output, IValue* data, int num_datums) {
-//     return try_catch_return_error_string([&]() {
-//         std::vector<torch::IValue> inputs;
-//         for (int i = 0; i < num_datums; i++) inputs.push_back(*data[i]);
-//         *output = new torch::IValue(inputs);
-//     });
-// }
-// const char* Torch_IValue_FromTuple(IValue* output, IValue* data, int num_datums) { return Torch_IValue_FromList(output, data, num_datums); }
+
+const char* Torch_IValue_FromOptionalTensorList(IValue* output, Tensor* data, int num_datums) {
+    return try_catch_return_error_string([&]() {
+        c10::List<c10::optional<torch::Tensor>> inputs;
+        for (int i = 0; i < num_datums; i++) {
+            if (data[i] == nullptr)
+                inputs.push_back(c10::nullopt);
+            else
+                inputs.push_back(*data[i]);
+        }
+        *output = new torch::IValue(inputs);
+    });
+}
+
+/// @brief Return the unified type of an array of IValues.
+/// @param values The IValues to unify the types of.
+/// @param num_datums The number of IValues.
+/// @param empty The type to return if there are no values.
+/// @returns The most specific type that all of the values are instances of.
+static c10::TypePtr unify_types(IValue* values, int64_t num_datums, c10::TypePtr empty) {
+    if (num_datums == 0) return empty;
+    // Drop the shapes of tensors so that lists of tensors are Tensor[].
+    c10::TypePtr type = c10::unshapedType(values[0]->type());
+    for (int64_t i = 1; i < num_datums; i++) {
+        auto unified = c10::unifyTypes(type, c10::unshapedType(values[i]->type()));
+        if (!unified.has_value()) {
+            throw std::runtime_error(
+                "Expected values of a single type but found " +
+                type->annotation_str() + " and " + values[i]->type()->annotation_str()
+            );
+        }
+        type = *unified;
+    }
+    return type;
+}
+
+const char* Torch_IValue_FromList(IValue* output, IValue* data, int num_datums) {
+    return try_catch_return_error_string([&]() {
+        c10::impl::GenericList list(unify_types(data, num_datums, c10::AnyType::get()));
+        list.reserve(num_datums);
+        for (int i = 0; i < num_datums; i++) list.push_back(*data[i]);
+        *output = new torch::IValue(list);
+    });
+}
+
+const char* Torch_IValue_FromTuple(IValue* output, IValue* data, int num_datums) {
+    return try_catch_return_error_string([&]() {
+        std::vector<torch::IValue> elements;
+        for (int i = 0; i < num_datums; i++) elements.push_back(*data[i]);
+        *output = new torch::IValue(c10::ivalue::Tuple::create(std::move(elements)));
+    });
+}
+
 const char* Torch_IValue_FromDevice(IValue* output, Device data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
 // const char* Torch_IValue_FromStorage(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
 // const char* Torch_IValue_FromCapsule(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
@@ -65,7 +123,34 @@ const char* Torch_IValue_FromDevice(IValue* output, Device data) { return try_ca
 // const char* Torch_IValue_FromRRef(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
 // const char* Torch_IValue_FromQuantizer(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
 // const char* Torch_IValue_FromSymInt(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
-// const char* Torch_IValue_FromGenericDict(IValue* output, IValue* keys, IValue* values, int64_t num_datums) { }
+const char* Torch_IValue_FromNamedTuple(IValue* output, const char* name, const char** field_names, IValue* values, int num_datums) {
+    return try_catch_return_error_string([&]() {
+        std::vector<torch::IValue> elements;
+        std::vector<std::string> names;
+        std::vector<c10::TypePtr> types;
+        for (int i = 0; i < num_datums; i++) {
+            elements.push_back(*values[i]);
+            names.push_back(field_names[i]);
+            types.push_back(c10::unshapedType(values[i]->type()));
+        }
+        auto qualified_name = name == nullptr ?
+            c10::optional<c10::QualifiedName>() : c10::optional<c10::QualifiedName>(name);
+        auto type = c10::TupleType::createNamed(qualified_name, names, types);
+        *output = new torch::IValue(c10::ivalue::Tuple::createNamed(std::move(elements), type));
+    });
+}
+
+const char* Torch_IValue_FromGenericDict(IValue* output, IValue* keys, IValue* values, int64_t num_datums) {
+    return try_catch_return_error_string([&]() {
+        c10::impl::GenericDict dict(
+            unify_types(keys, num_datums, c10::StringType::get()),
+            unify_types(values, num_datums, c10::AnyType::get())
+        );
+        dict.reserve(num_datums);
+        for (int64_t i = 0; i < num_datums; i++) dict.insert_or_assign(*keys[i], *values[i]);
+        *output = new torch::IValue(dict);
+    });
+}
 // const char* Torch_IValue_FromObject(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
 // const char* Torch_IValue_FromModule(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
 // const char* Torch_IValue_FromPyObject(IValue* output, IValue data) { return try_catch_return_error_string([&]() { *output = new torch::IValue(*data); }); }
@@ -105,7 +190,7 @@ const char* Torch_IValue_IsRRef              (bool* output, IValue ivalue) { ret
 const char* Torch_IValue_IsQuantizer         (bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isQuantizer();          }); }
 // const char* Torch_IValue_IsSymInt            (bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isSymInt();             }); }
 // const char* Torch_IValue_IsSymFloat          (bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isSymFloat();           }); }
-// const char* Torch_IValue_IsOptionalTensorList(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isOptionalTensorList(); }); }
+const char* Torch_IValue_IsOptionalTensorList(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isOptionalTensorList(); }); }
 const char* Torch_IValue_IsObject            (bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isObject();             }); }
 const char* Torch_IValue_IsModule            (bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isModule();             }); }
 const char* Torch_IValue_IsPyObject          (bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isPyObject();           }); }
@@ -114,6 +199,29 @@ const char* Torch_IValue_IsStream            (bool* output, IValue ivalue) { ret
 const char* Torch_IValue_IsGenerator         (bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isGenerator();          }); }
 const char* Torch_IValue_IsPtrType           (bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->isPtrType();            }); }
 
+// MARK: Type introspection
+
+const char* Torch_IValue_TypeName(char** output, IValue ivalue) {
+    return try_catch_return_error_string([&]() {
+        *output = copy_to_c_string(c10::unshapedType(ivalue->type())->annotation_str());
+    });
+}
+
+const char* Torch_IValue_TupleFieldNames(char** output, int64_t num_datums, IValue ivalue) {
+    return try_catch_return_error_string([&]() {
+        const auto& tuple = ivalue->toTupleRef();
+        throw_on_size_mismatch(num_datums, tuple.size());
+        auto type = tuple.type();
+        for (int64_t i = 0; i < num_datums; i++) {
+            if (!type->schema()) {
+                output[i] = nullptr;
+                continue;
+            }
+            output[i] = copy_to_c_string(type->names()[i]);
+        }
+    });
+}
+
 // MARK: Container length checkers
 
 const char* Torch_IValue_LengthTuple(int64_t* output, IValue ivalue) {
@@ -136,25 +244,14 @@ const char* Torch_IValue_LengthDict(int64_t* output, IValue ivalue) {
 
 // MARK: Data accessors
 
-template<typename T, typename U>
-void throw_runtime_error_on_inequal_array_lengths(const T& expected, const U& actual) {
-    if (expected == actual) return;
-    throw std::runtime_error(
-        "Expected input array of size " + std::to_string(expected) +
-        " but received array of size " + std::to_string(actual)
-    );
-}
-
 const char* Torch_IValue_ToNone(char** output, IValue ivalue) {
     return try_catch_return_error_string([&]() {
-        auto str = ivalue->toNone();
-        *output = reinterpret_cast<char*>(malloc(str.size() + 1));
-        snprintf(*output, str.size() + 1, "%s", str.c_str());
+        *output = copy_to_c_string(ivalue->toNone());
     });
 }
 // const char* Torch_IValue_ToScalar(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toScalar(); }); }
 const char* Torch_IValue_ToBool(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toBool(); }); }
-const char* Torch_IValue_ToInt(int* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toInt(); }); }
+const char* Torch_IValue_ToInt(int64_t* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toInt(); }); }
 const char* Torch_IValue_ToDouble(double* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toDouble(); }); }
 const char* Torch_IValue_ToComplexDouble(double _Complex* output, IValue ivalue) {
     return try_catch_return_error_string([&]() {
@@ -164,10 +261,7 @@ const char* Torch_IValue_ToComplexDouble(double _Complex* output, IValue ivalue)
 }
 const char* Torch_IValue_ToString(char** output, IValue ivalue) {
     return try_catch_return_error_string([&]() {
-        auto str = ivalue->toStringRef().c_str();
-        auto length = strlen(str);
-        *output = reinterpret_cast<char*>(malloc(length + 1));
-        snprintf(*output, length + 1, "%s", str);
+        *output = copy_to_c_string(ivalue->toStringRef());
     });
 }
 const char* Torch_IValue_ToTensor(Tensor* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = new torch::Tensor(ivalue->toTensor()); }); }
@@ -175,15 +269,15 @@ const char* Torch_IValue_ToTensor(Tensor* output, IValue ivalue) { return try_ca
 const char* Torch_IValue_ToBoolList(bool* output, int64_t num_datums, IValue ivalue) {
     return try_catch_return_error_string([&]() {
         auto values = ivalue->toBoolList();
-        throw_runtime_error_on_inequal_array_lengths(num_datums, values.size());
+        throw_on_size_mismatch(num_datums, values.size());
         for (int i = 0; i < values.size(); i++) output[i] = values[i];
     });
 }
 
-const char* Torch_IValue_ToIntList(int* output, int64_t num_datums, IValue ivalue) {
+const char* Torch_IValue_ToIntList(int64_t* output, int64_t num_datums, IValue ivalue) {
     return try_catch_return_error_string([&]() {
         auto values = ivalue->toIntList();
-        throw_runtime_error_on_inequal_array_lengths(num_datums, values.size());
+        throw_on_size_mismatch(num_datums, values.size());
         for (int i = 0; i < values.size(); i++) output[i] = values[i];
     });
 }
@@ -191,7 +285,7 @@ const char* Torch_IValue_ToIntList(int* output, int64_t num_datums, IValue ivalu
 const char* Torch_IValue_ToDoubleList(double* output, int64_t num_datums, IValue ivalue) {
     return try_catch_return_error_string([&]() {
         auto values = ivalue->toDoubleList();
-        throw_runtime_error_on_inequal_array_lengths(num_datums, values.size());
+        throw_on_size_mismatch(num_datums, values.size());
         for (int i = 0; i < values.size(); i++) output[i] = values[i];
     });
 }
@@ -199,9 +293,9 @@ const char* Torch_IValue_ToDoubleList(double* output, int64_t num_datums, IValue
 const char* Torch_IValue_ToComplexDoubleList(double _Complex* output, int64_t num_datums, IValue ivalue) {
     return try_catch_return_error_string([&]() {
         auto values = ivalue->toComplexDoubleList();
-        throw_runtime_error_on_inequal_array_lengths(num_datums, values.size());
+        throw_on_size_mismatch(num_datums, values.size());
         for (int i = 0; i < values.size(); i++) {
-            auto value = values.begin() + i;
+            c10::complex<double> value = values.get(i);
             output[i] = reinterpret_cast<double _Complex(&)>(value);
         }
     });
@@ -210,18 +304,27 @@ const char* Torch_IValue_ToComplexDoubleList(double _Complex* output, int64_t nu
 const char* Torch_IValue_ToTensorList(Tensor* output, int64_t num_datums, IValue ivalue) {
     return try_catch_return_error_string([&]() {
         auto values = ivalue->toTensorVector();
-        throw_runtime_error_on_inequal_array_lengths(num_datums, values.size());
+        throw_on_size_mismatch(num_datums, values.size());
         for (int i = 0; i < values.size(); i++)
             output[i] = new torch::Tensor(values.at(i));
     });
 }
 
-// const char* Torch_IValue_ToOptionalTensorList(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toOptionalTensorList(); }); }
+const char* Torch_IValue_ToOptionalTensorList(Tensor* output, int64_t num_datums, IValue ivalue) {
+    return try_catch_return_error_string([&]() {
+        auto values = ivalue->toOptionalTensorList();
+        throw_on_size_mismatch(num_datums, values.size());
+        for (int i = 0; i < values.size(); i++) {
+            c10::optional<torch::Tensor> value = values.get(i);
+            output[i] = value.has_value() ? new torch::Tensor(*value) : nullptr;
+        }
+    });
+}
 
 const char* Torch_IValue_ToList(IValue* output, int64_t num_datums, IValue ivalue) {
     return try_catch_return_error_string([&]() {
         auto values = ivalue->toListRef();
-        throw_runtime_error_on_inequal_array_lengths(num_datums, values.size());
+        throw_on_size_mismatch(num_datums, values.size());
         for (int i = 0; i < values.size(); i++)
             output[i] = new torch::IValue(values.at(i));
     });
@@ -230,7 +333,7 @@ const char* Torch_IValue_ToList(IValue* output, int64_t num_datums, IValue ivalu
 const char* Torch_IValue_ToTuple(IValue* output, int64_t num_datums, IValue ivalue) {
     return try_catch_return_error_string([&]() {
         auto values = ivalue->toTupleRef();
-        throw_runtime_error_on_inequal_array_lengths(num_datums, values.size());
+        throw_on_size_mismatch(num_datums, values.size());
         auto elements = values.elements();
         for (int i = 0; i < values.size(); i++)
             output[i] = new torch::IValue(elements.at(i));
@@ -240,7 +343,7 @@ const char* Torch_IValue_ToTuple(IValue* output, int64_t num_datums, IValue ival
 const char* Torch_IValue_ToGenericDict(IValue* keys, IValue* values, int64_t num_datums, IValue ivalue) {
     return try_catch_return_error_string([&]() {
         auto map = ivalue->toGenericDict();
-        throw_runtime_error_on_inequal_array_lengths(num_datums, map.size());
+        throw_on_size_mismatch(num_datums, map.size());
         int i = 0;
         for (const auto& pair : map) {
             keys[i] = new torch::IValue(pair.key());
@@ -252,18 +355,14 @@ const char* Torch_IValue_ToGenericDict(IValue* keys, IValue* values, int64_t num
 
 const char* Torch_IValue_ToDevice(Device* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = new torch::Device(ivalue->toDevice()); }); }
 
-// const char* Torch_IValue_ToFuture(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toFuture(); }); }
 // const char* Torch_IValue_ToRRef(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toRRef(); }); }
 // const char* Torch_IValue_ToQuantizer(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toQuantizer(); }); }
 // const char* Torch_IValue_ToSymInt(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toSymInt(); }); }
 // const char* Torch_IValue_ToSymFloat(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toSymInt(); }); }
 // const char* Torch_IValue_ToStorage(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toStorage(); }); }
 // const char* Torch_IValue_ToCapsule(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toCapsule(); }); }
-// const char* Torch_IValue_ToCustomClass(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toCustomClass(); }); }
-// const char* Torch_IValue_ToObject(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toObject(); }); }
 // const char* Torch_IValue_ToModule(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toModule(); }); }
 // const char* Torch_IValue_ToPyObject(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toPyObject(); }); }
-// const char* Torch_IValue_ToEnum(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toEnum(); }); }
 // const char* Torch_IValue_ToStream(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toStream(); }); }
 // const char* Torch_IValue_ToGenerator(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toGenerator(); }); }
 // const char* Torch_IValue_ToPtrType(bool* output, IValue ivalue) { return try_catch_return_error_string([&]() { *output = ivalue->toPtrType(); }); }
@@ -35,19 +35,20 @@ extern "C" {
 const char* Torch_IValue_FromNone(IValue* output);
 // const char* Torch_IValue_FromScalar(IValue* output, IValue data);
 const char* Torch_IValue_FromBool(IValue* output, bool data);
-const char* Torch_IValue_FromInt(IValue* output, int data);
+const char* Torch_IValue_FromInt(IValue* output, int64_t data);
 const char* Torch_IValue_FromDouble(IValue* output, double data);
 const char* Torch_IValue_FromComplexDouble(IValue* output, double _Complex data);
 const char* Torch_IValue_FromString(IValue* output, const char* data);
 const char* Torch_IValue_FromTensor(IValue* output, Tensor data);
 const char* Torch_IValue_FromBoolList(IValue* output, bool* data, int num_datums);
-const char* Torch_IValue_FromIntList(IValue* output, int* data, int num_datums);
+const char* Torch_IValue_FromIntList(IValue* output, int64_t* data, int num_datums);
 const char* Torch_IValue_FromDoubleList(IValue* output, double* data, int num_datums);
 const char* Torch_IValue_FromComplexDoubleList(IValue* output, double _Complex* data, int num_datums);
 const char* Torch_IValue_FromTensorList(IValue* output, Tensor* data, int num_datums);
-// const char* Torch_IValue_FromOptionalTensorList(IValue* output, IValue data);
-// const char* Torch_IValue_FromList(IValue* output, IValue* values, int num_datums);
-// const char* Torch_IValue_FromTuple(IValue* output, IValue* values, int num_datums);
+const char* Torch_IValue_FromOptionalTensorList(IValue* output, Tensor* data, int num_datums);
+// Create a list with the unified type of the values (Any if empty.)
+const char* Torch_IValue_FromList(IValue* output, IValue* values, int num_datums);
+const char* Torch_IValue_FromTuple(IValue* output, IValue* values, int num_datums);
 const char* Torch_IValue_FromDevice(IValue* output, Device data);
 // const char* Torch_IValue_FromStorage(IValue* output, IValue data);
 // const char* Torch_IValue_FromCapsule(IValue* output, IValue data);
@@ -57,7 +58,12 @@ const char* Torch_IValue_FromDevice(IValue* output, Device data);
 // const char* Torch_IValue_FromQuantizer(IValue* output, IValue data);
 // const char* Torch_IValue_FromSymInt(IValue* output, IValue data);
 // const char* Torch_IValue_FromSymFloat(IValue* output, IValue data);
-// const char* Torch_IValue_FromGenericDict(IValue* output, IValue* keys, IValue* values, int64_t num_datums);
+// Create a named tuple, i.e., a typing.NamedTuple, with the given type name
+// (may be nullptr) and field names.
+const char* Torch_IValue_FromNamedTuple(IValue* output, const char* name, const char** field_names, IValue* values, int num_datums);
+// Create a dictionary with the unified types of the keys and values (str to
+// Any if empty.)
+const char* Torch_IValue_FromGenericDict(IValue* output, IValue* keys, IValue* values, int64_t num_datums);
 // const char* Torch_IValue_FromObject(IValue* output, IValue data);
 // const char* Torch_IValue_FromModule(IValue* output, IValue data);
 // const char* Torch_IValue_FromPyObject(IValue* output, IValue data);
@@ -97,7 +103,7 @@ const char* Torch_IValue_IsRRef(bool* output, IValue ivalue);
 const char* Torch_IValue_IsQuantizer(bool* output, IValue ivalue);
 // const char* Torch_IValue_IsSymInt(bool* output, IValue ivalue);
 // const char* Torch_IValue_IsSymFloat(bool* output, IValue ivalue);
-// const char* Torch_IValue_IsOptionalTensorList(bool* output, IValue ivalue);
+const char* Torch_IValue_IsOptionalTensorList(bool* output, IValue ivalue);
 const char* Torch_IValue_IsObject(bool* output, IValue ivalue);
 const char* Torch_IValue_IsModule(bool* output, IValue ivalue);
 const char* Torch_IValue_IsPyObject(bool* output, IValue ivalue);
@@ -106,6 +112,13 @@ const char* Torch_IValue_IsStream(bool* output, IValue ivalue);
 const char* Torch_IValue_IsGenerator(bool* output, IValue ivalue);
 const char* Torch_IValue_IsPtrType(bool* output, IValue ivalue);
 
+// MARK: Type introspection
+
+// Return the TorchScript type of the IValue, e.g., "Dict[str, Tensor]".
+const char* Torch_IValue_TypeName(char** output, IValue ivalue);
+// Return the field names of a named tuple, or nullptr for unnamed tuples.
+const char* Torch_IValue_TupleFieldNames(char** output, int64_t num_datums, IValue ivalue);
+
 // MARK: Container length checkers
 
 const char* Torch_IValue_LengthTuple(int64_t* output, IValue ivalue);
@@ -116,17 +129,17 @@ const char* Torch_IValue_LengthDict(int64_t* output, IValue ivalue);
 
 const char* Torch_IValue_ToNone(char** output, IValue ivalue);
 const char* Torch_IValue_ToBool(bool* output, IValue ivalue);
-const char* Torch_IValue_ToInt(int* output, IValue ivalue);
+const char* Torch_IValue_ToInt(int64_t* output, IValue ivalue);
 const char* Torch_IValue_ToDouble(double* output, IValue ivalue);
 const char* Torch_IValue_ToComplexDouble(double _Complex* output, IValue ivalue);
 // const char* Torch_IValue_ToScalar(bool* output, IValue ivalue);
 const char* Torch_IValue_ToString(char** output, IValue ivalue);
 const char* Torch_IValue_ToTensor(Tensor* output, IValue ivalue);
 const char* Torch_IValue_ToBoolList(bool* output, int64_t num_datums, IValue ivalue);
-const char* Torch_IValue_ToIntList(int* output, int64_t num_datums, IValue ivalue);
+const char* Torch_IValue_ToIntList(int64_t* output, int64_t num_datums, IValue ivalue);
 const char* Torch_IValue_ToDoubleList(double* output, int64_t num_datums, IValue ivalue);
 const char* Torch_IValue_ToComplexDoubleList(double _Complex* output, int64_t num_datums, IValue ivalue);
-// const char* Torch_IValue_ToOptionalTensorList(Tensor* output, int64_t num_datums, IValue ivalue);
+const char* Torch_IValue_ToOptionalTensorList(Tensor* output, int64_t num_datums, IValue ivalue);
 const char* Torch_IValue_ToTensorList(Tensor* output, int64_t num_datums, IValue ivalue);
 const char* Torch_IValue_ToList(IValue* output, int64_t num_datums, IValue ivalue);
 const char* Torch_IValue_ToTuple(IValue* output, int64_t num_datums, IValue ivalue);
@@ -134,16 +147,12 @@ const char* Torch_IValue_ToGenericDict(IValue* keys, IValue* values, int64_t num
 const char* Torch_IValue_ToDevice(Device* output, IValue ivalue);
 // const char* Torch_IValue_ToStorage(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToCapsule(bool* output, IValue ivalue);
-// const char* Torch_IValue_ToCustomClass(bool* output, IValue ivalue);
-// const char* Torch_IValue_ToFuture(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToRRef(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToQuantizer(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToSymInt(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToSymFloat(bool* output, IValue ivalue);
-// const char* Torch_IValue_ToObject(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToModule(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToPyObject(bool* output, IValue ivalue);
-// const char* Torch_IValue_ToEnum(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToStream(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToGenerator(bool* output, IValue ivalue);
 // const char* Torch_IValue_ToPtrType(bool* output, IValue ivalue);
@@ -23,9 +23,20 @@
 // SOFTWARE.
 //
 
+#include <torch/jit.h>
 #include <torch/script.h>
+#include <caffe2/serialize/read_adapter_interface.h>
+#include <torch/csrc/jit/passes/fold_conv_bn.h>
+#include <torch/csrc/jit/passes/inliner.h>
+#include <torch/csrc/jit/runtime/graph_executor.h>
+#include <torch/csrc/jit/runtime/profiling_graph_executor_impl.h>
+#include <algorithm>
+#include <cstring>
+#include <memory>
+#include <sstream>
 #include <string>
 #include "cgotorch/jit.h"
+#include "cgotorch/output_buffers.hpp"
 #include "cgotorch/try_catch_return_error_string.hpp"
 
 const char* Torch_Jit_Load(JitModule* module, const char* path, Device device) {
@@ -34,10 +45,126 @@ const char* Torch_Jit_Load(JitModule* module, const char* path, Device device) {
     });
 }
 
+const char* Torch_Jit_Freeze(
+    JitModule* output,
+    JitModule module,
+    const char** preserved_attrs,
+    int64_t num_preserved_attrs
+) {
+    return try_catch_return_error_string([&]() {
+        std::vector<std::string> attrs(preserved_attrs, preserved_attrs + num_preserved_attrs);
+        *output = new torch::jit::Module(torch::jit::freeze(*module, attrs));
+    });
+}
+
+const char* Torch_Jit_OptimizeForInference(
+    JitModule* output,
+    JitModule module,
+    const char** other_methods,
+    int64_t num_other_methods
+) {
+    return try_catch_return_error_string([&]() {
+        std::vector<std::string> methods(other_methods, other_methods + num_other_methods);
+        *output = new torch::jit::Module(torch::jit::optimize_for_inference(*module, methods));
+    });
+}
+
+const char* Torch_Jit_FoldConvBatchNorm(JitModule* output, JitModule module) {
+    return try_catch_return_error_string([&]() {
+        *output = new torch::jit::Module(torch::jit::FoldConvBatchNorm(*module));
+    });
+}
+
+const char* Torch_Jit_SetFusionStrategy(int32_t* behaviors, int64_t* depths, int64_t num_stages) {
+    return try_catch_return_error_string([&]() {
+        torch::jit::FusionStrategy strategy;
+        for (int64_t i = 0; i < num_stages; i++) {
+            auto behavior = behaviors[i] == 0 ?
+                torch::jit::FusionBehavior::STATIC : torch::jit::FusionBehavior::DYNAMIC;
+            strategy.emplace_back(behavior, depths[i]);
+        }
+        torch::jit::setFusionStrategy(strategy);
+    });
+}
+
+bool Torch_Jit_SetProfilingMode(bool mode) {
+    return torch::jit::getProfilingMode().exchange(mode);
+}
+
+bool Torch_Jit_SetProfilingExecutor(bool mode) {
+    return torch::jit::getExecutorMode().exchange(mode);
+}
+
+/// @brief A reader over a buffer in memory that reads it in place instead of
+/// copying it into a stream.
+class MemoryReadAdapter final : public caffe2::serialize::ReadAdapterInterface {
+ public:
+    MemoryReadAdapter(const void* data, size_t size) :
+        data_(static_cast<const char*>(data)), size_(size) { }
+
+    size_t size() const override { return size_; }
+
+    size_t read(uint64_t pos, void* buf, size_t n, const char* what = "") const override {
+        if (pos >= size_) return 0;
+        n = std::min(n, static_cast<size_t>(size_ - pos));
+        std::memcpy(buf, data_ + pos, n);
+        return n;
+    }
+
+ private:
+    const char* data_;
+    size_t size_;
+};
+
+const char* Torch_Jit_LoadFromBuffer(JitModule* module, void* data, int64_t size, Device device) {
+    return try_catch_return_error_string([&]() {
+        auto reader = std::make_shared<MemoryReadAdapter>(data, static_cast<size_t>(size));
+        *module = new torch::jit::Module(torch::jit::load(reader, *device));
+    });
+}
+
 void Torch_Jit_Module_Free(JitModule module) { delete module; }
 
-const char* Torch_Jit_Module_Save(const char* path, JitModule module) {
-    return try_catch_return_error_string([&]() { module->save(path); });
+/// @brief Collect the names and contents of extra files into a map.
+static torch::jit::ExtraFilesMap collect_extra_files(
+    const char** extra_names,
+    void** extra_data,
+    int64_t* extra_sizes,
+    int64_t num_extra
+) {
+    torch::jit::ExtraFilesMap files;
+    for (int64_t i = 0; i < num_extra; i++)
+        files[extra_names[i]] = std::string(static_cast<const char*>(extra_data[i]), extra_sizes[i]);
+    return files;
+}
+
+const char* Torch_Jit_Module_Save(
+    const char* path,
+    JitModule module,
+    const char** extra_names,
+    void** extra_data,
+    int64_t* extra_sizes,
+    int64_t num_extra
+) {
+    return try_catch_return_error_string([&]() {
+        module->save(path, collect_extra_files(extra_names, extra_data, extra_sizes, num_extra));
+    });
+}
+
+const char* Torch_Jit_Module_SaveToBuffer(
+    ByteBuffer* output,
+    JitModule module,
+    const char** extra_names,
+    void** extra_data,
+    int64_t* extra_sizes,
+    int64_t num_extra
+) {
+    return try_catch_return_error_string([&]() {
+        std::ostringstream stream;
+        module->save(stream, collect_extra_files(extra_names, extra_data, extra_sizes, num_extra));
+        auto str = stream.str();
+        *output = new std::vector<char>(str.begin(), str.end());
+    });
 }
 
 const char* Torch_Jit_Module_String(JitModule module) {
@@ -75,9 +202,306 @@ const char* Torch_Jit_Module_CopyTo(JitModule module, Device device) {
     return try_catch_return_error_string([&]() { module->to(*device); });
 }
 
-// TODO: Torch_Jit_Module_Copy
-// TODO: Torch_Jit_Module_DeepCopy
-// TODO: Torch_Jit_Module_Clone
+const char* Torch_Jit_Module_To(JitModule module, Device device, int8_t dtype, bool non_blocking) {
+    return try_catch_return_error_string([&]() {
+        module->to(*device, static_cast<at::ScalarType>(dtype), non_blocking);
+    });
+}
+
+const char* Torch_Jit_Module_Copy(JitModule* output, JitModule module) {
+    return try_catch_return_error_string([&]() { *output = new torch::jit::Module(module->copy()); });
+}
+
+const char* Torch_Jit_Module_DeepCopy(JitModule* output, JitModule module) {
+    return try_catch_return_error_string([&]() { *output = new torch::jit::Module(module->deepcopy()); });
+}
+
+const char* Torch_Jit_Module_Clone(JitModule* output, JitModule module, bool inplace) {
+    return try_catch_return_error_string([&]() { *output = new torch::jit::Module(module->clone(inplace)); });
+}
+
+/// @brief Copy named tensors into output buffers of names and tensors.
+template<typename T>
+static void copy_named_tensors(char** names, Tensor* tensors, int64_t num_datums, const T& list) {
+    throw_on_size_mismatch(num_datums, list.size());
+    int64_t i = 0;
+    for (const auto& item : list) {
+        names[i] = copy_to_c_string(item.name);
+        tensors[i] = new torch::Tensor(item.value);
+        i++;
+    }
+}
+
+const char* Torch_Jit_Module_TypeName(char** output, JitModule module) {
+    return try_catch_return_error_string([&]() {
+        *output = copy_to_c_string(module->type()->name()->qualifiedName());
+    });
+}
+
+const char* Torch_Jit_Module_NumMethods(int64_t* output, JitModule module) {
+    return try_catch_return_error_string([&]() {
+        *output = module->get_methods().size();
+    });
+}
+
+const char* Torch_Jit_Module_MethodNames(char** names, int64_t num_datums, JitModule module) {
+    return try_catch_return_error_string([&]() {
+        auto methods = module->get_methods();
+        throw_on_size_mismatch(num_datums, methods.size());
+        for (int64_t i = 0; i < num_datums; i++)
+            names[i] = copy_to_c_string(methods[i].name());
+    });
+}
+
+const char* Torch_Jit_Module_HasMethod(bool* output, JitModule module, const char* method) {
+    return try_catch_return_error_string([&]() {
+        *output = module->find_method(method).has_value();
+    });
+}
+
+/// @brief Collect positional and keyword inputs into a stack and a map.
+static void collect_inputs(
+    std::vector<torch::jit::IValue>& stack,
+    torch::jit::Kwargs& kwargs,
+    IValue* inputs,
+    int64_t num_inputs,
+    const char** kwarg_names,
+    IValue* kwarg_values,
+    int64_t num_kwargs
+) {
+    for (int64_t i = 0; i < num_inputs; i++) stack.push_back(*inputs[i]);
+    for (int64_t i = 0; i < num_kwargs; i++) kwargs[kwarg_names[i]] = *kwarg_values[i];
+}
+
+const char* Torch_Jit_Module_CheckMethodInputs(
+    JitModule module,
+    const char* method,
+    IValue* inputs,
+    int64_t num_inputs,
+    const char** kwarg_names,
+    IValue* kwarg_values,
+    int64_t num_kwargs
+) {
+    return try_catch_return_error_string([&]() {
+        auto function = module->get_method(method);
+        // The schema includes self as the first argument.
+        std::vector<torch::jit::IValue> stack = {module->_ivalue()};
+        torch::jit::Kwargs kwargs;
+        collect_inputs(stack, kwargs, inputs, num_inputs, kwarg_names, kwarg_values, num_kwargs);
+        function.function().getSchema().checkAndNormalizeInputs(stack, kwargs);
+    });
+}
+
+const char* Torch_Jit_Module_RunMethod(
+    IValue* output,
+    JitModule module,
+    const char* method,
+    IValue* inputs,
+    int64_t num_inputs,
+    const char** kwarg_names,
+    IValue* kwarg_values,
+    int64_t num_kwargs
+) {
+    return try_catch_return_error_string([&]() {
+        std::vector<torch::jit::IValue> stack;
+        torch::jit::Kwargs kwargs;
+        collect_inputs(stack, kwargs, inputs, num_inputs, kwarg_names, kwarg_values, num_kwargs);
+        *output = new torch::IValue(module->get_method(method)(std::move(stack), kwargs));
+    });
+}
+
+const char* Torch_Jit_Compile(CompilationUnit* output, const char* source) {
+    return try_catch_return_error_string([&]() {
+        *output = new std::shared_ptr<torch::jit::CompilationUnit>(torch::jit::compile(source));
+    });
+}
+
+void Torch_Jit_CompilationUnit_Free(CompilationUnit unit) { delete unit; }
+
+const char* Torch_Jit_CompilationUnit_NumFunctions(int64_t* output, CompilationUnit unit) {
+    return try_catch_return_error_string([&]() { *output = (*unit)->get_functions().size(); });
+}
+
+const char* Torch_Jit_CompilationUnit_FunctionNames(char** names, int64_t num_datums, CompilationUnit unit) {
+    return try_catch_return_error_string([&]() {
+        auto functions = (*unit)->get_functions();
+        throw_on_size_mismatch(num_datums, functions.size());
+        for (int64_t i = 0; i < num_datums; i++)
+            names[i] = copy_to_c_string(functions[i]->name());
+    });
+}
+
+const char* Torch_Jit_CompilationUnit_HasFunction(bool* output, CompilationUnit unit, const char* function) {
+    return try_catch_return_error_string([&]() {
+        *output = (*unit)->find_function(function) != nullptr;
+    });
+}
+
+const char* Torch_Jit_CompilationUnit_RunFunction(
+    IValue* output,
+    CompilationUnit unit,
+    const char* function,
+    IValue* inputs,
+    int64_t num_inputs,
+    const char** kwarg_names,
+    IValue* kwarg_values,
+    int64_t num_kwargs
+) {
+    return try_catch_return_error_string([&]() {
+        std::vector<torch::jit::IValue> stack;
+        torch::jit::Kwargs kwargs;
+        collect_inputs(stack, kwargs, inputs, num_inputs, kwarg_names, kwarg_values, num_kwargs);
+        *output = new torch::IValue((*unit)->get_function(function)(std::move(stack), kwargs));
+    });
+}
+
+const char* Torch_Jit_Module_Define(JitModule module, const char* source) {
+    return try_catch_return_error_string([&]() { module->define(source); });
+}
+
+const char* Torch_Jit_Module_MethodSchemaSize(
+    int64_t* num_arguments,
+    int64_t* num_returns,
+    JitModule module,
+    const char* method
+) {
+    return try_catch_return_error_string([&]() {
+        const auto& schema = module->get_method(method).function().getSchema();
+        *num_arguments = schema.arguments().size();
+        *num_returns = schema.returns().size();
+    });
+}
+
+const char* Torch_Jit_Module_MethodSchema(
+    char** names,
+    char** types,
+    IValue* defaults,
+    int64_t num_arguments,
+    char** return_names,
+    char** return_types,
+    int64_t num_returns,
+    JitModule module,
+    const char* method
+) {
+    return try_catch_return_error_string([&]() {
+        const auto& schema = module->get_method(method).function().getSchema();
+        const auto& arguments = schema.arguments();
+        const auto& returns = schema.returns();
+        throw_on_size_mismatch(num_arguments, arguments.size());
+        throw_on_size_mismatch(num_returns, returns.size());
+        for (int64_t i = 0; i < num_arguments; i++) {
+            names[i] = copy_to_c_string(arguments[i].name());
+            types[i] = copy_to_c_string(arguments[i].type()->annotation_str());
+            defaults[i] = arguments[i].default_value().has_value() ?
+                new torch::IValue(*arguments[i].default_value()) : nullptr;
+        }
+        for (int64_t i = 0; i < num_returns; i++) {
+            return_names[i] = copy_to_c_string(returns[i].name());
+            return_types[i] = copy_to_c_string(returns[i].type()->annotation_str());
+        }
+    });
+}
+
+const char* Torch_Jit_Module_MethodGraph(char** output, JitModule module, const char* method) {
+    return try_catch_return_error_string([&]() {
+        auto graph = module->get_method(method).graph()->copy();
+        torch::jit::Inline(*graph);
+        *output = copy_to_c_string(graph->toString());
+    });
+}
+
+const char* Torch_Jit_Module_NumParameters(int64_t* output, JitModule module, bool recurse) {
+    return try_catch_return_error_string([&]() {
+        *output = module->named_parameters(recurse).size();
+    });
+}
+
+const char* Torch_Jit_Module_NamedParameters(
+    char** names,
+    Tensor* tensors,
+    int64_t num_datums,
+    JitModule module,
+    bool recurse
+) {
+    return try_catch_return_error_string([&]() {
+        copy_named_tensors(names, tensors, num_datums, module->named_parameters(recurse));
+    });
+}
+
+const char* Torch_Jit_Module_NumBuffers(int64_t* output, JitModule module, bool recurse) {
+    return try_catch_return_error_string([&]() {
+        *output = module->named_buffers(recurse).size();
+    });
+}
+
+const char* Torch_Jit_Module_NamedBuffers(
+    char** names,
+    Tensor* tensors,
+    int64_t num_datums,
+    JitModule module,
+    bool recurse
+) {
+    return try_catch_return_error_string([&]() {
+        copy_named_tensors(names, tensors, num_datums, module->named_buffers(recurse));
+    });
+}
+
+const char* Torch_Jit_Module_NumChildren(int64_t* output, JitModule module) {
+    return try_catch_return_error_string([&]() {
+        *output = module->named_children().size();
+    });
+}
+
+const char* Torch_Jit_Module_NamedChildren(
+    char** names,
+    JitModule* children,
+    int64_t num_datums,
+    JitModule module
+) {
+    return try_catch_return_error_string([&]() {
+        auto list = module->named_children();
+        throw_on_size_mismatch(num_datums, list.size());
+        int64_t i = 0;
+        for (const auto& item : list) {
+            names[i] = copy_to_c_string(item.name);
+            children[i] = new torch::jit::Module(item.value);
+            i++;
+        }
+    });
+}
+
+const char* Torch_Jit_Module_HasAttr(bool* output, JitModule module, const char* name) {
+    return try_catch_return_error_string([&]() { *output = module->hasattr(name); });
+}
+
+const char* Torch_Jit_Module_Attr(IValue* output, JitModule module, const char* name) {
+    return try_catch_return_error_string([&]() { *output = new torch::IValue(module->attr(name)); });
+}
+
+const char* Torch_Jit_Module_SetAttr(JitModule module, const char* name, IValue value) {
+    return try_catch_return_error_string([&]() { module->setattr(name, *value); });
+}
+
+const char* Torch_Jit_Module_RegisterParameter(JitModule module, const char* name, Tensor tensor, bool is_buffer) {
+    return try_catch_return_error_string([&]() {
+        if (is_buffer)
+            module->register_buffer(name, *tensor);
+        else
+            module->register_parameter(name, *tensor, false);
+    });
+}
+
+const char* Torch_Jit_Module_ToIValue(IValue* output, JitModule module) {
+    return try_catch_return_error_string([&]() { *output = new torch::IValue(module->_ivalue()); });
+}
+
+const char* Torch_Jit_Module_FromIValue(JitModule* output, IValue ivalue) {
+    return try_catch_return_error_string([&]() {
+        if (!ivalue->isModule())
+            throw std::runtime_error("Expected IValue to hold a module but received " + ivalue->tagKind());
+        *output = new torch::jit::Module(ivalue->toObject());
+    });
+}
 
 const char* Torch_Jit_Module_Forward(
     IValue* output,
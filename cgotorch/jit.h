@@ -31,7 +31,115 @@
 extern "C" {
 #endif
 
-// TODO: const char* Torch_Jit_OptimizeForInference
+/// @brief Freeze a module by inlining its parameters, attributes, and
+/// submodules into its graphs as constants.
+/// @param output A pointer to a pointer to initialize with the frozen module.
+/// @param module The module to freeze. It must be in evaluation mode.
+/// @param preserved_attrs The names of attributes and methods to preserve.
+/// @param num_preserved_attrs The number of preserved attributes.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Freeze(
+    JitModule* output,
+    JitModule module,
+    const char** preserved_attrs,
+    int64_t num_preserved_attrs
+);
+
+/// @brief Freeze a module, if it is not already frozen, and apply graph
+/// optimizations for inference, e.g., folding convolutions with batch norms.
+/// @param output A pointer to a pointer to initialize with the optimized module.
+/// @param module The module to optimize. It must be in evaluation mode.
+/// @param other_methods The names of methods to optimize besides forward.
+/// @param num_other_methods The number of other methods.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_OptimizeForInference(
+    JitModule* output,
+    JitModule module,
+    const char** other_methods,
+    int64_t num_other_methods
+);
+
+/// @brief Fold batch normalization layers into preceding convolutions.
+/// @param output A pointer to a pointer to initialize with the folded module.
+/// @param module The module to fold. It must be in evaluation mode.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_FoldConvBatchNorm(JitModule* output, JitModule module);
+
+/// @brief Set the fusion strategy of the profiling executor.
+/// @param behaviors The fusion behavior of each stage, 0 for static shapes
+/// and 1 for dynamic shapes.
+/// @param depths The number of specializations of each stage.
+/// @param num_stages The number of stages in the strategy.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_SetFusionStrategy(int32_t* behaviors, int64_t* depths, int64_t num_stages);
+
+/// @brief Enable/disable profiling of tensor shapes by the graph executor.
+/// @param mode True to enable profiling, false to disable it.
+/// @returns The previous profiling mode.
+bool Torch_Jit_SetProfilingMode(bool mode);
+
+/// @brief Enable/disable the profiling graph executor.
+/// @param mode True to use the profiling executor, false to use the legacy
+/// executor.
+/// @returns The previous executor mode.
+bool Torch_Jit_SetProfilingExecutor(bool mode);
+
+/// @brief Compile TorchScript source code defining free functions.
+/// @param output A pointer to a pointer to initialize with the compilation
+/// unit that holds the functions.
+/// @param source The TorchScript source code, e.g., "def add(x, y): return
+/// x + y".
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Compile(CompilationUnit* output, const char* source);
+
+/// @brief Free the heap memory used to hold the given CompilationUnit.
+/// @param unit The CompilationUnit to free from the heap.
+void Torch_Jit_CompilationUnit_Free(CompilationUnit unit);
+
+/// @brief Return the number of functions of a compilation unit.
+/// @param output A pointer to an integer to populate with the count.
+/// @param unit The compilation unit to count the functions of.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_CompilationUnit_NumFunctions(int64_t* output, CompilationUnit unit);
+
+/// @brief Return the names of the functions of a compilation unit.
+/// @param names A buffer to populate with the names of the functions.
+/// @param num_datums The length of the buffer.
+/// @param unit The compilation unit to return the function names of.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Ownership of the names is transferred to the caller. Names should be
+/// released using `std::free` when done.
+const char* Torch_Jit_CompilationUnit_FunctionNames(char** names, int64_t num_datums, CompilationUnit unit);
+
+/// @brief Check if a compilation unit has a function.
+/// @param output true if the compilation unit has the function, false
+/// otherwise.
+/// @param unit The compilation unit to check for the function.
+/// @param function The name of the function.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_CompilationUnit_HasFunction(bool* output, CompilationUnit unit, const char* function);
+
+/// @brief Run a function of a compilation unit.
+/// @param output A pointer to a pointer to initialize with the output.
+/// @param unit The compilation unit that owns the function.
+/// @param function The name of the function.
+/// @param inputs The positional inputs to the function.
+/// @param num_inputs The number of positional inputs.
+/// @param kwarg_names The names of the keyword inputs.
+/// @param kwarg_values The values of the keyword inputs.
+/// @param num_kwargs The number of keyword inputs.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_CompilationUnit_RunFunction(
+    IValue* output,
+    CompilationUnit unit,
+    const char* function,
+    IValue* inputs,
+    int64_t num_inputs,
+    const char** kwarg_names,
+    IValue* kwarg_values,
+    int64_t num_kwargs
+);
 
 /// @brief Load a module trace from the file-system.
 /// @param output A pointer to a pointer to initialize with the module.
@@ -40,6 +148,16 @@ extern "C" {
 /// @returns A pointer to a string error message (nullptr on success.)
 const char* Torch_Jit_Load(JitModule* output, const char* path, Device device);
 
+/// @brief Load a module trace from a buffer in memory.
+/// @param output A pointer to a pointer to initialize with the module.
+/// @param data A pointer to the serialized module, i.e., the contents of a
+/// file created by `torch.jit.save`.
+/// @param size The number of bytes in the buffer. The buffer is read in place
+/// and is not referenced once the function returns.
+/// @param device The device to load the module onto.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_LoadFromBuffer(JitModule* output, void* data, int64_t size, Device device);
+
 /// @brief Free the heap memory used to hold the given JitModule.
 /// @param module The JitModule to free from the heap.
 void Torch_Jit_Module_Free(JitModule module);
@@ -47,8 +165,39 @@ void Torch_Jit_Module_Free(JitModule module);
 /// @brief Save a module trace to the file-system.
 /// @param path A path on the file-system to save the module to.
 /// @param module The module to save.
+/// @param extra_names The names of extra files to store in the archive.
+/// @param extra_data The contents of the extra files.
+/// @param extra_sizes The number of bytes in each extra file.
+/// @param num_extra The number of extra files.
 /// @returns A pointer to a string error message (nullptr on success.)
-const char* Torch_Jit_Module_Save(const char* path, JitModule module);
+const char* Torch_Jit_Module_Save(
+    const char* path,
+    JitModule module,
+    const char** extra_names,
+    void** extra_data,
+    int64_t* extra_sizes,
+    int64_t num_extra
+);
+
+/// @brief Save a module trace to a buffer in memory.
+/// @param output A pointer to a byte buffer to allocate with the archive.
+/// @param module The module to save.
+/// @param extra_names The names of extra files to store in the archive.
+/// @param extra_data The contents of the extra files.
+/// @param extra_sizes The number of bytes in each extra file.
+/// @param num_extra The number of extra files.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Ownership of the byte buffer is transferred to the caller. The buffer
+/// should be released using `ByteBuffer_Free` when done.
+const char* Torch_Jit_Module_SaveToBuffer(
+    ByteBuffer* output,
+    JitModule module,
+    const char** extra_names,
+    void** extra_data,
+    int64_t* extra_sizes,
+    int64_t num_extra
+);
 
 /// @brief Convert the module to a string representation.
 /// @param a The module to convert into a string
@@ -59,6 +208,16 @@ const char* Torch_Jit_Module_Save(const char* path, JitModule module);
 /// `delete` operator to free the memory, it is allocated in C using `malloc`.
 const char* Torch_Jit_Module_String(JitModule module);
 
+/// @brief Copy the module to the given device and cast it to the given
+/// data-type in-place.
+/// @param module The module to convert.
+/// @param device The accelerator to copy the parameter data onto.
+/// @param dtype The data-type to cast the module's parameters to.
+/// @param non_blocking True to copy asynchronously with respect to the host
+/// when possible, e.g., from pinned memory to a CUDA device.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_To(JitModule module, Device device, int8_t dtype, bool non_blocking);
+
 /// @brief Check if a module has training features enabled.
 /// @param output true if training features are enabled, false otherwise.
 /// @param module The module to check the training setting of.
@@ -102,9 +261,285 @@ const char* Torch_Jit_Module_CastTo(JitModule module, int8_t dtype);
 /// @returns A pointer to a string error message (nullptr on success.)
 const char* Torch_Jit_Module_CopyTo(JitModule module, Device device);
 
-// TODO: const char* Torch_Jit_Module_Copy(JitModule* output, JitModule module)
-// TODO: const char* Torch_Jit_Module_DeepCopy(JitModule* output, JitModule module)
-// TODO: const char* Torch_Jit_Module_Clone(JitModule* output, JitModule module)
+/// @brief Return the qualified name of the class of a module.
+/// @param output A pointer to a string to allocate with the name.
+/// @param module The module to return the class name of.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Ownership of the output string is transferred to the caller. The memory
+/// should be released using `std::free` when done.
+const char* Torch_Jit_Module_TypeName(char** output, JitModule module);
+
+/// @brief Return the number of methods of a module.
+/// @param output A pointer to an integer to populate with the count.
+/// @param module The module to count the methods of.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_NumMethods(int64_t* output, JitModule module);
+
+/// @brief Return the names of the methods of a module.
+/// @param names A buffer to populate with the names of the methods.
+/// @param num_datums The length of the buffer.
+/// @param module The module to return the method names of.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Ownership of the names is transferred to the caller. Names should be
+/// released using `std::free` when done.
+const char* Torch_Jit_Module_MethodNames(char** names, int64_t num_datums, JitModule module);
+
+/// @brief Check if a module has a method.
+/// @param output true if the module has the method, false otherwise.
+/// @param module The module to check for the method.
+/// @param method The name of the method.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_HasMethod(bool* output, JitModule module, const char* method);
+
+/// @brief Check inputs against the schema of a method without running it.
+/// @param module The module that owns the method.
+/// @param method The name of the method.
+/// @param inputs The positional inputs to the method, excluding `self`.
+/// @param num_inputs The number of positional inputs.
+/// @param kwarg_names The names of the keyword inputs.
+/// @param kwarg_values The values of the keyword inputs.
+/// @param num_kwargs The number of keyword inputs.
+/// @returns A pointer to a string error message (nullptr if the inputs are
+/// valid.)
+const char* Torch_Jit_Module_CheckMethodInputs(
+    JitModule module,
+    const char* method,
+    IValue* inputs,
+    int64_t num_inputs,
+    const char** kwarg_names,
+    IValue* kwarg_values,
+    int64_t num_kwargs
+);
+
+/// @brief Run a method of a module.
+/// @param output A pointer to a pointer to initialize with the output.
+/// @param module The module that owns the method.
+/// @param method The name of the method.
+/// @param inputs The positional inputs to the method, excluding `self`.
+/// @param num_inputs The number of positional inputs.
+/// @param kwarg_names The names of the keyword inputs.
+/// @param kwarg_values The values of the keyword inputs.
+/// @param num_kwargs The number of keyword inputs.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_RunMethod(
+    IValue* output,
+    JitModule module,
+    const char* method,
+    IValue* inputs,
+    int64_t num_inputs,
+    const char** kwarg_names,
+    IValue* kwarg_values,
+    int64_t num_kwargs
+);
+
+/// @brief Return the number of arguments and return values of a method.
+/// @param num_arguments A pointer to an integer to populate with the number
+/// of arguments, including `self`.
+/// @param num_returns A pointer to an integer to populate with the number of
+/// return values.
+/// @param module The module that owns the method.
+/// @param method The name of the method.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_MethodSchemaSize(
+    int64_t* num_arguments,
+    int64_t* num_returns,
+    JitModule module,
+    const char* method
+);
+
+/// @brief Return the schema of a method.
+/// @param names A buffer to populate with the names of the arguments.
+/// @param types A buffer to populate with the types of the arguments.
+/// @param defaults A buffer to populate with the default values of the
+/// arguments, or nullptr for arguments without a default.
+/// @param num_arguments The length of the argument buffers.
+/// @param return_names A buffer to populate with the names of the returns.
+/// @param return_types A buffer to populate with the types of the returns.
+/// @param num_returns The length of the return buffers.
+/// @param module The module that owns the method.
+/// @param method The name of the method.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Ownership of the strings and default values is transferred to the
+/// caller. Strings should be released using `std::free` when done.
+const char* Torch_Jit_Module_MethodSchema(
+    char** names,
+    char** types,
+    IValue* defaults,
+    int64_t num_arguments,
+    char** return_names,
+    char** return_types,
+    int64_t num_returns,
+    JitModule module,
+    const char* method
+);
+
+/// @brief Return the graph of a method with all function calls inlined.
+/// @param output A pointer to a string to allocate with the graph.
+/// @param module The module that owns the method.
+/// @param method The name of the method.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Ownership of the output string is transferred to the caller. The memory
+/// should be released using `std::free` when done.
+const char* Torch_Jit_Module_MethodGraph(char** output, JitModule module, const char* method);
+
+/// @brief Return the number of parameters of a module.
+/// @param output A pointer to an integer to populate with the count.
+/// @param module The module to count the parameters of.
+/// @param recurse True to include the parameters of submodules.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_NumParameters(int64_t* output, JitModule module, bool recurse);
+
+/// @brief Return the names and values of the parameters of a module.
+/// @param names A buffer to populate with the names of the parameters.
+/// @param tensors A buffer to populate with the values of the parameters.
+/// @param num_datums The length of the buffers.
+/// @param module The module to return the parameters of.
+/// @param recurse True to include the parameters of submodules.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Ownership of the names and tensors is transferred to the caller. Names
+/// should be released using `std::free` when done.
+const char* Torch_Jit_Module_NamedParameters(
+    char** names,
+    Tensor* tensors,
+    int64_t num_datums,
+    JitModule module,
+    bool recurse
+);
+
+/// @brief Return the number of buffers of a module.
+/// @param output A pointer to an integer to populate with the count.
+/// @param module The module to count the buffers of.
+/// @param recurse True to include the buffers of submodules.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_NumBuffers(int64_t* output, JitModule module, bool recurse);
+
+/// @brief Return the names and values of the buffers of a module.
+/// @param names A buffer to populate with the names of the buffers.
+/// @param tensors A buffer to populate with the values of the buffers.
+/// @param num_datums The length of the buffers.
+/// @param module The module to return the buffers of.
+/// @param recurse True to include the buffers of submodules.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Ownership of the names and tensors is transferred to the caller. Names
+/// should be released using `std::free` when done.
+const char* Torch_Jit_Module_NamedBuffers(
+    char** names,
+    Tensor* tensors,
+    int64_t num_datums,
+    JitModule module,
+    bool recurse
+);
+
+/// @brief Return the number of immediate submodules of a module.
+/// @param output A pointer to an integer to populate with the count.
+/// @param module The module to count the submodules of.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_NumChildren(int64_t* output, JitModule module);
+
+/// @brief Return the names and handles of the immediate submodules of a module.
+/// @param names A buffer to populate with the names of the submodules.
+/// @param children A buffer to populate with the submodules.
+/// @param num_datums The length of the buffers.
+/// @param module The module to return the submodules of.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// The submodules share their state with the module. Ownership of the names
+/// and submodule handles is transferred to the caller. Names should be
+/// released using `std::free` when done.
+const char* Torch_Jit_Module_NamedChildren(
+    char** names,
+    JitModule* children,
+    int64_t num_datums,
+    JitModule module
+);
+
+/// @brief Check if a module has an attribute, e.g., a parameter, buffer,
+/// submodule, or constant.
+/// @param output true if the module has the attribute, false otherwise.
+/// @param module The module to check for the attribute.
+/// @param name The name of the attribute.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_HasAttr(bool* output, JitModule module, const char* name);
+
+/// @brief Return the value of an attribute of a module.
+/// @param output A pointer to a pointer to initialize with the value.
+/// @param module The module to return the attribute of.
+/// @param name The name of the attribute.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_Attr(IValue* output, JitModule module, const char* name);
+
+/// @brief Set the value of an existing attribute of a module.
+/// @param module The module to set the attribute of.
+/// @param name The name of the attribute.
+/// @param value The new value of the attribute. Its type must be compatible
+/// with the declared type of the attribute.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_SetAttr(JitModule module, const char* name, IValue value);
+
+/// @brief Register a parameter or buffer with a module.
+/// @param module The module to register the tensor with.
+/// @param name The name of the parameter or buffer.
+/// @param tensor The value of the parameter or buffer.
+/// @param is_buffer True to register a buffer, false to register a parameter.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// Registering a tensor with the name of an existing parameter or buffer
+/// replaces its value.
+const char* Torch_Jit_Module_RegisterParameter(JitModule module, const char* name, Tensor tensor, bool is_buffer);
+
+/// @brief Convert a module to an IValue holding its underlying object.
+/// @param output A pointer to a pointer to initialize with the IValue.
+/// @param module The module to convert.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_ToIValue(IValue* output, JitModule module);
+
+/// @brief Convert an IValue holding a module object to a module.
+/// @param output A pointer to a pointer to initialize with the module.
+/// @param ivalue The IValue to convert.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_FromIValue(JitModule* output, IValue ivalue);
+
+/// @brief Compile TorchScript source code defining methods of a module.
+/// @param module The module to add the methods to.
+/// @param source The TorchScript source code, e.g., "def scale(self, x):
+/// return x * self.alpha". Methods may refer to attributes and other methods
+/// of the module through `self`.
+/// @returns A pointer to a string error message (nullptr on success.)
+const char* Torch_Jit_Module_Define(JitModule module, const char* source);
+
+/// @brief Create a shallow copy of a module.
+/// @param output A pointer to a pointer to initialize with the copy.
+/// @param module The module to copy.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// The copy has its own attribute slots but shares its parameters, buffers,
+/// and submodules with the module.
+const char* Torch_Jit_Module_Copy(JitModule* output, JitModule module);
+
+/// @brief Create a deep copy of a module.
+/// @param output A pointer to a pointer to initialize with the copy.
+/// @param module The module to copy.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// The copy has its own parameters, buffers, and submodules.
+const char* Torch_Jit_Module_DeepCopy(JitModule* output, JitModule module);
+
+/// @brief Clone a module and its type.
+/// @param output A pointer to a pointer to initialize with the clone.
+/// @param module The module to clone.
+/// @param inplace True to share the parameters and buffers of the module
+/// with the clone, false to copy them.
+/// @returns A pointer to a string error message (nullptr on success.)
+/// @details
+/// The clone has a type of its own, so methods that are defined on the clone
+/// are not visible on the module.
+const char* Torch_Jit_Module_Clone(JitModule* output, JitModule module, bool inplace);
 
 /// @brief Forward pass data through a JIT module.s
 /// @param module The module to forward pass through.
@@ -0,0 +1,87 @@
+// C bindings for tracking libtorch CPU memory allocations.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+#include "cgotorch/memory.h"
+#include <atomic>
+#include <c10/core/CPUAllocator.h>
+#include "cgotorch/try_catch_return_error_string.hpp"
+
+static std::atomic<int64_t> live_bytes{0};
+static std::atomic<int64_t> peak_bytes{0};
+static std::atomic<int64_t> live_allocations{0};
+
+/// @brief An allocation from the wrapped allocator and its size in bytes.
+struct TrackedAllocation {
+    c10::DataPtr data;
+    int64_t size;
+};
+
+/// @brief Release a tracked allocation and remove it from the statistics.
+static void DeleteTrackedAllocation(void* context) {
+    auto allocation = static_cast<TrackedAllocation*>(context);
+    live_bytes -= allocation->size;
+    live_allocations--;
+    delete allocation;  // Destroying the DataPtr frees the memory.
+}
+
+/// @brief An allocator that counts the memory allocated by another allocator.
+class TrackingAllocator final : public c10::Allocator {
+ public:
+    explicit TrackingAllocator(c10::Allocator* base) : base_(base) { }
+
+    c10::DataPtr allocate(size_t size) const override {
+        c10::DataPtr data = base_->allocate(size);
+        void* pointer = data.get();
+        c10::Device device = data.device();
+        auto allocation = new TrackedAllocation{std::move(data), static_cast<int64_t>(size)};
+        int64_t live = live_bytes += allocation->size;
+        live_allocations++;
+        // Raise the peak to the current live bytes if it is lower.
+        int64_t peak = peak_bytes.load();
+        while (live > peak && !peak_bytes.compare_exchange_weak(peak, live)) { }
+        return {pointer, allocation, &DeleteTrackedAllocation, device};
+    }
+
+ private:
+    c10::Allocator* base_;
+};
+
+const char* Torch_Memory_InstallTrackingAllocator() {
+    return try_catch_return_error_string([&] () {
+        static TrackingAllocator* allocator = nullptr;
+        if (allocator != nullptr) return;
+        allocator = new TrackingAllocator(c10::GetCPUAllocator());
+        // Use a higher priority than the default allocator to replace it.
+        c10::SetCPUAllocator(allocator, /*priority=*/1);
+    });
+}
+
+int64_t Torch_Memory_LiveBytes() { return live_bytes.load(); }
+
+void Torch_Memory_Stats(int64_t* live, int64_t* peak, int64_t* allocations) {
+    *live = live_bytes.load();
+    *peak = peak_bytes.load();
+    *allocations = live_allocations.load();
+}
+
+void Torch_Memory_ResetPeakStats() { peak_bytes = live_bytes.load(); }
@@ -0,0 +1,51 @@
+// C bindings for tracking libtorch CPU memory allocations.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+#pragma once
+
+#include "cgotorch/torchdef.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/// @brief Replace the libtorch CPU allocator with one that tracks the number
+/// of bytes and allocations that are live. Allocations made before the
+/// tracking allocator is installed are not counted.
+const char* Torch_Memory_InstallTrackingAllocator();
+
+/// @brief Return the number of bytes currently allocated on the CPU.
+int64_t Torch_Memory_LiveBytes();
+
+/// @brief Return statistics about CPU memory allocated by libtorch.
+/// @param live_bytes The output number of bytes currently allocated.
+/// @param peak_bytes The output maximum number of bytes allocated at once.
+/// @param live_allocations The output number of allocations currently live.
+void Torch_Memory_Stats(int64_t* live_bytes, int64_t* peak_bytes, int64_t* live_allocations);
+
+/// @brief Reset the peak number of bytes to the number of live bytes.
+void Torch_Memory_ResetPeakStats();
+
+#ifdef __cplusplus
+}
+#endif
@@ -0,0 +1,110 @@
+// C bindings for TorchScript objects and enums held by c10::IValue.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+#include <torch/script.h>
+#include <string>
+#include "cgotorch/object.h"
+#include "cgotorch/output_buffers.hpp"
+#include "cgotorch/try_catch_return_error_string.hpp"
+
+/// @brief Return the object held by an IValue.
+static torch::jit::Object to_object(IValue ivalue) {
+    if (!ivalue->isObject())
+        throw std::runtime_error("Expected IValue to hold an object but received " + ivalue->tagKind());
+    return torch::jit::Object(ivalue->toObject());
+}
+
+// MARK: Objects
+
+const char* Torch_Object_TypeName(char** output, IValue object) {
+    return try_catch_return_error_string([&]() {
+        *output = copy_to_c_string(to_object(object).type()->name()->qualifiedName());
+    });
+}
+
+const char* Torch_Object_NumAttrs(int64_t* output, IValue object) {
+    return try_catch_return_error_string([&]() { *output = to_object(object).type()->numAttributes(); });
+}
+
+const char* Torch_Object_AttrNames(char** names, int64_t num_datums, IValue object) {
+    return try_catch_return_error_string([&]() {
+        auto type = to_object(object).type();
+        throw_on_size_mismatch(num_datums, type->numAttributes());
+        for (int64_t i = 0; i < num_datums; i++)
+            names[i] = copy_to_c_string(type->getAttributeName(i));
+    });
+}
+
+const char* Torch_Object_HasAttr(bool* output, IValue object, const char* name) {
+    return try_catch_return_error_string([&]() { *output = to_object(object).hasattr(name); });
+}
+
+const char* Torch_Object_Attr(IValue* output, IValue object, const char* name) {
+    return try_catch_return_error_string([&]() { *output = new torch::IValue(to_object(object).attr(name)); });
+}
+
+const char* Torch_Object_SetAttr(IValue object, const char* name, IValue value) {
+    return try_catch_return_error_string([&]() { to_object(object).setattr(name, *value); });
+}
+
+const char* Torch_Object_NumMethods(int64_t* output, IValue object) {
+    return try_catch_return_error_string([&]() { *output = to_object(object).type()->methods().size(); });
+}
+
+const char* Torch_Object_MethodNames(char** names, int64_t num_datums, IValue object) {
+    return try_catch_return_error_string([&]() {
+        auto methods = to_object(object).type()->methods();
+        throw_on_size_mismatch(num_datums, methods.size());
+        for (int64_t i = 0; i < num_datums; i++)
+            names[i] = copy_to_c_string(methods[i]->name());
+    });
+}
+
+const char* Torch_Object_HasMethod(bool* output, IValue object, const char* method) {
+    return try_catch_return_error_string([&]() { *output = to_object(object).find_method(method).has_value(); });
+}
+
+const char* Torch_Object_RunMethod(
+    IValue* output,
+    IValue object,
+    const char* method,
+    IValue* inputs,
+    int64_t num_inputs
+) {
+    return try_catch_return_error_string([&]() {
+        std::vector<torch::IValue> stack;
+        for (int64_t i = 0; i < num_inputs; i++) stack.push_back(*inputs[i]);
+        *output = new torch::IValue(to_object(object).get_method(method)(std::move(stack)));
+    });
+}
+
+// MARK: Enums
+
+const char* Torch_IValue_ToEnum(char** type_name, char** name, IValue* value, IValue ivalue) {
+    return try_catch_return_error_string([&]() {
+        auto holder = ivalue->toEnumHolder();
+        *type_name = copy_to_c_string(holder->qualifiedClassName());
+        *name = copy_to_c_string(holder->name());
+        *value = new torch::IValue(holder->value());
+    });
+}
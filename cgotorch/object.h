@@ -0,0 +1,111 @@
+// C bindings for TorchScript objects and enums held by c10::IValue.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+#pragma once
+
+#include "cgotorch/torchdef.h"
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+// MARK: Objects
+
+/// @brief Return the qualified name of the class of an object.
+/// @param output The output C string, which the caller must free.
+/// @param object An IValue holding an object or a custom class instance.
+const char* Torch_Object_TypeName(char** output, IValue object);
+
+/// @brief Return the number of attributes of an object.
+/// @param output The output number of attributes.
+/// @param object An IValue holding an object.
+const char* Torch_Object_NumAttrs(int64_t* output, IValue object);
+
+/// @brief Return the names of the attributes of an object.
+/// @param names The output array of C strings, which the caller must free.
+/// @param num_datums The size of the output array (see Torch_Object_NumAttrs).
+/// @param object An IValue holding an object.
+const char* Torch_Object_AttrNames(char** names, int64_t num_datums, IValue object);
+
+/// @brief Return true if an object has an attribute with the given name.
+/// @param output The output flag.
+/// @param object An IValue holding an object.
+/// @param name The name of the attribute.
+const char* Torch_Object_HasAttr(bool* output, IValue object, const char* name);
+
+/// @brief Return the value of an attribute of an object.
+/// @param output The output IValue, which the caller must free.
+/// @param object An IValue holding an object.
+/// @param name The name of the attribute.
+const char* Torch_Object_Attr(IValue* output, IValue object, const char* name);
+
+/// @brief Set the value of an existing attribute of an object.
+/// @param object An IValue holding an object.
+/// @param name The name of the attribute.
+/// @param value The new value, which must match the type of the attribute.
+const char* Torch_Object_SetAttr(IValue object, const char* name, IValue value);
+
+/// @brief Return the number of methods of an object.
+/// @param output The output number of methods.
+/// @param object An IValue holding an object or a custom class instance.
+const char* Torch_Object_NumMethods(int64_t* output, IValue object);
+
+/// @brief Return the names of the methods of an object.
+/// @param names The output array of C strings, which the caller must free.
+/// @param num_datums The size of the output array (see Torch_Object_NumMethods).
+/// @param object An IValue holding an object or a custom class instance.
+const char* Torch_Object_MethodNames(char** names, int64_t num_datums, IValue object);
+
+/// @brief Return true if an object has a method with the given name.
+/// @param output The output flag.
+/// @param object An IValue holding an object or a custom class instance.
+/// @param method The name of the method.
+const char* Torch_Object_HasMethod(bool* output, IValue object, const char* method);
+
+/// @brief Run a method of an object. The object is passed as self.
+/// @param output The output IValue, which the caller must free.
+/// @param object An IValue holding an object or a custom class instance.
+/// @param method The name of the method.
+/// @param inputs The positional inputs to the method.
+/// @param num_inputs The number of positional inputs.
+const char* Torch_Object_RunMethod(
+    IValue* output,
+    IValue object,
+    const char* method,
+    IValue* inputs,
+    int64_t num_inputs
+);
+
+// MARK: Enums
+
+/// @brief Return the class name, member name, and value of an enum.
+/// @param type_name The output qualified name of the enum class, which the
+/// caller must free.
+/// @param name The output name of the member, which the caller must free.
+/// @param value The output value of the member, which the caller must free.
+/// @param ivalue An IValue holding an enum.
+const char* Torch_IValue_ToEnum(char** type_name, char** name, IValue* value, IValue ivalue);
+
+#ifdef __cplusplus
+}
+#endif
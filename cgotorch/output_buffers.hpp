@@ -0,0 +1,54 @@
+// Internal helpers for writing libcgotorch outputs to caller-provided buffers.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+#pragma once
+
+#include <stdlib.h>   // for malloc
+#include <stdio.h>    // for snprintf
+#include <cstdint>
+#include <stdexcept>
+#include <string>
+
+#ifdef __cplusplus
+
+/// @brief Copy a string into a new buffer allocated with `malloc`.
+/// @param str The string to copy.
+/// @returns The new buffer, to be released with `std::free`.
+inline char* copy_to_c_string(const std::string& str) {
+    char* output = reinterpret_cast<char*>(malloc(str.size() + 1));
+    snprintf(output, str.size() + 1, "%s", str.c_str());
+    return output;
+}
+
+/// @brief Throw an error if the size of an output buffer is incorrect.
+/// @param expected The size of the buffer provided by the caller.
+/// @param actual The number of elements to write to the buffer.
+inline void throw_on_size_mismatch(int64_t expected, size_t actual) {
+    if (expected == static_cast<int64_t>(actual)) return;
+    throw std::runtime_error(
+        "Expected output array of size " + std::to_string(actual) +
+        " but received array of size " + std::to_string(expected)
+    );
+}
+
+#endif
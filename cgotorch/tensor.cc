@@ -41,6 +41,22 @@ const char* Torch_FromBlob(Tensor* output,
     });
 }
 
+const char* Torch_FromBlobWithDeleter(Tensor* output,
+    void* data,
+    int8_t dtype,
+    int64_t* size,
+    int64_t num_dims,
+    TensorDeleter deleter,
+    uintptr_t context
+) {
+    return try_catch_return_error_string([&] () {
+        *output = new at::Tensor(at::from_blob(data,
+            at::IntArrayRef(size, num_dims),
+            [deleter, context] (void*) { deleter(context); },
+            torch::dtype(at::ScalarType(dtype))));
+    });
+}
+
 const char* Torch_Tensor(Tensor* output,
     void* data,
     int8_t dtype,
@@ -56,6 +72,18 @@ const char* Torch_Tensor(Tensor* output,
 
 void Torch_Tensor_Close(Tensor tensor) { delete tensor; }
 
+const char* Torch_Tensor_ShallowCopy(Tensor* output, Tensor tensor) {
+    return try_catch_return_error_string([&] () {
+        *output = new at::Tensor(*tensor);
+    });
+}
+
+const char* Torch_Tensor_IsCPU(bool* output, Tensor tensor) {
+    return try_catch_return_error_string([&] () {
+        *output = tensor->is_cpu();
+    });
+}
+
 const char* Torch_Tensor_Clone(Tensor* output, Tensor tensor) {
     return try_catch_return_error_string([&] () {
         *output = new at::Tensor(tensor->clone());
@@ -120,6 +148,61 @@ const char* Torch_Tensor_Shape(int64_t* dims, Tensor tensor) {
     });
 }
 
+const char* Torch_Tensor_Strides(int64_t* strides, Tensor tensor) {
+    return try_catch_return_error_string([&] () {
+        int i = 0;
+        for (int64_t stride : tensor->strides()) strides[i++] = stride;
+    });
+}
+
+const char* Torch_Tensor_StorageOffset(int64_t* offset, Tensor tensor) {
+    return try_catch_return_error_string([&] () {
+        *offset = tensor->storage_offset();
+    });
+}
+
+const char* Torch_Tensor_DataPtr(void** data, Tensor tensor) {
+    return try_catch_return_error_string([&] () {
+        *data = tensor->data_ptr();
+    });
+}
+
+const char* Torch_Tensor_IsSameStorage(bool* output, Tensor tensor, Tensor other) {
+    return try_catch_return_error_string([&] () {
+        *output = tensor->is_alias_of(*other);
+    });
+}
+
+const char* Torch_Tensor_Layout(int8_t* layout, Tensor tensor) {
+    return try_catch_return_error_string([&] () {
+        *layout = static_cast<int8_t>(tensor->layout());
+    });
+}
+
+const char* Torch_Tensor_IsContiguous(bool* output, Tensor tensor, int8_t memory_format) {
+    return try_catch_return_error_string([&] () {
+        *output = tensor->is_contiguous(static_cast<c10::MemoryFormat>(memory_format));
+    });
+}
+
+const char* Torch_Tensor_Contiguous(Tensor* output, Tensor tensor, int8_t memory_format) {
+    return try_catch_return_error_string([&] () {
+        *output = new at::Tensor(tensor->contiguous(static_cast<c10::MemoryFormat>(memory_format)));
+    });
+}
+
+const char* Torch_Tensor_SuggestMemoryFormat(int8_t* memory_format, Tensor tensor) {
+    return try_catch_return_error_string([&] () {
+        *memory_format = static_cast<int8_t>(tensor->suggest_memory_format());
+    });
+}
+
+const char* Torch_Tensor_Unfold(Tensor* output, Tensor tensor, int64_t dimension, int64_t size, int64_t step) {
+    return try_catch_return_error_string([&] () {
+        *output = new at::Tensor(tensor->unfold(dimension, size, step));
+    });
+}
+
 const char* Torch_Tensor_View(Tensor* output, Tensor tensor, int64_t *size, int64_t size_len) {
   return try_catch_return_error_string([&] () {
     *output = new at::Tensor(tensor->view(torch::IntArrayRef(size, size_len)));
@@ -216,15 +299,25 @@ const char* Torch_Tensor_Detach(Tensor* output, Tensor tensor) {
     });
 }
 
-// const char* Torch_Tensor_Index(Tensor* output, Tensor input, int64_t* index, int64_t index_len) {
-//     return try_catch_return_error_string([&] () {
-//         std::vector<at::indexing::TensorIndex> indices;
-//         for (int i = 0; i < static_cast<int>(index_len); i++)
-//             indices.push_back(at::indexing::TensorIndex(index[i]));
-//         at::ArrayRef<at::indexing::TensorIndex> ref(indices.data(), index_len);
-//         *output = new at::Tensor(input->index(ref));
-//     });
-// }
+/// @brief Convert integer coordinates to tensor indices.
+static std::vector<at::indexing::TensorIndex> to_tensor_indices(int64_t* index, int64_t index_len) {
+    std::vector<at::indexing::TensorIndex> indices;
+    for (int64_t i = 0; i < index_len; i++)
+        indices.push_back(at::indexing::TensorIndex(index[i]));
+    return indices;
+}
+
+const char* Torch_Tensor_IndexAt(Tensor* output, Tensor input, int64_t* index, int64_t index_len) {
+    return try_catch_return_error_string([&] () {
+        *output = new at::Tensor(input->index(to_tensor_indices(index, index_len)));
+    });
+}
+
+const char* Torch_Tensor_IndexPutAt(Tensor tensor, int64_t* index, int64_t index_len, Tensor value) {
+    return try_catch_return_error_string([&] () {
+        tensor->index_put_(to_tensor_indices(index, index_len), *value);
+    });
+}
 
 const char* Torch_Tensor_Index(Tensor* output, Tensor tensor, Tensor index) { return try_catch_return_error_string([&] () { *output = new at::Tensor(tensor->index({*index})); }); }
 
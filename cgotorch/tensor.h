@@ -43,6 +43,27 @@ extern "C" {
 /// the tensor **will** mutate the input data.
 const char* Torch_FromBlob(Tensor* output, void* data, int8_t dtype, int64_t* size, int64_t num_dims);
 
+/// @brief A function that is called with a context when the storage of a
+/// tensor created from an existing memory buffer is freed.
+typedef void (*TensorDeleter)(uintptr_t context);
+
+/// @brief Create a tensor pointer to an existing memory buffer that calls a
+/// deleter when the storage of the tensor is freed.
+/// @param output The output to store the new Tensor pointer in.
+/// @param data The pointer to the data buffer.
+/// @param dtype The data-type of the data in the buffer
+/// @param size The size of the data buffer (as a list of integers.)
+/// @param num_dims The cardinality of the `sizes_data` buffer.
+/// @param deleter The function to call when the storage is freed.
+/// @param context The context to pass to the deleter.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+/// @details
+/// This function does not allocate new space, in-place operation performed on
+/// the tensor **will** mutate the input data. The storage is freed when the
+/// tensor and all of the views of it are freed, which may happen on any
+/// thread. The deleter is not called if an error occurs.
+const char* Torch_FromBlobWithDeleter(Tensor* output, void* data, int8_t dtype, int64_t* size, int64_t num_dims, TensorDeleter deleter, uintptr_t context);
+
 /// @brief Create a tensor pointer to an existing memory buffer.
 /// @param result The output to store the new Tensor pointer in.
 /// @param data The pointer to the data buffer.
@@ -59,6 +80,21 @@ const char* Torch_Tensor(Tensor* output, void* data, int8_t dtype, int64_t* size
 /// @param tensor An existing tensor that has been allocated by CGoTorch.
 void Torch_Tensor_Close(Tensor tensor);
 
+/// @brief Create a new reference to an existing tensor.
+/// @param output A pointer to store the new tensor pointer in.
+/// @param tensor The tensor to reference.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+/// @details
+/// The output shares the data and storage of the input and keeps them alive
+/// until it is closed with `Torch_Tensor_Close`, even if the input is closed.
+const char* Torch_Tensor_ShallowCopy(Tensor* output, Tensor tensor);
+
+/// @brief Determine whether a tensor is stored in CPU memory.
+/// @param output A pointer to a return register for the boolean value.
+/// @param tensor The tensor to check the device of.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_IsCPU(bool* output, Tensor tensor);
+
 /// @brief Create a new tensor by deep copy of an existing tensor.
 /// @param tensor The tensor to create a deep copy of
 /// @param output A pointer to store the resulting tensor pointer in.
@@ -98,6 +134,76 @@ const char* Torch_Tensor_Decode(Tensor* output, void* addr, int64_t size);
 const char* Torch_Tensor_Dtype(int8_t *dtype, Tensor tensor);
 const char* Torch_Tensor_Dim(int64_t *dim, Tensor tensor);
 const char* Torch_Tensor_Shape(int64_t *dims, Tensor tensor);
+
+/// @brief Access the strides of a tensor.
+/// @param strides A pointer to a buffer of `dim` elements to store the
+/// number of elements to skip along each dimension in.
+/// @param tensor The tensor to access the strides of.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_Strides(int64_t* strides, Tensor tensor);
+
+/// @brief Access the offset of a tensor in its underlying storage.
+/// @param offset A pointer to a return register for the number of elements
+/// between the start of the storage and the first element of the tensor.
+/// @param tensor The tensor to access the storage offset of.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_StorageOffset(int64_t* offset, Tensor tensor);
+
+/// @brief Access the address of the first element of a tensor.
+/// @param data A pointer to a return register for the address.
+/// @param tensor The tensor to access the data pointer of.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+/// @details
+/// The address accounts for the storage offset of the tensor and remains
+/// valid only as long as the storage of the tensor is alive.
+const char* Torch_Tensor_DataPtr(void** data, Tensor tensor);
+
+/// @brief Determine whether two tensors share the same underlying storage.
+/// @param output A pointer to a return register for the boolean value.
+/// @param tensor The first tensor to compare the storage of.
+/// @param other The second tensor to compare the storage of.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_IsSameStorage(bool* output, Tensor tensor, Tensor other);
+
+/// @brief Access the layout of a tensor, e.g., strided or sparse.
+/// @param layout A pointer to a return register for the `c10::Layout` code.
+/// @param tensor The tensor to access the layout of.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_Layout(int8_t* layout, Tensor tensor);
+
+/// @brief Determine whether a tensor is contiguous in a memory format.
+/// @param output A pointer to a return register for the boolean value.
+/// @param tensor The tensor to check the contiguity of.
+/// @param memory_format The `c10::MemoryFormat` code to check against.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_IsContiguous(bool* output, Tensor tensor, int8_t memory_format);
+
+/// @brief Create a tensor that is contiguous in a memory format.
+/// @param output A pointer to store the resulting tensor pointer in.
+/// @param tensor The tensor to make contiguous.
+/// @param memory_format The `c10::MemoryFormat` code to lay out memory in.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+/// @details
+/// The output shares storage with the input if it is already contiguous in
+/// the given memory format, otherwise the data is copied.
+const char* Torch_Tensor_Contiguous(Tensor* output, Tensor tensor, int8_t memory_format);
+
+/// @brief Determine the memory format that best matches the strides of a
+/// tensor, e.g., channels-last for a permuted NHWC tensor.
+/// @param memory_format A pointer to a return register for the
+/// `c10::MemoryFormat` code.
+/// @param tensor The tensor to inspect the strides of.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_SuggestMemoryFormat(int8_t* memory_format, Tensor tensor);
+
+/// @brief Create a view of all the slices of a given size along a dimension.
+/// @param output A pointer to store the resulting tensor pointer in.
+/// @param tensor The tensor to unfold.
+/// @param dimension The dimension to slice along.
+/// @param size The size of each slice.
+/// @param step The step between the start of consecutive slices.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_Unfold(Tensor* output, Tensor tensor, int64_t dimension, int64_t size, int64_t step);
 const char* Torch_Tensor_View(Tensor* output, Tensor tensor, int64_t *size, int64_t size_len);
 const char* Torch_Tensor_ViewAs(Tensor* output, Tensor tensor, Tensor other);
 const char* Torch_Tensor_Reshape(Tensor* output, Tensor tensor, int64_t *size, int64_t size_len);
@@ -135,13 +241,24 @@ const char* Torch_Tensor_Grad(Tensor* output, Tensor tensor);
 /// @returns A dynamically allocated message if an error occurs, else a nullptr.
 const char* Torch_Tensor_Detach(Tensor* output, Tensor tensor);
 
-/// @brief Convert an N-dimensional tensor to a scalar by index.
+/// @brief Select a sub-tensor of an N-dimensional tensor by integer index.
 /// @param output A pointer to the buffer to store the result in.
 /// @param tensor The tensor to select a scalar quantity from.
 /// @param index A pointer to the coordinates of the value to select.
+/// Negative coordinates count from the end of their dimension.
+/// @param index_len The length of the input coordinate array.
+/// @returns A dynamically allocated message if an error occurs, else a nullptr.
+const char* Torch_Tensor_IndexAt(Tensor* output, Tensor tensor, int64_t* index, int64_t index_len);
+
+/// @brief Assign a value to a sub-tensor of an N-dimensional tensor by
+/// integer index, in-place.
+/// @param tensor The tensor to assign a value in.
+/// @param index A pointer to the coordinates of the sub-tensor to assign.
+/// Negative coordinates count from the end of their dimension.
 /// @param index_len The length of the input coordinate array.
+/// @param value The value to assign, which is broadcast to the sub-tensor.
 /// @returns A dynamically allocated message if an error occurs, else a nullptr.
-// const char* Torch_Tensor_Index(Tensor* output, Tensor tensor, int64_t *index, int64_t index_len);
+const char* Torch_Tensor_IndexPutAt(Tensor tensor, int64_t* index, int64_t index_len, Tensor value);
 
 const char* Torch_Tensor_Index(Tensor* output, Tensor tensor, Tensor index);
 
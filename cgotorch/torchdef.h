@@ -40,6 +40,7 @@ typedef torch::Device* Device;
 typedef std::vector<char>* ByteBuffer;
 typedef torch::jit::Module* JitModule;
 typedef torch::IValue* IValue;
+typedef std::shared_ptr<torch::jit::CompilationUnit>* CompilationUnit;
 #else
 typedef void* Tensor;
 typedef void* TensorOptions;
@@ -49,6 +50,7 @@ typedef void* Device;
 typedef void* ByteBuffer;
 typedef void* JitModule;
 typedef void* IValue;
+typedef void* CompilationUnit;
 #endif
 
 typedef void* CUDAStream;
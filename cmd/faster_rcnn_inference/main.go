@@ -16,6 +16,20 @@ import (
 	T "github.com/Kautenja/gotorch/vision/transforms/functional"
 )
 
+// The detections of the Faster R-CNN model for a single image.
+type Detection struct {
+	Boxes *torch.Tensor `torch:"boxes"`
+	Labels *torch.Tensor `torch:"labels"`
+	Scores *torch.Tensor `torch:"scores"`
+}
+
+// The outputs of the traced Faster R-CNN model, i.e., a tuple of the losses
+// (empty in evaluation mode) and the detections for each image.
+type Outputs struct {
+	Losses map[string]*torch.Tensor
+	Detections []Detection
+}
+
 func main() {
 	if len(os.Args) < 3 {
 		log.Fatal("Usage: go run main.go <model.pt> <image.png>")
@@ -54,16 +68,22 @@ func main() {
 
 	// Copy the pixel data into a tensor representation on the CPU.
 	tensor := T.ToTensor(imageData).CopyTo(device)
-	// Forward pass the tensor and extract the predictions from the IValue.
-	predictions := model.Forward([]*torch.IValue{torch.NewIValue([]*torch.Tensor{tensor})}).ToTuple()[1].ToList()[0].ToGenericDict()
+	// Forward pass the tensor and decode the predictions from the IValue.
+	var outputs Outputs
+	err = torch.UnmarshalIValue(model.Forward([]*torch.IValue{torch.NewIValue([]*torch.Tensor{tensor})}), &outputs)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	predictions := outputs.Detections[0]
 
 	// Select the scores, boxes, and labels, and filter predictions with scores
 	// that are above the threshold.
-	scores := predictions["scores"].ToTensor()
+	scores := predictions.Scores
 	is_object := scores.GreaterEqual(torch.FullLike(scores, 0.7))
 	scores = scores.Index(is_object)
-	boxes := predictions["boxes"].ToTensor().Index(is_object)
-	labels := predictions["labels"].ToTensor().Index(is_object)
+	boxes := predictions.Boxes.Index(is_object)
+	labels := predictions.Labels.Index(is_object)
 
 	// Print the string label of the first box
 	fmt.Println(coco_labels[labels.Slice(0, 0, 1, 1).Item().(int64) - 1], scores.Slice(0, 0, 1, 1).Item().(float32))
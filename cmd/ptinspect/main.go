@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+	torch "github.com/Kautenja/gotorch"
+	jit "github.com/Kautenja/gotorch/jit"
+)
+
+// The names of data-types as they are printed by PyTorch.
+var dtypeNames = map[torch.Dtype]string{
+	torch.Byte: "uint8",
+	torch.Char: "int8",
+	torch.Short: "int16",
+	torch.Int: "int32",
+	torch.Long: "int64",
+	torch.Half: "float16",
+	torch.Float: "float32",
+	torch.Double: "float64",
+	torch.ComplexHalf: "complex32",
+	torch.ComplexFloat: "complex64",
+	torch.ComplexDouble: "complex128",
+	torch.Bool: "bool",
+	torch.QInt8: "qint8",
+	torch.QUInt8: "quint8",
+	torch.QInt32: "qint32",
+	torch.BFloat16: "bfloat16",
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: ptinspect [flags] <model.pt>")
+		flag.PrintDefaults()
+	}
+	graphs := flag.Bool("graph", false, "print the inlined graph of each method")
+	maxExtraBytes := flag.Int("extra-bytes", 1024, "the maximum number of bytes to print from each extra file, or -1 for all")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	module, err := jit.Load(path, torch.NewDevice("cpu"))
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	fmt.Println("Modules:")
+	printHierarchy(module, "", "  ")
+
+	fmt.Println("\nMethods:")
+	for _, name := range module.MethodNames() {
+		schema, err := module.MethodSchema(name)
+		if err != nil {
+			log.Fatal(err)
+			return
+		}
+		fmt.Printf("  %v\n", schema)
+	}
+
+	fmt.Println("\nParameters:")
+	count, size := printTensors(module.NamedParameters())
+	fmt.Printf("\nTotal parameters: %d (%s)\n", count, formatBytes(size))
+
+	fmt.Println("\nBuffers:")
+	count, size = printTensors(module.NamedBuffers())
+	fmt.Printf("\nTotal buffer elements: %d (%s)\n", count, formatBytes(size))
+
+	fmt.Println("\nExtra files:")
+	if err := printExtraFiles(path, *maxExtraBytes); err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	if *graphs {
+		for _, name := range module.MethodNames() {
+			graph, err := module.Graph(name)
+			if err != nil {
+				log.Fatal(err)
+				return
+			}
+			fmt.Printf("\nGraph of %s:\n%s", name, graph)
+		}
+	}
+}
+
+// Print the class of a module and, recursively, its submodules as a tree.
+func printHierarchy(module *jit.JitModule, name, indent string) {
+	if name == "" {
+		fmt.Printf("%s%s\n", indent, module.TypeName())
+	} else {
+		fmt.Printf("%s(%s): %s\n", indent, name, module.TypeName())
+	}
+	for _, child := range module.NamedChildren() {
+		printHierarchy(child.Module, child.Name, indent + "  ")
+	}
+}
+
+// Print a table of named tensors and return their total number of elements
+// and bytes.
+func printTensors(tensors []jit.NamedTensor) (count, size int64) {
+	if len(tensors) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "  NAME\tSHAPE\tDTYPE\tSIZE")
+	for _, named := range tensors {
+		numel := named.Tensor.Numel()
+		dtype := named.Tensor.Dtype()
+		bytes := numel * dtype.NumBytes()
+		count += numel
+		size += bytes
+		fmt.Fprintf(writer, "  %s\t%v\t%s\t%s\n", named.Name, named.Tensor.Shape(), dtypeNames[dtype], formatBytes(bytes))
+	}
+	writer.Flush()
+	return
+}
+
+// Print the extra files of a TorchScript archive, i.e., the files passed as
+// _extra_files to torch.jit.save. Files are printed as text if they are valid
+// UTF-8 and summarized otherwise.
+func printExtraFiles(path string, maxBytes int) error {
+	files, err := jit.ReadExtraFiles(path)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println("  (none)")
+		return nil
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		contents := files[name]
+		fmt.Printf("  %s (%s)\n", name, formatBytes(int64(len(contents))))
+		if !utf8.Valid(contents) {
+			continue
+		}
+		truncated := maxBytes >= 0 && len(contents) > maxBytes
+		if truncated {
+			contents = contents[:maxBytes]
+		}
+		for _, line := range strings.Split(strings.TrimRight(string(contents), "\n"), "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+		if truncated {
+			fmt.Println("    ...")
+		}
+	}
+	return nil
+}
+
+// Format a number of bytes with a binary unit, e.g., 1.5 MiB.
+func formatBytes(bytes int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units) - 1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"gopkg.in/yaml.v3"
+)
+
+// The configuration of the models served by the server.
+type Config struct {
+	Models []ModelConfig `json:"models" yaml:"models"`
+}
+
+// The configuration of a single TorchScript model.
+type ModelConfig struct {
+	// The name of the model in request URLs, i.e., /v1/models/<name>/predict.
+	Name string `json:"name" yaml:"name"`
+	// The path to the TorchScript archive to load with jit.Load.
+	Path string `json:"path" yaml:"path"`
+	// The device to load the model onto. Defaults to "cpu".
+	Device string `json:"device" yaml:"device"`
+	// The number of inference workers. Defaults to 1.
+	Workers int `json:"workers" yaml:"workers"`
+	// The number of intra-op threads used by libtorch. The setting is global,
+	// so the last model loaded with a value applies to every model. Defaults
+	// to libtorch's.
+	NumThreads int32 `json:"num_threads" yaml:"num_threads"`
+	// The preprocessing applied to image inputs.
+	Preprocess PreprocessConfig `json:"preprocess" yaml:"preprocess"`
+	// The postprocessing applied to model outputs.
+	Postprocess PostprocessConfig `json:"postprocess" yaml:"postprocess"`
+}
+
+// The preprocessing applied to image inputs using vision/transforms.
+type PreprocessConfig struct {
+	// The [height, width] to resize images to.
+	Resize []int64 `json:"resize" yaml:"resize"`
+	// The [height, width] to center crop images to after resizing.
+	CenterCrop []int64 `json:"center_crop" yaml:"center_crop"`
+	// The per-channel mean and standard deviation to normalize images with.
+	Normalize *NormalizeConfig `json:"normalize" yaml:"normalize"`
+	// Whether the model expects a list of (C, H, W) tensors instead of a
+	// batched (N, C, H, W) tensor, e.g., torchvision detection models.
+	ListInput bool `json:"list_input" yaml:"list_input"`
+}
+
+// The parameters of transforms.Normalize.
+type NormalizeConfig struct {
+	Mean []float32 `json:"mean" yaml:"mean"`
+	Std []float32 `json:"std" yaml:"std"`
+}
+
+// The postprocessing applied to model outputs.
+type PostprocessConfig struct {
+	// Whether to apply a softmax over dimension 1 of (N, C) logits.
+	Softmax bool `json:"softmax" yaml:"softmax"`
+	// The number of top scoring classes to return for each sample, or 0 to
+	// return the raw output.
+	TopK int64 `json:"top_k" yaml:"top_k"`
+	// The names of the classes, indexed by label.
+	Labels []string `json:"labels" yaml:"labels"`
+	// Filtering of detections from torchvision-style detection models.
+	Detection *DetectionConfig `json:"detection" yaml:"detection"`
+}
+
+// Filtering of dictionaries of "boxes", "scores", and "labels" tensors.
+type DetectionConfig struct {
+	// The minimum score of detections to return.
+	ScoreThreshold float32 `json:"score_threshold" yaml:"score_threshold"`
+	// The maximum number of detections to return per image, or 0 for all.
+	MaxDetections int `json:"max_detections" yaml:"max_detections"`
+	// The offset subtracted from labels before looking up class names, e.g.,
+	// 1 for models that reserve label 0 for the background.
+	LabelOffset int64 `json:"label_offset" yaml:"label_offset"`
+}
+
+// Load a configuration from a JSON file (.json) or a YAML file (otherwise).
+// Unknown fields are rejected to catch typos in the configuration.
+func LoadConfig(path string) (*Config, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(config)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(contents))
+		decoder.KnownFields(true)
+		err = decoder.Decode(config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %v", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate the configuration and fill in default values.
+func (config *Config) Validate() error {
+	if len(config.Models) == 0 {
+		return fmt.Errorf("Configuration does not declare any models")
+	}
+	names := make(map[string]bool)
+	for index := range config.Models {
+		model := &config.Models[index]
+		if model.Name == "" {
+			return fmt.Errorf("Model %d does not have a name", index)
+		}
+		if names[model.Name] {
+			return fmt.Errorf("Model name %q is declared more than once", model.Name)
+		}
+		names[model.Name] = true
+		if model.Path == "" {
+			return fmt.Errorf("Model %q does not have a path", model.Name)
+		}
+		if model.Device == "" {
+			model.Device = "cpu"
+		}
+		if model.Workers < 0 {
+			return fmt.Errorf("Model %q has a negative number of workers", model.Name)
+		}
+		preprocess := model.Preprocess
+		if preprocess.Resize != nil && len(preprocess.Resize) != 2 {
+			return fmt.Errorf("Model %q resize should be [height, width]", model.Name)
+		}
+		if preprocess.CenterCrop != nil && len(preprocess.CenterCrop) != 2 {
+			return fmt.Errorf("Model %q center_crop should be [height, width]", model.Name)
+		}
+		if normalize := preprocess.Normalize; normalize != nil && len(normalize.Mean) != len(normalize.Std) {
+			return fmt.Errorf("Model %q normalize mean and std should have the same length", model.Name)
+		}
+		if model.Postprocess.TopK < 0 {
+			return fmt.Errorf("Model %q has a negative top_k", model.Name)
+		}
+	}
+	return nil
+}
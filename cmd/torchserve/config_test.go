@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	config, err := LoadConfig("torchserve.example.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(config.Models))
+	assert.Equal(t, "resnet18", config.Models[0].Name)
+	assert.Equal(t, []int64{224, 224}, config.Models[0].Preprocess.CenterCrop)
+	assert.Equal(t, int64(5), config.Models[0].Postprocess.TopK)
+	assert.Equal(t, "cpu", config.Models[1].Device)
+	assert.Equal(t, float32(0.5), config.Models[1].Postprocess.Detection.ScoreThreshold)
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	path := writeConfig(t, "config.json", `{"models": [{"name": "identity", "path": "identity.pt", "postprocess": {"softmax": true}}]}`)
+	config, err := LoadConfig(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "identity.pt", config.Models[0].Path)
+	assert.True(t, config.Models[0].Postprocess.Softmax)
+}
+
+func TestLoadConfigRejectsUnknownFields(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "models:\n  - name: identity\n    path: identity.pt\n    sofmax: true\n")
+	_, err := LoadConfig(path)
+	assert.NotNil(t, err)
+}
+
+func TestConfigValidate(t *testing.T) {
+	invalid := []Config{
+		{},
+		{Models: []ModelConfig{{Path: "model.pt"}}},
+		{Models: []ModelConfig{{Name: "model"}}},
+		{Models: []ModelConfig{{Name: "model", Path: "a.pt"}, {Name: "model", Path: "b.pt"}}},
+		{Models: []ModelConfig{{Name: "model", Path: "model.pt", Workers: -1}}},
+		{Models: []ModelConfig{{Name: "model", Path: "model.pt", Preprocess: PreprocessConfig{Resize: []int64{224}}}}},
+		{Models: []ModelConfig{{Name: "model", Path: "model.pt", Preprocess: PreprocessConfig{Normalize: &NormalizeConfig{Mean: []float32{0}}}}}},
+		{Models: []ModelConfig{{Name: "model", Path: "model.pt", Postprocess: PostprocessConfig{TopK: -1}}}},
+	}
+	for _, config := range invalid {
+		assert.NotNil(t, config.Validate())
+	}
+}
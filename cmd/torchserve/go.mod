@@ -0,0 +1,23 @@
+module github.com/Kautenja/gotorch/cmd/torchserve
+
+go 1.18
+
+replace github.com/Kautenja/gotorch => ../..
+
+require (
+	github.com/Kautenja/gotorch v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.8.1
+	google.golang.org/grpc v1.56.3
+	google.golang.org/protobuf v1.30.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+)
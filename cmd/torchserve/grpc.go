@@ -0,0 +1,97 @@
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative torchserve.proto
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// The Inference service backed by a server.
+type inferenceService struct {
+	UnimplementedInferenceServer
+	server *Server
+}
+
+// Run inference for a single model.
+func (service inferenceService) Predict(ctx context.Context, request *PredictRequest) (*PredictResponse, error) {
+	var contentType string
+	var body []byte
+	switch inputs := request.Inputs.(type) {
+	case *PredictRequest_Tensor:
+		contentType, body = "application/octet-stream", inputs.Tensor
+	case *PredictRequest_Npy:
+		contentType, body = "application/x-npy", inputs.Npy
+	case *PredictRequest_Json:
+		contentType, body = "application/json", []byte(inputs.Json)
+	case *PredictRequest_Image:
+		contentType, body = "image/*", inputs.Image
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Expected one of tensor, npy, json, or image inputs")
+	}
+	var acceptTensor string
+	switch {
+	case request.AcceptNpy:    acceptTensor = "application/x-npy"
+	case request.AcceptTensor: acceptTensor = "application/octet-stream"
+	}
+	response, err := service.server.predict(ctx, request.Model, predictRequest{
+		contentType: contentType,
+		body: bytes.NewReader(body),
+		acceptTensor: acceptTensor,
+	})
+	if err != nil {
+		return nil, rpcError(err)
+	}
+	switch {
+	case response.tensor != nil && response.tensorType == "application/x-npy":
+		return &PredictResponse{Outputs: &PredictResponse_Npy{Npy: response.tensor}}, nil
+	case response.tensor != nil:
+		return &PredictResponse{Outputs: &PredictResponse_Tensor{Tensor: response.tensor}}, nil
+	}
+	outputs, err := json.Marshal(response.outputs)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &PredictResponse{Outputs: &PredictResponse_Json{Json: string(outputs)}}, nil
+}
+
+// Convert an error from a predict request to a gRPC status error.
+func rpcError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	code := codes.Internal
+	switch errorStatus(err) {
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	}
+	return status.Error(code, err.Error())
+}
+
+// Create a gRPC server with the Inference service and the standard health
+// service of the server registered.
+func (server *Server) NewGRPCServer(options ...grpc.ServerOption) *grpc.Server {
+	options = append([]grpc.ServerOption{grpc.MaxRecvMsgSize(maxRequestBytes)}, options...)
+	grpcServer := grpc.NewServer(options...)
+	RegisterInferenceServer(grpcServer, inferenceService{server: server})
+	healthpb.RegisterHealthServer(grpcServer, server.health)
+	return grpcServer
+}
+
+// Create a health service that reports the server as not serving until the
+// models are loaded.
+func newHealthServer() *health.Server {
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return healthServer
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	torch "github.com/Kautenja/gotorch"
+)
+
+func main() {
+	configPath := flag.String("config", "torchserve.yaml", "path to the YAML or JSON model configuration")
+	address := flag.String("addr", ":8080", "address to listen on for HTTP requests")
+	grpcAddress := flag.String("grpc-addr", ":9090", "address to listen on for gRPC requests, or empty to disable gRPC")
+	flag.Parse()
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	// Disable autograd for this inference context
+	torch.SetGradEnabled(false)
+
+	// Start listening before loading the models so that liveness probes
+	// succeed while readiness probes wait for the models.
+	server := NewServer(config)
+	httpServer := &http.Server{Addr: *address, Handler: server}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	log.Printf("Listening on %s", *address)
+	grpcServer := server.NewGRPCServer()
+	if *grpcAddress != "" {
+		listener, err := net.Listen("tcp", *grpcAddress)
+		if err != nil {
+			log.Fatal(err)
+			return
+		}
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal(err)
+			}
+		}()
+		log.Printf("Listening on %s for gRPC", *grpcAddress)
+	}
+	if err := server.Load(); err != nil {
+		log.Fatal(err)
+		return
+	}
+	log.Printf("Loaded %d model(s)", len(config.Models))
+
+	// Shutdown gracefully on interrupt, letting in-flight requests finish.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	ctx, cancel := context.WithTimeout(context.Background(), 30 * time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		log.Print(err)
+	}
+	grpcServer.GracefulStop()
+	server.Close()
+}
@@ -0,0 +1,737 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png"
+	_ "image/jpeg"
+	_ "image/gif"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	torch "github.com/Kautenja/gotorch"
+	F "github.com/Kautenja/gotorch/nn/functional"
+	jit "github.com/Kautenja/gotorch/jit"
+	transforms "github.com/Kautenja/gotorch/vision/transforms"
+	T "github.com/Kautenja/gotorch/vision/transforms/functional"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// The maximum size of a request body in bytes.
+const maxRequestBytes = 64 << 20
+
+// An HTTP server for TorchScript models. The server exposes the endpoints:
+//
+//	GET  /healthz                    liveness of the process
+//	GET  /readyz                     503 until all models are loaded
+//	GET  /metrics                    Prometheus text exposition format
+//	GET  /v1/models                  the names of the served models
+//	POST /v1/models/<name>/predict   inference on a single model
+//
+// Predict requests are decoded based on their Content-Type: tensors encoded
+// with Tensor.Encode (application/octet-stream) or in the NumPy .npy format
+// (application/x-npy), JSON objects of the form {"inputs": <nested arrays>}
+// (application/json), or images (image/*) that are passed through the
+// model's preprocessing pipeline. The same models are served over gRPC by the
+// server returned from NewGRPCServer.
+type Server struct {
+	config *Config
+	mutex sync.RWMutex
+	models map[string]*servedModel
+	ready int32
+	health *health.Server
+}
+
+// A model loaded into a worker pool along with its request statistics.
+type servedModel struct {
+	config ModelConfig
+	device *torch.Device
+	pool *jit.Pool
+	transform transforms.ITransformer
+	requests uint64
+	errors uint64
+	mutex sync.Mutex
+	latency float64
+}
+
+// Create a new server for the given configuration. The server responds to
+// requests immediately but reports itself as unready until Load returns.
+func NewServer(config *Config) *Server {
+	return &Server{
+		config: config,
+		models: make(map[string]*servedModel),
+		health: newHealthServer(),
+	}
+}
+
+// Load the models of the configuration into worker pools and mark the server
+// as ready.
+func (server *Server) Load() error {
+	for _, config := range server.config.Models {
+		model, err := loadModel(config)
+		if err != nil {
+			return fmt.Errorf("Failed to load model %q: %v", config.Name, err)
+		}
+		server.mutex.Lock()
+		server.models[config.Name] = model
+		server.mutex.Unlock()
+		server.health.SetServingStatus(config.Name, healthpb.HealthCheckResponse_SERVING)
+	}
+	atomic.StoreInt32(&server.ready, 1)
+	server.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	return nil
+}
+
+// Return true if the server has loaded all of its models.
+func (server *Server) IsReady() bool {
+	return atomic.LoadInt32(&server.ready) == 1
+}
+
+// Close the worker pools of the models served by the server.
+func (server *Server) Close() {
+	atomic.StoreInt32(&server.ready, 0)
+	server.health.Shutdown()
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	for name, model := range server.models {
+		model.pool.Close()
+		delete(server.models, name)
+	}
+}
+
+// Load a single model into a pool of replicas.
+func loadModel(config ModelConfig) (*servedModel, error) {
+	if !torch.IsDevice(config.Device) {
+		return nil, fmt.Errorf("Invalid device %q", config.Device)
+	}
+	device := torch.NewDevice(config.Device)
+	pool, err := jit.NewReplicaPool(func() (*jit.JitModule, error) {
+		module, err := jit.Load(config.Path, device)
+		if err != nil {
+			return nil, err
+		}
+		return module.Eval(), nil
+	}, jit.PoolOptions{Workers: config.Workers, NumThreads: config.NumThreads})
+	if err != nil {
+		return nil, err
+	}
+	return &servedModel{
+		config: config,
+		device: device,
+		pool: pool,
+		transform: newTransform(config.Preprocess),
+	}, nil
+}
+
+// Create the image preprocessing pipeline for the configuration.
+func newTransform(config PreprocessConfig) transforms.ITransformer {
+	var pipeline []transforms.ITransformer
+	if config.Resize != nil {
+		pipeline = append(pipeline, transforms.Resize(config.Resize[0], config.Resize[1], F.InterpolateBilinear, true, true))
+	}
+	if config.CenterCrop != nil {
+		pipeline = append(pipeline, transforms.CenterCrop(config.CenterCrop[0], config.CenterCrop[1]))
+	}
+	if config.Normalize != nil {
+		pipeline = append(pipeline, transforms.Normalize(config.Normalize.Mean, config.Normalize.Std))
+	}
+	return transforms.Compose(pipeline...)
+}
+
+// An error with an associated HTTP status code.
+type httpError struct {
+	status int
+	message string
+}
+
+func (err *httpError) Error() string {
+	return err.message
+}
+
+// Create an error that responds with 400 Bad Request.
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// Route a request to its handler.
+func (server *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	path := request.URL.Path
+	switch {
+	case path == "/healthz":
+		server.handleHealth(writer, request)
+	case path == "/readyz":
+		server.handleReady(writer, request)
+	case path == "/metrics":
+		server.handleMetrics(writer, request)
+	case path == "/v1/models":
+		server.handleModels(writer, request)
+	case strings.HasPrefix(path, "/v1/models/") && strings.HasSuffix(path, "/predict"):
+		name := strings.TrimSuffix(strings.TrimPrefix(path, "/v1/models/"), "/predict")
+		server.handlePredict(writer, request, name)
+	default:
+		writeError(writer, http.StatusNotFound, "Not found")
+	}
+}
+
+// Respond to liveness probes.
+func (server *Server) handleHealth(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Respond to readiness probes.
+func (server *Server) handleReady(writer http.ResponseWriter, request *http.Request) {
+	if !server.IsReady() {
+		writeJSON(writer, http.StatusServiceUnavailable, map[string]string{"status": "loading"})
+		return
+	}
+	writeJSON(writer, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// Respond with the names of the models that are loaded.
+func (server *Server) handleModels(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	writeJSON(writer, http.StatusOK, map[string][]string{"models": server.modelNames()})
+}
+
+// Return the sorted names of the models that are loaded.
+func (server *Server) modelNames() []string {
+	server.mutex.RLock()
+	defer server.mutex.RUnlock()
+	names := make([]string, 0, len(server.models))
+	for name := range server.models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Respond with request statistics and memory usage in the Prometheus text
+// exposition format.
+func (server *Server) handleMetrics(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	names := server.modelNames()
+	server.mutex.RLock()
+	models := make([]*servedModel, len(names))
+	for index, name := range names {
+		models[index] = server.models[name]
+	}
+	server.mutex.RUnlock()
+	fmt.Fprintln(writer, "# HELP torchserve_requests_total The number of predict requests.")
+	fmt.Fprintln(writer, "# TYPE torchserve_requests_total counter")
+	for _, model := range models {
+		fmt.Fprintf(writer, "torchserve_requests_total{model=%q} %d\n", model.config.Name, atomic.LoadUint64(&model.requests))
+	}
+	fmt.Fprintln(writer, "# HELP torchserve_errors_total The number of failed predict requests.")
+	fmt.Fprintln(writer, "# TYPE torchserve_errors_total counter")
+	for _, model := range models {
+		fmt.Fprintf(writer, "torchserve_errors_total{model=%q} %d\n", model.config.Name, atomic.LoadUint64(&model.errors))
+	}
+	fmt.Fprintln(writer, "# HELP torchserve_request_duration_seconds The latency of predict requests.")
+	fmt.Fprintln(writer, "# TYPE torchserve_request_duration_seconds summary")
+	for _, model := range models {
+		model.mutex.Lock()
+		latency := model.latency
+		model.mutex.Unlock()
+		fmt.Fprintf(writer, "torchserve_request_duration_seconds_sum{model=%q} %g\n", model.config.Name, latency)
+		fmt.Fprintf(writer, "torchserve_request_duration_seconds_count{model=%q} %d\n", model.config.Name, atomic.LoadUint64(&model.requests))
+	}
+	stats := torch.MemoryStats()
+	gauges := []struct {
+		name, help string
+		value int64
+	}{
+		{"torch_memory_live_bytes", "The number of bytes allocated on the CPU by libtorch.", stats.LiveBytes},
+		{"torch_memory_peak_bytes", "The maximum number of bytes allocated on the CPU by libtorch.", stats.PeakBytes},
+		{"torch_memory_live_allocations", "The number of live CPU allocations.", stats.LiveAllocations},
+		{"torch_memory_live_tensors", "The number of tensor handles that have not been freed.", stats.LiveTensors},
+	}
+	for _, gauge := range gauges {
+		fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", gauge.name, gauge.help, gauge.name, gauge.name, gauge.value)
+	}
+}
+
+// Run inference for a single model.
+func (server *Server) handlePredict(writer http.ResponseWriter, request *http.Request, name string) {
+	if request.Method != http.MethodPost {
+		writeError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var acceptTensor string
+	switch accept := request.Header.Get("Accept"); {
+	case strings.Contains(accept, "application/x-npy"):         acceptTensor = "application/x-npy"
+	case strings.Contains(accept, "application/octet-stream"): acceptTensor = "application/octet-stream"
+	}
+	response, err := server.predict(request.Context(), name, predictRequest{
+		contentType: request.Header.Get("Content-Type"),
+		body: http.MaxBytesReader(writer, request.Body, maxRequestBytes),
+		acceptTensor: acceptTensor,
+	})
+	if err != nil {
+		writeError(writer, errorStatus(err), err.Error())
+		return
+	}
+	if response.tensor != nil {
+		writer.Header().Set("Content-Type", response.tensorType)
+		writer.Write(response.tensor)
+		return
+	}
+	writeJSON(writer, http.StatusOK, map[string]interface{}{"outputs": response.outputs})
+}
+
+// Return the HTTP status code of an error.
+func errorStatus(err error) int {
+	var httpErr *httpError
+	if errors.As(err, &httpErr) {
+		return httpErr.status
+	}
+	return http.StatusInternalServerError
+}
+
+// A predict request independent of the transport that received it.
+type predictRequest struct {
+	// The Content-Type of the body, i.e., application/octet-stream,
+	// application/x-npy, application/json, or image/*.
+	contentType string
+	// The encoded inputs to the model.
+	body io.Reader
+	// The media type of the tensor to respond with when the model outputs a
+	// tensor and no postprocessing is configured, i.e.,
+	// application/octet-stream for the Tensor.Encode format,
+	// application/x-npy for the NumPy .npy format, or empty for JSON.
+	acceptTensor string
+}
+
+// The response to a predict request, either a tensor in the format of its
+// media type or a JSON-serializable value.
+type predictResponse struct {
+	tensor []byte
+	tensorType string
+	outputs interface{}
+}
+
+// Run inference for the named model and record the request statistics.
+func (server *Server) predict(ctx context.Context, name string, request predictRequest) (response predictResponse, err error) {
+	server.mutex.RLock()
+	model, ok := server.models[name]
+	server.mutex.RUnlock()
+	if !ok {
+		return response, &httpError{http.StatusNotFound, fmt.Sprintf("Model %q not found", name)}
+	}
+	start := time.Now()
+	atomic.AddUint64(&model.requests, 1)
+	defer func() {
+		model.mutex.Lock()
+		model.latency += time.Since(start).Seconds()
+		model.mutex.Unlock()
+	}()
+	torch.WithScope(func(scope *torch.Scope) {
+		response, err = model.predict(ctx, request)
+	})
+	if err != nil {
+		atomic.AddUint64(&model.errors, 1)
+	}
+	return response, err
+}
+
+// Decode the inputs of a request, forward them through the model, and
+// postprocess the outputs.
+func (model *servedModel) predict(ctx context.Context, request predictRequest) (response predictResponse, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+	inputs, err := model.decodeInputs(request)
+	if err != nil {
+		return response, err
+	}
+	output, err := model.pool.Forward(ctx, inputs)
+	if err != nil {
+		return response, err
+	}
+	postprocess := model.config.Postprocess
+	if output.IsTensor() && !postprocess.Softmax && postprocess.TopK == 0 && request.acceptTensor != "" {
+		response.tensorType = request.acceptTensor
+		if request.acceptTensor == "application/x-npy" {
+			var buffer bytes.Buffer
+			err = torch.WriteNpy(&buffer, output.ToTensor())
+			response.tensor = buffer.Bytes()
+			return response, err
+		}
+		response.tensor, err = output.ToTensor().Encode()
+		return response, err
+	}
+	response.outputs, err = model.postprocess(output)
+	return response, err
+}
+
+// Decode the inputs to the model from the body of a request.
+func (model *servedModel) decodeInputs(request predictRequest) (inputs []*torch.IValue, err error) {
+	// Conversions from request data to tensors panic on malformed data.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = badRequest("%v", recovered)
+		}
+	}()
+	mediaType, _, err := mime.ParseMediaType(request.contentType)
+	if err != nil {
+		return nil, badRequest("Invalid Content-Type: %v", err)
+	}
+	body := request.body
+	switch {
+	case mediaType == "application/octet-stream":
+		buffer, err := io.ReadAll(body)
+		if err != nil {
+			return nil, badRequest("Failed to read body: %v", err)
+		}
+		tensor, err := torch.Decode(buffer)
+		if err != nil {
+			return nil, badRequest("Failed to decode tensor: %v", err)
+		}
+		return []*torch.IValue{torch.NewIValue(tensor.CopyTo(model.device))}, nil
+	case mediaType == "application/x-npy":
+		tensor, err := torch.ReadNpy(body)
+		if err != nil {
+			return nil, badRequest("Failed to decode tensor: %v", err)
+		}
+		return []*torch.IValue{torch.NewIValue(tensor.CopyTo(model.device))}, nil
+	case mediaType == "application/json":
+		var payload struct {
+			Inputs interface{} `json:"inputs"`
+		}
+		if err := json.NewDecoder(body).Decode(&payload); err != nil {
+			return nil, badRequest("Failed to decode JSON: %v", err)
+		}
+		tensor, err := tensorFromJSON(payload.Inputs)
+		if err != nil {
+			return nil, badRequest("%v", err)
+		}
+		return []*torch.IValue{torch.NewIValue(tensor.CopyTo(model.device))}, nil
+	case strings.HasPrefix(mediaType, "image/"):
+		frame, _, err := image.Decode(body)
+		if err != nil {
+			return nil, badRequest("Failed to decode image: %v", err)
+		}
+		tensor := model.transform.Forward(T.ToTensor(frame).CopyTo(model.device).Unsqueeze(0))
+		if model.config.Preprocess.ListInput {
+			return []*torch.IValue{torch.NewIValue([]*torch.Tensor{tensor.Squeeze(0)})}, nil
+		}
+		return []*torch.IValue{torch.NewIValue(tensor)}, nil
+	default:
+		return nil, &httpError{http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported Content-Type %q", mediaType)}
+	}
+}
+
+// Convert nested JSON arrays of numbers to a float32 tensor.
+func tensorFromJSON(value interface{}) (*torch.Tensor, error) {
+	var shape []int64
+	for element := value; ; {
+		array, ok := element.([]interface{})
+		if !ok {
+			break
+		}
+		shape = append(shape, int64(len(array)))
+		if len(array) == 0 {
+			break
+		}
+		element = array[0]
+	}
+	if len(shape) == 0 {
+		return nil, errors.New("Expected inputs to be a nested array of numbers")
+	}
+	var data []float32
+	var flatten func(element interface{}, dim int) error
+	flatten = func(element interface{}, dim int) error {
+		if dim == len(shape) {
+			number, ok := element.(float64)
+			if !ok {
+				return fmt.Errorf("Expected a number but received %v", element)
+			}
+			data = append(data, float32(number))
+			return nil
+		}
+		array, ok := element.([]interface{})
+		if !ok || int64(len(array)) != shape[dim] {
+			return fmt.Errorf("Expected inputs to be a rectangular array with shape %v", shape)
+		}
+		for _, child := range array {
+			if err := flatten(child, dim + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := flatten(value, 0); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return torch.Zeros(shape, torch.NewTensorOptions().Dtype(torch.Float)), nil
+	}
+	return torch.NewTensor(data).Reshape(shape...), nil
+}
+
+// A class prediction from softmax/top-k postprocessing.
+type prediction struct {
+	Label int64 `json:"label"`
+	Name string `json:"name,omitempty"`
+	Score float64 `json:"score"`
+}
+
+// A detection from detection postprocessing.
+type detection struct {
+	Box []float64 `json:"box"`
+	Label int64 `json:"label"`
+	Name string `json:"name,omitempty"`
+	Score float64 `json:"score"`
+}
+
+// Apply the configured postprocessing to the output of the model.
+func (model *servedModel) postprocess(output *torch.IValue) (interface{}, error) {
+	config := model.config.Postprocess
+	if config.Detection != nil {
+		var results [][]detection
+		for _, dict := range findDetections(output) {
+			detections, err := model.filterDetections(dict)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, detections)
+		}
+		if results == nil {
+			return nil, errors.New("Expected model to output dictionaries with boxes, scores, and labels")
+		}
+		return results, nil
+	}
+	if !config.Softmax && config.TopK == 0 {
+		return ivalueToJSON(output), nil
+	}
+	if !output.IsTensor() {
+		return nil, errors.New("Expected model to output a tensor for classification")
+	}
+	scores := output.ToTensor()
+	if scores.Dim() != 2 {
+		return nil, fmt.Errorf("Expected outputs to be in (N, C) format, but received tensor with shape %v", scores.Shape())
+	}
+	if config.Softmax {
+		scores = F.Softmax(scores, 1)
+	}
+	if config.TopK == 0 {
+		return tensorToJSON(scores), nil
+	}
+	k := config.TopK
+	if classes := scores.Shape()[1]; k > classes {
+		k = classes
+	}
+	topk := scores.TopK(k, 1, true, true)
+	values := toFloat64s(topk.Values)
+	indices := topk.Indices.CastTo(torch.Long).ToSlice().([]int64)
+	results := make([][]prediction, scores.Shape()[0])
+	for sample := range results {
+		results[sample] = make([]prediction, k)
+		for rank := range results[sample] {
+			index := int64(sample) * k + int64(rank)
+			results[sample][rank] = prediction{
+				Label: indices[index],
+				Name: model.labelName(indices[index]),
+				Score: values[index],
+			}
+		}
+	}
+	return results, nil
+}
+
+// Return the name of a class label, or an empty string if it is unknown.
+func (model *servedModel) labelName(label int64) string {
+	labels := model.config.Postprocess.Labels
+	if label < 0 || label >= int64(len(labels)) {
+		return ""
+	}
+	return labels[label]
+}
+
+// Find the dictionaries of "boxes", "scores", and "labels" in the output of a
+// detection model, e.g., the list of dictionaries returned by torchvision
+// detection models, possibly nested in a (losses, detections) tuple.
+func findDetections(output *torch.IValue) (dicts []map[interface{}]*torch.IValue) {
+	switch {
+	case output.IsGenericDict():
+		dict := output.ToGenericDict()
+		_, hasBoxes := dict["boxes"]
+		_, hasScores := dict["scores"]
+		_, hasLabels := dict["labels"]
+		if hasBoxes && hasScores && hasLabels {
+			return []map[interface{}]*torch.IValue{dict}
+		}
+	case output.IsTuple():
+		for _, element := range output.ToTuple() {
+			dicts = append(dicts, findDetections(element)...)
+		}
+	case output.IsList():
+		for _, element := range output.ToList() {
+			dicts = append(dicts, findDetections(element)...)
+		}
+	}
+	return
+}
+
+// Filter the detections of a single image by score and count.
+func (model *servedModel) filterDetections(dict map[interface{}]*torch.IValue) ([]detection, error) {
+	config := model.config.Postprocess.Detection
+	scores := toFloat64s(dict["scores"].ToTensor())
+	boxes := toFloat64s(dict["boxes"].ToTensor())
+	var labels []int64
+	if tensor := dict["labels"].ToTensor(); tensor.Numel() > 0 {
+		labels = tensor.CastTo(torch.Long).ToSlice().([]int64)
+	}
+	if len(boxes) != 4 * len(scores) || len(labels) != len(scores) {
+		return nil, fmt.Errorf("Expected 4 box coordinates and 1 label per score, but received %d scores, %d box coordinates, and %d labels", len(scores), len(boxes), len(labels))
+	}
+	indices := make([]int, 0, len(scores))
+	for index, score := range scores {
+		if score >= float64(config.ScoreThreshold) {
+			indices = append(indices, index)
+		}
+	}
+	sort.SliceStable(indices, func(i, j int) bool { return scores[indices[i]] > scores[indices[j]] })
+	if config.MaxDetections > 0 && len(indices) > config.MaxDetections {
+		indices = indices[:config.MaxDetections]
+	}
+	detections := make([]detection, len(indices))
+	for position, index := range indices {
+		detections[position] = detection{
+			Box: boxes[4 * index:4 * index + 4],
+			Label: labels[index],
+			Name: model.labelName(labels[index] - config.LabelOffset),
+			Score: scores[index],
+		}
+	}
+	return detections, nil
+}
+
+// Convert a tensor to a flat slice of float64 on the CPU.
+func toFloat64s(tensor *torch.Tensor) []float64 {
+	if tensor.Numel() == 0 {
+		return []float64{}
+	}
+	return tensor.CopyTo(torch.NewDevice("cpu")).CastTo(torch.Double).ToSlice().([]float64)
+}
+
+// The JSON representation of a tensor.
+type tensorJSON struct {
+	Shape []int64 `json:"shape"`
+	Data interface{} `json:"data"`
+}
+
+// Convert a tensor to its JSON representation with flattened data.
+func tensorToJSON(tensor *torch.Tensor) tensorJSON {
+	tensor = tensor.CopyTo(torch.NewDevice("cpu"))
+	output := tensorJSON{Shape: tensor.Shape(), Data: []interface{}{}}
+	if tensor.Numel() == 0 {
+		return output
+	}
+	switch tensor.Dtype() {
+	// Byte slices would be encoded as base64 strings.
+	case torch.Byte, torch.Char, torch.Short:
+		tensor = tensor.CastTo(torch.Int)
+	case torch.Half, torch.BFloat16:
+		tensor = tensor.CastTo(torch.Float)
+	}
+	data := tensor.ToSlice()
+	// JSON does not support non-finite numbers.
+	switch values := data.(type) {
+	case []float32:
+		for _, value := range values {
+			if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+				data = nonFinite(toFloat64s(tensor))
+				break
+			}
+		}
+	case []float64:
+		data = nonFinite(values)
+	}
+	output.Data = data
+	return output
+}
+
+// Replace non-finite values with null, leaving finite values as numbers.
+func nonFinite(values []float64) interface{} {
+	for _, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			output := make([]interface{}, len(values))
+			for index, value := range values {
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					output[index] = nil
+				} else {
+					output[index] = value
+				}
+			}
+			return output
+		}
+	}
+	return values
+}
+
+// Convert an arbitrary IValue to a JSON-serializable value.
+func ivalueToJSON(output *torch.IValue) interface{} {
+	switch {
+	case output.IsTensor():
+		return tensorToJSON(output.ToTensor())
+	case output.IsTuple(), output.IsList():
+		var elements []*torch.IValue
+		if output.IsTuple() {
+			elements = output.ToTuple()
+		} else {
+			elements = output.ToList()
+		}
+		values := make([]interface{}, len(elements))
+		for index, element := range elements {
+			values[index] = ivalueToJSON(element)
+		}
+		return values
+	case output.IsGenericDict():
+		values := make(map[string]interface{})
+		for key, value := range output.ToGenericDict() {
+			values[fmt.Sprint(key)] = ivalueToJSON(value)
+		}
+		return values
+	case output.IsBool():
+		return output.ToBool()
+	case output.IsInt():
+		return output.ToInt()
+	case output.IsDouble():
+		if value := output.ToDouble(); !math.IsNaN(value) && !math.IsInf(value, 0) {
+			return value
+		}
+		return nil
+	case output.IsString():
+		return output.ToString()
+	default:
+		return nil
+	}
+}
+
+// Write a value as a JSON response with the given status code.
+func writeJSON(writer http.ResponseWriter, status int, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(value)
+}
+
+// Write an error as a JSON response with the given status code.
+func writeError(writer http.ResponseWriter, status int, message string) {
+	writeJSON(writer, status, map[string]string{"error": message})
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	torch "github.com/Kautenja/gotorch"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func newIdentityServer(t *testing.T, postprocess PostprocessConfig) *Server {
+	config := &Config{Models: []ModelConfig{{
+		Name: "identity",
+		Path: "../../data/trace_identity.pt",
+		Postprocess: postprocess,
+	}}}
+	assert.Nil(t, config.Validate())
+	return NewServer(config)
+}
+
+func TestServerReadiness(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	response, err := http.Get(httpServer.URL + "/healthz")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, err = http.Get(httpServer.URL + "/readyz")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Nil(t, server.Load())
+	response, err = http.Get(httpServer.URL + "/readyz")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestServerPredictJSON(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	response, err := http.Post(httpServer.URL + "/v1/models/identity/predict", "application/json", strings.NewReader(`{"inputs": [[1, 2], [3, 4]]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var body struct {
+		Outputs tensorJSON `json:"outputs"`
+	}
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, []int64{2, 2}, body.Outputs.Shape)
+	assert.Equal(t, []interface{}{1.0, 2.0, 3.0, 4.0}, body.Outputs.Data)
+}
+
+func TestServerPredictTensor(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	tensor := torch.NewTensor([][]float32{{1, 2, 3}})
+	encoded, err := tensor.Encode()
+	assert.Nil(t, err)
+	request, _ := http.NewRequest(http.MethodPost, httpServer.URL + "/v1/models/identity/predict", bytes.NewReader(encoded))
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("Accept", "application/octet-stream")
+	response, err := http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	assert.Equal(t, "application/octet-stream", response.Header.Get("Content-Type"))
+	buffer := new(bytes.Buffer)
+	buffer.ReadFrom(response.Body)
+	output, err := torch.Decode(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(tensor, output))
+}
+
+func TestServerPredictNpy(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	tensor := torch.NewTensor([][]float32{{1, 2, 3}})
+	var encoded bytes.Buffer
+	assert.Nil(t, torch.WriteNpy(&encoded, tensor))
+	request, _ := http.NewRequest(http.MethodPost, httpServer.URL + "/v1/models/identity/predict", &encoded)
+	request.Header.Set("Content-Type", "application/x-npy")
+	request.Header.Set("Accept", "application/x-npy")
+	response, err := http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	assert.Equal(t, "application/x-npy", response.Header.Get("Content-Type"))
+	output, err := torch.ReadNpy(response.Body)
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(tensor, output))
+}
+
+// Encode a 2x1 PNG image with a red pixel on the left and a blue pixel on the
+// right.
+func encodePNG(t *testing.T) []byte {
+	frame := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	frame.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	frame.Set(1, 0, color.RGBA{0, 0, 255, 255})
+	var buffer bytes.Buffer
+	assert.Nil(t, png.Encode(&buffer, frame))
+	return buffer.Bytes()
+}
+
+func TestServerPredictImage(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	url := httpServer.URL + "/v1/models/identity/predict"
+	response, err := http.Post(url, "image/png", bytes.NewReader(encodePNG(t)))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	var body struct {
+		Outputs tensorJSON `json:"outputs"`
+	}
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, []int64{1, 3, 1, 2}, body.Outputs.Shape)
+	assert.Equal(t, []interface{}{1.0, 0.0, 0.0, 0.0, 0.0, 1.0}, body.Outputs.Data)
+	response, err = http.Post(url, "image/png", strings.NewReader("not an image"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestServerPredictTopK(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{Softmax: true, TopK: 2, Labels: []string{"a", "b", "c"}})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	response, err := http.Post(httpServer.URL + "/v1/models/identity/predict", "application/json", strings.NewReader(`{"inputs": [[0, 2, 1]]}`))
+	assert.Nil(t, err)
+	var body struct {
+		Outputs [][]prediction `json:"outputs"`
+	}
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, 1, len(body.Outputs))
+	assert.Equal(t, 2, len(body.Outputs[0]))
+	assert.Equal(t, "b", body.Outputs[0][0].Name)
+	assert.Equal(t, "c", body.Outputs[0][1].Name)
+}
+
+func TestServerPredictErrors(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	url := httpServer.URL + "/v1/models/identity/predict"
+	response, err := http.Post(httpServer.URL + "/v1/models/missing/predict", "application/json", strings.NewReader(`{"inputs": [1]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	response, err = http.Post(url, "application/json", strings.NewReader(`{"inputs": [[1], [2, 3]]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	response, err = http.Post(url, "text/plain", strings.NewReader("1"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, response.StatusCode)
+	response, err = http.Get(url)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+}
+
+func TestServerMetrics(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	http.Post(httpServer.URL + "/v1/models/identity/predict", "application/json", strings.NewReader(`{"inputs": [1]}`))
+	response, err := http.Get(httpServer.URL + "/metrics")
+	assert.Nil(t, err)
+	buffer := new(bytes.Buffer)
+	buffer.ReadFrom(response.Body)
+	assert.Contains(t, buffer.String(), `torchserve_requests_total{model="identity"} 1`)
+	assert.Contains(t, buffer.String(), `torchserve_errors_total{model="identity"} 0`)
+	assert.Contains(t, buffer.String(), "torch_memory_live_bytes")
+}
+
+func TestFilterDetections(t *testing.T) {
+	model := &servedModel{config: ModelConfig{Postprocess: PostprocessConfig{
+		Detection: &DetectionConfig{ScoreThreshold: 0.5},
+	}}}
+	dict := map[interface{}]*torch.IValue{
+		"boxes": torch.NewIValue(torch.NewTensor([][]float32{{0, 0, 1, 1}, {1, 1, 2, 2}})),
+		"scores": torch.NewIValue(torch.NewTensor([]float32{0.25, 0.75})),
+		"labels": torch.NewIValue(torch.NewTensor([]int64{1, 2})),
+	}
+	detections, err := model.filterDetections(dict)
+	assert.Nil(t, err)
+	assert.Equal(t, []detection{{Box: []float64{1, 1, 2, 2}, Label: 2, Score: 0.75}}, detections)
+	dict["labels"] = torch.NewIValue(torch.NewTensor([]int64{1}))
+	_, err = model.filterDetections(dict)
+	assert.NotNil(t, err)
+	dict["labels"] = torch.NewIValue(torch.NewTensor([]int64{1, 2}))
+	dict["boxes"] = torch.NewIValue(torch.NewTensor([][]float32{{0, 0, 1, 1}}))
+	_, err = model.filterDetections(dict)
+	assert.NotNil(t, err)
+}
+
+func dialGRPC(t *testing.T, server *Server) (*grpc.ClientConn, func()) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.Nil(t, err)
+	grpcServer := server.NewGRPCServer()
+	go grpcServer.Serve(listener)
+	connection, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	return connection, func() {
+		connection.Close()
+		grpcServer.Stop()
+	}
+}
+
+func TestGRPCHealth(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	connection, closer := dialGRPC(t, server)
+	defer closer()
+	client := healthpb.NewHealthClient(connection)
+	response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, response.Status)
+	assert.Nil(t, server.Load())
+	response, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "identity"})
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, response.Status)
+}
+
+func TestGRPCPredict(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	connection, closer := dialGRPC(t, server)
+	defer closer()
+	client := NewInferenceClient(connection)
+	tensor := torch.NewTensor([][]float32{{1, 2, 3}})
+	encoded, err := tensor.Encode()
+	assert.Nil(t, err)
+	response, err := client.Predict(context.Background(), &PredictRequest{
+		Model: "identity",
+		Inputs: &PredictRequest_Tensor{Tensor: encoded},
+		AcceptTensor: true,
+	})
+	assert.Nil(t, err)
+	output, err := torch.Decode(response.GetTensor())
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(tensor, output))
+	var npy bytes.Buffer
+	assert.Nil(t, torch.WriteNpy(&npy, tensor))
+	response, err = client.Predict(context.Background(), &PredictRequest{
+		Model: "identity",
+		Inputs: &PredictRequest_Npy{Npy: npy.Bytes()},
+		AcceptNpy: true,
+	})
+	assert.Nil(t, err)
+	output, err = torch.ReadNpy(bytes.NewReader(response.GetNpy()))
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(tensor, output))
+	response, err = client.Predict(context.Background(), &PredictRequest{Model: "identity", Inputs: &PredictRequest_Json{Json: `{"inputs": [1, 2]}`}})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"shape":[2],"data":[1,2]}`, response.GetJson())
+	_, err = client.Predict(context.Background(), &PredictRequest{Model: "missing", Inputs: &PredictRequest_Json{Json: `{"inputs": [1]}`}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.Predict(context.Background(), &PredictRequest{Model: "identity"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCPredictImage(t *testing.T) {
+	server := newIdentityServer(t, PostprocessConfig{})
+	defer server.Close()
+	assert.Nil(t, server.Load())
+	connection, closer := dialGRPC(t, server)
+	defer closer()
+	response, err := NewInferenceClient(connection).Predict(context.Background(), &PredictRequest{
+		Model: "identity",
+		Inputs: &PredictRequest_Image{Image: encodePNG(t)},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"shape":[1,3,1,2],"data":[1,0,0,0,0,1]}`, response.GetJson())
+}
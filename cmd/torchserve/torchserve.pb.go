@@ -0,0 +1,392 @@
+// The gRPC interface of torchserve. torchserve.pb.go and
+// torchserve_grpc.pb.go are generated from this file by the go:generate
+// directive in grpc.go, and it can be used to generate clients in other
+// languages.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v3.21.12
+// source: torchserve.proto
+
+package main
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PredictRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the model declared in the configuration.
+	Model string `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	// The inputs to the model.
+	//
+	// Types that are assignable to Inputs:
+	//	*PredictRequest_Tensor
+	//	*PredictRequest_Json
+	//	*PredictRequest_Image
+	//	*PredictRequest_Npy
+	Inputs isPredictRequest_Inputs `protobuf_oneof:"inputs"`
+	// Whether to respond with a tensor in the Tensor.Encode format when the
+	// model outputs a tensor and no postprocessing is configured.
+	AcceptTensor bool `protobuf:"varint,5,opt,name=accept_tensor,json=acceptTensor,proto3" json:"accept_tensor,omitempty"`
+	// Whether to respond with a tensor in the NumPy .npy format instead, which
+	// takes precedence over accept_tensor.
+	AcceptNpy bool `protobuf:"varint,7,opt,name=accept_npy,json=acceptNpy,proto3" json:"accept_npy,omitempty"`
+}
+
+func (x *PredictRequest) Reset() {
+	*x = PredictRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torchserve_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PredictRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictRequest) ProtoMessage() {}
+
+func (x *PredictRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_torchserve_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictRequest.ProtoReflect.Descriptor instead.
+func (*PredictRequest) Descriptor() ([]byte, []int) {
+	return file_torchserve_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PredictRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (m *PredictRequest) GetInputs() isPredictRequest_Inputs {
+	if m != nil {
+		return m.Inputs
+	}
+	return nil
+}
+
+func (x *PredictRequest) GetTensor() []byte {
+	if x, ok := x.GetInputs().(*PredictRequest_Tensor); ok {
+		return x.Tensor
+	}
+	return nil
+}
+
+func (x *PredictRequest) GetJson() string {
+	if x, ok := x.GetInputs().(*PredictRequest_Json); ok {
+		return x.Json
+	}
+	return ""
+}
+
+func (x *PredictRequest) GetImage() []byte {
+	if x, ok := x.GetInputs().(*PredictRequest_Image); ok {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *PredictRequest) GetNpy() []byte {
+	if x, ok := x.GetInputs().(*PredictRequest_Npy); ok {
+		return x.Npy
+	}
+	return nil
+}
+
+func (x *PredictRequest) GetAcceptTensor() bool {
+	if x != nil {
+		return x.AcceptTensor
+	}
+	return false
+}
+
+func (x *PredictRequest) GetAcceptNpy() bool {
+	if x != nil {
+		return x.AcceptNpy
+	}
+	return false
+}
+
+type isPredictRequest_Inputs interface {
+	isPredictRequest_Inputs()
+}
+
+type PredictRequest_Tensor struct {
+	// A tensor in the Tensor.Encode format.
+	Tensor []byte `protobuf:"bytes,2,opt,name=tensor,proto3,oneof"`
+}
+
+type PredictRequest_Json struct {
+	// A JSON object of the form {"inputs": <nested arrays>}.
+	Json string `protobuf:"bytes,3,opt,name=json,proto3,oneof"`
+}
+
+type PredictRequest_Image struct {
+	// A PNG, JPEG, or GIF image passed through the model's preprocessing.
+	Image []byte `protobuf:"bytes,4,opt,name=image,proto3,oneof"`
+}
+
+type PredictRequest_Npy struct {
+	// A tensor in the NumPy .npy format.
+	Npy []byte `protobuf:"bytes,6,opt,name=npy,proto3,oneof"`
+}
+
+func (*PredictRequest_Tensor) isPredictRequest_Inputs() {}
+
+func (*PredictRequest_Json) isPredictRequest_Inputs() {}
+
+func (*PredictRequest_Image) isPredictRequest_Inputs() {}
+
+func (*PredictRequest_Npy) isPredictRequest_Inputs() {}
+
+type PredictResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The outputs of the model.
+	//
+	// Types that are assignable to Outputs:
+	//	*PredictResponse_Tensor
+	//	*PredictResponse_Json
+	//	*PredictResponse_Npy
+	Outputs isPredictResponse_Outputs `protobuf_oneof:"outputs"`
+}
+
+func (x *PredictResponse) Reset() {
+	*x = PredictResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_torchserve_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PredictResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PredictResponse) ProtoMessage() {}
+
+func (x *PredictResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_torchserve_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PredictResponse.ProtoReflect.Descriptor instead.
+func (*PredictResponse) Descriptor() ([]byte, []int) {
+	return file_torchserve_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *PredictResponse) GetOutputs() isPredictResponse_Outputs {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (x *PredictResponse) GetTensor() []byte {
+	if x, ok := x.GetOutputs().(*PredictResponse_Tensor); ok {
+		return x.Tensor
+	}
+	return nil
+}
+
+func (x *PredictResponse) GetJson() string {
+	if x, ok := x.GetOutputs().(*PredictResponse_Json); ok {
+		return x.Json
+	}
+	return ""
+}
+
+func (x *PredictResponse) GetNpy() []byte {
+	if x, ok := x.GetOutputs().(*PredictResponse_Npy); ok {
+		return x.Npy
+	}
+	return nil
+}
+
+type isPredictResponse_Outputs interface {
+	isPredictResponse_Outputs()
+}
+
+type PredictResponse_Tensor struct {
+	// A tensor in the Tensor.Encode format.
+	Tensor []byte `protobuf:"bytes,1,opt,name=tensor,proto3,oneof"`
+}
+
+type PredictResponse_Json struct {
+	// The JSON encoding of the postprocessed outputs.
+	Json string `protobuf:"bytes,2,opt,name=json,proto3,oneof"`
+}
+
+type PredictResponse_Npy struct {
+	// A tensor in the NumPy .npy format.
+	Npy []byte `protobuf:"bytes,3,opt,name=npy,proto3,oneof"`
+}
+
+func (*PredictResponse_Tensor) isPredictResponse_Outputs() {}
+
+func (*PredictResponse_Json) isPredictResponse_Outputs() {}
+
+func (*PredictResponse_Npy) isPredictResponse_Outputs() {}
+
+var File_torchserve_proto protoreflect.FileDescriptor
+
+var file_torchserve_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x74, 0x6f, 0x72, 0x63, 0x68, 0x73, 0x65, 0x72, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0a, 0x74, 0x6f, 0x72, 0x63, 0x68, 0x73, 0x65, 0x72, 0x76, 0x65, 0x22, 0xd0,
+	0x01, 0x0a, 0x0e, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x18, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x73, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x73, 0x6f,
+	0x72, 0x12, 0x14, 0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x00, 0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12,
+	0x12, 0x0a, 0x03, 0x6e, 0x70, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x03,
+	0x6e, 0x70, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x5f, 0x74, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x63, 0x63, 0x65,
+	0x70, 0x74, 0x54, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x65,
+	0x70, 0x74, 0x5f, 0x6e, 0x70, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x63,
+	0x63, 0x65, 0x70, 0x74, 0x4e, 0x70, 0x79, 0x42, 0x08, 0x0a, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74,
+	0x73, 0x22, 0x60, 0x0a, 0x0f, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x06, 0x74, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x12, 0x14,
+	0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04,
+	0x6a, 0x73, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x03, 0x6e, 0x70, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x48, 0x00, 0x52, 0x03, 0x6e, 0x70, 0x79, 0x42, 0x09, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x73, 0x32, 0x4f, 0x0a, 0x09, 0x49, 0x6e, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65,
+	0x12, 0x42, 0x0a, 0x07, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x12, 0x1a, 0x2e, 0x74, 0x6f,
+	0x72, 0x63, 0x68, 0x73, 0x65, 0x72, 0x76, 0x65, 0x2e, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x74, 0x6f, 0x72, 0x63, 0x68, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x2e, 0x50, 0x72, 0x65, 0x64, 0x69, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x4b, 0x61, 0x75, 0x74, 0x65, 0x6e, 0x6a, 0x61, 0x2f, 0x67, 0x6f, 0x74, 0x6f,
+	0x72, 0x63, 0x68, 0x2f, 0x63, 0x6d, 0x64, 0x2f, 0x74, 0x6f, 0x72, 0x63, 0x68, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x3b, 0x6d, 0x61, 0x69, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_torchserve_proto_rawDescOnce sync.Once
+	file_torchserve_proto_rawDescData = file_torchserve_proto_rawDesc
+)
+
+func file_torchserve_proto_rawDescGZIP() []byte {
+	file_torchserve_proto_rawDescOnce.Do(func() {
+		file_torchserve_proto_rawDescData = protoimpl.X.CompressGZIP(file_torchserve_proto_rawDescData)
+	})
+	return file_torchserve_proto_rawDescData
+}
+
+var file_torchserve_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_torchserve_proto_goTypes = []interface{}{
+	(*PredictRequest)(nil),  // 0: torchserve.PredictRequest
+	(*PredictResponse)(nil), // 1: torchserve.PredictResponse
+}
+var file_torchserve_proto_depIdxs = []int32{
+	0, // 0: torchserve.Inference.Predict:input_type -> torchserve.PredictRequest
+	1, // 1: torchserve.Inference.Predict:output_type -> torchserve.PredictResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_torchserve_proto_init() }
+func file_torchserve_proto_init() {
+	if File_torchserve_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_torchserve_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PredictRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_torchserve_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PredictResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_torchserve_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*PredictRequest_Tensor)(nil),
+		(*PredictRequest_Json)(nil),
+		(*PredictRequest_Image)(nil),
+		(*PredictRequest_Npy)(nil),
+	}
+	file_torchserve_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*PredictResponse_Tensor)(nil),
+		(*PredictResponse_Json)(nil),
+		(*PredictResponse_Npy)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_torchserve_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_torchserve_proto_goTypes,
+		DependencyIndexes: file_torchserve_proto_depIdxs,
+		MessageInfos:      file_torchserve_proto_msgTypes,
+	}.Build()
+	File_torchserve_proto = out.File
+	file_torchserve_proto_rawDesc = nil
+	file_torchserve_proto_goTypes = nil
+	file_torchserve_proto_depIdxs = nil
+}
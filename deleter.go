@@ -0,0 +1,45 @@
+// Deleters for tensors that wrap Go memory.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch
+
+// #cgo CPPFLAGS: -I/usr/local/include -I/usr/local/include/cgotorch
+// #cgo LDFLAGS: -L/usr/local/lib -lc10 -ltorch_cpu -ltorch -lcgotorch
+// #include <stdint.h>
+import "C"
+import (
+	"runtime/cgo"
+)
+
+// Call the deleter of a tensor created by TensorFromBlobWithDeleter when
+// libtorch frees its storage. context is a cgo.Handle to the deleter, which
+// is deleted so that the deleter, and any Go values it references, can be
+// garbage collected.
+//
+//export goTorchCallDeleter
+func goTorchCallDeleter(context C.uintptr_t) {
+	handle := cgo.Handle(context)
+	deleter := handle.Value().(func())
+	handle.Delete()
+	deleter()
+}
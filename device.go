@@ -48,7 +48,7 @@ func NewDevice(deviceName string) (device *Device) {
 	deviceNameCString := C.CString(deviceName)
 	defer C.free(unsafe.Pointer(deviceNameCString))
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Device(&device.Pointer, deviceNameCString)))
-	runtime.SetFinalizer(device, (*Device).free)
+	setDeviceFinalizer(device)
 	return
 }
 
@@ -59,6 +59,14 @@ func (device *Device) free() {
 	}
 	C.Torch_Device_Free(device.Pointer)
 	device.Pointer = nil
+	internal.UntrackObject(unsafe.Pointer(device))
+}
+
+// Attach the garbage collection finalizer to a newly created device and
+// record its allocation site for leak detection.
+func setDeviceFinalizer(device *Device) {
+	runtime.SetFinalizer(device, (*Device).free)
+	internal.TrackObject("Device", unsafe.Pointer(device))
 }
 
 // Return true if the given device is valid, false otherwise.
@@ -27,6 +27,7 @@ package torch
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 )
 
@@ -54,6 +55,17 @@ const (
 	Invalid Dtype = -1
 )
 
+// The bit pattern of a bfloat16 number, i.e., the upper 16 bits of a float32.
+// BFloat16 tensors are converted to slices of BFloat16Bits by ToSlice and
+// created from them by NewTensor, so that their bit patterns are not mistaken
+// for those of Half data, which are plain uint16.
+type BFloat16Bits uint16
+
+// Return the float32 value of the bfloat16 number.
+func (bits BFloat16Bits) Float32() float32 {
+	return math.Float32frombits(uint32(bits) << 16)
+}
+
 // Map an element type kind to its associated Dtype.
 func GetDtypeOfKind(kind reflect.Kind) Dtype {
 	switch (kind) {
@@ -63,7 +75,8 @@ func GetDtypeOfKind(kind reflect.Kind) Dtype {
 	case reflect.Int16:      return Short
 	case reflect.Int32:      return Int
 	case reflect.Int64:      return Long
-	case reflect.Uint16:     return Half // TODO: add Bfloat16.
+	case reflect.Int:        return Long
+	case reflect.Uint16:     return Half // BFloat16 uses BFloat16Bits.
 	case reflect.Float32:    return Float
 	case reflect.Float64:    return Double
 	// case reflect.Uint32:     return ComplexHalf
@@ -73,6 +86,15 @@ func GetDtypeOfKind(kind reflect.Kind) Dtype {
 	return Invalid
 }
 
+// Map an element type to its associated Dtype, i.e., BFloat16 for
+// BFloat16Bits and otherwise the Dtype of its kind (see GetDtypeOfKind).
+func GetDtypeOfType(elementType reflect.Type) Dtype {
+	if elementType == reflect.TypeOf(BFloat16Bits(0)) {
+		return BFloat16
+	}
+	return GetDtypeOfKind(elementType.Kind())
+}
+
 // Return the number of bytes consumed by each element of the given data-type.
 func (dtype Dtype) NumBytes() int64 {
 	switch (dtype) {
@@ -39,6 +39,7 @@ func TestGetDtypeOfKind(t *testing.T) {
 	assert.Equal(t, torch.Short,         torch.GetDtypeOfKind(reflect.Int16))
 	assert.Equal(t, torch.Int,           torch.GetDtypeOfKind(reflect.Int32))
 	assert.Equal(t, torch.Long,          torch.GetDtypeOfKind(reflect.Int64))
+	assert.Equal(t, torch.Long,          torch.GetDtypeOfKind(reflect.Int))
 	assert.Equal(t, torch.Half,          torch.GetDtypeOfKind(reflect.Uint16))
 	assert.Equal(t, torch.Float,         torch.GetDtypeOfKind(reflect.Float32))
 	assert.Equal(t, torch.Double,        torch.GetDtypeOfKind(reflect.Float64))
@@ -56,6 +57,18 @@ func TestGetDtypeOfKind(t *testing.T) {
 	assert.Equal(t, torch.Invalid, torch.GetDtypeOfKind(reflect.Slice))
 }
 
+func TestGetDtypeOfType(t *testing.T) {
+	assert.Equal(t, torch.BFloat16, torch.GetDtypeOfType(reflect.TypeOf(torch.BFloat16Bits(0))))
+	assert.Equal(t, torch.Half,     torch.GetDtypeOfType(reflect.TypeOf(uint16(0))))
+	assert.Equal(t, torch.Float,    torch.GetDtypeOfType(reflect.TypeOf(float32(0))))
+	assert.Equal(t, torch.Invalid,  torch.GetDtypeOfType(reflect.TypeOf("")))
+}
+
+func TestBFloat16BitsFloat32(t *testing.T) {
+	assert.Equal(t, float32(1), torch.BFloat16Bits(0x3F80).Float32())
+	assert.Equal(t, float32(-2), torch.BFloat16Bits(0xC000).Float32())
+}
+
 func TestDtypeNumBytes(t *testing.T) {
 	assert.Equal(t, int64(1), torch.Bool.NumBytes())
 	assert.Equal(t, int64(1), torch.Byte.NumBytes())
@@ -31,6 +31,7 @@ package torch
 import "C"
 import (
 	"unsafe"
+	"reflect"
 	"runtime"
 	"github.com/Kautenja/gotorch/internal"
 )
@@ -124,7 +125,7 @@ func Zeros(size []int64, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -137,7 +138,7 @@ func ZerosLike(reference *Tensor) *Tensor {
 		reference.Pointer,
 	)))
 	runtime.KeepAlive(reference)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -152,7 +153,7 @@ func Ones(size []int64, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -165,7 +166,7 @@ func OnesLike(reference *Tensor) *Tensor {
 		reference.Pointer,
 	)))
 	runtime.KeepAlive(reference)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -181,7 +182,7 @@ func Arange(begin, end, step float32, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -197,7 +198,7 @@ func Range(begin, end, step float32, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -213,7 +214,7 @@ func Linspace(begin, end float32, steps int64, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -230,7 +231,7 @@ func Logspace(begin, end float32, steps int64, base float64, options *TensorOpti
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -244,7 +245,7 @@ func Eye(n, m int64, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -259,7 +260,7 @@ func Empty(size []int64, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -272,7 +273,7 @@ func EmptyLike(reference *Tensor) *Tensor {
 		reference.Pointer,
 	)))
 	runtime.KeepAlive(reference)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -287,7 +288,7 @@ func Full(size []int64, value float32, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -301,7 +302,7 @@ func FullLike(reference *Tensor, value float32) *Tensor {
 		C.float(value),
 	)))
 	runtime.KeepAlive(reference)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -316,7 +317,7 @@ func Rand(size []int64, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -328,7 +329,7 @@ func RandLike(reference *Tensor) *Tensor {
 		&tensor.Pointer, reference.Pointer,
 	)))
 	runtime.KeepAlive(reference)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -345,7 +346,7 @@ func RandInt(size []int64, low int64, high int64, options *TensorOptions) *Tenso
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -361,7 +362,7 @@ func RandIntLike(reference *Tensor, low int64, high int64) *Tensor {
 		C.int64_t(high),
 	)))
 	runtime.KeepAlive(reference)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -376,7 +377,7 @@ func RandN(size []int64, options *TensorOptions) *Tensor {
 		options.Pointer,
 	)))
 	runtime.KeepAlive(options)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -390,7 +391,7 @@ func RandNLike(reference *Tensor) *Tensor {
 		reference.Pointer,
 	)))
 	runtime.KeepAlive(reference)
-	runtime.SetFinalizer(tensor, (*Tensor).free)
+	SetTensorFinalizer(tensor)
 	return tensor
 }
 
@@ -398,80 +399,50 @@ func RandNLike(reference *Tensor) *Tensor {
 // MARK: ToSlice
 // ---------------------------------------------------------------------------
 
+// The Go element types of the slices returned by ToSlice for each data-type.
+// Half and BFloat16 are not natively supported, so their elements are the
+// 16-bit patterns of the values, e.g., for use with float16.Frombits, and
+// BFloat16Bits for BFloat16 to tell them apart.
+var sliceElementTypes = map[Dtype]reflect.Type{
+	Byte:          reflect.TypeOf(uint8(0)),
+	Char:          reflect.TypeOf(int8(0)),
+	Short:         reflect.TypeOf(int16(0)),
+	Int:           reflect.TypeOf(int32(0)),
+	Long:          reflect.TypeOf(int64(0)),
+	Half:          reflect.TypeOf(uint16(0)),
+	Float:         reflect.TypeOf(float32(0)),
+	Double:        reflect.TypeOf(float64(0)),
+	// ComplexHalf: TODO
+	ComplexFloat:  reflect.TypeOf(complex64(0)),
+	ComplexDouble: reflect.TypeOf(complex128(0)),
+	Bool:          reflect.TypeOf(false),
+	// QInt8: TODO
+	// QUInt8: TODO
+	// QInt32: TODO
+	BFloat16:      reflect.TypeOf(BFloat16Bits(0)),
+}
+
 // Convert a torch Tensor to a Go slice. This function implies a flattening
 // of the tensor to return 1-dimensional vectors.
 func ToSlice(tensor *Tensor) interface{} {
+	return toSlice(tensor).Interface()
+}
+
+// Copy the elements of a tensor into a new 1-dimensional slice.
+func toSlice(tensor *Tensor) reflect.Value {
 	dtype := tensor.Dtype()
-	tensor = tensor.Flatten(0, -1)
-	length := tensor.Shape()[0]
-	switch dtype {
-	case Byte:
-		output := make([]uint8, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Byte, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case Char:
-		output := make([]int8, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Char, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case Short:
-		output := make([]int16, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Short, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case Int:
-		output := make([]int32, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Int, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case Long:
-		output := make([]int64, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Long, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case Half:  // Not natively supported, use uint16 container
-		output := make([]uint16, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Half, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case Float:
-		output := make([]float32, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Float, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case Double:
-		output := make([]float64, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Double, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	// case ComplexHalf:  // Not natively supported, use uint32 container
-	//     output := make([]uint32, length)
-	//     blob := TensorFromBlob(unsafe.Pointer(&output[0]), ComplexHalf, []int64{length})
-	//     blob.Copy_(tensor)
-	//     return output
-	case ComplexFloat:
-		output := make([]complex64, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), ComplexFloat, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case ComplexDouble:
-		output := make([]complex128, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), ComplexDouble, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	case Bool:
-		output := make([]bool, length)
-		blob := TensorFromBlob(unsafe.Pointer(&output[0]), Bool, []int64{length})
-		blob.Copy_(tensor)
-		return output
-	// case QInt8: TODO
-	// case QUInt8: TODO
-	// case QInt32: TODO
-	// case BFloat16: TODO
-	default:
+	elementType, ok := sliceElementTypes[dtype]
+	if !ok {
 		panic("ToSlice is not supported for dtype")
 	}
+	tensor = tensor.Flatten(0, -1)
+	length := tensor.Shape()[0]
+	output := reflect.MakeSlice(reflect.SliceOf(elementType), int(length), int(length))
+	if length == 0 { return output }
+	blob := TensorFromBlob(output.Index(0).Addr().UnsafePointer(), dtype, []int64{length})
+	blob.Copy_(tensor)
+	runtime.KeepAlive(output.Interface())
+	return output
 }
 
 // Convert the Tensor to a Go slice. This function implies a flattening of the
@@ -480,6 +451,39 @@ func (tensor *Tensor) ToSlice() interface{} {
 	return ToSlice(tensor)
 }
 
+// Convert a torch Tensor to a nested Go slice with one level of nesting per
+// dimension, e.g., [][]float32 for a Float matrix, such that
+// NewTensor(ToNestedSlice(tensor)) recreates the tensor. The element types
+// are those of ToSlice. The nested slices share a single backing array.
+// 0-dimensional tensors are converted to their only element instead.
+func ToNestedSlice(tensor *Tensor) interface{} {
+	shape := tensor.Shape()
+	flat := toSlice(tensor)
+	if len(shape) == 0 { return flat.Index(0).Interface() }
+	return nestSlice(flat, shape).Interface()
+}
+
+// Convert the Tensor to a nested Go slice with one level of nesting per
+// dimension (see ToNestedSlice.)
+func (tensor *Tensor) ToNestedSlice() interface{} {
+	return ToNestedSlice(tensor)
+}
+
+// Split a 1-dimensional slice into nested slices with the given shape.
+func nestSlice(flat reflect.Value, shape []int64) reflect.Value {
+	if len(shape) == 1 { return flat }
+	stride := 1
+	for _, size := range shape[1:] { stride *= int(size) }
+	elementType := flat.Type()
+	for range shape[2:] { elementType = reflect.SliceOf(elementType) }
+	output := reflect.MakeSlice(reflect.SliceOf(elementType), int(shape[0]), int(shape[0]))
+	for index := 0; index < int(shape[0]); index++ {
+		start, end := index * stride, (index + 1) * stride
+		output.Index(index).Set(nestSlice(flat.Slice3(start, end, end), shape[1:]))
+	}
+	return output
+}
+
 // ---------------------------------------------------------------------------
 // MARK: Maths
 // ---------------------------------------------------------------------------
@@ -495,7 +499,7 @@ func Add(tensor, other *Tensor, alpha float32) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -530,7 +534,7 @@ func Sub(tensor, other *Tensor, alpha float32) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -564,7 +568,7 @@ func Mul(tensor, other *Tensor) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -597,7 +601,7 @@ func Div(tensor, other *Tensor) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -628,7 +632,7 @@ func Abs(tensor *Tensor) *Tensor {
 		&output.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -657,7 +661,7 @@ func Square(tensor *Tensor) *Tensor {
 		&output.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -686,7 +690,7 @@ func Sqrt(tensor *Tensor) *Tensor {
 		&output.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -716,7 +720,7 @@ func Pow(tensor *Tensor, exponent float64) *Tensor {
 		&output.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -733,7 +737,7 @@ func Tanh(tensor *Tensor) *Tensor {
 		&output.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -751,7 +755,7 @@ func Sigmoid(tensor *Tensor) *Tensor {
 		&output.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -774,7 +778,7 @@ func LogSoftmax(tensor *Tensor, dim int64) *Tensor {
 		&output.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -798,7 +802,7 @@ func LogicalAnd(tensor, other *Tensor) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -818,7 +822,7 @@ func LogicalNot(tensor *Tensor) *Tensor {
 		tensor.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -840,7 +844,7 @@ func LogicalOr(tensor, other *Tensor) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -861,7 +865,7 @@ func LogicalXor(tensor, other *Tensor) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -883,7 +887,7 @@ func Clamp(tensor, minimum, maximum *Tensor) *Tensor {
 	runtime.KeepAlive(tensor)
     runtime.KeepAlive(minimum)
     runtime.KeepAlive(maximum)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -920,7 +924,7 @@ func ClampMax(tensor, maximum *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(maximum)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -955,7 +959,7 @@ func ClampMin(tensor, minimum *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(minimum)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -984,6 +988,40 @@ func (tensor *Tensor) ClampMin_(minimum *Tensor) *Tensor {
 // MARK: Data layout
 // ---------------------------------------------------------------------------
 
+// Return a view of the storage of the input with the given size, strides, and
+// storage offset, i.e., element i of dimension d is found at storageOffset +
+// sum(i * stride[d]) in the storage. Views that overlap themselves should be
+// treated as read-only.
+func AsStrided(tensor *Tensor, size, stride []int64, storageOffset int64) *Tensor {
+	// An empty size and stride create a 0-dimensional view.
+	var sizePointer, stridePointer *C.int64_t
+	if len(size) > 0 { sizePointer = (*C.int64_t)(unsafe.Pointer(&size[0])) }
+	if len(stride) > 0 { stridePointer = (*C.int64_t)(unsafe.Pointer(&stride[0])) }
+	output := &Tensor{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_AsStrided(
+		&output.Pointer,
+		tensor.Pointer,
+		sizePointer,
+		C.int64_t(len(size)),
+		stridePointer,
+		C.int64_t(len(stride)),
+		C.int64_t(storageOffset),
+	)))
+	runtime.KeepAlive(tensor)
+	runtime.KeepAlive(size)
+	runtime.KeepAlive(stride)
+	SetTensorFinalizer(output)
+	return output
+}
+
+// Return a view of the storage of the tensor with the given size, strides, and
+// storage offset, i.e., element i of dimension d is found at storageOffset +
+// sum(i * stride[d]) in the storage. Views that overlap themselves should be
+// treated as read-only.
+func (tensor *Tensor) AsStrided(size, stride []int64, storageOffset int64) *Tensor {
+	return AsStrided(tensor, size, stride, storageOffset)
+}
+
 // Return a view of the original tensor input with its dimensions permuted.
 func Permute(tensor *Tensor, dims ...int64) *Tensor {
 	output := &Tensor{}
@@ -994,7 +1032,7 @@ func Permute(tensor *Tensor, dims ...int64) *Tensor {
 		&output.Pointer,
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1026,7 +1064,7 @@ func Transpose(tensor *Tensor, dim0, dim1 int64) *Tensor {
 		&output.Pointer,
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1067,7 +1105,7 @@ func Flatten(tensor *Tensor, startDim, endDim int64) *Tensor {
 		&output.Pointer,
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1109,7 +1147,7 @@ func Squeeze(tensor *Tensor, dim ...int64) *Tensor {
 		panic("Squeeze only accepts 0-1 dim as input")
 	}
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1136,7 +1174,7 @@ func Unsqueeze(tensor *Tensor, dim int64) *Tensor {
 		C.int64_t(dim),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1164,7 +1202,7 @@ func Stack(tensors []*Tensor, dim int64) *Tensor {
 		C.int64_t(dim),
 	)))
     runtime.KeepAlive(tensors)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1184,7 +1222,7 @@ func Cat(tensors []*Tensor, dim int64) *Tensor {
 		C.int64_t(dim),
 	)))
     runtime.KeepAlive(tensors)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1216,7 +1254,7 @@ func Slice(tensor *Tensor, dim, start, stop, step int64) *Tensor {
 		C.int64_t(step),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1241,7 +1279,7 @@ func IndexSelect(tensor *Tensor, dim int64, index *Tensor) *Tensor {
 		&output.Pointer,
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1263,7 +1301,7 @@ func Argmin(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Argmin(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1282,7 +1320,7 @@ func ArgminByDim(tensor *Tensor, dim int, keep_dims bool) *Tensor {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1296,7 +1334,7 @@ func Argmax(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Argmax(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1315,7 +1353,7 @@ func ArgmaxByDim(tensor *Tensor, dim int, keep_dims bool) *Tensor {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1333,7 +1371,7 @@ func All(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_All(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1352,7 +1390,7 @@ func AllByDim(tensor *Tensor, dim int, keep_dims bool) *Tensor {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1366,7 +1404,7 @@ func Any(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Any(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1385,7 +1423,7 @@ func AnyByDim(tensor *Tensor, dim int, keep_dims bool) *Tensor {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1402,7 +1440,7 @@ func Max(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Max(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1423,8 +1461,8 @@ func MaxByDim(tensor *Tensor, dim int, keep_dims bool) ValueIndexPair {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(values, (*Tensor).free)
-	runtime.SetFinalizer(indices, (*Tensor).free)
+	SetTensorFinalizer(values)
+	SetTensorFinalizer(indices)
 	return ValueIndexPair{values, indices}
 }
 
@@ -1438,7 +1476,7 @@ func Min(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Min(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1459,8 +1497,8 @@ func MinByDim(tensor *Tensor, dim int, keep_dims bool) ValueIndexPair {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(values, (*Tensor).free)
-	runtime.SetFinalizer(indices, (*Tensor).free)
+	SetTensorFinalizer(values)
+	SetTensorFinalizer(indices)
 	return ValueIndexPair{values, indices}
 }
 
@@ -1474,7 +1512,7 @@ func Mean(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Mean(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1493,7 +1531,7 @@ func MeanByDim(tensor *Tensor, dim int, keep_dims bool) *Tensor {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1509,7 +1547,7 @@ func Median(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Median(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1530,8 +1568,8 @@ func MedianByDim(tensor *Tensor, dim int, keep_dims bool) ValueIndexPair {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(values, (*Tensor).free)
-	runtime.SetFinalizer(indices, (*Tensor).free)
+	SetTensorFinalizer(values)
+	SetTensorFinalizer(indices)
 	return ValueIndexPair{values, indices}
 }
 
@@ -1553,7 +1591,7 @@ func Std(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Std(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1573,7 +1611,7 @@ func StdByDim(tensor *Tensor, dim int, unbiased bool, keep_dims bool) *Tensor {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1592,8 +1630,8 @@ func StdMean(tensor *Tensor) (*Tensor, *Tensor) {
 		tensor.Pointer,
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(std, (*Tensor).free)
-	runtime.SetFinalizer(mean, (*Tensor).free)
+	SetTensorFinalizer(std)
+	SetTensorFinalizer(mean)
 	return std, mean
 }
 
@@ -1615,8 +1653,8 @@ func StdMeanByDim(tensor *Tensor, dim int, unbiased, keep_dims bool) (*Tensor, *
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(std, (*Tensor).free)
-	runtime.SetFinalizer(mean, (*Tensor).free)
+	SetTensorFinalizer(std)
+	SetTensorFinalizer(mean)
 	return std, mean
 }
 
@@ -1630,7 +1668,7 @@ func Sum(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Sum(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1649,7 +1687,7 @@ func SumByDim(tensor *Tensor, dim int, keep_dims bool) *Tensor {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1666,7 +1704,7 @@ func Var(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Var(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1686,7 +1724,7 @@ func VarByDim(tensor *Tensor, dim int, unbiased, keep_dims bool) *Tensor {
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1705,8 +1743,8 @@ func VarMean(tensor *Tensor) (*Tensor, *Tensor) {
 		tensor.Pointer,
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(variance, (*Tensor).free)
-	runtime.SetFinalizer(mean, (*Tensor).free)
+	SetTensorFinalizer(variance)
+	SetTensorFinalizer(mean)
 	return variance, mean
 }
 
@@ -1728,8 +1766,8 @@ func VarMeanByDim(tensor *Tensor, dim int, unbiased, keep_dims bool) (*Tensor, *
 		C.bool(keep_dims),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(variance, (*Tensor).free)
-	runtime.SetFinalizer(mean, (*Tensor).free)
+	SetTensorFinalizer(variance)
+	SetTensorFinalizer(mean)
 	return variance, mean
 }
 
@@ -1792,7 +1830,7 @@ func IsClose(tensor, other *Tensor, rtol, atol float64) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1819,7 +1857,7 @@ func Eq(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1857,7 +1895,7 @@ func GreaterEqual(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1876,7 +1914,7 @@ func Greater(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1895,7 +1933,7 @@ func LessEqual(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1914,7 +1952,7 @@ func Less(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1933,7 +1971,7 @@ func Maximum(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1952,7 +1990,7 @@ func Minimum(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1971,7 +2009,7 @@ func NotEqual(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -1991,7 +2029,7 @@ func IsIn(tensor, other *Tensor) *Tensor {
 	)))
     runtime.KeepAlive(tensor)
     runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -2006,7 +2044,7 @@ func IsFinite(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IsFinite(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -2020,7 +2058,7 @@ func IsInf(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IsInf(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -2034,7 +2072,7 @@ func IsPosInf(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IsPosInf(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -2048,7 +2086,7 @@ func IsNegInf(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IsNegInf(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -2064,7 +2102,7 @@ func IsNaN(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IsNan(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -2082,7 +2120,7 @@ func IsReal(tensor *Tensor) *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IsReal(&output.Pointer, tensor.Pointer)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -2122,8 +2160,8 @@ func TopK(tensor *Tensor, k, dim int64, largest, sorted bool) ValueIndexPair {
 		C.int8_t(s),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(values, (*Tensor).free)
-	runtime.SetFinalizer(indices, (*Tensor).free)
+	SetTensorFinalizer(values)
+	SetTensorFinalizer(indices)
 	return ValueIndexPair{values, indices}
 }
 
@@ -2153,8 +2191,8 @@ func Sort(tensor *Tensor, dim int64, descending bool) ValueIndexPair {
 		C.bool(descending),
 	)))
     runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(values, (*Tensor).free)
-	runtime.SetFinalizer(indices, (*Tensor).free)
+	SetTensorFinalizer(values)
+	SetTensorFinalizer(indices)
 	return ValueIndexPair{values, indices}
 }
 
@@ -2276,7 +2314,7 @@ func MM(a, b *Tensor) *Tensor {
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_MM(&output.Pointer, a.Pointer, b.Pointer)))
     runtime.KeepAlive(a)
     runtime.KeepAlive(b)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -30,6 +30,7 @@ import (
 	"testing"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/x448/float16"
 	"github.com/Kautenja/gotorch"
 )
 
@@ -812,6 +813,72 @@ func TestToSliceBool(t *testing.T) {
 	}
 }
 
+func TestToSliceBFloat16(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1, -2}).CastTo(torch.BFloat16)
+	assert.Equal(t, []torch.BFloat16Bits{0x3F80, 0xC000}, tensor.ToSlice())
+}
+
+func TestToSliceEmpty(t *testing.T) {
+	assert.Equal(t, []int32{}, torch.NewTensor([][]int32{}).ToSlice())
+}
+
+func TestToSliceScalar(t *testing.T) {
+	assert.Equal(t, []float32{3}, torch.NewTensor([]float32{1, 2}).Sum().ToSlice())
+}
+
+// MARK: ToNestedSlice
+
+func TestToNestedSliceRoundTrip(t *testing.T) {
+	for _, data := range []interface{}{
+		[][]bool{{true, false}, {false, true}},
+		[][]uint8{{1, 2}, {3, 4}},
+		[][]int8{{-1, 2}, {3, -4}},
+		[][]int16{{-1, 2}, {3, -4}},
+		[][]int32{{-1, 2}, {3, -4}},
+		[][]int64{{-1, 2}, {3, 1 << 40}},
+		[][]uint16{{float16.Fromfloat32(1).Bits(), float16.Fromfloat32(-0.5).Bits()}},
+		[][]torch.BFloat16Bits{{0x3F80, 0xC000}, {0x3F00, 0x4080}},
+		[][]float32{{0.5, 2}, {3, -4}},
+		[][]float64{{0.5, 2}, {3, -4}},
+		[][]complex64{{complex(1, 0.5), 2}, {3, complex(0, -4)}},
+		[][]complex128{{complex(1, 0.5), 2}, {3, complex(0, -4)}},
+		[][][]float32{{{1, 2, 3}, {4, 5, 6}}, {{7, 8, 9}, {10, 11, 12}}},
+		[]float32{1, 2, 3},
+	} {
+		tensor := torch.NewTensor(data)
+		nested := tensor.ToNestedSlice()
+		assert.Equal(t, data, nested)
+		restored := torch.NewTensor(nested)
+		assert.Equal(t, tensor.Dtype(), restored.Dtype())
+		assert.True(t, torch.Equal(tensor, restored))
+	}
+}
+
+func TestToNestedSliceBFloat16(t *testing.T) {
+	tensor := torch.NewTensor([][]float32{{1}, {-2}}).CastTo(torch.BFloat16)
+	assert.Equal(t, [][]torch.BFloat16Bits{{0x3F80}, {0xC000}}, tensor.ToNestedSlice())
+}
+
+func TestToNestedSliceFromArray(t *testing.T) {
+	tensor := torch.NewTensor([2][3]float32{{1, 2, 3}, {4, 5, 6}})
+	assert.Equal(t, [][]float32{{1, 2, 3}, {4, 5, 6}}, tensor.ToNestedSlice())
+}
+
+func TestToNestedSliceOfNonContiguousTensor(t *testing.T) {
+	tensor := torch.NewTensor([][]int64{{1, 2, 3}, {4, 5, 6}}).Transpose(0, 1)
+	assert.Equal(t, [][]int64{{1, 4}, {2, 5}, {3, 6}}, tensor.ToNestedSlice())
+}
+
+func TestToNestedSliceEmpty(t *testing.T) {
+	tensor := torch.Zeros([]int64{2, 0, 3}, torch.NewTensorOptions())
+	assert.Equal(t, [][][]float32{{}, {}}, tensor.ToNestedSlice())
+}
+
+func TestToNestedSliceScalar(t *testing.T) {
+	tensor := torch.NewTensor([]int64{1, 2}).Sum()
+	assert.Equal(t, int64(3), tensor.ToNestedSlice())
+}
+
 // -----------------------------------------------------------------------------
 // MARK: Maths
 // -----------------------------------------------------------------------------
@@ -937,6 +1004,41 @@ func TestPermute(t *testing.T) {
 	a.True(torch.Equal(expected, y))
 }
 
+// >>> x = torch.arange(6.)
+// >>> torch.as_strided(x, (2, 2), (1, 2), 1)
+// tensor([[1., 3.],
+//         [2., 4.]])
+func TestAsStrided(t *testing.T) {
+	x := torch.Arange(0, 6, 1, torch.NewTensorOptions())
+	y := torch.AsStrided(x, []int64{2, 2}, []int64{1, 2}, 1)
+	expected := torch.NewTensor([][]float32{{1, 3}, {2, 4}})
+	assert.True(t, y.Equal(expected), "Expected %v got %v", expected, y)
+	assert.Equal(t, []int64{1, 2}, y.Strides())
+	assert.Equal(t, int64(1), y.StorageOffset())
+	assert.True(t, y.IsSameStorage(x))
+}
+
+func TestAsStridedSharesStorage(t *testing.T) {
+	x := torch.Zeros([]int64{4}, torch.NewTensorOptions())
+	// A view of the diagonal of x as a 2x2 matrix.
+	y := x.AsStrided([]int64{2}, []int64{3}, 0)
+	y.Copy_(torch.NewTensor([]float32{1, 1}))
+	expected := torch.NewTensor([]float32{1, 0, 0, 1})
+	assert.True(t, x.Equal(expected), "Expected %v got %v", expected, x)
+}
+
+func TestAsStridedScalar(t *testing.T) {
+	x := torch.Arange(0, 6, 1, torch.NewTensorOptions())
+	y := x.AsStrided([]int64{}, []int64{}, 4)
+	assert.Equal(t, []int64{}, y.Shape())
+	assert.Equal(t, float32(4), y.Item())
+}
+
+func TestAsStridedPanicsOutOfBounds(t *testing.T) {
+	x := torch.Zeros([]int64{4}, torch.NewTensorOptions())
+	assert.Panics(t, func() { x.AsStrided([]int64{2, 2}, []int64{2, 1}, 2) })
+}
+
 // >>> torch.nn.functional.log_softmax(torch.tensor([[-0.5, -1.], [1., 0.5]]), dim=1)
 // tensor([[-0.4741, -0.9741],
 //         [-0.4741, -0.9741]])
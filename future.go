@@ -0,0 +1,222 @@
+// Go futures for asynchronous computations and TorchScript futures.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch
+
+// #cgo CPPFLAGS: -I/usr/local/include -I/usr/local/include/cgotorch
+// #cgo LDFLAGS: -L/usr/local/lib -lc10 -ltorch_cpu -ltorch -lcgotorch
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// A Future is the eventual output of an asynchronous computation, e.g., of
+// JitModule.ForwardAsync or of a TorchScript function started with
+// torch.jit.fork. A Future resolves exactly once, either with a value or with
+// an error. Its methods are safe for concurrent use.
+type Future struct {
+	done chan struct{}
+	value *IValue
+	err error
+	resolveOnce sync.Once
+	// Starts the computation the first time the future is observed, or nil.
+	start func()
+	startOnce sync.Once
+}
+
+// Create a future that is resolved by a call to resolve.
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// Resolve the future with a value or an error. Only the first call has an
+// effect.
+func (future *Future) resolve(value *IValue, err error) {
+	future.resolveOnce.Do(func() {
+		future.value = value
+		future.err = err
+		close(future.done)
+	})
+}
+
+// How long a worker of RunAsync waits for another function before it exits.
+const asyncWorkerIdleTimeout = time.Minute
+
+// Functions for idle workers of RunAsync. The channel is unbuffered so that a
+// send succeeds only if a worker is waiting for work.
+var asyncTasks = make(chan func())
+
+// Run a task on an idle worker of RunAsync, or start a new worker if they are
+// all busy.
+func runOnAsyncWorker(task func()) {
+	select {
+	case asyncTasks <- task:
+	default:
+		go asyncWorker(task)
+	}
+}
+
+// Run tasks on a goroutine that is locked to its own OS thread until no task
+// arrives for asyncWorkerIdleTimeout. The thread is never unlocked, so the Go
+// runtime terminates it along with its libtorch thread-local storage when the
+// worker exits.
+func asyncWorker(task func()) {
+	runtime.LockOSThread()
+	idle := time.NewTimer(asyncWorkerIdleTimeout)
+	defer idle.Stop()
+	for {
+		task()
+		if !idle.Stop() {
+			<-idle.C
+		}
+		idle.Reset(asyncWorkerIdleTimeout)
+		select {
+		case task = <-asyncTasks:
+		case <-idle.C:
+			return
+		}
+	}
+}
+
+// Run a function on a worker goroutine that is locked to its own OS thread
+// and return a future of its output. Panics in the function resolve the
+// future with an error, which is the panic value itself if it is an error,
+// e.g., a *TorchError. If the context is cancelled first, the future resolves
+// with the context's error: the function does not start if it has not
+// already, and its output is discarded if it has.
+//
+// Workers are reused by later calls and exit after they have been idle for a
+// minute, so libtorch does not rebuild its thread-local state for every call.
+// Thread-local state, e.g., SetGradEnabled, persists on a worker between
+// calls, so the function must set any state that it depends on itself.
+func RunAsync(ctx context.Context, function func() (*IValue, error)) *Future {
+	future := newFuture()
+	runOnAsyncWorker(func() {
+		if err := ctx.Err(); err != nil {
+			future.resolve(nil, err)
+			return
+		}
+		future.resolve(runRecovered(function))
+	})
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-future.done:
+			case <-ctx.Done():
+				future.resolve(nil, ctx.Err())
+			}
+		}()
+	}
+	return future
+}
+
+// Call a function and convert panics to errors, preserving panic values that
+// are already errors.
+func runRecovered(function func() (*IValue, error)) (value *IValue, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			value, err = nil, recoveredError(recovered)
+		}
+	}()
+	return function()
+}
+
+// Return a value recovered from a panic as an error.
+func recoveredError(recovered interface{}) error {
+	if err, ok := recovered.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", recovered)
+}
+
+// Convert the IValue to a future, e.g., the output of a TorchScript method
+// that returns torch.jit.fork(...) without waiting for it. The returned
+// future waits for the TorchScript future on its own goroutine, which starts
+// the first time the future is observed. ToFuture panics if the IValue does
+// not hold a future.
+func (ivalue *IValue) ToFuture() *Future {
+	if !ivalue.IsFuture() {
+		panic(fmt.Sprintf("Expected IValue to hold a future but found %s", ivalue.TypeName()))
+	}
+	future := newFuture()
+	future.start = func() {
+		go func() {
+			output := &IValue{}
+			err := unsafe.Pointer(C.Torch_Future_Wait(&output.Pointer, ivalue.Pointer))
+			runtime.KeepAlive(ivalue)
+			if err != nil {
+				future.resolve(nil, internal.NewTorchError(err))
+				return
+			}
+			SetIValueFinalizer(output)
+			future.resolve(output, nil)
+		}()
+	}
+	return future
+}
+
+// Start the computation of the future if it is started lazily.
+func (future *Future) observe() {
+	if future.start == nil { return }
+	future.startOnce.Do(future.start)
+}
+
+// Return a channel that is closed when the future resolves, e.g., to select
+// on the future alongside other channels:
+//
+//	select {
+//	case <-future.Done():
+//		output, err := future.Wait()
+//		...
+//	case <-ctx.Done():
+//		...
+//	}
+func (future *Future) Done() <-chan struct{} {
+	future.observe()
+	return future.done
+}
+
+// Return true if the future has resolved.
+func (future *Future) IsCompleted() bool {
+	select {
+	case <-future.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Block until the future resolves and return its value or error.
+func (future *Future) Wait() (*IValue, error) {
+	<-future.Done()
+	return future.value, future.err
+}
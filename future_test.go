@@ -0,0 +1,141 @@
+// test cases for future.go
+//
+// Copyright (c) 2023 Christian Kauten
+// Copyright (c) 2022 Sensory, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package torch_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+)
+
+// MARK: RunAsync
+
+func TestRunAsync(t *testing.T) {
+	future := torch.RunAsync(context.Background(), func() (*torch.IValue, error) {
+		return torch.NewIValue(1), nil
+	})
+	output, err := future.Wait()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, output.ToInt())
+	assert.True(t, future.IsCompleted())
+	// Wait returns the same result every time.
+	again, _ := future.Wait()
+	assert.Equal(t, output, again)
+}
+
+func TestRunAsyncReturnsErrors(t *testing.T) {
+	expected := errors.New("foo")
+	output, err := torch.RunAsync(context.Background(), func() (*torch.IValue, error) {
+		return nil, expected
+	}).Wait()
+	assert.Nil(t, output)
+	assert.Equal(t, expected, err)
+}
+
+func TestRunAsyncConvertsPanicsToErrors(t *testing.T) {
+	output, err := torch.RunAsync(context.Background(), func() (*torch.IValue, error) {
+		panic("foo")
+	}).Wait()
+	assert.Nil(t, output)
+	assert.EqualError(t, err, "foo")
+}
+
+type runAsyncTestError struct{ message string }
+
+func (err *runAsyncTestError) Error() string { return err.message }
+
+func TestRunAsyncKeepsTheTypesOfPanickedErrors(t *testing.T) {
+	_, err := torch.RunAsync(context.Background(), func() (*torch.IValue, error) {
+		panic(&runAsyncTestError{"foo"})
+	}).Wait()
+	var expected *runAsyncTestError
+	if !assert.True(t, errors.As(err, &expected)) { return }
+	assert.Equal(t, "foo", expected.message)
+}
+
+func TestRunAsyncRunsConcurrentFunctions(t *testing.T) {
+	release := make(chan struct{})
+	blocked := torch.RunAsync(context.Background(), func() (*torch.IValue, error) {
+		<-release
+		return torch.NewIValue(1), nil
+	})
+	// A busy worker does not delay other functions.
+	output, err := torch.RunAsync(context.Background(), func() (*torch.IValue, error) {
+		return torch.NewIValue(2), nil
+	}).Wait()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, output.ToInt())
+	assert.False(t, blocked.IsCompleted())
+	close(release)
+	output, err = blocked.Wait()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, output.ToInt())
+}
+
+func TestRunAsyncIsNotCompletedWhileRunning(t *testing.T) {
+	release := make(chan struct{})
+	future := torch.RunAsync(context.Background(), func() (*torch.IValue, error) {
+		<-release
+		return torch.NewIValue(1), nil
+	})
+	assert.False(t, future.IsCompleted())
+	close(release)
+	<-future.Done()
+	assert.True(t, future.IsCompleted())
+}
+
+func TestRunAsyncDoesNotStartAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	started := false
+	output, err := torch.RunAsync(ctx, func() (*torch.IValue, error) {
+		started = true
+		return torch.NewIValue(1), nil
+	}).Wait()
+	assert.Nil(t, output)
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, started)
+}
+
+func TestRunAsyncResolvesOnCancelWhileRunning(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+	defer cancel()
+	release := make(chan struct{})
+	defer close(release)
+	output, err := torch.RunAsync(ctx, func() (*torch.IValue, error) {
+		<-release
+		return torch.NewIValue(1), nil
+	}).Wait()
+	assert.Nil(t, output)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+// MARK: ToFuture
+
+func TestIValueToFuturePanicsForNonFuture(t *testing.T) {
+	assert.Panics(t, func() { torch.NewIValue(1).ToFuture() })
+}
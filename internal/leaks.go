@@ -0,0 +1,180 @@
+// Allocation site tracking for detecting leaked native objects.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// The environment variable that enables leak detection when set to a
+// non-empty value. Leak detection is also enabled by the gotorch_debug build
+// tag.
+const LeakDetectionEnvironmentVariable = "GOTORCH_DEBUG_LEAKS"
+
+// The maximum number of stack frames recorded for each allocation.
+const maxAllocationFrames = 32
+
+// A native object that has been allocated but not yet freed.
+type allocation struct {
+	kind string
+	sequence uint64
+	stack []uintptr
+}
+
+// A group of live native objects of the same kind allocated from the same
+// call stack.
+type AllocationSite struct {
+	// The kind of the objects, e.g., "Tensor".
+	Kind string
+	// The number of live objects allocated from the site.
+	Count int
+	// The formatted call stack of the allocation site.
+	Stack string
+}
+
+var (
+	// Non-zero if allocation sites are being recorded.
+	leakDetection int32
+	// The sequence number of the most recent allocation.
+	allocationSequence uint64
+	allocationsMutex sync.Mutex
+	// The live allocations keyed by the address of their Go struct. Addresses
+	// are stored as integers so the registry does not keep objects reachable
+	// and prevent their finalizers from running.
+	allocations = make(map[uintptr]allocation)
+	// The number of entries in allocations, which lets UntrackObject skip the
+	// mutex when nothing has been recorded, e.g., when leak detection is off.
+	trackedObjects int64
+)
+
+func init() {
+	SetLeakDetection(leakDetectionBuild || os.Getenv(LeakDetectionEnvironmentVariable) != "")
+}
+
+// Enable or disable recording of allocation sites. Objects allocated while
+// leak detection is disabled are never reported.
+func SetLeakDetection(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&leakDetection, value)
+}
+
+// Return true if allocation sites are being recorded.
+func LeakDetectionEnabled() bool {
+	return atomic.LoadInt32(&leakDetection) != 0
+}
+
+// Record the allocation site of a native object of the given kind. object is
+// the address of the Go struct that wraps it. The call stack is recorded from
+// the caller of the function that calls TrackObject, which is expected to be
+// the helper that attaches the object's finalizer.
+func TrackObject(kind string, object unsafe.Pointer) {
+	if !LeakDetectionEnabled() {
+		return
+	}
+	stack := make([]uintptr, maxAllocationFrames)
+	// Skip runtime.Callers, TrackObject, and the finalizer helper.
+	stack = stack[:runtime.Callers(3, stack)]
+	allocationsMutex.Lock()
+	defer allocationsMutex.Unlock()
+	allocationSequence++
+	if _, ok := allocations[uintptr(object)]; !ok {
+		atomic.AddInt64(&trackedObjects, 1)
+	}
+	allocations[uintptr(object)] = allocation{kind, allocationSequence, stack}
+}
+
+// Remove a native object from the registry when it is freed.
+func UntrackObject(object unsafe.Pointer) {
+	if atomic.LoadInt64(&trackedObjects) == 0 {
+		return
+	}
+	allocationsMutex.Lock()
+	defer allocationsMutex.Unlock()
+	if _, ok := allocations[uintptr(object)]; ok {
+		delete(allocations, uintptr(object))
+		atomic.AddInt64(&trackedObjects, -1)
+	}
+}
+
+// Return the sequence number of the most recent allocation.
+func AllocationSequence() uint64 {
+	allocationsMutex.Lock()
+	defer allocationsMutex.Unlock()
+	return allocationSequence
+}
+
+// Format a call stack with one "function\n\tfile:line" entry per frame.
+func formatStack(stack []uintptr) string {
+	var builder strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
+
+// Return the live objects allocated after the given sequence number grouped
+// by kind and allocation site, in descending order of count.
+func LiveAllocationSites(since uint64) []AllocationSite {
+	allocationsMutex.Lock()
+	groups := make(map[string]*AllocationSite)
+	for _, live := range allocations {
+		if live.sequence <= since {
+			continue
+		}
+		stack := formatStack(live.stack)
+		key := live.kind + "\n" + stack
+		if site, ok := groups[key]; ok {
+			site.Count++
+		} else {
+			groups[key] = &AllocationSite{live.kind, 1, stack}
+		}
+	}
+	allocationsMutex.Unlock()
+	sites := make([]AllocationSite, 0, len(groups))
+	for _, site := range groups {
+		sites = append(sites, *site)
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Count != sites[j].Count {
+			return sites[i].Count > sites[j].Count
+		}
+		return sites[i].Kind + sites[i].Stack < sites[j].Kind + sites[j].Stack
+	})
+	return sites
+}
@@ -0,0 +1,7 @@
+//go:build gotorch_debug
+// +build gotorch_debug
+
+package internal
+
+// Leak detection is enabled by default in builds with the gotorch_debug tag.
+const leakDetectionBuild = true
@@ -0,0 +1,7 @@
+//go:build !gotorch_debug
+// +build !gotorch_debug
+
+package internal
+
+// Leak detection is disabled by default unless enabled by the environment.
+const leakDetectionBuild = false
@@ -32,33 +32,67 @@ import (
 
 // Extract the shape and data-type of a slice. Returns a tuple of the shape of
 // the tensor-like data structure, and the kind of the elements contained by
-// the tensor-like structure.
+// the tensor-like structure. Slices and arrays may be nested in any order,
+// e.g., [][3]float32. The shape is read from the first element of each
+// dimension, so jagged slices should be rejected with CheckSliceSizes.
 func GetSizesAndKindOfSlice(data interface{}) ([]int64, reflect.Kind) {
 	var size []int64
 	current_vector := reflect.ValueOf(data)
+	current_type := current_vector.Type()
 	for {  // Recursively unwrap the interface to find the shape and data-type.
-		// Check the type of the data, if it isn't a slice then we've reached
-		// the bottom of the recursion and can return the shape and data type.
-		kind := current_vector.Type().Kind()
-		if kind != reflect.Slice {
+		// Check the type of the data, if it isn't a slice or array then we've
+		// reached the bottom of the recursion and can return the shape and
+		// data type.
+		kind := current_type.Kind()
+		if kind != reflect.Slice && kind != reflect.Array {
 			return size, kind
 		}
-		// Otherwise, we're working with a slice, add the length of the slice to
-		// the output shape.
-		size = append(size, int64(current_vector.Len()))
-		// It's possible that we're dealing with an empty slice. This can be the
-		// case for inputs like `[]float32{}` expressing an empty vector. In
-		// This special case we return the current size (which validly contains)
-		// a 0 for the current dimension and introspect the type of the empty
-		// slice using `Elem`.
-		if current_vector.Len() == 0 {
-			return size, current_vector.Type().Elem().Kind()
+		if current_vector.IsValid() {
+			// Add the length of the slice to the output shape.
+			size = append(size, int64(current_vector.Len()))
+			// It's possible that we're dealing with an empty slice, e.g.,
+			// `[][]float32{}`. In this case there is no element to unwrap, so
+			// the remaining dimensions are introspected from the types alone.
+			if current_vector.Len() == 0 {
+				current_vector = reflect.Value{}
+			} else {
+				current_vector = current_vector.Index(0)
+			}
+		} else if kind == reflect.Array {
+			// The length of arrays is part of their type.
+			size = append(size, int64(current_type.Len()))
+		} else {
+			// The slices inside an empty slice have no elements.
+			size = append(size, 0)
 		}
-		// If we reach this point, unwrap the next slice/value and recurse.
-		current_vector = current_vector.Index(0)
+		current_type = current_type.Elem()
 	}
 }
 
+// Check that every nested slice has the length given by sizes, i.e., that the
+// data is not jagged, and return an error describing the first slice that
+// does not. sizes is typically the output of GetSizesAndKindOfSlice.
+func CheckSliceSizes(data interface{}, sizes []int64) error {
+	return checkSliceSizes(reflect.ValueOf(data), sizes, "")
+}
+
+// Recursively check the lengths of nested slices. path is the index of the
+// value in the top-level data, e.g., "[1][0]".
+func checkSliceSizes(value reflect.Value, sizes []int64, path string) error {
+	if len(sizes) == 0 {
+		return nil
+	}
+	if value.Len() != int(sizes[0]) {
+		return fmt.Errorf("Expected slice of length %d at %s but found length %d", sizes[0], path, value.Len())
+	}
+	for index := 0; index < value.Len(); index++ {
+		if err := checkSliceSizes(value.Index(index), sizes[1:], fmt.Sprintf("%s[%d]", path, index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Flatten a slice of the given kind to a 1-dimensional buffer of contiguous
 // data. The input is expected to be an n-dimensional slice of data and the
 // output is the `Data` buffer of the flattened representation of the input.
@@ -85,7 +119,7 @@ func FlattenSlice(slc interface{}, kind reflect.Kind) unsafe.Pointer {
 	case reflect.Int32:
 		f := flattenSliceInt(nil, reflect.ValueOf(slc))
 		return unsafe.Pointer((*reflect.SliceHeader)(unsafe.Pointer(&f)).Data)
-	case reflect.Int64:
+	case reflect.Int, reflect.Int64:
 		f := flattenSliceLong(nil, reflect.ValueOf(slc))
 		return unsafe.Pointer((*reflect.SliceHeader)(unsafe.Pointer(&f)).Data)
 	case reflect.Uint16:
@@ -58,6 +58,26 @@ func TestGetSizesAndKindOfSlice_2DSlice(t *testing.T) {
 	assert.Equal(t, []int64{3, 2}, shape)
 }
 
+func TestGetSizesAndKindOfSlice_Array(t *testing.T) {
+	data := [3][2]float32{{1, 2}, {3, 4}, {5, 6}}
+	shape, kind := internal.GetSizesAndKindOfSlice(data)
+	assert.Equal(t, []int64{3, 2}, shape)
+	assert.Equal(t, reflect.Float32, kind)
+}
+
+func TestGetSizesAndKindOfSlice_EmptyNestedSlice(t *testing.T) {
+	data := [][]float32{}
+	shape, kind := internal.GetSizesAndKindOfSlice(data)
+	assert.Equal(t, []int64{0, 0}, shape)
+	assert.Equal(t, reflect.Float32, kind)
+}
+
+func TestGetSizesAndKindOfSlice_EmptySliceOfArrays(t *testing.T) {
+	data := [][4]float32{}
+	shape, _ := internal.GetSizesAndKindOfSlice(data)
+	assert.Equal(t, []int64{0, 4}, shape)
+}
+
 func TestGetSizesAndKindOfSlice_String(t *testing.T) {
 	data := "foo"
 	_, kind := internal.GetSizesAndKindOfSlice(data)
@@ -130,6 +150,22 @@ func TestGetSizesAndKindOfSlice_Complex128(t *testing.T) {
 	assert.Equal(t, reflect.Complex128, kind)
 }
 
+// MARK: CheckSliceSizes
+
+func TestCheckSliceSizes(t *testing.T) {
+	data := [][]float32{{1, 2}, {3, 4}}
+	assert.NoError(t, internal.CheckSliceSizes(data, []int64{2, 2}))
+}
+
+func TestCheckSliceSizes_Jagged(t *testing.T) {
+	data := [][]float32{{1, 2}, {3}}
+	err := internal.CheckSliceSizes(data, []int64{2, 2})
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Equal(t, "Expected slice of length 2 at [1] but found length 1", err.Error())
+}
+
 // MARK: FlattenSlice
 
 func TestFlattenSlice_PanicsOnInvalidKindInput(t *testing.T) {
@@ -357,3 +393,15 @@ func TestFlattenSlice_Complex128(t *testing.T) {
 	assert.Equal(t, complex128(complex(1.3, 0.4)), data[4])
 	assert.Equal(t, complex128(complex(1.3, 0.0)), data[5])
 }
+
+
+func TestFlattenSlice_Int(t *testing.T) {
+	slice := [][]int{{1, 2}, {3, 4}}
+	// FlattenSlice returns raw data, we need to mock a slice with the buffer.
+	var data []int64
+	header := (*reflect.SliceHeader)(unsafe.Pointer(&data))
+	header.Data = uintptr(internal.FlattenSlice(slice, reflect.Int))
+	header.Len = 4
+	header.Cap = 4
+	assert.Equal(t, []int64{1, 2, 3, 4}, data)
+}
@@ -0,0 +1,379 @@
+// Zero-copy adapters between tensors and Apache Arrow arrays and tensors.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package arrow adapts CPU tensors to Apache Arrow arrays and tensors, and
+// back, without copying. Tensors become primitive arrays when 1-dimensional
+// and nested fixed-size-list arrays otherwise, e.g., a tensor of shape (N, D)
+// becomes N lists of D values, which is how feature stores commonly hold
+// embeddings.
+//
+// The adapters share memory and keep it alive for as long as either side
+// uses it. Arrow buffers made from a tensor retain the storage of the tensor,
+// with a reference counted by Retain and Release, until the last reference is
+// released. Tensors made from Arrow data retain the data until libtorch frees
+// their storage. As with any Arrow allocator, slices of buffers, e.g.,
+// Float32Values, must not be used after the array is released. cgo forbids
+// libtorch from retaining Go memory, so tensors can only share Arrow data
+// allocated outside of Go, e.g., by memory.NewCgoArrowAllocator or by ToArray;
+// CopyFromArray and CopyFromTensor copy Arrow data in Go memory instead.
+// Arrow data is immutable by convention, so tensors that share Arrow data
+// should not be modified in-place.
+package arrow
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/apache/arrow/go/v11/arrow/tensor"
+	"github.com/Kautenja/gotorch"
+)
+
+// Return the Arrow data-type of the elements of a tensor.
+func dataTypeOf(dtype torch.Dtype) (arrow.DataType, error) {
+	switch dtype {
+	case torch.Byte:   return arrow.PrimitiveTypes.Uint8, nil
+	case torch.Char:   return arrow.PrimitiveTypes.Int8, nil
+	case torch.Short:  return arrow.PrimitiveTypes.Int16, nil
+	case torch.Int:    return arrow.PrimitiveTypes.Int32, nil
+	case torch.Long:   return arrow.PrimitiveTypes.Int64, nil
+	case torch.Half:   return arrow.FixedWidthTypes.Float16, nil
+	case torch.Float:  return arrow.PrimitiveTypes.Float32, nil
+	case torch.Double: return arrow.PrimitiveTypes.Float64, nil
+	}
+	return nil, fmt.Errorf("Tensors of dtype %v have no Arrow equivalent", dtype)
+}
+
+// Return the tensor data-type of Arrow elements.
+func dtypeOf(dataType arrow.DataType) (torch.Dtype, error) {
+	switch dataType.ID() {
+	case arrow.UINT8:   return torch.Byte, nil
+	case arrow.INT8:    return torch.Char, nil
+	case arrow.INT16:   return torch.Short, nil
+	case arrow.INT32:   return torch.Int, nil
+	case arrow.INT64:   return torch.Long, nil
+	case arrow.FLOAT16: return torch.Half, nil
+	case arrow.FLOAT32: return torch.Float, nil
+	case arrow.FLOAT64: return torch.Double, nil
+	}
+	return torch.Invalid, fmt.Errorf("Arrow data of type %v has no tensor equivalent", dataType)
+}
+
+// Return the number of elements between the first and the last element of a
+// tensor with the given shape and strides, inclusive, or 0 if it is empty.
+func extentOf(shape, strides []int64) int64 {
+	extent := int64(1)
+	for index, size := range shape {
+		if size == 0 {
+			return 0
+		}
+		extent += (size - 1) * strides[index]
+	}
+	return extent
+}
+
+// An Arrow allocator for the memory of a single tensor storage. Free releases
+// the storage when the reference count of the buffer that owns the memory
+// drops to zero. The memory belongs to libtorch, so it cannot be resized.
+type storageAllocator struct {
+	release func()
+}
+
+func (allocator *storageAllocator) Allocate(size int) []byte {
+	panic("Cannot allocate memory in the storage of a tensor")
+}
+
+func (allocator *storageAllocator) Reallocate(size int, bytes []byte) []byte {
+	panic("Cannot resize memory in the storage of a tensor")
+}
+
+func (allocator *storageAllocator) Free(bytes []byte) {
+	allocator.release()
+}
+
+// Return an Arrow buffer that shares the data of a tensor from its first
+// element to its last, which preserves the strides of the tensor. The buffer
+// retains the storage of the tensor until its reference count drops to zero.
+func newBuffer(data *torch.Tensor) (*memory.Buffer, error) {
+	if !data.IsCPU() {
+		return nil, errors.New("Expected CPU tensor but found tensor on another device")
+	}
+	strides := data.Strides()
+	for _, stride := range strides {
+		if stride < 0 {
+			return nil, fmt.Errorf("Expected tensor with non-negative strides but found strides %v", strides)
+		}
+	}
+	extent := extentOf(data.Shape(), strides)
+	if extent == 0 {
+		return memory.NewBufferBytes([]byte{}), nil
+	}
+	// The memory belongs to libtorch, so Go may hold on to it.
+	bytes := unsafe.Slice((*byte)(data.DataPtr()), extent * data.Dtype().NumBytes())
+	buffer := memory.NewResizableBuffer(&storageAllocator{release: data.RetainStorage()})
+	runtime.KeepAlive(data)
+	buffer.Reset(bytes)
+	return buffer, nil
+}
+
+// Return an Arrow array that shares the data of a CPU tensor with at least 1
+// dimension. 1-dimensional tensors become primitive arrays, e.g.,
+// *array.Float32, and tensors of shape (N, D1, ..., Dk) become N nested
+// fixed-size lists, i.e., *array.FixedSizeList, of D1 lists and so on down to
+// Dk values. Arrow arrays are row-major, so tensors that are not contiguous,
+// e.g., transposes, are copied to contiguous memory first. The array retains
+// the storage of the tensor, so the caller should Release the array when done
+// with it.
+func ToArray(data *torch.Tensor) (arrow.Array, error) {
+	dataType, err := dataTypeOf(data.Dtype())
+	if err != nil {
+		return nil, err
+	}
+	shape := data.Shape()
+	if len(shape) == 0 {
+		return nil, errors.New("Expected tensor with at least 1 dimension but found a scalar")
+	}
+	// Contiguous returns the tensor itself when it is already contiguous.
+	buffer, err := newBuffer(data.Contiguous())
+	if err != nil {
+		return nil, err
+	}
+	arrayData := array.NewData(dataType, int(data.Numel()), []*memory.Buffer{nil, buffer}, nil, 0, 0)
+	// The array data holds its own reference to the buffer.
+	buffer.Release()
+	// Wrap the values in a list for each dimension from the innermost out.
+	for dim := len(shape) - 1; dim > 0; dim-- {
+		dataType = arrow.FixedSizeListOf(int32(shape[dim]), dataType)
+		child := arrayData
+		arrayData = array.NewData(dataType, int(product(shape[:dim])), []*memory.Buffer{nil}, []arrow.ArrayData{child}, 0, 0)
+		child.Release()
+	}
+	defer arrayData.Release()
+	return array.MakeFromData(arrayData), nil
+}
+
+// Return the product of the sizes of a shape.
+func product(shape []int64) int64 {
+	output := int64(1)
+	for _, size := range shape {
+		output *= size
+	}
+	return output
+}
+
+// Return the bytes of the values buffer of primitive Arrow data.
+func valueBytes(data arrow.ArrayData) []byte {
+	buffers := data.Buffers()
+	if len(buffers) < 2 || buffers[1] == nil {
+		return nil
+	}
+	return buffers[1].Bytes()
+}
+
+// Return a tensor of the given shape and strides that shares count elements of
+// raw data from the given element offset. owner is retained until libtorch
+// frees the storage of the tensor. The data must not be in Go memory.
+func wrapBytes(bytes []byte, dtype torch.Dtype, offset, count int64, shape, strides []int64, owner interface{ Retain(); Release() }) (*torch.Tensor, error) {
+	if count == 0 {
+		return torch.Empty(shape, torch.NewTensorOptions().Dtype(dtype)), nil
+	}
+	if int64(len(bytes)) < (offset + count) * dtype.NumBytes() {
+		return nil, fmt.Errorf("Expected %d bytes of data but found %d", (offset + count) * dtype.NumBytes(), len(bytes))
+	}
+	owner.Retain()
+	blob := torch.TensorFromBlobWithDeleter(
+		unsafe.Pointer(&bytes[offset * dtype.NumBytes()]),
+		dtype,
+		[]int64{count},
+		owner.Release,
+	)
+	// Free the blob so that the storage is freed with the view of it.
+	defer blob.Free()
+	return blob.AsStrided(shape, strides, 0), nil
+}
+
+// Return a tensor of the given shape and strides with a copy of count elements
+// of raw data from the given element offset.
+func copyBytes(bytes []byte, dtype torch.Dtype, offset, count int64, shape, strides []int64) (*torch.Tensor, error) {
+	if count == 0 {
+		return torch.Empty(shape, torch.NewTensorOptions().Dtype(dtype)), nil
+	}
+	if int64(len(bytes)) < (offset + count) * dtype.NumBytes() {
+		return nil, fmt.Errorf("Expected %d bytes of data but found %d", (offset + count) * dtype.NumBytes(), len(bytes))
+	}
+	blob := torch.NewTensorFromBlob(unsafe.Pointer(&bytes[offset * dtype.NumBytes()]), dtype, []int64{count})
+	runtime.KeepAlive(bytes)
+	// Free the blob so that its storage is freed with the copy of its view.
+	defer blob.Free()
+	view := blob.AsStrided(shape, strides, 0)
+	defer view.Free()
+	return view.Clone(), nil
+}
+
+// Return the values of a primitive Arrow array of numeric values or of a
+// nested fixed-size-list array of them, along with the shape of the tensor and
+// the element offset of the array in the values.
+func arrayValues(values arrow.Array) (data arrow.ArrayData, shape []int64, offset int64, err error) {
+	data = values.Data()
+	shape = []int64{int64(data.Len())}
+	// The index of the first element of the array in the innermost data.
+	offset = int64(data.Offset())
+	for {
+		if data.NullN() > 0 {
+			return nil, nil, 0, fmt.Errorf("Expected array without nulls but found %d nulls", data.NullN())
+		}
+		listType, ok := data.DataType().(*arrow.FixedSizeListType)
+		if !ok {
+			break
+		}
+		size := int64(listType.Len())
+		data = data.Children()[0]
+		offset = int64(data.Offset()) + offset * size
+		shape = append(shape, size)
+	}
+	return data, shape, offset, nil
+}
+
+// Return a tensor that shares the data of a primitive Arrow array of numeric
+// values or of a nested fixed-size-list array of them, e.g., an array made by
+// ToArray. Nested lists become the inner dimensions of the tensor. The array
+// must not have nulls. Its memory must be allocated outside of Go, e.g., by
+// memory.NewCgoArrowAllocator or by ToArray, because libtorch retains it; use
+// CopyFromArray for arrays in Go memory. The array data is retained until
+// libtorch frees the storage of the tensor and every view of it.
+func FromArray(values arrow.Array) (*torch.Tensor, error) {
+	data, shape, offset, err := arrayValues(values)
+	if err != nil {
+		return nil, err
+	}
+	dtype, err := dtypeOf(data.DataType())
+	if err != nil {
+		return nil, err
+	}
+	return wrapBytes(valueBytes(data), dtype, offset, product(shape), shape, contiguousStrides(shape), values.Data())
+}
+
+// Return a tensor with a copy of the data of an array that FromArray accepts,
+// which may be in Go memory, e.g., an array built with memory.DefaultAllocator.
+// The array is not retained.
+func CopyFromArray(values arrow.Array) (*torch.Tensor, error) {
+	data, shape, offset, err := arrayValues(values)
+	if err != nil {
+		return nil, err
+	}
+	dtype, err := dtypeOf(data.DataType())
+	if err != nil {
+		return nil, err
+	}
+	return copyBytes(valueBytes(data), dtype, offset, product(shape), shape, contiguousStrides(shape))
+}
+
+// Return the strides of a contiguous tensor with the given shape.
+func contiguousStrides(shape []int64) []int64 {
+	strides := make([]int64, len(shape))
+	stride := int64(1)
+	for dim := len(shape) - 1; dim >= 0; dim-- {
+		strides[dim] = stride
+		stride *= shape[dim]
+	}
+	return strides
+}
+
+// Return an Arrow tensor that shares the data of a CPU tensor. The strides of
+// the tensor are preserved, so views, e.g., transposes, are not copied and
+// become column-major Arrow tensors. The Arrow tensor retains the storage of
+// the tensor, so the caller should Release the Arrow tensor when done with it.
+func ToTensor(data *torch.Tensor) (tensor.Interface, error) {
+	dtype := data.Dtype()
+	if dtype == torch.Half {
+		return nil, errors.New("Arrow tensors do not support Half data")
+	}
+	dataType, err := dataTypeOf(dtype)
+	if err != nil {
+		return nil, err
+	}
+	buffer, err := newBuffer(data)
+	if err != nil {
+		return nil, err
+	}
+	shape := data.Shape()
+	// Arrow tensors measure strides in bytes instead of elements.
+	strides := data.Strides()
+	for dim := range strides {
+		strides[dim] *= dtype.NumBytes()
+	}
+	length := int(extentOf(shape, data.Strides()))
+	arrayData := array.NewData(dataType, length, []*memory.Buffer{nil, buffer}, nil, 0, 0)
+	buffer.Release()
+	defer arrayData.Release()
+	return tensor.New(arrayData, shape, strides, nil), nil
+}
+
+// Return the dtype, strides in elements, element offset, and values of an
+// Arrow tensor of numeric values.
+func tensorValues(values tensor.Interface) (dtype torch.Dtype, strides []int64, offset int64, bytes []byte, err error) {
+	dtype, err = dtypeOf(values.DataType())
+	if err != nil {
+		return torch.Invalid, nil, 0, nil, err
+	}
+	// Arrow tensors measure strides in bytes instead of elements.
+	strides = make([]int64, len(values.Shape()))
+	for dim, stride := range values.Strides() {
+		if stride < 0 || stride % dtype.NumBytes() != 0 {
+			return torch.Invalid, nil, 0, nil, fmt.Errorf("Expected non-negative strides in multiples of %d bytes but found strides %v", dtype.NumBytes(), values.Strides())
+		}
+		strides[dim] = stride / dtype.NumBytes()
+	}
+	data := values.Data()
+	return dtype, strides, int64(data.Offset()), valueBytes(data), nil
+}
+
+// Return a tensor that shares the data of an Arrow tensor of numeric values,
+// e.g., an Arrow tensor made by ToTensor. The strides of the Arrow tensor are
+// preserved. Its memory must be allocated outside of Go, e.g., by
+// memory.NewCgoArrowAllocator or by ToTensor, because libtorch retains it; use
+// CopyFromTensor for Arrow tensors in Go memory. The Arrow tensor is retained
+// until libtorch frees the storage of the tensor and every view of it.
+func FromTensor(values tensor.Interface) (*torch.Tensor, error) {
+	dtype, strides, offset, bytes, err := tensorValues(values)
+	if err != nil {
+		return nil, err
+	}
+	shape := values.Shape()
+	return wrapBytes(bytes, dtype, offset, extentOf(shape, strides), shape, strides, values)
+}
+
+// Return a tensor with a copy of the data of an Arrow tensor that FromTensor
+// accepts, which may be in Go memory. The strides of the Arrow tensor are
+// preserved. The Arrow tensor is not retained.
+func CopyFromTensor(values tensor.Interface) (*torch.Tensor, error) {
+	dtype, strides, offset, bytes, err := tensorValues(values)
+	if err != nil {
+		return nil, err
+	}
+	shape := values.Shape()
+	return copyBytes(bytes, dtype, offset, extentOf(shape, strides), shape, strides)
+}
@@ -0,0 +1,325 @@
+// test cases for arrow.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package arrow_test
+
+import (
+	"testing"
+	"unsafe"
+	"github.com/stretchr/testify/assert"
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/apache/arrow/go/v11/arrow/tensor"
+	"github.com/Kautenja/gotorch"
+	torcharrow "github.com/Kautenja/gotorch/interop/arrow"
+)
+
+// MARK: ToArray
+
+func TestToArray(t *testing.T) {
+	data := torch.NewTensor([]float32{1, 2, 3})
+	values, err := torcharrow.ToArray(data)
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	floats, ok := values.(*array.Float32)
+	if !assert.True(t, ok) { return }
+	assert.Equal(t, []float32{1, 2, 3}, floats.Float32Values())
+	assert.Equal(t, data.DataPtr(), unsafe.Pointer(&floats.Float32Values()[0]))
+}
+
+func TestToArrayOfMatrix(t *testing.T) {
+	data := torch.NewTensor([][]int64{{1, 2, 3}, {4, 5, 6}})
+	values, err := torcharrow.ToArray(data)
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	assert.True(t, arrow.TypeEqual(arrow.FixedSizeListOf(3, arrow.PrimitiveTypes.Int64), values.DataType()))
+	lists, ok := values.(*array.FixedSizeList)
+	if !assert.True(t, ok) { return }
+	assert.Equal(t, 2, lists.Len())
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6}, lists.ListValues().(*array.Int64).Int64Values())
+}
+
+func TestToArrayOfNestedLists(t *testing.T) {
+	data := torch.NewTensor([][][]uint8{{{1, 2}, {3, 4}, {5, 6}}, {{7, 8}, {9, 10}, {11, 12}}})
+	values, err := torcharrow.ToArray(data)
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	expected := arrow.FixedSizeListOf(3, arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Uint8))
+	assert.True(t, arrow.TypeEqual(expected, values.DataType()))
+	assert.Equal(t, 2, values.Len())
+	rows := values.(*array.FixedSizeList).ListValues()
+	assert.Equal(t, 6, rows.Len())
+}
+
+func TestToArrayOfHalf(t *testing.T) {
+	values, err := torcharrow.ToArray(torch.NewTensor([]float32{1, 2}).CastTo(torch.Half))
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	assert.Equal(t, arrow.FLOAT16, values.DataType().ID())
+	assert.Equal(t, float32(2), values.(*array.Float16).Value(1).Float32())
+}
+
+func TestToArrayOfTranspose(t *testing.T) {
+	data := torch.NewTensor([][]float32{{1, 2}, {3, 4}}).Transpose(0, 1)
+	values, err := torcharrow.ToArray(data)
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	assert.Equal(t, []float32{1, 3, 2, 4}, values.(*array.FixedSizeList).ListValues().(*array.Float32).Float32Values())
+}
+
+func TestToArraySharesData(t *testing.T) {
+	data := torch.NewTensor([]float64{1, 2, 3})
+	values, err := torcharrow.ToArray(data)
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	data.Copy_(torch.NewTensor([]float64{4, 5, 6}))
+	// The array retains the storage after the tensor is freed.
+	data.Free()
+	assert.Equal(t, []float64{4, 5, 6}, values.(*array.Float64).Float64Values())
+}
+
+func TestToArrayReleasesStorage(t *testing.T) {
+	data := torch.Rand([]int64{1024}, torch.NewTensorOptions())
+	values, err := torcharrow.ToArray(data)
+	if !assert.NoError(t, err) { return }
+	data.Free()
+	before := torch.MemoryStats()
+	values.Release()
+	after := torch.MemoryStats()
+	assert.Less(t, after.LiveAllocations, before.LiveAllocations)
+}
+
+func TestToArrayErrors(t *testing.T) {
+	_, err := torcharrow.ToArray(torch.NewTensor([]bool{true}))
+	assert.Error(t, err)
+	_, err = torcharrow.ToArray(torch.NewTensor([]float32{1}).Squeeze())
+	assert.EqualError(t, err, "Expected tensor with at least 1 dimension but found a scalar")
+}
+
+// MARK: FromArray
+
+func TestFromArray(t *testing.T) {
+	data := torch.NewTensor([]float32{1, 2, 3})
+	values, err := torcharrow.ToArray(data)
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	output, err := torcharrow.FromArray(values)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, torch.Float, output.Dtype())
+	assert.Equal(t, []float32{1, 2, 3}, output.ToSlice())
+	assert.Equal(t, data.DataPtr(), output.DataPtr())
+}
+
+func TestFromArrayOfSlice(t *testing.T) {
+	values, err := torcharrow.ToArray(torch.NewTensor([]int32{1, 2, 3, 4}))
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	slice := array.NewSlice(values, 1, 3)
+	defer slice.Release()
+	data, err := torcharrow.FromArray(slice)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int32{2, 3}, data.ToSlice())
+}
+
+func TestFromArrayOfFixedSizeLists(t *testing.T) {
+	values, err := torcharrow.ToArray(torch.NewTensor([][]int64{{0, 0}, {1, 10}, {2, 20}}))
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	data, err := torcharrow.FromArray(values)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]int64{{0, 0}, {1, 10}, {2, 20}}, data.ToNestedSlice())
+	// Slices of lists are offset by whole lists.
+	slice := array.NewSlice(values, 1, 3)
+	defer slice.Release()
+	data, err = torcharrow.FromArray(slice)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]int64{{1, 10}, {2, 20}}, data.ToNestedSlice())
+}
+
+func TestFromArrayOfToArray(t *testing.T) {
+	data := torch.Rand([]int64{2, 3, 4}, torch.NewTensorOptions())
+	values, err := torcharrow.ToArray(data)
+	if !assert.NoError(t, err) { return }
+	defer values.Release()
+	output, err := torcharrow.FromArray(values)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{2, 3, 4}, output.Shape())
+	assert.True(t, output.Equal(data))
+	assert.True(t, output.IsSameStorage(data))
+}
+
+func TestFromArrayRetainsArray(t *testing.T) {
+	values, err := torcharrow.ToArray(torch.NewTensor([]float32{1, 2, 3}))
+	if !assert.NoError(t, err) { return }
+	data, err := torcharrow.FromArray(values)
+	if !assert.NoError(t, err) { return }
+	// The tensor holds its own reference to the array data.
+	values.Release()
+	assert.Equal(t, []float32{1, 2, 3}, data.ToSlice())
+}
+
+func TestFromArrayOfEmptyArray(t *testing.T) {
+	builder := array.NewFloat64Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	values := builder.NewArray()
+	defer values.Release()
+	data, err := torcharrow.FromArray(values)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{0}, data.Shape())
+	assert.Equal(t, torch.Double, data.Dtype())
+}
+
+func TestFromArrayErrors(t *testing.T) {
+	builder := array.NewFloat32Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.AppendValues([]float32{1, 2}, []bool{true, false})
+	values := builder.NewArray()
+	defer values.Release()
+	_, err := torcharrow.FromArray(values)
+	assert.EqualError(t, err, "Expected array without nulls but found 1 nulls")
+	strings := array.NewStringBuilder(memory.DefaultAllocator)
+	defer strings.Release()
+	strings.Append("a")
+	values = strings.NewArray()
+	defer values.Release()
+	_, err = torcharrow.FromArray(values)
+	assert.EqualError(t, err, "Arrow data of type utf8 has no tensor equivalent")
+}
+
+// MARK: CopyFromArray
+
+func TestCopyFromArray(t *testing.T) {
+	builder := array.NewFloat32Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.AppendValues([]float32{1, 2, 3}, nil)
+	values := builder.NewFloat32Array()
+	defer values.Release()
+	data, err := torcharrow.CopyFromArray(values)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, torch.Float, data.Dtype())
+	assert.Equal(t, []float32{1, 2, 3}, data.ToSlice())
+	assert.NotEqual(t, unsafe.Pointer(&values.Float32Values()[0]), data.DataPtr())
+}
+
+func TestCopyFromArrayOfFixedSizeListSlice(t *testing.T) {
+	builder := array.NewFixedSizeListBuilder(memory.DefaultAllocator, 2, arrow.PrimitiveTypes.Int64)
+	defer builder.Release()
+	for index := int64(0); index < 3; index++ {
+		builder.Append(true)
+		builder.ValueBuilder().(*array.Int64Builder).AppendValues([]int64{index, 10 * index}, nil)
+	}
+	values := builder.NewArray()
+	defer values.Release()
+	slice := array.NewSlice(values, 1, 3)
+	defer slice.Release()
+	data, err := torcharrow.CopyFromArray(slice)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]int64{{1, 10}, {2, 20}}, data.ToNestedSlice())
+}
+
+func TestCopyFromArrayDoesNotRetainArray(t *testing.T) {
+	allocator := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	builder := array.NewFloat32Builder(allocator)
+	builder.AppendValues([]float32{1, 2, 3}, nil)
+	values := builder.NewArray()
+	builder.Release()
+	data, err := torcharrow.CopyFromArray(values)
+	if !assert.NoError(t, err) { return }
+	values.Release()
+	allocator.AssertSize(t, 0)
+	assert.Equal(t, []float32{1, 2, 3}, data.ToSlice())
+}
+
+// MARK: ToTensor
+
+func TestToTensor(t *testing.T) {
+	data := torch.NewTensor([][]float32{{1, 2, 3}, {4, 5, 6}})
+	output, err := torcharrow.ToTensor(data)
+	if !assert.NoError(t, err) { return }
+	defer output.Release()
+	assert.Equal(t, []int64{2, 3}, output.Shape())
+	assert.Equal(t, []int64{12, 4}, output.Strides())
+	assert.True(t, output.IsRowMajor())
+	assert.Equal(t, float32(6), output.(*tensor.Float32).Value([]int64{1, 2}))
+}
+
+func TestToTensorOfTranspose(t *testing.T) {
+	data := torch.NewTensor([][]float64{{1, 2, 3}, {4, 5, 6}}).Transpose(0, 1)
+	output, err := torcharrow.ToTensor(data)
+	if !assert.NoError(t, err) { return }
+	defer output.Release()
+	assert.Equal(t, []int64{3, 2}, output.Shape())
+	assert.True(t, output.IsColMajor())
+	assert.Equal(t, 6.0, output.(*tensor.Float64).Value([]int64{2, 1}))
+	assert.Equal(t, 2.0, output.(*tensor.Float64).Value([]int64{1, 0}))
+}
+
+func TestToTensorErrorsOnHalf(t *testing.T) {
+	_, err := torcharrow.ToTensor(torch.NewTensor([]float32{1}).CastTo(torch.Half))
+	assert.EqualError(t, err, "Arrow tensors do not support Half data")
+}
+
+// MARK: FromTensor
+
+func TestFromTensorOfToTensor(t *testing.T) {
+	data := torch.Rand([]int64{3, 4}, torch.NewTensorOptions()).Transpose(0, 1)
+	output, err := torcharrow.ToTensor(data)
+	if !assert.NoError(t, err) { return }
+	defer output.Release()
+	input, err := torcharrow.FromTensor(output)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, data.Strides(), input.Strides())
+	assert.True(t, input.Equal(data))
+	assert.True(t, input.IsSameStorage(data))
+}
+
+// MARK: CopyFromTensor
+
+func TestCopyFromTensor(t *testing.T) {
+	builder := array.NewFloat64Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.AppendValues([]float64{1, 2, 3, 4, 5, 6}, nil)
+	values := builder.NewArray()
+	defer values.Release()
+	input := tensor.NewFloat64(values.Data(), []int64{2, 3}, nil, nil)
+	defer input.Release()
+	data, err := torcharrow.CopyFromTensor(input)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]float64{{1, 2, 3}, {4, 5, 6}}, data.ToNestedSlice())
+	assert.Equal(t, []int64{3, 1}, data.Strides())
+}
+
+func TestCopyFromTensorOfColumnMajorTensor(t *testing.T) {
+	builder := array.NewFloat64Builder(memory.DefaultAllocator)
+	defer builder.Release()
+	builder.AppendValues([]float64{1, 4, 2, 5, 3, 6}, nil)
+	values := builder.NewArray()
+	defer values.Release()
+	input := tensor.NewFloat64(values.Data(), []int64{2, 3}, []int64{8, 16}, nil)
+	defer input.Release()
+	data, err := torcharrow.CopyFromTensor(input)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{1, 2}, data.Strides())
+	assert.Equal(t, [][]float64{{1, 2, 3}, {4, 5, 6}}, data.ToNestedSlice())
+}
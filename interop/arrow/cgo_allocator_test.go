@@ -0,0 +1,76 @@
+//go:build cgo && ccalloc
+// +build cgo,ccalloc
+
+// test cases for arrow.go with memory from the Arrow C++ memory pool
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package arrow_test
+
+import (
+	"testing"
+	"unsafe"
+	"github.com/stretchr/testify/assert"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/apache/arrow/go/v11/arrow/tensor"
+	torcharrow "github.com/Kautenja/gotorch/interop/arrow"
+)
+
+// MARK: FromArray
+
+func TestFromArrayOfCgoArrowAllocator(t *testing.T) {
+	allocator := memory.NewCgoArrowAllocator()
+	builder := array.NewFloat32Builder(allocator)
+	builder.AppendValues([]float32{1, 2, 3}, nil)
+	values := builder.NewFloat32Array()
+	builder.Release()
+	data, err := torcharrow.FromArray(values)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, unsafe.Pointer(&values.Float32Values()[0]), data.DataPtr())
+	// The tensor retains the array data until libtorch frees its storage.
+	values.Release()
+	assert.NotZero(t, allocator.AllocatedBytes())
+	assert.Equal(t, []float32{1, 2, 3}, data.ToSlice())
+	data.Free()
+	allocator.AssertSize(t, 0)
+}
+
+// MARK: FromTensor
+
+func TestFromTensorOfCgoArrowAllocator(t *testing.T) {
+	allocator := memory.NewCgoArrowAllocator()
+	builder := array.NewFloat64Builder(allocator)
+	builder.AppendValues([]float64{1, 4, 2, 5, 3, 6}, nil)
+	values := builder.NewArray()
+	builder.Release()
+	input := tensor.NewFloat64(values.Data(), []int64{2, 3}, []int64{8, 16}, nil)
+	values.Release()
+	data, err := torcharrow.FromTensor(input)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{1, 2}, data.Strides())
+	assert.Equal(t, [][]float64{{1, 2, 3}, {4, 5, 6}}, data.ToNestedSlice())
+	input.Release()
+	assert.NotZero(t, allocator.AllocatedBytes())
+	data.Free()
+	allocator.AssertSize(t, 0)
+}
@@ -0,0 +1,17 @@
+module github.com/Kautenja/gotorch/interop/gonum
+
+go 1.18
+
+replace github.com/Kautenja/gotorch => ../..
+
+require (
+	github.com/Kautenja/gotorch v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.8.1
+	gonum.org/v1/gonum v0.13.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,112 @@
+// Zero-copy adapters between tensors and gonum matrices and vectors.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package gonum converts CPU tensors of Double data to gonum matrices and
+// vectors, and back. Unlike the adapters of interop/arrow, the conversions
+// copy the data, because gonum has no reference counting that could tie the
+// lifetimes of the two sides together safely; each function documents why.
+package gonum
+
+import (
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"github.com/Kautenja/gotorch"
+)
+
+// Check that a tensor is a CPU tensor of Double data with the given number of
+// dimensions and no empty dimensions, as gonum requires.
+func checkTensor(tensor *torch.Tensor, dim int64) error {
+	if !tensor.IsCPU() {
+		return errors.New("Expected CPU tensor but found tensor on another device")
+	}
+	if dtype := tensor.Dtype(); dtype != torch.Double {
+		return fmt.Errorf("Expected tensor of dtype %v but found dtype %v", torch.Double, dtype)
+	}
+	shape := tensor.Shape()
+	if int64(len(shape)) != dim {
+		return fmt.Errorf("Expected %d-dimensional tensor but found shape %v", dim, shape)
+	}
+	for _, size := range shape {
+		if size == 0 {
+			return fmt.Errorf("Expected tensor without empty dimensions but found shape %v", shape)
+		}
+	}
+	return nil
+}
+
+// Return a Dense matrix with a copy of the data of a 2-dimensional tensor of
+// Double data. The tensor may be strided, e.g., a transpose. The data is not
+// shared because views of the matrix, e.g., from Slice, RowView, and
+// RawMatrix, share its backing slice and can outlive it, so nothing could tell
+// when to release the storage of the tensor.
+func ToDense(tensor *torch.Tensor) (*mat.Dense, error) {
+	if err := checkTensor(tensor, 2); err != nil {
+		return nil, err
+	}
+	shape := tensor.Shape()
+	return mat.NewDense(int(shape[0]), int(shape[1]), tensor.ToSlice().([]float64)), nil
+}
+
+// Return a VecDense vector with a copy of the data of a 1-dimensional tensor
+// of Double data. The tensor may be strided, e.g., a column of a matrix. The
+// data is not shared because views of the vector, e.g., from SliceVec and
+// RawVector, share its backing slice and can outlive it, so nothing could tell
+// when to release the storage of the tensor.
+func ToVecDense(tensor *torch.Tensor) (*mat.VecDense, error) {
+	if err := checkTensor(tensor, 1); err != nil {
+		return nil, err
+	}
+	return mat.NewVecDense(int(tensor.Shape()[0]), tensor.ToSlice().([]float64)), nil
+}
+
+// Return a 2-dimensional tensor of Double data with a copy of the data of a
+// Dense matrix. The matrix may be a view, e.g., from Slice. The data is not
+// shared because it is Go memory, which cgo forbids libtorch from retaining.
+func FromDense(dense *mat.Dense) (*torch.Tensor, error) {
+	if dense.IsEmpty() {
+		return nil, errors.New("Expected non-empty matrix")
+	}
+	raw := dense.RawMatrix()
+	data := make([]float64, raw.Rows * raw.Cols)
+	for row := 0; row < raw.Rows; row++ {
+		copy(data[row * raw.Cols:(row + 1) * raw.Cols], raw.Data[row * raw.Stride:])
+	}
+	return torch.FromSlice(data, int64(raw.Rows), int64(raw.Cols)).Tensor, nil
+}
+
+// Return a 1-dimensional tensor of Double data with a copy of the data of a
+// VecDense vector. The vector may be a view, e.g., from ColView. The data is
+// not shared because it is Go memory, which cgo forbids libtorch from
+// retaining.
+func FromVecDense(vector *mat.VecDense) (*torch.Tensor, error) {
+	if vector.IsEmpty() {
+		return nil, errors.New("Expected non-empty vector")
+	}
+	raw := vector.RawVector()
+	data := make([]float64, raw.N)
+	for index := range data {
+		data[index] = raw.Data[index * raw.Inc]
+	}
+	return torch.FromSlice(data).Tensor, nil
+}
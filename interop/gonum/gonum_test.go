@@ -0,0 +1,168 @@
+// test cases for gonum.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package gonum_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/mat"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/interop/gonum"
+)
+
+// MARK: ToDense
+
+func TestToDense(t *testing.T) {
+	tensor := torch.NewTensor([][]float64{{1, 2, 3}, {4, 5, 6}})
+	dense, err := gonum.ToDense(tensor)
+	if !assert.NoError(t, err) { return }
+	expected := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	assert.True(t, mat.Equal(expected, dense))
+}
+
+func TestToDenseCopiesData(t *testing.T) {
+	tensor := torch.NewTensor([][]float64{{1, 2}, {3, 4}})
+	dense, err := gonum.ToDense(tensor)
+	if !assert.NoError(t, err) { return }
+	dense.Set(0, 1, 5)
+	assert.Equal(t, [][]float64{{1, 2}, {3, 4}}, tensor.ToNestedSlice())
+	tensor.Free()
+	expected := mat.NewDense(2, 2, []float64{1, 5, 3, 4})
+	assert.True(t, mat.Equal(expected, dense))
+}
+
+func TestToDenseOfSlicedColumns(t *testing.T) {
+	tensor := torch.NewTensor([][]float64{{1, 2, 3}, {4, 5, 6}})
+	dense, err := gonum.ToDense(tensor.Slice(1, 1, 3, 1))
+	if !assert.NoError(t, err) { return }
+	expected := mat.NewDense(2, 2, []float64{2, 3, 5, 6})
+	assert.True(t, mat.Equal(expected, dense))
+}
+
+func TestToDenseOfTranspose(t *testing.T) {
+	tensor := torch.NewTensor([][]float64{{1, 2}, {3, 4}}).Transpose(0, 1)
+	dense, err := gonum.ToDense(tensor)
+	if !assert.NoError(t, err) { return }
+	expected := mat.NewDense(2, 2, []float64{1, 3, 2, 4})
+	assert.True(t, mat.Equal(expected, dense))
+}
+
+func TestToDenseErrors(t *testing.T) {
+	_, err := gonum.ToDense(torch.NewTensor([][]float32{{1, 2}, {3, 4}}))
+	assert.Error(t, err)
+	_, err = gonum.ToDense(torch.NewTensor([]float64{1, 2}))
+	assert.EqualError(t, err, "Expected 2-dimensional tensor but found shape [2]")
+	_, err = gonum.ToDense(torch.Zeros([]int64{2, 0}, torch.NewTensorOptions().Dtype(torch.Double)))
+	assert.EqualError(t, err, "Expected tensor without empty dimensions but found shape [2 0]")
+}
+
+// MARK: ToVecDense
+
+func TestToVecDense(t *testing.T) {
+	tensor := torch.NewTensor([]float64{1, 2, 3})
+	vector, err := gonum.ToVecDense(tensor)
+	if !assert.NoError(t, err) { return }
+	assert.True(t, mat.Equal(mat.NewVecDense(3, []float64{1, 2, 3}), vector))
+	vector.SetVec(0, 4)
+	assert.Equal(t, []float64{1, 2, 3}, tensor.ToSlice())
+}
+
+func TestToVecDenseOfColumn(t *testing.T) {
+	tensor := torch.NewTensor([][]float64{{1, 2, 3}, {4, 5, 6}})
+	vector, err := gonum.ToVecDense(tensor.Slice(1, 1, 2, 1).Squeeze(1))
+	if !assert.NoError(t, err) { return }
+	assert.True(t, mat.Equal(mat.NewVecDense(2, []float64{2, 5}), vector))
+}
+
+func TestToVecDenseOfExpandedTensor(t *testing.T) {
+	vector, err := gonum.ToVecDense(torch.NewTensor([]float64{1}).Expand(3))
+	if !assert.NoError(t, err) { return }
+	assert.True(t, mat.Equal(mat.NewVecDense(3, []float64{1, 1, 1}), vector))
+}
+
+func TestToVecDenseErrors(t *testing.T) {
+	_, err := gonum.ToVecDense(torch.NewTensor([][]float64{{1, 2}}))
+	assert.EqualError(t, err, "Expected 1-dimensional tensor but found shape [1 2]")
+}
+
+// MARK: FromDense
+
+func TestFromDense(t *testing.T) {
+	dense := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	tensor, err := gonum.FromDense(dense)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, torch.Double, tensor.Dtype())
+	assert.Equal(t, [][]float64{{1, 2, 3}, {4, 5, 6}}, tensor.ToNestedSlice())
+	tensor.Copy_(torch.Zeros([]int64{2, 3}, torch.NewTensorOptions().Dtype(torch.Double)))
+	assert.Equal(t, 6.0, dense.At(1, 2))
+}
+
+func TestFromDenseOfSlice(t *testing.T) {
+	dense := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	tensor, err := gonum.FromDense(dense.Slice(0, 2, 1, 3).(*mat.Dense))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{2, 1}, tensor.Strides())
+	assert.Equal(t, [][]float64{{2, 3}, {5, 6}}, tensor.ToNestedSlice())
+}
+
+func TestFromDenseErrorsOnEmptyMatrix(t *testing.T) {
+	_, err := gonum.FromDense(&mat.Dense{})
+	assert.EqualError(t, err, "Expected non-empty matrix")
+}
+
+// MARK: FromVecDense
+
+func TestFromVecDense(t *testing.T) {
+	vector := mat.NewVecDense(3, []float64{1, 2, 3})
+	tensor, err := gonum.FromVecDense(vector)
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []float64{1, 2, 3}, tensor.ToSlice())
+	vector.SetVec(0, 4)
+	assert.Equal(t, []float64{1, 2, 3}, tensor.ToSlice())
+}
+
+func TestFromVecDenseOfColumn(t *testing.T) {
+	dense := mat.NewDense(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	tensor, err := gonum.FromVecDense(dense.ColView(2).(*mat.VecDense))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{1}, tensor.Strides())
+	assert.Equal(t, []float64{3, 6}, tensor.ToSlice())
+}
+
+func TestFromVecDenseErrorsOnEmptyVector(t *testing.T) {
+	_, err := gonum.FromVecDense(&mat.VecDense{})
+	assert.EqualError(t, err, "Expected non-empty vector")
+}
+
+// MARK: Round trip
+
+func TestFromDenseOfToDense(t *testing.T) {
+	tensor := torch.NewTensor([][]float64{{1, 2}, {3, 4}})
+	dense, err := gonum.ToDense(tensor)
+	if !assert.NoError(t, err) { return }
+	output, err := gonum.FromDense(dense)
+	if !assert.NoError(t, err) { return }
+	assert.True(t, torch.Equal(tensor, output))
+	assert.NotEqual(t, tensor.DataPtr(), output.DataPtr())
+}
@@ -35,7 +35,9 @@ import (
 	"unsafe"
 	"reflect"
 	"fmt"
+	"math"
 	"runtime"
+	"sort"
 	"github.com/Kautenja/gotorch/internal"
 )
 
@@ -48,8 +50,38 @@ type IValue struct {
 // MARK: Constructors
 // ---------------------------------------------------------------------------
 
-// Create a new IValue from arbitrary data.
+// Create a new IValue from arbitrary data. The supported types are:
+//
+//	nil                                 None
+//	bool                                bool
+//	int, int8, ..., int64, uint8, ...   int
+//	float32, float64                    float
+//	complex64, complex128               complex
+//	string                              str
+//	*Tensor                             Tensor
+//	*Device                             Device
+//	*IValue                             the IValue itself
+//	[]bool                              List[bool]
+//	[]int, []int32, []int64             List[int]
+//	[]float32, []float64                List[float]
+//	[]complex64, []complex128           List[complex]
+//	[]*Tensor                           List[Tensor], or List[Optional[Tensor]]
+//	                                    if an element is nil
+//	[]*IValue                           a list of the unified type
+//	other slices and arrays             a list of the converted elements
+//	maps                                a dictionary of the converted items
+//
+// TorchScript ints are 64-bit and signed, so NewIValue panics for unsigned
+// integers greater than math.MaxInt64. Nil pointers become None and other
+// pointers are dereferenced, so *T represents Optional[T]. Slices and maps
+// are converted recursively, so nested structures such as
+// map[string][]float64 are supported. Use NewTuple to create tuples and
+// NewOptionalTensorList to create lists of optional tensors without nil
+// elements.
 func NewIValue(data interface{}) (ivalue *IValue) {
+	if t, ok := data.(*IValue); ok && t != nil {
+		return t
+	}
 	ivalue = &IValue{}
 	switch t := data.(type) {
 	case nil:
@@ -59,14 +91,35 @@ func NewIValue(data interface{}) (ivalue *IValue) {
 		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromBool(&ivalue.Pointer, C.bool(t))))
 		break
 	case int:
-		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int(t))))
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(t))))
+		break
+	case int8:
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(t))))
+		break
+	case int16:
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(t))))
 		break
 	case int32:
-		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int(t))))
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(t))))
+		break
+	case int64:
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(t))))
+		break
+	case uint8:
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(t))))
+		break
+	case uint16:
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(t))))
+		break
+	case uint32:
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(t))))
+		break
+	case uint:
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(uintToInt64(uint64(t))))))
+		break
+	case uint64:
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int64_t(uintToInt64(t)))))
 		break
-	// case int64:
-	// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromInt(&ivalue.Pointer, C.int(t))))
-	// 	break
 	case float32:
 		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromDouble(&ivalue.Pointer, C.double(t))))
 		break
@@ -85,80 +138,332 @@ func NewIValue(data interface{}) (ivalue *IValue) {
 		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromString(&ivalue.Pointer, stringData)))
 		break
 	case *Tensor:
+		if t == nil {
+			internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromNone(&ivalue.Pointer)))
+			break
+		}
 		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromTensor(&ivalue.Pointer, t.Pointer)))
 		break
 	case []bool:
-		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromBoolList(
-			&ivalue.Pointer,
-			(*C.bool)(unsafe.Pointer(&t[0])),
-			C.int(len(t)),
-		)))
+		var data *C.bool
+		if len(t) > 0 { data = (*C.bool)(unsafe.Pointer(&t[0])) }
+		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromBoolList(&ivalue.Pointer, data, C.int(len(t)))))
 		break
-	// case []int:
-	// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromIntList(
-	// 		&ivalue.Pointer,
-	// 		(*C.int)(unsafe.Pointer(&t[0])),
-	// 		C.int(len(t)),
-	// 	)))
-	// 	break
-	// case []int32:
-	// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromIntList(
-	// 		&ivalue.Pointer,
-	// 		(*C.int)(unsafe.Pointer(&t[0])),
-	// 		C.int(len(t)),
-	// 	)))
-	// 	break
-	// case []int64:
-	// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromIntList(&ivalue.Pointer, (*C.int)(&t[0]))))
-	// 	break
-	case []float32:  // TODO: implement C-level function for float32
-		datums := []float64{}
-		for _, datum := range t { datums = append(datums, float64(datum)) }
-		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromDoubleList(
-			&ivalue.Pointer,
-			(*C.double)(unsafe.Pointer(&datums[0])),
-			C.int(len(datums)),
-		)))
+	case []int:
+		datums := make([]int64, len(t))
+		for index, datum := range t { datums[index] = int64(datum) }
+		newIntList(ivalue, datums)
+		break
+	case []int32:
+		datums := make([]int64, len(t))
+		for index, datum := range t { datums[index] = int64(datum) }
+		newIntList(ivalue, datums)
+		break
+	case []int64:
+		newIntList(ivalue, t)
+		break
+	case []float32:
+		datums := make([]float64, len(t))
+		for index, datum := range t { datums[index] = float64(datum) }
+		newDoubleList(ivalue, datums)
 		break
 	case []float64:
-		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromDoubleList(
-			&ivalue.Pointer,
-			(*C.double)(unsafe.Pointer(&t[0])),
-			C.int(len(t)),
-		)))
+		newDoubleList(ivalue, t)
+		break
+	case []complex64:
+		datums := make([]complex128, len(t))
+		for index, datum := range t { datums[index] = complex128(datum) }
+		newComplexDoubleList(ivalue, datums)
+		break
+	case []complex128:
+		newComplexDoubleList(ivalue, t)
 		break
-	// case []complex64:
-	// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromComplexDoubleList(&ivalue.Pointer, (*C.complexdouble)(&t[0]))))
-	// 	break
-	// case []complex128:
-	// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromComplexDoubleList(&ivalue.Pointer, (*C.complexdouble)(&t[0]))))
-	// 	break
 	case []*Tensor:
-		tensors := []C.Tensor{}
-		for _, tensor := range t { tensors = append(tensors, tensor.Pointer) }
+		tensors := make([]C.Tensor, len(t) + 1)
+		for index, tensor := range t {
+			// Nil tensors are None, which only lists of optional tensors hold.
+			if tensor == nil { return NewOptionalTensorList(t) }
+			tensors[index] = tensor.Pointer
+		}
 		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromTensorList(
 			&ivalue.Pointer,
-			(*C.Tensor)(&tensors[0]),
-			C.int(len(tensors)),
+			&tensors[0],
+			C.int(len(t)),
 		)))
+		runtime.KeepAlive(t)
 		break
-	// case []*IValue:
-	// 	values := []C.IValue{}
-	// 	for _, value := range t { values = append(values, C.IValue(*value.Pointer)) }
-	// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromList(&ivalue.Pointer, (*C.IValue)(&values[0]), C.int(len(values)))))
-	// 	runtime.KeepAlive(t)
-	// 	break
+	case []*IValue:
+		return NewList(t)
 	case *Device:
+		if t == nil {
+			internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromNone(&ivalue.Pointer)))
+			break
+		}
 		internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromDevice(&ivalue.Pointer, t.Pointer)))
 		break
 	default:
-		panic(fmt.Sprintf("IValue not supported for data of type %s", reflect.TypeOf(data)))
+		// Fall back to reflection for pointers, nested containers, and named
+		// types, e.g., type Labels []string.
+		return newIValueFromValue(reflect.ValueOf(data))
 	}
 	runtime.KeepAlive(data)
-	runtime.SetFinalizer(ivalue, (*IValue).free)
+	SetIValueFinalizer(ivalue)
 	return
 }
 
+// Create a new IValue from data that is not handled by the type switch of
+// NewIValue.
+func newIValueFromValue(value reflect.Value) *IValue {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() { return NewIValue(nil) }
+		return NewIValue(value.Elem().Interface())
+	case reflect.Bool:
+		return NewIValue(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewIValue(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewIValue(uintToInt64(value.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return NewIValue(value.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return NewIValue(value.Complex())
+	case reflect.String:
+		return NewIValue(value.String())
+	case reflect.Slice, reflect.Array:
+		values := make([]*IValue, value.Len())
+		for index := range values {
+			values[index] = NewIValue(value.Index(index).Interface())
+		}
+		return NewList(values)
+	case reflect.Map:
+		return NewGenericDict(value.Interface())
+	}
+	panic(fmt.Sprintf("IValue not supported for data of type %s", value.Type()))
+}
+
+// Convert an unsigned integer to the int64 of a TorchScript int, panicking if
+// it is out of range.
+func uintToInt64(value uint64) int64 {
+	if value > math.MaxInt64 {
+		panic(fmt.Sprintf("%d overflows the int64 of a TorchScript int", value))
+	}
+	return int64(value)
+}
+
+// Return the keys of a map in a deterministic order so that dictionaries
+// created from Go maps always have the same insertion order. Numbers, strings,
+// and booleans are sorted in ascending order. Other keys, e.g., tensors,
+// follow them in map iteration order.
+func sortedMapKeys(value reflect.Value) []reflect.Value {
+	keys := value.MapKeys()
+	sort.SliceStable(keys, func(i, j int) bool { return lessMapKey(keys[i], keys[j]) })
+	return keys
+}
+
+// Return true if map key a sorts before map key b.
+func lessMapKey(a, b reflect.Value) bool {
+	// Compare the dynamic values of interface keys, e.g., map[interface{}]int.
+	if a.Kind() == reflect.Interface && !a.IsNil() { a = a.Elem() }
+	if b.Kind() == reflect.Interface && !b.IsNil() { b = b.Elem() }
+	if a.Kind() != b.Kind() {
+		return a.Kind() < b.Kind()
+	}
+	switch a.Kind() {
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	}
+	return false
+}
+
+// Initialize an IValue with a list of integers.
+func newIntList(ivalue *IValue, data []int64) {
+	var pointer *C.int64_t
+	if len(data) > 0 { pointer = (*C.int64_t)(unsafe.Pointer(&data[0])) }
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromIntList(&ivalue.Pointer, pointer, C.int(len(data)))))
+}
+
+// Initialize an IValue with a list of double-precision floats.
+func newDoubleList(ivalue *IValue, data []float64) {
+	var pointer *C.double
+	if len(data) > 0 { pointer = (*C.double)(unsafe.Pointer(&data[0])) }
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromDoubleList(&ivalue.Pointer, pointer, C.int(len(data)))))
+}
+
+// Initialize an IValue with a list of complex double-precision floats.
+func newComplexDoubleList(ivalue *IValue, data []complex128) {
+	var pointer *C.complexdouble
+	if len(data) > 0 { pointer = (*C.complexdouble)(unsafe.Pointer(&data[0])) }
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromComplexDoubleList(&ivalue.Pointer, pointer, C.int(len(data)))))
+}
+
+// Return the C pointers of IValues. The output has an extra nil element so
+// that it is never empty.
+func newCIValues(values []*IValue) []C.IValue {
+	pointers := make([]C.IValue, len(values) + 1)
+	for index, value := range values { pointers[index] = value.Pointer }
+	return pointers
+}
+
+// Create a new IValue holding None, e.g., for an Optional argument.
+func None() *IValue {
+	return NewIValue(nil)
+}
+
+// Create a new list of IValues. The type of the list is the unified type of
+// the values, e.g., List[int] if every value is an int, List[Optional[int]]
+// if some of the values are nil (None), or List[Any] if the list is empty.
+// NewList panics if the values do not share a type.
+func NewList(values []*IValue) *IValue {
+	ivalue, err := newList(values)
+	if err != nil { panic(err) }
+	return ivalue
+}
+
+// Create a new list of IValues or return an error if the values do not share
+// a type.
+func newList(values []*IValue) (*IValue, error) {
+	values = append([]*IValue{}, values...)
+	for index, value := range values {
+		if value == nil { values[index] = None() }
+	}
+	ivalue := &IValue{}
+	pointers := newCIValues(values)
+	err := unsafe.Pointer(C.Torch_IValue_FromList(&ivalue.Pointer, &pointers[0], C.int(len(values))))
+	runtime.KeepAlive(values)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	SetIValueFinalizer(ivalue)
+	return ivalue, nil
+}
+
+// Create a new tuple from arbitrary data. Each value is converted with
+// NewIValue, e.g., NewTuple(1, "foo", tensor) creates a Tuple[int, str,
+// Tensor].
+func NewTuple(values ...interface{}) *IValue {
+	elements := make([]*IValue, len(values))
+	for index, value := range values {
+		elements[index] = NewIValue(value)
+	}
+	ivalue, err := newTuple(elements)
+	if err != nil { panic(err) }
+	return ivalue
+}
+
+// Create a new tuple of IValues.
+func newTuple(elements []*IValue) (*IValue, error) {
+	ivalue := &IValue{}
+	pointers := newCIValues(elements)
+	err := unsafe.Pointer(C.Torch_IValue_FromTuple(&ivalue.Pointer, &pointers[0], C.int(len(elements))))
+	runtime.KeepAlive(elements)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	SetIValueFinalizer(ivalue)
+	return ivalue, nil
+}
+
+// Create a new named tuple, i.e., a typing.NamedTuple, of IValues. name is
+// the qualified name of the tuple type and may be empty.
+func newNamedTuple(name string, fields []string, elements []*IValue) (*IValue, error) {
+	var name_cstring *C.char
+	if name != "" {
+		name_cstring = C.CString(name)
+		defer C.free(unsafe.Pointer(name_cstring))
+	}
+	fieldCStrings := make([]*C.char, len(fields) + 1)
+	for index, field := range fields {
+		fieldCStrings[index] = C.CString(field)
+		defer C.free(unsafe.Pointer(fieldCStrings[index]))
+	}
+	ivalue := &IValue{}
+	pointers := newCIValues(elements)
+	err := unsafe.Pointer(C.Torch_IValue_FromNamedTuple(
+		&ivalue.Pointer,
+		name_cstring,
+		&fieldCStrings[0],
+		&pointers[0],
+		C.int(len(elements)),
+	))
+	runtime.KeepAlive(elements)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	SetIValueFinalizer(ivalue)
+	return ivalue, nil
+}
+
+// Create a new dictionary from a Go map, e.g., a map[string]*Tensor or a
+// map[int][]float64. The keys and values are converted with NewIValue. The
+// types of the dictionary are the unified types of its keys and values, or
+// Dict[str, Any] if the map is empty. Items are inserted in ascending order of
+// their keys when the keys are numbers, strings, or booleans, so dictionaries
+// iterate in the same order in TorchScript every time. NewGenericDict panics
+// if data is not a map or if the keys or values do not share a type.
+func NewGenericDict(data interface{}) *IValue {
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Map {
+		panic(fmt.Sprintf("Expected a map but received data of type %s", reflect.TypeOf(data)))
+	}
+	mapKeys := sortedMapKeys(value)
+	keys := make([]*IValue, len(mapKeys))
+	values := make([]*IValue, len(mapKeys))
+	for index, key := range mapKeys {
+		keys[index] = NewIValue(key.Interface())
+		values[index] = NewIValue(value.MapIndex(key).Interface())
+	}
+	ivalue, err := newGenericDict(keys, values)
+	if err != nil { panic(err) }
+	return ivalue
+}
+
+// Create a new dictionary from zipped keys and values.
+func newGenericDict(keys, values []*IValue) (*IValue, error) {
+	ivalue := &IValue{}
+	keyPointers := newCIValues(keys)
+	valuePointers := newCIValues(values)
+	err := unsafe.Pointer(C.Torch_IValue_FromGenericDict(
+		&ivalue.Pointer,
+		&keyPointers[0],
+		&valuePointers[0],
+		C.int64_t(len(keys)),
+	))
+	runtime.KeepAlive(keys)
+	runtime.KeepAlive(values)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	SetIValueFinalizer(ivalue)
+	return ivalue, nil
+}
+
+// Create a new list of optional tensors, i.e., a List[Optional[Tensor]],
+// where nil tensors are None.
+func NewOptionalTensorList(tensors []*Tensor) *IValue {
+	pointers := make([]C.Tensor, len(tensors) + 1)
+	for index, tensor := range tensors {
+		if tensor != nil { pointers[index] = tensor.Pointer }
+	}
+	ivalue := &IValue{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_FromOptionalTensorList(
+		&ivalue.Pointer,
+		&pointers[0],
+		C.int(len(tensors)),
+	)))
+	runtime.KeepAlive(tensors)
+	SetIValueFinalizer(ivalue)
+	return ivalue
+}
+
 // Free an ivalue from memory.
 func (ivalue *IValue) free() {
 	if ivalue.Pointer == nil {
@@ -166,6 +471,15 @@ func (ivalue *IValue) free() {
 	}
 	C.Torch_IValue_Free(ivalue.Pointer)
 	ivalue.Pointer = nil
+	internal.UntrackObject(unsafe.Pointer(ivalue))
+}
+
+// Attach the garbage collection finalizer to a newly created IValue and
+// record its allocation site for leak detection. Every function that creates
+// an IValue should call this once the IValue's Pointer is set.
+func SetIValueFinalizer(ivalue *IValue) {
+	runtime.SetFinalizer(ivalue, (*IValue).free)
+	internal.TrackObject("IValue", unsafe.Pointer(ivalue))
 }
 
 // ---------------------------------------------------------------------------
@@ -292,13 +606,13 @@ func (ivalue *IValue) IsTensorList() bool {
 	return bool(output)
 }
 
-// // Return true if the IValue is an optional tensor list.
-// func (ivalue *IValue) IsOptionalTensorList() bool {
-// 	var output C.bool
-// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_IsOptionalTensorList(&output, ivalue.Pointer)))
-// 	runtime.KeepAlive(ivalue)
-// 	return bool(output)
-// }
+// Return true if the IValue is a list of optional tensors.
+func (ivalue *IValue) IsOptionalTensorList() bool {
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_IsOptionalTensorList(&output, ivalue.Pointer)))
+	runtime.KeepAlive(ivalue)
+	return bool(output)
+}
 
 // Return true if the IValue is a dictionary.
 func (ivalue *IValue) IsGenericDict() bool {
@@ -436,6 +750,34 @@ func (ivalue *IValue) IsPtrType() bool {
 	return bool(output)
 }
 
+// Return the TorchScript type of the IValue, e.g., "Dict[str, Tensor]".
+func (ivalue *IValue) TypeName() string {
+	var output *C.char
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_TypeName(&output, ivalue.Pointer)))
+	runtime.KeepAlive(ivalue)
+	defer C.free(unsafe.Pointer(output))
+	return C.GoString(output)
+}
+
+// If the ivalue is a named tuple, i.e., a typing.NamedTuple, return the names
+// of its fields. Return nil for unnamed tuples.
+func (ivalue *IValue) TupleFieldNames() []string {
+	pointers := make([]*C.char, ivalue.LengthTuple() + 1)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_TupleFieldNames(
+		&pointers[0],
+		C.int64_t(len(pointers) - 1),
+		ivalue.Pointer,
+	)))
+	runtime.KeepAlive(ivalue)
+	if pointers[0] == nil { return nil }
+	names := make([]string, len(pointers) - 1)
+	for index := range names {
+		names[index] = C.GoString(pointers[index])
+		C.free(unsafe.Pointer(pointers[index]))
+	}
+	return names
+}
+
 // ---------------------------------------------------------------------------
 // MARK: Container length checkers
 // ---------------------------------------------------------------------------
@@ -478,8 +820,21 @@ func (ivalue *IValue) ToNone() string {
 	return C.GoString(output)
 }
 
-// // Convert the IValue to a generic scalar representation.
-// func (ivalue *IValue) ToScalar()
+// Convert the IValue to a Go scalar, i.e., a bool, int, float64, or
+// complex128. ToScalar panics if the IValue is not a scalar.
+func (ivalue *IValue) ToScalar() interface{} {
+	switch {
+	case ivalue.IsBool():
+		return ivalue.ToBool()
+	case ivalue.IsInt():
+		return ivalue.ToInt()
+	case ivalue.IsDouble():
+		return ivalue.ToDouble()
+	case ivalue.IsComplexDouble():
+		return ivalue.ToComplexDouble()
+	}
+	panic("Expected a scalar IValue (bool, int, float, or complex)")
+}
 
 // Convert the IValue to a boolean.
 func (ivalue *IValue) ToBool() bool {
@@ -491,7 +846,7 @@ func (ivalue *IValue) ToBool() bool {
 
 // Convert the IValue to an integer.
 func (ivalue *IValue) ToInt() int {
-	var output C.int32_t
+	var output C.int64_t
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToInt(&output, ivalue.Pointer)))
 	runtime.KeepAlive(ivalue)
 	return int(output)
@@ -528,21 +883,57 @@ func (ivalue *IValue) ToTensor() *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToTensor(&output.Pointer, ivalue.Pointer)))
 	runtime.KeepAlive(ivalue)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
-// // Convert the IValue to a list of booleans.
-// func (ivalue *IValue) ToBoolList()
+// Convert the IValue to a list of booleans.
+func (ivalue *IValue) ToBoolList() []bool {
+	output := make([]C.bool, ivalue.LengthList())
+	if len(output) == 0 { return []bool{} }
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToBoolList(&output[0], C.int64_t(len(output)), ivalue.Pointer)))
+	runtime.KeepAlive(ivalue)
+	values := make([]bool, len(output))
+	for index, value := range output { values[index] = bool(value) }
+	return values
+}
 
-// // Convert the IValue to a list of integers.
-// func (ivalue *IValue) ToIntList()
+// Convert the IValue to a list of integers.
+func (ivalue *IValue) ToIntList() []int {
+	output := make([]C.int64_t, ivalue.LengthList())
+	if len(output) == 0 { return []int{} }
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToIntList(&output[0], C.int64_t(len(output)), ivalue.Pointer)))
+	runtime.KeepAlive(ivalue)
+	values := make([]int, len(output))
+	for index, value := range output { values[index] = int(value) }
+	return values
+}
 
-// // Convert the IValue to a list of double-precision floats.
-// func (ivalue *IValue) ToDoubleList()
+// Convert the IValue to a list of double-precision floats.
+func (ivalue *IValue) ToDoubleList() []float64 {
+	output := make([]float64, ivalue.LengthList())
+	if len(output) == 0 { return output }
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToDoubleList(
+		(*C.double)(unsafe.Pointer(&output[0])),
+		C.int64_t(len(output)),
+		ivalue.Pointer,
+	)))
+	runtime.KeepAlive(ivalue)
+	return output
+}
 
-// // Convert the IValue to a list of complex double-precision floats.
-// func (ivalue *IValue) ToComplexDoubleList()
+// Convert the IValue to a list of complex double-precision floats.
+func (ivalue *IValue) ToComplexDoubleList() []complex128 {
+	output := make([]complex128, ivalue.LengthList())
+	if len(output) == 0 { return output }
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToComplexDoubleList(
+		(*C.complexdouble)(unsafe.Pointer(&output[0])),
+		C.int64_t(len(output)),
+		ivalue.Pointer,
+	)))
+	runtime.KeepAlive(ivalue)
+	return output
+}
 
 // Convert the IValue to a list of tensors.
 func (ivalue *IValue) ToTensorList() []*Tensor {
@@ -558,14 +949,31 @@ func (ivalue *IValue) ToTensorList() []*Tensor {
 	tensors := []*Tensor{}
 	for index, _ := range pointers {
 		tensor := &Tensor{pointers[index]}
-		runtime.SetFinalizer(tensor, (*Tensor).free)
+		SetTensorFinalizer(tensor)
 		tensors = append(tensors, tensor)
 	}
 	return tensors
 }
 
-// // Convert the IValue to an optional tensor list.
-// func (ivalue *IValue) ToOptionalTensorList()
+// Convert the IValue to a list of optional tensors, where None is nil.
+func (ivalue *IValue) ToOptionalTensorList() []*Tensor {
+	pointers := make([]C.Tensor, ivalue.LengthList())
+	if len(pointers) == 0 { return []*Tensor{} }
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToOptionalTensorList(
+		&pointers[0],
+		C.int64_t(len(pointers)),
+		ivalue.Pointer,
+	)))
+	runtime.KeepAlive(ivalue)
+	// Wrap the pointers with finalized Go structs
+	tensors := make([]*Tensor, len(pointers))
+	for index, pointer := range pointers {
+		if pointer == nil { continue }
+		tensors[index] = &Tensor{pointer}
+		SetTensorFinalizer(tensors[index])
+	}
+	return tensors
+}
 
 // Convert the IValue to a generic list of IValues (as a slice.)
 func (ivalue *IValue) ToList() []*IValue {
@@ -581,7 +989,7 @@ func (ivalue *IValue) ToList() []*IValue {
 	ivalues := []*IValue{}
 	for _, pointer := range pointers {
 		ivalue := &IValue{pointer}
-		runtime.SetFinalizer(ivalue, (*IValue).free)
+		SetIValueFinalizer(ivalue)
 		ivalues = append(ivalues, ivalue)
 	}
 	return ivalues
@@ -604,7 +1012,7 @@ func (ivalue *IValue) ToTuple() []*IValue {
 	ivalues := []*IValue{}
 	for _, pointer := range pointers {
 		ivalue := &IValue{pointer}
-		runtime.SetFinalizer(ivalue, (*IValue).free)
+		SetIValueFinalizer(ivalue)
 		ivalues = append(ivalues, ivalue)
 	}
 	return ivalues
@@ -657,7 +1065,7 @@ func (ivalue *IValue) ToGenericDict() map[interface{}]*IValue {
 		// so we need to setup the runtime finalizer to free these values when
 		// the garbage collector triggers.
 		output[key] = &IValue{valPointers[index]}
-		runtime.SetFinalizer(output[key], (*IValue).free)
+		SetIValueFinalizer(output[key])
 	}
 	return output
 }
@@ -667,22 +1075,82 @@ func (ivalue *IValue) ToDevice() (device *Device) {
 	device = &Device{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToDevice(&device.Pointer, ivalue.Pointer)))
 	runtime.KeepAlive(ivalue)
-	runtime.SetFinalizer(device, (*Device).free)
+	setDeviceFinalizer(device)
 	return
 }
 
+// Convert the IValue to Go data recursively, i.e., the inverse of NewIValue:
+//
+//	None                 nil
+//	bool                 bool
+//	int                  int
+//	float                float64
+//	complex              complex128
+//	str                  string
+//	Tensor               *Tensor
+//	Device               *Device
+//	List[bool]           []bool
+//	List[int]            []int
+//	List[float]          []float64
+//	List[complex]        []complex128
+//	List[Tensor]         []*Tensor
+//	other lists, tuples  []interface{}
+//	dictionaries         map[interface{}]interface{}
+//
+// Other IValues, e.g., objects, are returned as-is.
+func (ivalue *IValue) ToInterface() interface{} {
+	switch {
+	case ivalue.IsNil():
+		return nil
+	case ivalue.IsScalar():
+		return ivalue.ToScalar()
+	case ivalue.IsString():
+		return ivalue.ToString()
+	case ivalue.IsTensor():
+		return ivalue.ToTensor()
+	case ivalue.IsDevice():
+		return ivalue.ToDevice()
+	case ivalue.IsBoolList():
+		return ivalue.ToBoolList()
+	case ivalue.IsIntList():
+		return ivalue.ToIntList()
+	case ivalue.IsDoubleList():
+		return ivalue.ToDoubleList()
+	case ivalue.IsComplexDoubleList():
+		return ivalue.ToComplexDoubleList()
+	case ivalue.IsTensorList():
+		return ivalue.ToTensorList()
+	case ivalue.IsList():
+		return interfaces(ivalue.ToList())
+	case ivalue.IsTuple():
+		return interfaces(ivalue.ToTuple())
+	case ivalue.IsGenericDict():
+		output := make(map[interface{}]interface{})
+		for key, value := range ivalue.ToGenericDict() {
+			output[key] = value.ToInterface()
+		}
+		return output
+	}
+	return ivalue
+}
+
+// Convert IValues to Go data with ToInterface.
+func interfaces(ivalues []*IValue) []interface{} {
+	output := make([]interface{}, len(ivalues))
+	for index, ivalue := range ivalues {
+		output[index] = ivalue.ToInterface()
+	}
+	return output
+}
+
 // func (ivalue *IValue) ToStorage()
 // func (ivalue *IValue) ToCapsule()
-// func (ivalue *IValue) ToCustomClass()
-// func (ivalue *IValue) ToFuture()
 // func (ivalue *IValue) ToRRef()
 // func (ivalue *IValue) ToQuantizer()
 // func (ivalue *IValue) ToSymInt()
 // func (ivalue *IValue) ToSymFloat()
-// func (ivalue *IValue) ToObject()
 // func (ivalue *IValue) ToModule()
 // func (ivalue *IValue) ToPyObject()
-// func (ivalue *IValue) ToEnum()
 // func (ivalue *IValue) ToStream()
 // func (ivalue *IValue) ToGenerator()
 // func (ivalue *IValue) ToPtrType()
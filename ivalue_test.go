@@ -25,6 +25,7 @@
 package torch_test
 
 import (
+	"math"
 	"runtime"
 	"testing"
 	"github.com/stretchr/testify/assert"
@@ -91,14 +92,33 @@ func TestIValueInt32(t *testing.T) {
 	assert.Equal(t, 7, data.ToInt())
 }
 
-// func TestIValueInt64(t *testing.T) {
-//     data := torch.NewIValue(int64(7))
-//     assert.NotNil(t, data.Pointer)
-//     assert.False(t, data.IsNil())
-//     assert.True(t, data.IsScalar())
-//     assert.True(t, data.IsInt())
-//     assert.Equal(t, 7, data.ToInt())
-// }
+func TestIValueInt64(t *testing.T) {
+	data := torch.NewIValue(int64(7))
+	assert.NotNil(t, data.Pointer)
+	assert.False(t, data.IsNil())
+	assert.True(t, data.IsScalar())
+	assert.True(t, data.IsInt())
+	assert.Equal(t, 7, data.ToInt())
+}
+
+func TestIValueInt64DoesNotTruncate(t *testing.T) {
+	data := torch.NewIValue(int64(1) << 40)
+	assert.Equal(t, 1 << 40, data.ToInt())
+}
+
+func TestIValueUint8(t *testing.T) {
+	data := torch.NewIValue(uint8(7))
+	assert.True(t, data.IsInt())
+	assert.Equal(t, 7, data.ToInt())
+}
+
+func TestIValueUint(t *testing.T) {
+	assert.Equal(t, 7, torch.NewIValue(uint(7)).ToInt())
+	assert.Equal(t, math.MaxInt64, torch.NewIValue(uint64(math.MaxInt64)).ToInt())
+	assert.PanicsWithValue(t, "18446744073709551615 overflows the int64 of a TorchScript int", func() {
+		torch.NewIValue(uint64(math.MaxUint64))
+	})
+}
 
 func TestIValueBool(t *testing.T) {
 	data := torch.NewIValue(true)
@@ -139,14 +159,37 @@ func TestIValueComplex128(t *testing.T) {
 	assert.InEpsilon(t, float64(0.6), imag(output), 1e-5)
 }
 
-// func TestIValueList(t *testing.T) {
-//     valueA := torch.NewIValue(7)
-//     valueB := torch.NewIValue(2.22)
-//     data := torch.NewIValue([]torch.IValue{valueA, valueB})
-//     assert.NotNil(t, data.Pointer)
-//     assert.False(t, data.IsNil())
-//     assert.True(t, data.IsList())
-// }
+func TestIValueList(t *testing.T) {
+	valueA := torch.NewIValue(7)
+	valueB := torch.NewIValue(2)
+	data := torch.NewIValue([]*torch.IValue{valueA, valueB})
+	assert.NotNil(t, data.Pointer)
+	assert.False(t, data.IsNil())
+	assert.True(t, data.IsList())
+	assert.True(t, data.IsIntList())
+	assert.Equal(t, []int{7, 2}, data.ToIntList())
+}
+
+func TestIValueListPanicsOnMixedTypes(t *testing.T) {
+	valueA := torch.NewIValue(7)
+	valueB := torch.NewIValue("foo")
+	assert.Panics(t, func() { torch.NewList([]*torch.IValue{valueA, valueB}) })
+}
+
+func TestIValueListWithNone(t *testing.T) {
+	data := torch.NewList([]*torch.IValue{torch.NewIValue(7), nil})
+	assert.True(t, data.IsList())
+	assert.False(t, data.IsIntList())
+	values := data.ToList()
+	assert.Equal(t, 7, values[0].ToInt())
+	assert.True(t, values[1].IsNil())
+}
+
+func TestIValueEmptyList(t *testing.T) {
+	data := torch.NewList([]*torch.IValue{})
+	assert.True(t, data.IsList())
+	assert.Equal(t, int64(0), data.LengthList())
+}
 
 func TestIValueBoolList(t *testing.T) {
 	slice := []bool{true, false, false, true, true}
@@ -157,11 +200,7 @@ func TestIValueBoolList(t *testing.T) {
 	assert.Equal(t, int64(5), data.LengthList())
 	// ToBoolList API
 	assert.True(t, data.IsBoolList())
-	// outputs := data.ToBoolList()
-	// assert.Equal(t, 5, len(outputs))
-	// for idx, value := range slice {
-	//     assert.Equal(t, value, outputs[idx])
-	// }
+	assert.Equal(t, slice, data.ToBoolList())
 	// ToList API
 	assert.True(t, data.IsList())
 	ivalues := data.ToList()
@@ -172,53 +211,62 @@ func TestIValueBoolList(t *testing.T) {
 	}
 }
 
-// func TestIValueInt32List(t *testing.T) {
-//     slice := []int32{1, 0, 0, 1, 1}
-//     var data *torch.IValue
-//     assert.NotPanics(t, func() { data = torch.NewIValue(slice) })
-//     assert.NotNil(t, data.Pointer)
-//     assert.False(t, data.IsNil())
-//     assert.Equal(t, int64(5), data.LengthList())
-//     // ToBoolList API
-//     assert.True(t, data.IsIntList())
-//     // outputs := data.ToIntList()
-//     // assert.Equal(t, 5, len(outputs))
-//     // for idx, value := range slice {
-//     //     assert.Equal(t, value, outputs[idx])
-//     // }
-//     // ToList API
-//     assert.True(t, data.IsList())
-//     ivalues := data.ToList()
-//     assert.Equal(t, 5, len(ivalues))
-//     for idx, value := range slice {
-//         assert.True(t, ivalues[idx].IsInt())
-//         assert.Equal(t, value, ivalues[idx].ToInt())
-//     }
-// }
-
-// func TestIValueIntList(t *testing.T) {
-//     slice := []int{1, 0, 0, 1, 1}
-//     var data *torch.IValue
-//     assert.NotPanics(t, func() { data = torch.NewIValue(slice) })
-//     assert.NotNil(t, data.Pointer)
-//     assert.False(t, data.IsNil())
-//     assert.Equal(t, int64(5), data.LengthList())
-//     // ToBoolList API
-//     assert.True(t, data.IsIntList())
-//     // outputs := data.ToIntList()
-//     // assert.Equal(t, 5, len(outputs))
-//     // for idx, value := range slice {
-//     //     assert.Equal(t, value, outputs[idx])
-//     // }
-//     // ToList API
-//     assert.True(t, data.IsList())
-//     ivalues := data.ToList()
-//     assert.Equal(t, 5, len(ivalues))
-//     for idx, value := range slice {
-//         assert.True(t, ivalues[idx].IsInt())
-//         assert.Equal(t, value, ivalues[idx].ToInt())
-//     }
-// }
+func TestIValueInt32List(t *testing.T) {
+	slice := []int32{1, 0, 0, 1, 1}
+	var data *torch.IValue
+	assert.NotPanics(t, func() { data = torch.NewIValue(slice) })
+	assert.NotNil(t, data.Pointer)
+	assert.False(t, data.IsNil())
+	assert.Equal(t, int64(5), data.LengthList())
+	// ToIntList API
+	assert.True(t, data.IsIntList())
+	outputs := data.ToIntList()
+	assert.Equal(t, 5, len(outputs))
+	for idx, value := range slice {
+		assert.Equal(t, int(value), outputs[idx])
+	}
+	// ToList API
+	assert.True(t, data.IsList())
+	ivalues := data.ToList()
+	assert.Equal(t, 5, len(ivalues))
+	for idx, value := range slice {
+		assert.True(t, ivalues[idx].IsInt())
+		assert.Equal(t, int(value), ivalues[idx].ToInt())
+	}
+}
+
+func TestIValueIntList(t *testing.T) {
+	slice := []int{1, 0, 0, 1, 1}
+	var data *torch.IValue
+	assert.NotPanics(t, func() { data = torch.NewIValue(slice) })
+	assert.NotNil(t, data.Pointer)
+	assert.False(t, data.IsNil())
+	assert.Equal(t, int64(5), data.LengthList())
+	// ToIntList API
+	assert.True(t, data.IsIntList())
+	assert.Equal(t, slice, data.ToIntList())
+	// ToList API
+	assert.True(t, data.IsList())
+	ivalues := data.ToList()
+	assert.Equal(t, 5, len(ivalues))
+	for idx, value := range slice {
+		assert.True(t, ivalues[idx].IsInt())
+		assert.Equal(t, value, ivalues[idx].ToInt())
+	}
+}
+
+func TestIValueInt64List(t *testing.T) {
+	data := torch.NewIValue([]int64{1 << 40, -3})
+	assert.True(t, data.IsIntList())
+	assert.Equal(t, []int{1 << 40, -3}, data.ToIntList())
+}
+
+func TestIValueEmptyIntList(t *testing.T) {
+	var data *torch.IValue
+	assert.NotPanics(t, func() { data = torch.NewIValue([]int{}) })
+	assert.True(t, data.IsIntList())
+	assert.Equal(t, []int{}, data.ToIntList())
+}
 
 func TestIValueFloat32List(t *testing.T) {
 	slice := []float32{1, 0, 0, 1, 1}
@@ -227,13 +275,13 @@ func TestIValueFloat32List(t *testing.T) {
 	assert.NotNil(t, data.Pointer)
 	assert.False(t, data.IsNil())
 	assert.Equal(t, int64(5), data.LengthList())
-	// ToBoolList API
+	// ToDoubleList API
 	assert.True(t, data.IsDoubleList())
-	// outputs := data.ToFloatList()
-	// assert.Equal(t, 5, len(outputs))
-	// for idx, value := range slice {
-	//     assert.Equal(t, float64(value), outputs[idx])
-	// }
+	outputs := data.ToDoubleList()
+	assert.Equal(t, 5, len(outputs))
+	for idx, value := range slice {
+		assert.Equal(t, float64(value), outputs[idx])
+	}
 	// ToList API
 	assert.True(t, data.IsList())
 	ivalues := data.ToList()
@@ -251,13 +299,9 @@ func TestIValueFloat64List(t *testing.T) {
 	assert.NotNil(t, data.Pointer)
 	assert.False(t, data.IsNil())
 	assert.Equal(t, int64(5), data.LengthList())
-	// ToBoolList API
+	// ToDoubleList API
 	assert.True(t, data.IsDoubleList())
-	// outputs := data.ToDoubleList()
-	// assert.Equal(t, 5, len(outputs))
-	// for idx, value := range slice {
-	//     assert.Equal(t, value, outputs[idx])
-	// }
+	assert.Equal(t, slice, data.ToDoubleList())
 	// ToList API
 	assert.True(t, data.IsList())
 	ivalues := data.ToList()
@@ -268,6 +312,39 @@ func TestIValueFloat64List(t *testing.T) {
 	}
 }
 
+func TestIValueComplex128List(t *testing.T) {
+	slice := []complex128{complex(1, 2), complex(3, -4)}
+	data := torch.NewIValue(slice)
+	assert.True(t, data.IsComplexDoubleList())
+	assert.Equal(t, slice, data.ToComplexDoubleList())
+	ivalues := data.ToList()
+	assert.Equal(t, slice[1], ivalues[1].ToComplexDouble())
+}
+
+func TestIValueComplex64List(t *testing.T) {
+	data := torch.NewIValue([]complex64{complex(1, 2)})
+	assert.True(t, data.IsComplexDoubleList())
+	assert.Equal(t, []complex128{complex(1, 2)}, data.ToComplexDoubleList())
+}
+
+func TestIValueStringList(t *testing.T) {
+	data := torch.NewIValue([]string{"foo", "bar"})
+	assert.True(t, data.IsList())
+	ivalues := data.ToList()
+	assert.Equal(t, "foo", ivalues[0].ToString())
+	assert.Equal(t, "bar", ivalues[1].ToString())
+	assert.Equal(t, []interface{}{"foo", "bar"}, data.ToInterface())
+}
+
+func TestIValueNestedList(t *testing.T) {
+	data := torch.NewIValue([][]float64{{1, 2}, {3}})
+	assert.True(t, data.IsList())
+	ivalues := data.ToList()
+	assert.Equal(t, 2, len(ivalues))
+	assert.True(t, ivalues[0].IsDoubleList())
+	assert.Equal(t, []interface{}{[]float64{1, 2}, []float64{3}}, data.ToInterface())
+}
+
 func TestIValueTensorList(t *testing.T) {
 	tensorA := torch.NewTensor([]float32{1})
 	tensorB := torch.NewTensor([]float32{2})
@@ -312,3 +389,105 @@ func TestIValueDevice(t *testing.T) {
 	assert.NotEqual(t, device, data.ToDevice())
 	// TODO: test device name for equality
 }
+
+func TestIValueNilTensor(t *testing.T) {
+	var tensor *torch.Tensor
+	data := torch.NewIValue(tensor)
+	assert.True(t, data.IsNil())
+}
+
+func TestIValueOptionalPointer(t *testing.T) {
+	value := 7
+	assert.Equal(t, 7, torch.NewIValue(&value).ToInt())
+	var missing *int
+	assert.True(t, torch.NewIValue(missing).IsNil())
+}
+
+func TestIValueNone(t *testing.T) {
+	assert.True(t, torch.None().IsNil())
+}
+
+func TestIValueFromIValue(t *testing.T) {
+	data := torch.NewIValue(7)
+	assert.Equal(t, data, torch.NewIValue(data))
+}
+
+// MARK: Tuple
+
+func TestIValueTuple(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1})
+	data := torch.NewTuple(1, "foo", tensor, nil)
+	assert.True(t, data.IsTuple())
+	assert.Equal(t, int64(4), data.LengthTuple())
+	values := data.ToTuple()
+	assert.Equal(t, 1, values[0].ToInt())
+	assert.Equal(t, "foo", values[1].ToString())
+	assert.True(t, values[2].ToTensor().Equal(tensor))
+	assert.True(t, values[3].IsNil())
+}
+
+func TestIValueNestedTuple(t *testing.T) {
+	data := torch.NewTuple(torch.NewTuple(1, 2), []int{3})
+	assert.Equal(t, []interface{}{[]interface{}{1, 2}, []int{3}}, data.ToInterface())
+}
+
+// MARK: GenericDict
+
+func TestIValueGenericDict(t *testing.T) {
+	data := torch.NewGenericDict(map[string]int{"foo": 1, "bar": 2})
+	assert.True(t, data.IsGenericDict())
+	assert.Equal(t, int64(2), data.LengthDict())
+	dict := data.ToGenericDict()
+	assert.Equal(t, 1, dict["foo"].ToInt())
+	assert.Equal(t, 2, dict["bar"].ToInt())
+}
+
+func TestIValueGenericDictFromNewIValue(t *testing.T) {
+	data := torch.NewIValue(map[int][]float64{1: {1, 2}, 2: {}})
+	assert.True(t, data.IsGenericDict())
+	expected := map[interface{}]interface{}{1: []float64{1, 2}, 2: []float64{}}
+	assert.Equal(t, expected, data.ToInterface())
+}
+
+func TestIValueEmptyGenericDict(t *testing.T) {
+	data := torch.NewGenericDict(map[string]int{})
+	assert.True(t, data.IsGenericDict())
+	assert.Equal(t, int64(0), data.LengthDict())
+}
+
+func TestIValueGenericDictPanicsOnNonMap(t *testing.T) {
+	assert.Panics(t, func() { torch.NewGenericDict([]int{1}) })
+}
+
+// MARK: OptionalTensorList
+
+func TestIValueOptionalTensorList(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1})
+	data := torch.NewOptionalTensorList([]*torch.Tensor{tensor, nil})
+	assert.True(t, data.IsOptionalTensorList())
+	assert.False(t, data.IsTensorList())
+	outputs := data.ToOptionalTensorList()
+	assert.Equal(t, 2, len(outputs))
+	assert.True(t, outputs[0].Equal(tensor))
+	assert.Nil(t, outputs[1])
+}
+
+func TestIValueTensorListWithNilIsOptionalTensorList(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1})
+	data := torch.NewIValue([]*torch.Tensor{nil, tensor})
+	assert.True(t, data.IsOptionalTensorList())
+	outputs := data.ToOptionalTensorList()
+	if !assert.Equal(t, 2, len(outputs)) { return }
+	assert.Nil(t, outputs[0])
+	assert.True(t, outputs[1].Equal(tensor))
+}
+
+// MARK: ToScalar
+
+func TestIValueToScalar(t *testing.T) {
+	assert.Equal(t, true, torch.NewIValue(true).ToScalar())
+	assert.Equal(t, 7, torch.NewIValue(7).ToScalar())
+	assert.Equal(t, 2.5, torch.NewIValue(2.5).ToScalar())
+	assert.Equal(t, complex(1, 2), torch.NewIValue(complex(1, 2)).ToScalar())
+	assert.Panics(t, func() { torch.NewIValue("foo").ToScalar() })
+}
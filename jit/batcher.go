@@ -0,0 +1,386 @@
+// Dynamic request batching for concurrent inference with TorchScript modules.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package jit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"github.com/Kautenja/gotorch"
+	F "github.com/Kautenja/gotorch/nn/functional"
+)
+
+// The error returned by Batcher.Forward after the batcher has been closed.
+var ErrBatcherClosed = errors.New("jit: batcher is closed")
+
+// A function that assigns a request to a bucket. Only requests in the same
+// bucket are batched together.
+type BucketFunc func(inputs []*torch.Tensor) string
+
+// Options for creating a Batcher.
+type BatcherOptions struct {
+	// The maximum number of requests in a batch. Defaults to 32.
+	MaxBatchSize int
+	// The maximum time that the first request of a batch waits for other
+	// requests before the batch is run. Defaults to 5 milliseconds.
+	MaxLatency time.Duration
+	// Whether to pad inputs with different shapes to the largest size in
+	// each dimension before stacking them. Outputs are not cropped.
+	Pad bool
+	// The value that padded elements are filled with.
+	PadValue float64
+	// An optional function that assigns requests to buckets. Requests are
+	// always bucketed by the shapes of their inputs as well, or by the ranks
+	// of their inputs with padding.
+	Bucket BucketFunc
+}
+
+// Create a BucketFunc that buckets requests by the size of dim of their
+// first input. Sizes are grouped by the smallest boundary that is greater
+// than or equal to them, so padding a bucket wastes at most the distance
+// between two boundaries. Sizes larger than every boundary share a bucket.
+func SizeBuckets(dim int64, boundaries ...int64) BucketFunc {
+	boundaries = append([]int64{}, boundaries...)
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i] < boundaries[j] })
+	return func(inputs []*torch.Tensor) string {
+		if len(inputs) == 0 {
+			return ""
+		}
+		shape := inputs[0].Shape()
+		if dim < 0 {
+			dim += int64(len(shape))
+		}
+		if dim < 0 || dim >= int64(len(shape)) {
+			return ""
+		}
+		index := sort.Search(len(boundaries), func(i int) bool { return boundaries[i] >= shape[dim] })
+		return fmt.Sprint(index)
+	}
+}
+
+// A single-sample request waiting to be batched.
+type batchRequest struct {
+	inputs []*torch.Tensor
+	response chan batchResponse
+}
+
+// The result of a batchRequest.
+type batchResponse struct {
+	output interface{}
+	err error
+}
+
+// A group of requests in the same bucket and the time the first arrived.
+type pendingBatch struct {
+	requests []batchRequest
+	deadline time.Time
+}
+
+// A Batcher collects single-sample requests from many goroutines, stacks
+// them along a new batch dimension, runs one forward pass on a Pool, and
+// splits the output back into per-request outputs. A batch is run when it
+// reaches the maximum batch size or when its first request has waited for
+// the maximum latency.
+type Batcher struct {
+	pool *Pool
+	options BatcherOptions
+	requests chan batchRequest
+	done chan struct{}
+	closeOnce sync.Once
+	collector sync.WaitGroup
+}
+
+// Create a new Batcher that runs batches on the given pool.
+func NewBatcher(pool *Pool, options BatcherOptions) *Batcher {
+	if options.MaxBatchSize == 0 {
+		options.MaxBatchSize = 32
+	}
+	if options.MaxBatchSize < 0 { panic("MaxBatchSize should be greater than 0") }
+	if options.MaxLatency == 0 {
+		options.MaxLatency = 5 * time.Millisecond
+	}
+	if options.MaxLatency < 0 { panic("MaxLatency should be greater than 0") }
+	batcher := &Batcher{
+		pool: pool,
+		options: options,
+		requests: make(chan batchRequest),
+		done: make(chan struct{}),
+	}
+	batcher.collector.Add(1)
+	go batcher.collect()
+	return batcher
+}
+
+// Return the bucket of a request. Padded requests are bucketed by the ranks
+// of their inputs, as only inputs of the same rank can be padded together.
+func (batcher *Batcher) bucket(inputs []*torch.Tensor) string {
+	var key strings.Builder
+	fmt.Fprint(&key, len(inputs))
+	for _, input := range inputs {
+		if batcher.options.Pad {
+			fmt.Fprintf(&key, "[%d]", input.Dim())
+		} else {
+			fmt.Fprint(&key, input.Shape())
+		}
+	}
+	if batcher.options.Bucket != nil {
+		key.WriteString("/")
+		key.WriteString(batcher.options.Bucket(inputs))
+	}
+	return key.String()
+}
+
+// Collect requests into batches until the batcher is closed.
+func (batcher *Batcher) collect() {
+	defer batcher.collector.Done()
+	pending := make(map[string]*pendingBatch)
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		// Wake up when the oldest pending batch reaches its deadline.
+		var next time.Time
+		for _, batch := range pending {
+			if next.IsZero() || batch.deadline.Before(next) {
+				next = batch.deadline
+			}
+		}
+		var wake <-chan time.Time
+		if !next.IsZero() {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(time.Until(next))
+			wake = timer.C
+		}
+		select {
+		case <-batcher.done:
+			for _, batch := range pending {
+				for _, request := range batch.requests {
+					request.response <- batchResponse{nil, ErrBatcherClosed}
+				}
+			}
+			return
+		case request := <-batcher.requests:
+			key := batcher.bucket(request.inputs)
+			batch, ok := pending[key]
+			if !ok {
+				batch = &pendingBatch{deadline: time.Now().Add(batcher.options.MaxLatency)}
+				pending[key] = batch
+			}
+			batch.requests = append(batch.requests, request)
+			if len(batch.requests) >= batcher.options.MaxBatchSize {
+				delete(pending, key)
+				go batcher.run(batch.requests)
+			}
+		case now := <-wake:
+			for key, batch := range pending {
+				if !batch.deadline.After(now) {
+					delete(pending, key)
+					go batcher.run(batch.requests)
+				}
+			}
+		}
+	}
+}
+
+// Pad tensors at the end of each dimension to the largest size of each
+// dimension among them.
+func padToLargest(tensors []*torch.Tensor, value float64) []*torch.Tensor {
+	sizes := append([]int64{}, tensors[0].Shape()...)
+	for _, tensor := range tensors[1:] {
+		shape := tensor.Shape()
+		if len(shape) != len(sizes) {
+			panic(fmt.Sprintf("Cannot pad tensors with shapes %v and %v", sizes, shape))
+		}
+		for dim := range shape {
+			if shape[dim] > sizes[dim] {
+				sizes[dim] = shape[dim]
+			}
+		}
+	}
+	output := make([]*torch.Tensor, len(tensors))
+	for index, tensor := range tensors {
+		shape := tensor.Shape()
+		// Padding is specified from the last dimension to the first.
+		padding := make([]int64, 2 * len(shape))
+		needsPadding := false
+		for dim := range shape {
+			padding[2 * (len(shape) - 1 - dim) + 1] = sizes[dim] - shape[dim]
+			needsPadding = needsPadding || sizes[dim] != shape[dim]
+		}
+		if needsPadding {
+			tensor = F.Pad(tensor, padding, F.PadConstant, value)
+		}
+		output[index] = tensor
+	}
+	return output
+}
+
+// Stack the inputs of a batch of requests and forward them through the pool.
+func (batcher *Batcher) forward(requests []batchRequest) (outputs []interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+	inputs := make([]*torch.IValue, len(requests[0].inputs))
+	for argument := range inputs {
+		tensors := make([]*torch.Tensor, len(requests))
+		for index, request := range requests {
+			tensors[index] = request.inputs[argument]
+		}
+		if batcher.options.Pad {
+			tensors = padToLargest(tensors, batcher.options.PadValue)
+		}
+		inputs[argument] = torch.NewIValue(torch.Stack(tensors, 0))
+	}
+	output, err := batcher.pool.Forward(context.Background(), inputs)
+	if err != nil {
+		return nil, err
+	}
+	return splitOutput(output, len(requests))
+}
+
+// Run a batch of requests and send each request its output.
+func (batcher *Batcher) run(requests []batchRequest) {
+	outputs, err := batcher.forward(requests)
+	for index, request := range requests {
+		if err != nil {
+			request.response <- batchResponse{nil, err}
+		} else {
+			request.response <- batchResponse{outputs[index], nil}
+		}
+	}
+}
+
+// Split the output of a batch into the outputs of its n samples. Tensors are
+// split along their first dimension. Tuples and lists become []interface{}
+// and dictionaries become map[interface{}]interface{} with their elements
+// split recursively. Other values are shared by every sample.
+func splitOutput(output *torch.IValue, n int) ([]interface{}, error) {
+	outputs := make([]interface{}, n)
+	switch {
+	case output.IsTensor():
+		tensor := output.ToTensor()
+		if tensor.Dim() == 0 || tensor.Shape()[0] != int64(n) {
+			return nil, fmt.Errorf("Expected output with batch size %d, but received tensor with shape %v", n, tensor.Shape())
+		}
+		for index := range outputs {
+			outputs[index] = tensor.Slice(0, int64(index), int64(index + 1), 1).Squeeze(0)
+		}
+	case output.IsTuple(), output.IsList():
+		var elements []*torch.IValue
+		if output.IsTuple() {
+			elements = output.ToTuple()
+		} else {
+			elements = output.ToList()
+		}
+		for index := range outputs {
+			outputs[index] = make([]interface{}, len(elements))
+		}
+		for position, element := range elements {
+			split, err := splitOutput(element, n)
+			if err != nil {
+				return nil, err
+			}
+			for index := range outputs {
+				outputs[index].([]interface{})[position] = split[index]
+			}
+		}
+	case output.IsGenericDict():
+		for index := range outputs {
+			outputs[index] = make(map[interface{}]interface{})
+		}
+		for key, value := range output.ToGenericDict() {
+			split, err := splitOutput(value, n)
+			if err != nil {
+				return nil, err
+			}
+			for index := range outputs {
+				outputs[index].(map[interface{}]interface{})[key] = split[index]
+			}
+		}
+	default:
+		var value interface{}
+		switch {
+		case output.IsNil():
+			value = nil
+		case output.IsBool():
+			value = output.ToBool()
+		case output.IsInt():
+			value = output.ToInt()
+		case output.IsDouble():
+			value = output.ToDouble()
+		case output.IsString():
+			value = output.ToString()
+		default:
+			return nil, errors.New("Unsupported output type for batching")
+		}
+		for index := range outputs {
+			outputs[index] = value
+		}
+	}
+	return outputs, nil
+}
+
+// Forward the inputs of a single sample, i.e., without a batch dimension,
+// through the module as part of a batch and return the sample's output. The
+// output is a *torch.Tensor, []interface{} (tuples and lists),
+// map[interface{}]interface{} (dictionaries), or a scalar, as produced by
+// splitting the batch output along its first dimension. Forward is safe for
+// concurrent use and returns the context's error if it is cancelled first.
+func (batcher *Batcher) Forward(ctx context.Context, inputs ...*torch.Tensor) (interface{}, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("Forward requires at least one input")
+	}
+	request := batchRequest{inputs, make(chan batchResponse, 1)}
+	select {
+	case <-batcher.done:
+		return nil, ErrBatcherClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case batcher.requests <- request:
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case response := <-request.response:
+		return response.output, response.err
+	}
+}
+
+// Stop collecting requests. Requests waiting to be batched fail with
+// ErrBatcherClosed, while batches that are already running complete. The
+// underlying pool is not closed.
+func (batcher *Batcher) Close() {
+	batcher.closeOnce.Do(func() { close(batcher.done) })
+	batcher.collector.Wait()
+}
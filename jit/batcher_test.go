@@ -0,0 +1,148 @@
+// test cases for batcher.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package jit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/jit"
+)
+
+func newBatcher(t *testing.T, path string, options jit.BatcherOptions) (*jit.Batcher, func()) {
+	module, err := jit.Load(path, torch.NewDevice("cpu"))
+	assert.Nil(t, err)
+	pool := jit.NewPool(module, jit.PoolOptions{Workers: 2})
+	batcher := jit.NewBatcher(pool, options)
+	return batcher, func() {
+		batcher.Close()
+		pool.Close()
+	}
+}
+
+func TestSizeBuckets(t *testing.T) {
+	bucket := jit.SizeBuckets(-1, 16, 8)
+	assert.Equal(t, "0", bucket([]*torch.Tensor{torch.Zeros([]int64{2, 5}, torch.NewTensorOptions())}))
+	assert.Equal(t, "0", bucket([]*torch.Tensor{torch.Zeros([]int64{2, 8}, torch.NewTensorOptions())}))
+	assert.Equal(t, "1", bucket([]*torch.Tensor{torch.Zeros([]int64{2, 9}, torch.NewTensorOptions())}))
+	assert.Equal(t, "2", bucket([]*torch.Tensor{torch.Zeros([]int64{2, 17}, torch.NewTensorOptions())}))
+}
+
+func TestNewBatcherPanicsOnNegativeMaxBatchSize(t *testing.T) {
+	module, _ := loadIdentity()
+	pool := jit.NewPool(module, jit.PoolOptions{})
+	defer pool.Close()
+	assert.PanicsWithValue(t, "MaxBatchSize should be greater than 0", func() {
+		jit.NewBatcher(pool, jit.BatcherOptions{MaxBatchSize: -1})
+	})
+}
+
+func TestBatcherForwardSplitsTensorOutputs(t *testing.T) {
+	batcher, closer := newBatcher(t, "../data/trace_identity.pt", jit.BatcherOptions{MaxBatchSize: 4, MaxLatency: time.Second})
+	defer closer()
+	var group sync.WaitGroup
+	for index := 0; index < 8; index++ {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			tensor := torch.NewTensor([]float32{float32(index), float32(index)})
+			output, err := batcher.Forward(context.Background(), tensor)
+			assert.Nil(t, err)
+			assert.True(t, torch.Equal(output.(*torch.Tensor), tensor))
+		}(index)
+	}
+	group.Wait()
+}
+
+func TestBatcherForwardRunsPartialBatchAfterMaxLatency(t *testing.T) {
+	batcher, closer := newBatcher(t, "../data/trace_identity.pt", jit.BatcherOptions{MaxBatchSize: 64, MaxLatency: time.Millisecond})
+	defer closer()
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	output, err := batcher.Forward(context.Background(), tensor)
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(output.(*torch.Tensor), tensor))
+}
+
+func TestBatcherForwardPadsVariableSizedInputs(t *testing.T) {
+	options := jit.BatcherOptions{MaxBatchSize: 2, MaxLatency: time.Second, Pad: true, PadValue: -1}
+	batcher, closer := newBatcher(t, "../data/trace_identity.pt", options)
+	defer closer()
+	var short, long interface{}
+	var group sync.WaitGroup
+	group.Add(2)
+	go func() {
+		defer group.Done()
+		short, _ = batcher.Forward(context.Background(), torch.NewTensor([]float32{1}))
+	}()
+	go func() {
+		defer group.Done()
+		long, _ = batcher.Forward(context.Background(), torch.NewTensor([]float32{1, 2, 3}))
+	}()
+	group.Wait()
+	assert.Equal(t, []float32{1, -1, -1}, short.(*torch.Tensor).ToSlice())
+	assert.Equal(t, []float32{1, 2, 3}, long.(*torch.Tensor).ToSlice())
+}
+
+func TestBatcherForwardDoesNotPadInputsOfDifferentRanks(t *testing.T) {
+	options := jit.BatcherOptions{MaxBatchSize: 2, MaxLatency: 10 * time.Millisecond, Pad: true}
+	batcher, closer := newBatcher(t, "../data/trace_identity.pt", options)
+	defer closer()
+	vector := torch.NewTensor([]float32{1, 2})
+	matrix := torch.NewTensor([][]float32{{1, 2}, {3, 4}})
+	var vectorOutput, matrixOutput interface{}
+	var vectorErr, matrixErr error
+	var group sync.WaitGroup
+	group.Add(2)
+	go func() {
+		defer group.Done()
+		vectorOutput, vectorErr = batcher.Forward(context.Background(), vector)
+	}()
+	go func() {
+		defer group.Done()
+		matrixOutput, matrixErr = batcher.Forward(context.Background(), matrix)
+	}()
+	group.Wait()
+	if !assert.Nil(t, vectorErr) || !assert.Nil(t, matrixErr) { return }
+	assert.True(t, torch.Equal(vectorOutput.(*torch.Tensor), vector))
+	assert.True(t, torch.Equal(matrixOutput.(*torch.Tensor), matrix))
+}
+
+func TestBatcherForwardSharesNonTensorOutputs(t *testing.T) {
+	batcher, closer := newBatcher(t, "../data/module_that_returns_tuple.pt", jit.BatcherOptions{MaxLatency: time.Millisecond})
+	defer closer()
+	output, err := batcher.Forward(context.Background(), torch.NewTensor([]float32{1}))
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{9, 6, 3}, output)
+}
+
+func TestBatcherForwardReturnsErrorAfterClose(t *testing.T) {
+	batcher, closer := newBatcher(t, "../data/trace_identity.pt", jit.BatcherOptions{})
+	closer()
+	output, err := batcher.Forward(context.Background(), torch.NewTensor([]float32{1}))
+	assert.Nil(t, output)
+	assert.Equal(t, jit.ErrBatcherClosed, err)
+}
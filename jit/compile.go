@@ -0,0 +1,157 @@
+// Compilation of TorchScript source code at runtime.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package jit
+
+// #include <stdlib.h>
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// A container for the functions compiled from TorchScript source code.
+type CompilationUnit struct {
+	Pointer C.CompilationUnit
+}
+
+// Compile TorchScript source code that defines free functions, e.g.,
+//
+//	unit, err := jit.Compile(`
+//	def normalize(x, mean: float, std: float):
+//	    return (x - mean) / std
+//	`)
+//
+// The error describes syntax and type errors in the source code.
+func Compile(source string) (*CompilationUnit, error) {
+	source_cstring := C.CString(source)
+	defer C.free(unsafe.Pointer(source_cstring))
+	unit := &CompilationUnit{}
+	err := unsafe.Pointer(C.Torch_Jit_Compile(&unit.Pointer, source_cstring))
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	unit.setFinalizer()
+	return unit, nil
+}
+
+// Free a compilation unit from memory.
+func (unit *CompilationUnit) free() {
+	if unit.Pointer == nil {
+		panic("Attempting to free a compilation unit that has already been freed!")
+	}
+	C.Torch_Jit_CompilationUnit_Free(unit.Pointer)
+	unit.Pointer = nil
+	internal.UntrackObject(unsafe.Pointer(unit))
+}
+
+// Attach the garbage collection finalizer to a newly created compilation unit
+// and record its allocation site for leak detection.
+func (unit *CompilationUnit) setFinalizer() {
+	runtime.SetFinalizer(unit, (*CompilationUnit).free)
+	internal.TrackObject("CompilationUnit", unsafe.Pointer(unit))
+}
+
+// Return the names of the functions in the compilation unit.
+func (unit *CompilationUnit) FunctionNames() []string {
+	var length C.int64_t
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_CompilationUnit_NumFunctions(&length, unit.Pointer)))
+	if length == 0 { return []string{} }
+	names := make([]*C.char, length)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_CompilationUnit_FunctionNames(&names[0], length, unit.Pointer)))
+	runtime.KeepAlive(unit)
+	output := make([]string, length)
+	for index, name := range names {
+		output[index] = goStringAndFree(name)
+	}
+	return output
+}
+
+// Return true if the compilation unit has a function with the given name.
+func (unit *CompilationUnit) HasFunction(name string) bool {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_CompilationUnit_HasFunction(&output, unit.Pointer, name_cstring)))
+	runtime.KeepAlive(unit)
+	return bool(output)
+}
+
+// Call the function with the given name with positional and keyword inputs
+// and return its output. kwargs may be nil. Arguments that are omitted take
+// their default values. Call returns an error if the function does not
+// exist, if the inputs do not match its schema, or if it raises an
+// exception.
+func (unit *CompilationUnit) Call(name string, inputs []*torch.IValue, kwargs map[string]*torch.IValue) (*torch.IValue, error) {
+	if !unit.HasFunction(name) {
+		return nil, fmt.Errorf("Compilation unit does not have a function named %q", name)
+	}
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	arrays := newMethodInputs(inputs, kwargs)
+	defer arrays.free()
+	names, values := arrays.kwargsPointers()
+	output := &torch.IValue{}
+	err := unsafe.Pointer(C.Torch_Jit_CompilationUnit_RunFunction(
+		(*C.IValue)(&output.Pointer),
+		unit.Pointer,
+		name_cstring,
+		arrays.inputsPointer(),
+		C.int64_t(len(arrays.inputs)),
+		names,
+		values,
+		C.int64_t(len(arrays.kwargNames)),
+	))
+	runtime.KeepAlive(unit)
+	runtime.KeepAlive(inputs)
+	runtime.KeepAlive(kwargs)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	torch.SetIValueFinalizer(output)
+	return output, nil
+}
+
+// Compile TorchScript source code that defines methods of the module, e.g.,
+//
+//	err := module.Define(`
+//	def postprocess(self, x):
+//	    return self.forward(x).softmax(-1)
+//	`)
+//
+// The methods may refer to the attributes and methods of the module through
+// self and can be called with Method once defined.
+func (module *JitModule) Define(source string) error {
+	source_cstring := C.CString(source)
+	defer C.free(unsafe.Pointer(source_cstring))
+	err := unsafe.Pointer(C.Torch_Jit_Module_Define(module.Pointer, source_cstring))
+	runtime.KeepAlive(module)
+	if err != nil {
+		return internal.NewTorchError(err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+// test cases for compile.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+
+package jit_test
+
+import (
+	"runtime"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/jit"
+)
+
+const compileSource = `
+def add(x: int, y: int = 1) -> int:
+    return x + y
+
+def normalize(x, mean: float, std: float):
+    return (x - mean) / std
+`
+
+// MARK: Compile
+
+func TestCompile(t *testing.T) {
+	unit, err := jit.Compile(compileSource)
+	if !assert.Nil(t, err) { return }
+	assert.ElementsMatch(t, []string{"add", "normalize"}, unit.FunctionNames())
+	assert.True(t, unit.HasFunction("add"))
+	assert.False(t, unit.HasFunction("subtract"))
+}
+
+func TestCompileTracksCompilationUnitsForLeakDetection(t *testing.T) {
+	torch.SetLeakDetection(true)
+	defer torch.SetLeakDetection(false)
+	unit, err := jit.Compile(compileSource)
+	if !assert.Nil(t, err) { return }
+	count := 0
+	for _, site := range torch.LiveObjects() {
+		if site.Kind == "CompilationUnit" {
+			count += site.Count
+		}
+	}
+	assert.Equal(t, 1, count)
+	runtime.KeepAlive(unit)
+}
+
+func TestCompileReturnsErrorForInvalidSource(t *testing.T) {
+	unit, err := jit.Compile("def add(x, y):\n    return x +\n")
+	assert.Nil(t, unit)
+	assert.NotNil(t, err)
+}
+
+func TestCompileReturnsErrorForTypeErrors(t *testing.T) {
+	unit, err := jit.Compile("def add(x: int) -> str:\n    return x\n")
+	assert.Nil(t, unit)
+	assert.NotNil(t, err)
+}
+
+// MARK: CompilationUnit.Call
+
+func TestCompilationUnitCall(t *testing.T) {
+	unit, _ := jit.Compile(compileSource)
+	output, err := unit.Call("add", []*torch.IValue{torch.NewIValue(2), torch.NewIValue(3)}, nil)
+	if !assert.Nil(t, err) { return }
+	assert.Equal(t, 5, output.ToInt())
+}
+
+func TestCompilationUnitCallWithGenericDictPreservesSortedKeyOrder(t *testing.T) {
+	unit, err := jit.Compile("def keys(x: Dict[str, int]) -> List[str]:\n    return list(x.keys())\n")
+	if !assert.Nil(t, err) { return }
+	dict := torch.NewGenericDict(map[string]int{"c": 3, "a": 1, "d": 4, "b": 2, "e": 5})
+	output, err := unit.Call("keys", []*torch.IValue{dict}, nil)
+	if !assert.Nil(t, err) { return }
+	assert.Equal(t, []interface{}{"a", "b", "c", "d", "e"}, output.ToInterface())
+}
+
+func TestCompilationUnitCallUsesDefaults(t *testing.T) {
+	unit, _ := jit.Compile(compileSource)
+	output, err := unit.Call("add", []*torch.IValue{torch.NewIValue(2)}, nil)
+	if !assert.Nil(t, err) { return }
+	assert.Equal(t, 3, output.ToInt())
+}
+
+func TestCompilationUnitCallWithKwargs(t *testing.T) {
+	unit, _ := jit.Compile(compileSource)
+	tensor := torch.NewTensor([]float32{1, 3, 5})
+	output, err := unit.Call("normalize", []*torch.IValue{torch.NewIValue(tensor)}, map[string]*torch.IValue{
+		"mean": torch.NewIValue(3.0),
+		"std": torch.NewIValue(2.0),
+	})
+	if !assert.Nil(t, err) { return }
+	expected := torch.NewTensor([]float32{-1, 0, 1})
+	assert.True(t, expected.AllClose(output.ToTensor(), 1e-5, 1e-8))
+}
+
+func TestCompilationUnitCallReturnsErrorForMissingFunction(t *testing.T) {
+	unit, _ := jit.Compile(compileSource)
+	output, err := unit.Call("subtract", []*torch.IValue{torch.NewIValue(2)}, nil)
+	assert.Nil(t, output)
+	assert.NotNil(t, err)
+}
+
+func TestCompilationUnitCallReturnsErrorForInvalidInputs(t *testing.T) {
+	unit, _ := jit.Compile(compileSource)
+	output, err := unit.Call("add", []*torch.IValue{torch.NewIValue("2")}, nil)
+	assert.Nil(t, output)
+	assert.NotNil(t, err)
+}
+
+// MARK: Define
+
+func TestJitModuleDefine(t *testing.T) {
+	module, _ := jit.Load("../data/script_sequential.pt", torch.NewDevice("cpu"))
+	err := module.Define("def postprocess(self, x):\n    return self.forward(x).softmax(-1)\n")
+	if !assert.Nil(t, err) { return }
+	assert.True(t, module.HasMethod("postprocess"))
+	method, err := module.Method("postprocess")
+	if !assert.Nil(t, err) { return }
+	input := torch.Rand([]int64{4, 2}, torch.NewTensorOptions())
+	output, err := method.Call([]*torch.IValue{torch.NewIValue(input)}, nil)
+	if !assert.Nil(t, err) { return }
+	assert.Equal(t, []int64{4, 3}, output.ToTensor().Shape())
+}
+
+func TestJitModuleDefineReturnsErrorForInvalidSource(t *testing.T) {
+	module, _ := jit.Load("../data/script_sequential.pt", torch.NewDevice("cpu"))
+	assert.NotNil(t, module.Define("def postprocess(self, x):\n    return self.missing(x)\n"))
+	assert.False(t, module.HasMethod("postprocess"))
+}
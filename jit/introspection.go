@@ -0,0 +1,401 @@
+// Introspection of the structure, state, and methods of TorchScript modules.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package jit
+
+// #include <stdlib.h>
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"unsafe"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// Return the qualified name of the class of the module, e.g.,
+// "__torch__.torch.nn.modules.linear.Linear".
+func (module *JitModule) TypeName() string {
+	var output *C.char
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_TypeName(&output, module.Pointer)))
+	runtime.KeepAlive(module)
+	defer C.free(unsafe.Pointer(output))
+	return C.GoString(output)
+}
+
+// Return the names of the methods of the module, e.g., "forward".
+func (module *JitModule) MethodNames() []string {
+	var length C.int64_t
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_NumMethods(&length, module.Pointer)))
+	if length == 0 { return []string{} }
+	names := make([]*C.char, length)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_MethodNames(&names[0], length, module.Pointer)))
+	runtime.KeepAlive(module)
+	output := make([]string, length)
+	for index, name := range names {
+		output[index] = C.GoString(name)
+		C.free(unsafe.Pointer(name))
+	}
+	return output
+}
+
+// An argument or return value of a method.
+type Argument struct {
+	// The name of the argument. Return values are usually unnamed.
+	Name string
+	// The TorchScript type of the argument, e.g., "Tensor" or "List[int]".
+	Type string
+	// The default value of the argument, or nil if it does not have one.
+	Default *torch.IValue
+}
+
+// The signature of a TorchScript method.
+type Schema struct {
+	// The name of the method.
+	Name string
+	// The arguments of the method, starting with self.
+	Arguments []Argument
+	// The return values of the method.
+	Returns []Argument
+}
+
+// Convert the schema to a human-readable string, e.g.,
+// "forward(Tensor x, int k=1) -> Tensor".
+func (schema *Schema) String() string {
+	var arguments []string
+	for _, argument := range schema.Arguments {
+		if argument.Name == "self" { continue }
+		text := argument.Type + " " + argument.Name
+		if argument.Default != nil {
+			text += "=" + formatDefault(argument.Default)
+		}
+		arguments = append(arguments, text)
+	}
+	var returns []string
+	for _, value := range schema.Returns {
+		returns = append(returns, strings.TrimSpace(value.Type + " " + value.Name))
+	}
+	output := fmt.Sprintf("%s(%s) -> ", schema.Name, strings.Join(arguments, ", "))
+	if len(returns) == 1 {
+		return output + returns[0]
+	}
+	return output + "(" + strings.Join(returns, ", ") + ")"
+}
+
+// Format the default value of an argument for display.
+func formatDefault(value *torch.IValue) string {
+	switch {
+	case value.IsNil():
+		return "None"
+	case value.IsBool():
+		if value.ToBool() { return "True" }
+		return "False"
+	case value.IsInt():
+		return fmt.Sprint(value.ToInt())
+	case value.IsDouble():
+		return fmt.Sprint(value.ToDouble())
+	case value.IsString():
+		return fmt.Sprintf("%q", value.ToString())
+	default:
+		return "..."
+	}
+}
+
+// Return the schema of the method with the given name.
+func (module *JitModule) MethodSchema(name string) (*Schema, error) {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	var numArguments, numReturns C.int64_t
+	err := unsafe.Pointer(C.Torch_Jit_Module_MethodSchemaSize(&numArguments, &numReturns, module.Pointer, name_cstring))
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	// Allocate one extra element so that the buffers are never empty.
+	names := make([]*C.char, numArguments + 1)
+	types := make([]*C.char, numArguments + 1)
+	defaults := make([]C.IValue, numArguments + 1)
+	returnNames := make([]*C.char, numReturns + 1)
+	returnTypes := make([]*C.char, numReturns + 1)
+	err = unsafe.Pointer(C.Torch_Jit_Module_MethodSchema(
+		&names[0],
+		&types[0],
+		&defaults[0],
+		numArguments,
+		&returnNames[0],
+		&returnTypes[0],
+		numReturns,
+		module.Pointer,
+		name_cstring,
+	))
+	runtime.KeepAlive(module)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	schema := &Schema{
+		Name: name,
+		Arguments: make([]Argument, numArguments),
+		Returns: make([]Argument, numReturns),
+	}
+	for index := range schema.Arguments {
+		schema.Arguments[index] = Argument{Name: goStringAndFree(names[index]), Type: goStringAndFree(types[index])}
+		if defaults[index] != nil {
+			schema.Arguments[index].Default = &torch.IValue{}
+			*(*C.IValue)(&schema.Arguments[index].Default.Pointer) = defaults[index]
+			torch.SetIValueFinalizer(schema.Arguments[index].Default)
+		}
+	}
+	for index := range schema.Returns {
+		schema.Returns[index] = Argument{Name: goStringAndFree(returnNames[index]), Type: goStringAndFree(returnTypes[index])}
+	}
+	return schema, nil
+}
+
+// Return the graph of the method with the given name with all function calls
+// inlined, i.e., the graph printed by torch.jit.ScriptModule.inlined_graph.
+func (module *JitModule) Graph(method string) (string, error) {
+	method_cstring := C.CString(method)
+	defer C.free(unsafe.Pointer(method_cstring))
+	var output *C.char
+	err := unsafe.Pointer(C.Torch_Jit_Module_MethodGraph(&output, module.Pointer, method_cstring))
+	runtime.KeepAlive(module)
+	if err != nil {
+		return "", internal.NewTorchError(err)
+	}
+	return goStringAndFree(output), nil
+}
+
+// Convert a C string to a Go string and free the C string.
+func goStringAndFree(cstring *C.char) string {
+	defer C.free(unsafe.Pointer(cstring))
+	return C.GoString(cstring)
+}
+
+// A tensor owned by a module along with its qualified name, e.g.,
+// "layer1.0.conv1.weight".
+type NamedTensor struct {
+	Name string
+	Tensor *torch.Tensor
+}
+
+// A submodule of a module along with its name.
+type NamedModule struct {
+	Name string
+	Module *JitModule
+}
+
+// Wrap C names and tensors with Go strings and finalized tensors, freeing the
+// C names.
+func wrapNamedTensors(names []*C.char, pointers []C.Tensor) []NamedTensor {
+	tensors := make([]NamedTensor, len(names))
+	for index := range names {
+		tensors[index].Name = C.GoString(names[index])
+		C.free(unsafe.Pointer(names[index]))
+		tensors[index].Tensor = &torch.Tensor{}
+		*(*C.Tensor)(&tensors[index].Tensor.Pointer) = pointers[index]
+		torch.SetTensorFinalizer(tensors[index].Tensor)
+	}
+	return tensors
+}
+
+// Return the parameters of the module and its submodules in definition order.
+// The tensors share their storage with the module.
+func (module *JitModule) NamedParameters() []NamedTensor {
+	var length C.int64_t
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_NumParameters(&length, module.Pointer, C.bool(true))))
+	if length == 0 { return []NamedTensor{} }
+	names := make([]*C.char, length)
+	pointers := make([]C.Tensor, length)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_NamedParameters(
+		&names[0],
+		&pointers[0],
+		length,
+		module.Pointer,
+		C.bool(true),
+	)))
+	runtime.KeepAlive(module)
+	return wrapNamedTensors(names, pointers)
+}
+
+// Return the buffers of the module and its submodules in definition order,
+// e.g., the running statistics of batch normalization layers. The tensors
+// share their storage with the module.
+func (module *JitModule) NamedBuffers() []NamedTensor {
+	var length C.int64_t
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_NumBuffers(&length, module.Pointer, C.bool(true))))
+	if length == 0 { return []NamedTensor{} }
+	names := make([]*C.char, length)
+	pointers := make([]C.Tensor, length)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_NamedBuffers(
+		&names[0],
+		&pointers[0],
+		length,
+		module.Pointer,
+		C.bool(true),
+	)))
+	runtime.KeepAlive(module)
+	return wrapNamedTensors(names, pointers)
+}
+
+// Return the immediate submodules of the module in definition order. The
+// submodules share their state with the module.
+func (module *JitModule) NamedChildren() []NamedModule {
+	var length C.int64_t
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_NumChildren(&length, module.Pointer)))
+	if length == 0 { return []NamedModule{} }
+	names := make([]*C.char, length)
+	pointers := make([]C.JitModule, length)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_NamedChildren(
+		&names[0],
+		&pointers[0],
+		length,
+		module.Pointer,
+	)))
+	runtime.KeepAlive(module)
+	children := make([]NamedModule, length)
+	for index := range names {
+		children[index].Name = C.GoString(names[index])
+		C.free(unsafe.Pointer(names[index]))
+		children[index].Module = &JitModule{pointers[index]}
+		children[index].Module.setFinalizer()
+	}
+	return children
+}
+
+// Return the module and all of its submodules, recursively, in definition
+// order. The module itself is first with an empty name and submodules have
+// qualified names, e.g., "layer1.0.conv1".
+func (module *JitModule) NamedModules() []NamedModule {
+	modules := []NamedModule{{"", module}}
+	for _, child := range module.NamedChildren() {
+		for _, descendant := range child.Module.NamedModules() {
+			name := child.Name
+			if descendant.Name != "" {
+				name += "." + descendant.Name
+			}
+			modules = append(modules, NamedModule{name, descendant.Module})
+		}
+	}
+	return modules
+}
+
+// Return true if the module has an attribute with the given name, e.g., a
+// parameter, buffer, submodule, or constant.
+func (module *JitModule) HasAttr(name string) bool {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_HasAttr(&output, module.Pointer, name_cstring)))
+	runtime.KeepAlive(module)
+	return bool(output)
+}
+
+// Return the value of the attribute with the given name. Tensors in the value
+// share their storage with the module and submodules can be converted with
+// FromIValue. Attr panics if the module does not have the attribute.
+func (module *JitModule) Attr(name string) *torch.IValue {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	output := &torch.IValue{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_Attr(
+		(*C.IValue)(&output.Pointer),
+		module.Pointer,
+		name_cstring,
+	)))
+	runtime.KeepAlive(module)
+	torch.SetIValueFinalizer(output)
+	return output
+}
+
+// Set the value of an existing attribute of the module, e.g., replace a
+// submodule with the IValue of another module of the same class. SetAttr
+// panics if the module does not have the attribute or if the type of the
+// value does not match the type of the attribute.
+func (module *JitModule) SetAttr(name string, value *torch.IValue) *JitModule {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_SetAttr(
+		module.Pointer,
+		name_cstring,
+		(C.IValue)(value.Pointer),
+	)))
+	runtime.KeepAlive(module)
+	runtime.KeepAlive(value)
+	return module
+}
+
+// Register a tensor as a parameter of the module. The tensor replaces the
+// value of an existing parameter with the same name.
+func (module *JitModule) RegisterParameter(name string, tensor *torch.Tensor) *JitModule {
+	return module.registerParameter(name, tensor, false)
+}
+
+// Register a tensor as a buffer of the module. The tensor replaces the value
+// of an existing buffer with the same name.
+func (module *JitModule) RegisterBuffer(name string, tensor *torch.Tensor) *JitModule {
+	return module.registerParameter(name, tensor, true)
+}
+
+// Register a tensor as a parameter or buffer of the module.
+func (module *JitModule) registerParameter(name string, tensor *torch.Tensor, isBuffer bool) *JitModule {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_RegisterParameter(
+		module.Pointer,
+		name_cstring,
+		(C.Tensor)(tensor.Pointer),
+		C.bool(isBuffer),
+	)))
+	runtime.KeepAlive(module)
+	runtime.KeepAlive(tensor)
+	return module
+}
+
+// Convert the module to an IValue holding its underlying object, e.g., to
+// pass it to SetAttr or to a method. The IValue shares its state with the
+// module.
+func (module *JitModule) ToIValue() *torch.IValue {
+	output := &torch.IValue{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_ToIValue(
+		(*C.IValue)(&output.Pointer),
+		module.Pointer,
+	)))
+	runtime.KeepAlive(module)
+	torch.SetIValueFinalizer(output)
+	return output
+}
+
+// Convert an IValue holding a module object, e.g., the value returned by Attr
+// for a submodule, to a module that shares its state. FromIValue panics if
+// the IValue does not hold a module.
+func FromIValue(ivalue *torch.IValue) *JitModule {
+	module := &JitModule{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_FromIValue(
+		&module.Pointer,
+		(C.IValue)(ivalue.Pointer),
+	)))
+	runtime.KeepAlive(ivalue)
+	module.setFinalizer()
+	return module
+}
@@ -0,0 +1,216 @@
+// test cases for introspection.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jit_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/jit"
+)
+
+func loadSequential(t *testing.T) *jit.JitModule {
+	module, err := jit.Load("../data/script_sequential.pt", torch.NewDevice("cpu"))
+	assert.Nil(t, err)
+	return module
+}
+
+// MARK: TypeName
+
+func TestJitModuleTypeName(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	assert.Contains(t, module.TypeName(), "torch.nn.modules.linear.Identity")
+}
+
+// MARK: MethodNames
+
+func TestJitModuleMethodNames(t *testing.T) {
+	assert.Contains(t, loadSequential(t).MethodNames(), "forward")
+}
+
+// MARK: MethodSchema
+
+func TestJitModuleMethodSchema(t *testing.T) {
+	schema, err := loadSequential(t).MethodSchema("forward")
+	assert.Nil(t, err)
+	if !assert.Equal(t, 2, len(schema.Arguments)) { return }
+	assert.Equal(t, "self", schema.Arguments[0].Name)
+	assert.Equal(t, "input", schema.Arguments[1].Name)
+	assert.Equal(t, "Tensor", schema.Arguments[1].Type)
+	assert.Nil(t, schema.Arguments[1].Default)
+	assert.Equal(t, 1, len(schema.Returns))
+	assert.Equal(t, "forward(Tensor input) -> Tensor", schema.String())
+}
+
+func TestJitModuleMethodSchemaReturnsErrorForMissingMethod(t *testing.T) {
+	schema, err := loadSequential(t).MethodSchema("missing")
+	assert.Nil(t, schema)
+	assert.NotNil(t, err)
+}
+
+// MARK: Graph
+
+func TestJitModuleGraph(t *testing.T) {
+	graph, err := loadSequential(t).Graph("forward")
+	assert.Nil(t, err)
+	assert.Contains(t, graph, "graph(")
+	// Calls to the submodules are inlined into the graph.
+	assert.Contains(t, graph, "aten::linear")
+	assert.NotContains(t, graph, "prim::CallMethod")
+}
+
+func TestJitModuleGraphReturnsErrorForMissingMethod(t *testing.T) {
+	_, err := loadSequential(t).Graph("missing")
+	assert.NotNil(t, err)
+}
+
+// MARK: NamedParameters
+
+func TestJitModuleNamedParameters(t *testing.T) {
+	parameters := loadSequential(t).NamedParameters()
+	if !assert.Equal(t, 4, len(parameters)) { return }
+	assert.Equal(t, "0.weight", parameters[0].Name)
+	assert.Equal(t, []int64{3, 2}, parameters[0].Tensor.Shape())
+	assert.Equal(t, "0.bias", parameters[1].Name)
+	assert.Equal(t, "1.weight", parameters[2].Name)
+	assert.Equal(t, "1.bias", parameters[3].Name)
+}
+
+func TestJitModuleNamedParametersEmpty(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	assert.Equal(t, []jit.NamedTensor{}, module.NamedParameters())
+}
+
+// MARK: NamedBuffers
+
+func TestJitModuleNamedBuffers(t *testing.T) {
+	buffers := loadSequential(t).NamedBuffers()
+	if !assert.Equal(t, 3, len(buffers)) { return }
+	assert.Equal(t, "1.running_mean", buffers[0].Name)
+	assert.Equal(t, []int64{3}, buffers[0].Tensor.Shape())
+	assert.Equal(t, "1.running_var", buffers[1].Name)
+	assert.Equal(t, "1.num_batches_tracked", buffers[2].Name)
+	assert.Equal(t, torch.Long, buffers[2].Tensor.Dtype())
+}
+
+// MARK: NamedChildren
+
+func TestJitModuleNamedChildren(t *testing.T) {
+	children := loadSequential(t).NamedChildren()
+	if !assert.Equal(t, 2, len(children)) { return }
+	assert.Equal(t, "0", children[0].Name)
+	assert.Contains(t, children[0].Module.TypeName(), "Linear")
+	assert.Equal(t, 2, len(children[0].Module.NamedParameters()))
+	assert.Equal(t, "1", children[1].Name)
+	assert.Contains(t, children[1].Module.TypeName(), "BatchNorm1d")
+}
+
+// MARK: NamedModules
+
+func TestJitModuleNamedModules(t *testing.T) {
+	modules := loadSequential(t).NamedModules()
+	if !assert.Equal(t, 3, len(modules)) { return }
+	assert.Equal(t, "", modules[0].Name)
+	assert.Contains(t, modules[0].Module.TypeName(), "Sequential")
+	assert.Equal(t, "0", modules[1].Name)
+	assert.Equal(t, "1", modules[2].Name)
+}
+
+// MARK: HasAttr/Attr/SetAttr
+
+func TestJitModuleHasAttr(t *testing.T) {
+	module := loadSequential(t)
+	assert.True(t, module.HasAttr("0"))
+	assert.True(t, module.HasAttr("training"))
+	assert.False(t, module.HasAttr("missing"))
+}
+
+func TestJitModuleAttr(t *testing.T) {
+	module := loadSequential(t)
+	linear := jit.FromIValue(module.Attr("0"))
+	weight := linear.Attr("weight")
+	assert.True(t, weight.IsTensor())
+	assert.Equal(t, []int64{3, 2}, weight.ToTensor().Shape())
+	assert.True(t, module.Attr("training").IsBool())
+}
+
+func TestJitModuleAttrPanicsOnMissingAttribute(t *testing.T) {
+	module := loadSequential(t)
+	assert.Panics(t, func() { module.Attr("missing") })
+}
+
+func TestJitModuleSetAttr(t *testing.T) {
+	module := loadSequential(t)
+	linear := jit.FromIValue(module.Attr("0"))
+	weight := torch.Ones([]int64{3, 2}, torch.NewTensorOptions())
+	assert.Equal(t, linear.Pointer, linear.SetAttr("weight", torch.NewIValue(weight)).Pointer)
+	// The submodule shares its state with the module.
+	parameters := module.NamedParameters()
+	assert.True(t, torch.Equal(weight, parameters[0].Tensor))
+}
+
+func TestJitModuleSetAttrPanicsOnTypeMismatch(t *testing.T) {
+	module := loadSequential(t)
+	assert.Panics(t, func() { module.SetAttr("training", torch.NewIValue(1)) })
+}
+
+func TestJitModuleSetAttrSwapsSubmodule(t *testing.T) {
+	module := loadSequential(t)
+	other := loadSequential(t)
+	head := jit.FromIValue(other.Attr("0"))
+	head.SetAttr("weight", torch.NewIValue(torch.Zeros([]int64{3, 2}, torch.NewTensorOptions())))
+	module.SetAttr("0", head.ToIValue())
+	weight := jit.FromIValue(module.Attr("0")).Attr("weight").ToTensor()
+	assert.True(t, torch.Equal(torch.Zeros([]int64{3, 2}, torch.NewTensorOptions()), weight))
+}
+
+// MARK: RegisterParameter/RegisterBuffer
+
+func TestJitModuleRegisterParameter(t *testing.T) {
+	module := loadSequential(t)
+	scale := torch.Ones([]int64{1}, torch.NewTensorOptions())
+	module.RegisterParameter("scale", scale)
+	assert.True(t, module.HasAttr("scale"))
+	parameters := module.NamedParameters()
+	assert.Equal(t, "scale", parameters[len(parameters) - 1].Name)
+}
+
+func TestJitModuleRegisterBuffer(t *testing.T) {
+	module := loadSequential(t)
+	module.RegisterBuffer("mask", torch.Ones([]int64{2}, torch.NewTensorOptions()))
+	buffers := module.NamedBuffers()
+	assert.Equal(t, "mask", buffers[len(buffers) - 1].Name)
+}
+
+// MARK: ToIValue/FromIValue
+
+func TestJitModuleToIValue(t *testing.T) {
+	module := loadSequential(t)
+	ivalue := module.ToIValue()
+	assert.True(t, ivalue.IsModule())
+	assert.Equal(t, module.TypeName(), jit.FromIValue(ivalue).TypeName())
+}
+
+func TestFromIValuePanicsOnNonModule(t *testing.T) {
+	assert.Panics(t, func() { jit.FromIValue(torch.NewIValue(1)) })
+}
@@ -31,7 +31,12 @@ package jit
 // #include "cgotorch/cgotorch.h"
 import "C"
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
 	"runtime"
+	"strings"
 	"unsafe"
 	"github.com/Kautenja/gotorch"
 	"github.com/Kautenja/gotorch/internal"
@@ -56,10 +61,92 @@ func Load(path string, device *torch.Device) (*JitModule, error) {
 	if internalErr != nil {
 		return nil, internal.NewTorchError(internalErr)
 	}
-	runtime.SetFinalizer(module, (*JitModule).free)
+	module.setFinalizer()
 	return module, nil
 }
 
+// Load the JIT module from a buffer holding a TorchScript archive, e.g., the
+// contents of a file embedded with go:embed, and map it onto a compute device.
+func LoadFromBytes(buffer []byte, device *torch.Device) (*JitModule, error) {
+	module := &JitModule{}
+	// libtorch reads the buffer in place and does not retain it, so the Go
+	// memory can be passed without copying it to C memory.
+	var data unsafe.Pointer
+	if len(buffer) > 0 {
+		data = unsafe.Pointer(&buffer[0])
+	}
+	internalErr := unsafe.Pointer(C.Torch_Jit_LoadFromBuffer(
+		&module.Pointer,
+		data,
+		C.int64_t(len(buffer)),
+		(C.Device)(device.Pointer),
+	))
+	runtime.KeepAlive(buffer)
+	runtime.KeepAlive(device)
+	if internalErr != nil {
+		return nil, internal.NewTorchError(internalErr)
+	}
+	module.setFinalizer()
+	return module, nil
+}
+
+// Load the JIT module from a reader holding a TorchScript archive, e.g., an
+// object in blob storage, and map it onto a compute device.
+func LoadFromReader(reader io.Reader, device *torch.Device) (*JitModule, error) {
+	buffer, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFromBytes(buffer, device)
+}
+
+// Files stored in a TorchScript archive alongside the module, e.g., label
+// maps or preprocessing configuration, keyed by name. These are the
+// _extra_files of torch.jit.save and torch.jit.load.
+type ExtraFiles map[string][]byte
+
+// Read the extra files of the TorchScript archive at the given path.
+func ReadExtraFiles(path string) (ExtraFiles, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	return readExtraFiles(&archive.Reader)
+}
+
+// Read the extra files of a TorchScript archive in memory.
+func ReadExtraFilesFromBytes(buffer []byte) (ExtraFiles, error) {
+	archive, err := zip.NewReader(bytes.NewReader(buffer), int64(len(buffer)))
+	if err != nil {
+		return nil, err
+	}
+	return readExtraFiles(archive)
+}
+
+// Read the extra files of a TorchScript archive. The archive is a zip file in
+// which extra files are stored as <archive name>/extra/<file name>.
+func readExtraFiles(archive *zip.Reader) (ExtraFiles, error) {
+	files := make(ExtraFiles)
+	for _, file := range archive.File {
+		parts := strings.SplitN(file.Name, "/", 3)
+		if len(parts) != 3 || parts[1] != "extra" || parts[2] == "" {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[parts[2]] = contents
+	}
+	return files, nil
+}
+
 // Free a JIT module from memory.
 func (module *JitModule) free() {
 	if module.Pointer == nil {
@@ -67,21 +154,118 @@ func (module *JitModule) free() {
 	}
 	C.Torch_Jit_Module_Free(module.Pointer)
 	module.Pointer = nil
+	internal.UntrackObject(unsafe.Pointer(module))
+}
+
+// Attach the garbage collection finalizer to a newly created module and
+// record its allocation site for leak detection.
+func (module *JitModule) setFinalizer() {
+	runtime.SetFinalizer(module, (*JitModule).free)
+	internal.TrackObject("JitModule", unsafe.Pointer(module))
 }
 
 // Save the module to the given path.
 func (module *JitModule) Save(path string) error {
+	return module.SaveWithExtraFiles(path, nil)
+}
+
+// Extra files in the layout expected by the C API. The names and contents are
+// copied to C memory and must be released with free.
+type cExtraFiles struct {
+	names []*C.char
+	data []unsafe.Pointer
+	sizes []C.int64_t
+}
+
+// Copy extra files to C memory.
+func newCExtraFiles(files ExtraFiles) *cExtraFiles {
+	output := &cExtraFiles{}
+	for name, contents := range files {
+		output.names = append(output.names, C.CString(name))
+		output.data = append(output.data, C.CBytes(contents))
+		output.sizes = append(output.sizes, C.int64_t(len(contents)))
+	}
+	return output
+}
+
+// Release the C memory of the extra files.
+func (files *cExtraFiles) free() {
+	for index := range files.names {
+		C.free(unsafe.Pointer(files.names[index]))
+		C.free(files.data[index])
+	}
+}
+
+// Return pointers to the first name, contents, and size, or nil if there are
+// no extra files.
+func (files *cExtraFiles) pointers() (**C.char, *unsafe.Pointer, *C.int64_t) {
+	if len(files.names) == 0 { return nil, nil, nil }
+	return &files.names[0], &files.data[0], &files.sizes[0]
+}
+
+// Save the module to the given path along with extra files, which may be nil.
+func (module *JitModule) SaveWithExtraFiles(path string, files ExtraFiles) error {
 	// Wrap the GoString with a C string and defer the release of the memory.
 	path_cstring := C.CString(path)
 	defer C.free(unsafe.Pointer(path_cstring))
-	// Attempt to save the tensor to the given path and catch any errors.
-	err := unsafe.Pointer(C.Torch_Jit_Module_Save(path_cstring, module.Pointer))
+	extra := newCExtraFiles(files)
+	defer extra.free()
+	names, data, sizes := extra.pointers()
+	// Attempt to save the module to the given path and catch any errors.
+	err := unsafe.Pointer(C.Torch_Jit_Module_Save(
+		path_cstring,
+		module.Pointer,
+		names,
+		data,
+		sizes,
+		C.int64_t(len(extra.names)),
+	))
+	runtime.KeepAlive(module)
 	if err != nil {
 		return internal.NewTorchError(err)
 	}
 	return nil
 }
 
+// Save the module to a buffer in memory along with extra files, which may be
+// nil.
+func (module *JitModule) SaveToBytes(files ExtraFiles) ([]byte, error) {
+	extra := newCExtraFiles(files)
+	defer extra.free()
+	names, data, sizes := extra.pointers()
+	var buffer C.ByteBuffer
+	err := unsafe.Pointer(C.Torch_Jit_Module_SaveToBuffer(
+		&buffer,
+		module.Pointer,
+		names,
+		data,
+		sizes,
+		C.int64_t(len(extra.names)),
+	))
+	runtime.KeepAlive(module)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	defer C.ByteBuffer_Free(buffer)
+	// C.GoBytes takes a C.int length, which would truncate archives > 2 GiB.
+	size := int(C.ByteBuffer_Size(buffer))
+	output := make([]byte, size)
+	if size > 0 {
+		copy(output, unsafe.Slice((*byte)(C.ByteBuffer_Data(buffer)), size))
+	}
+	return output, nil
+}
+
+// Save the module to a writer along with extra files, which may be nil.
+func (module *JitModule) SaveToWriter(writer io.Writer, files ExtraFiles) error {
+	buffer, err := module.SaveToBytes(files)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(buffer)
+	return err
+}
+
 // Convert the module to a human-readable string representation.
 func (module *JitModule) String() string {
 	cstring := C.Torch_Jit_Module_String(module.Pointer)
@@ -134,6 +318,7 @@ func (module *JitModule) CastTo(dtype torch.Dtype) *JitModule {
 		module.Pointer,
 		C.int8_t(dtype),
 	)))
+	runtime.KeepAlive(module)
 	return module
 }
 
@@ -143,24 +328,61 @@ func (module *JitModule) CopyTo(device *torch.Device) *JitModule {
 		module.Pointer,
 		(C.Device)(device.Pointer),
 	)))
+	runtime.KeepAlive(module)
 	runtime.KeepAlive(device)
 	return module
 }
 
-// Cast the model's parameters to the given data-type in-place.
-func (module *JitModule) To(device *torch.Device, dtype torch.Dtype) *JitModule {
-	return module.CopyTo(device).CastTo(dtype)
-	// internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_To(
-	// 	module.Pointer,
-	// 	(C.Device)(device.Pointer),
-	// 	C.int8_t(dtype),
-	// )))
-	// return module
+// Copy the model's parameters to the given compute accelerator and cast them
+// to the given data-type in-place in a single pass. If nonBlocking is true,
+// copies are asynchronous with respect to the host when possible, e.g., from
+// pinned memory to a CUDA device.
+func (module *JitModule) To(device *torch.Device, dtype torch.Dtype, nonBlocking bool) *JitModule {
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_To(
+		module.Pointer,
+		(C.Device)(device.Pointer),
+		C.int8_t(dtype),
+		C.bool(nonBlocking),
+	)))
+	runtime.KeepAlive(module)
+	runtime.KeepAlive(device)
+	return module
+}
+
+// Create a shallow copy of the module. The copy has its own attribute slots,
+// so assigning an attribute of the copy with SetAttr does not affect the
+// module, but it shares its parameters, buffers, and submodules with the
+// module, so in-place conversions such as To affect both.
+func (module *JitModule) Copy() *JitModule {
+	output := &JitModule{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_Copy(&output.Pointer, module.Pointer)))
+	runtime.KeepAlive(module)
+	output.setFinalizer()
+	return output
+}
+
+// Create a deep copy of the module with its own parameters, buffers, and
+// submodules, e.g., a replica for an inference worker or a copy to convert to
+// a different data-type while keeping the original.
+func (module *JitModule) DeepCopy() *JitModule {
+	output := &JitModule{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_DeepCopy(&output.Pointer, module.Pointer)))
+	runtime.KeepAlive(module)
+	output.setFinalizer()
+	return output
 }
 
-// TODO: func (module *JitModule) Copy() { }
-// TODO: func (module *JitModule) DeepCopy() { }
-// TODO: func (module *JitModule) Clone(inplace bool) { }
+// Clone the module and its submodules with a type of their own, so methods
+// added to the clone with Define are not visible on the module. If inplace is
+// true, the clone shares its parameters and buffers with the module;
+// otherwise, they are copied as in DeepCopy.
+func (module *JitModule) Clone(inplace bool) *JitModule {
+	output := &JitModule{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_Clone(&output.Pointer, module.Pointer, C.bool(inplace))))
+	runtime.KeepAlive(module)
+	output.setFinalizer()
+	return output
+}
 
 // Forward pass IValues through the module and return the resulting IValue.
 func (module *JitModule) Forward(inputs []*torch.IValue) (output *torch.IValue) {
@@ -177,14 +399,25 @@ func (module *JitModule) Forward(inputs []*torch.IValue) (output *torch.IValue)
 		&ivalues[0],
 		C.int64_t(len(ivalues)),
 	)))
+	runtime.KeepAlive(module)
 	runtime.KeepAlive(inputs)
-	// We can't access the `free` method of the IValue, so redefine it here...
-	runtime.SetFinalizer(output, func(ivalue *torch.IValue) {
-		if ivalue.Pointer == nil {
-			panic("Attempting to free an ivalue that has already been freed!")
-		}
-		C.Torch_IValue_Free((C.IValue)(ivalue.Pointer))
-		ivalue.Pointer = nil
-	})
+	torch.SetIValueFinalizer(output)
 	return
 }
+
+// Forward pass IValues through the module on a reusable worker goroutine that
+// is locked to its own OS thread and return a future of the output (see
+// torch.RunAsync). The worker inherits the gradient mode of the caller.
+// Cancelling the context resolves the future with the context's error; a
+// forward pass that has already started runs to completion and its output is
+// discarded. Errors raised by the module resolve the future with an error.
+func (module *JitModule) ForwardAsync(ctx context.Context, inputs []*torch.IValue) *torch.Future {
+	gradEnabled := torch.IsGradEnabled()
+	return torch.RunAsync(ctx, func() (*torch.IValue, error) {
+		// Gradient mode is thread-local, so copy it to the worker thread.
+		torch.SetGradEnabled(gradEnabled)
+		output := module.Forward(inputs)
+		runtime.KeepAlive(module)
+		return output, nil
+	})
+}
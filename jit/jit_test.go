@@ -25,6 +25,8 @@
 package jit_test
 
 import (
+	"bytes"
+	"context"
 	"testing"
 	"github.com/stretchr/testify/assert"
 	"errors"
@@ -105,6 +107,70 @@ func TestJitModuleSaveDoesSaveModule(t *testing.T) {
 	assert.NotNil(t, deserialized_module.Pointer)
 }
 
+// MARK: LoadFromBytes/LoadFromReader
+
+func TestJitLoadFromBytes(t *testing.T) {
+	buffer, err := os.ReadFile("../data/trace_identity.pt")
+	if !assert.Nil(t, err) { return }
+	module, err := jit.LoadFromBytes(buffer, torch.NewDevice("cpu"))
+	assert.Nil(t, err)
+	assert.Contains(t, module.String(), "torch.nn.modules.linear.Identity")
+}
+
+func TestJitLoadFromBytesThrowsErrorOnInvalidBuffer(t *testing.T) {
+	module, err := jit.LoadFromBytes([]byte("not a module"), torch.NewDevice("cpu"))
+	assert.Nil(t, module)
+	assert.NotNil(t, err)
+}
+
+func TestJitLoadFromReader(t *testing.T) {
+	file, err := os.Open("../data/trace_identity.pt")
+	if !assert.Nil(t, err) { return }
+	defer file.Close()
+	module, err := jit.LoadFromReader(file, torch.NewDevice("cpu"))
+	assert.Nil(t, err)
+	assert.NotNil(t, module.Pointer)
+}
+
+// MARK: SaveToWriter/SaveWithExtraFiles/ReadExtraFiles
+
+func TestJitModuleSaveToWriterRoundTripsExtraFiles(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	files := jit.ExtraFiles{"labels.txt": []byte("cat\ndog\n"), "config.json": []byte("{}")}
+	var buffer bytes.Buffer
+	assert.Nil(t, module.SaveToWriter(&buffer, files))
+	loaded, err := jit.LoadFromBytes(buffer.Bytes(), torch.NewDevice("cpu"))
+	assert.Nil(t, err)
+	assert.NotNil(t, loaded.Pointer)
+	extra, err := jit.ReadExtraFilesFromBytes(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, files, extra)
+}
+
+func TestJitModuleSaveToBytesWithoutExtraFiles(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	buffer, err := module.SaveToBytes(nil)
+	assert.Nil(t, err)
+	extra, err := jit.ReadExtraFilesFromBytes(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, jit.ExtraFiles{}, extra)
+}
+
+func TestJitModuleSaveWithExtraFiles(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	path := filepath.Join(t.TempDir(), "identity.pt")
+	files := jit.ExtraFiles{"labels.txt": []byte("cat\n")}
+	assert.Nil(t, module.SaveWithExtraFiles(path, files))
+	extra, err := jit.ReadExtraFiles(path)
+	assert.Nil(t, err)
+	assert.Equal(t, files, extra)
+}
+
+func TestReadExtraFilesThrowsErrorOnInvalidPath(t *testing.T) {
+	_, err := jit.ReadExtraFiles("../data/nonexistent.pt")
+	assert.NotNil(t, err)
+}
+
 // MARK: String
 
 func TestJitModuleString(t *testing.T) {
@@ -209,7 +275,7 @@ func TestJitModuleCopyToReturnsSelf(t *testing.T) {
 func TestJitModuleTo(t *testing.T) {
 	module, err := jit.Load("../data/trace_linear.pt", torch.NewDevice("cpu"))
 	if !assert.Nil(t, err) { return }
-	module = module.To(torch.NewDevice("cpu"), torch.Double)
+	module = module.To(torch.NewDevice("cpu"), torch.Double, false)
 	tensor := torch.Rand([]int64{1, 1}, torch.NewTensorOptions()).CastTo(torch.Double)
 	ivalues := []*torch.IValue{torch.NewIValue(tensor)}
 	assert.NotPanics(t, func() { module.Forward(ivalues) })
@@ -218,9 +284,57 @@ func TestJitModuleTo(t *testing.T) {
 	assert.Equal(t, outputs.ToTensor().Dtype(), torch.Double)
 }
 
-// TODO: Torch_Jit_Module_Copy
-// TODO: Torch_Jit_Module_DeepCopy
-// TODO: Torch_Jit_Module_Clone
+func TestJitModuleToReturnsSelf(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	assert.Equal(t, module.Pointer, module.To(torch.NewDevice("cpu"), torch.Float, true).Pointer)
+}
+
+// MARK: Copy
+
+func TestJitModuleCopySharesParameters(t *testing.T) {
+	module, _ := jit.Load("../data/trace_linear.pt", torch.NewDevice("cpu"))
+	copied := module.Copy()
+	assert.NotEqual(t, module.Pointer, copied.Pointer)
+	copied.CastTo(torch.Double)
+	assert.Equal(t, torch.Double, module.NamedParameters()[0].Tensor.Dtype())
+}
+
+// MARK: DeepCopy
+
+func TestJitModuleDeepCopy(t *testing.T) {
+	module, _ := jit.Load("../data/trace_linear.pt", torch.NewDevice("cpu"))
+	copied := module.DeepCopy()
+	assert.NotEqual(t, module.Pointer, copied.Pointer)
+	// keep a float32 master and a float64 copy side by side
+	copied.To(torch.NewDevice("cpu"), torch.Double, false)
+	assert.Equal(t, torch.Float, module.NamedParameters()[0].Tensor.Dtype())
+	assert.Equal(t, torch.Double, copied.NamedParameters()[0].Tensor.Dtype())
+	tensor := torch.Rand([]int64{1, 1}, torch.NewTensorOptions())
+	expected := module.Forward([]*torch.IValue{torch.NewIValue(tensor)}).ToTensor()
+	output := copied.Forward([]*torch.IValue{torch.NewIValue(tensor.CastTo(torch.Double))}).ToTensor()
+	assert.True(t, expected.AllClose(output.CastTo(torch.Float), 1e-5, 1e-6))
+}
+
+// MARK: Clone
+
+func TestJitModuleClone(t *testing.T) {
+	module, _ := jit.Load("../data/script_sequential.pt", torch.NewDevice("cpu"))
+	clone := module.Clone(false)
+	clone.CastTo(torch.Double)
+	assert.Equal(t, torch.Float, module.NamedParameters()[0].Tensor.Dtype())
+	// methods defined on the clone are not visible on the module
+	assert.Nil(t, clone.Define("def double(self, x):\n    return 2 * x\n"))
+	assert.True(t, clone.HasMethod("double"))
+	assert.False(t, module.HasMethod("double"))
+}
+
+func TestJitModuleCloneInplaceSharesParameters(t *testing.T) {
+	module, _ := jit.Load("../data/script_sequential.pt", torch.NewDevice("cpu"))
+	clone := module.Clone(true)
+	assert.NotEqual(t, module.Pointer, clone.Pointer)
+	clone.CastTo(torch.Double)
+	assert.Equal(t, torch.Double, module.NamedParameters()[0].Tensor.Dtype())
+}
 
 // MARK: Forward
 
@@ -340,6 +454,161 @@ func TestJitModuleForwardScriptedModuleToTuple(t *testing.T) {
 	assert.Equal(t, 3, values[2].ToInt())
 }
 
+func TestJitModuleForwardScriptedModuleToIntList(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_int_list.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([][]float32{{1}})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	assert.True(t, output.IsIntList())
+	assert.Equal(t, []int{1 << 40, -3}, output.ToIntList())
+}
+
+func TestJitModuleForwardScriptedModuleToDoubleList(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_float_list.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([][]float32{{1}})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	assert.True(t, output.IsDoubleList())
+	assert.Equal(t, []float64{1.5, -2.5}, output.ToDoubleList())
+}
+
+func TestJitModuleForwardScriptedModuleToBoolList(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_bool_list.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([][]float32{{1}})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	assert.True(t, output.IsBoolList())
+	assert.Equal(t, []bool{true, false}, output.ToBoolList())
+}
+
+func TestJitModuleForwardScriptedModuleToComplexDoubleList(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_complex_list.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([][]float32{{1}})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	assert.True(t, output.IsComplexDoubleList())
+	assert.Equal(t, []complex128{complex(1, 2), complex(3, -4)}, output.ToComplexDoubleList())
+}
+
+func TestJitModuleForwardScriptedModuleToOptionalTensorList(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_optional_tensor_list.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([][]float32{{1}})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	assert.True(t, output.IsOptionalTensorList())
+	tensors := output.ToOptionalTensorList()
+	assert.Equal(t, 2, len(tensors))
+	assert.True(t, tensors[0].Equal(tensor))
+	assert.Nil(t, tensors[1])
+}
+
+func TestJitModuleForwardScriptedModuleToNested(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_nested.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)}).ToInterface()
+	dict, ok := output.(map[interface{}]interface{})
+	if !assert.True(t, ok) { return }
+	assert.Equal(t, []interface{}{}, dict["bar"])
+	foo := dict["foo"].([]interface{})
+	assert.Equal(t, 2, len(foo))
+	second := foo[1].([]interface{})
+	assert.Equal(t, 2, second[0])
+	assert.True(t, second[1].(*torch.Tensor).Equal(torch.NewTensor([]float32{2})))
+}
+
+func TestJitModuleForwardScriptedModuleToNamedTuple(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_named_tuple.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	assert.Equal(t, []string{"boxes", "label", "score"}, output.ToList()[0].TupleFieldNames())
+	var detections []struct {
+		Boxes *torch.Tensor `torch:"boxes"`
+		Label int `torch:"label"`
+		Score float32 `torch:"score"`
+	}
+	if !assert.Nil(t, torch.UnmarshalIValue(output, &detections)) { return }
+	assert.Equal(t, 2, len(detections))
+	assert.True(t, detections[1].Boxes.Equal(torch.NewTensor([]float32{2})))
+	assert.Equal(t, 2, detections[1].Label)
+	assert.Equal(t, float32(0.25), detections[1].Score)
+}
+
+func TestJitModuleForwardScriptedModuleToObject(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_object.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	if !assert.True(t, output.IsObject()) { return }
+	object := output.ToObject()
+	assert.Equal(t, "__torch__.Counter", object.TypeName())
+	assert.Equal(t, []string{"count", "name"}, object.AttrNames())
+	assert.True(t, object.HasAttr("count"))
+	assert.False(t, object.HasAttr("foo"))
+	assert.Equal(t, 3, object.Attr("count").ToInt())
+	assert.Equal(t, "counter", object.Attr("name").ToString())
+	assert.Contains(t, object.MethodNames(), "increment")
+	assert.True(t, object.HasMethod("increment"))
+}
+
+func TestJitModuleForwardScriptedModuleToObjectCallMethod(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_object.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	object := module.Forward([]*torch.IValue{torch.NewIValue(tensor)}).ToObject()
+	output, err := object.CallMethod("increment", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, output.ToInt())
+	assert.Equal(t, 5, object.Attr("count").ToInt())
+	_, err = object.CallMethod("increment", "foo")
+	assert.NotNil(t, err)
+	_, err = object.CallMethod("decrement", 2)
+	assert.NotNil(t, err)
+}
+
+func TestJitModuleForwardScriptedModuleToObjectSetAttr(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_object.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	object := module.Forward([]*torch.IValue{torch.NewIValue(tensor)}).ToObject()
+	object.SetAttr("count", 10)
+	assert.Equal(t, 10, object.Attr("count").ToInt())
+	assert.Panics(t, func() { object.SetAttr("count", "foo") })
+	assert.Panics(t, func() { object.Attr("foo") })
+}
+
+func TestJitModuleForwardScriptedModuleToObjectUnmarshal(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_object.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	var counter struct {
+		Count int `torch:"count"`
+		Name string `torch:"name"`
+	}
+	assert.Nil(t, torch.UnmarshalIValue(output, &counter))
+	assert.Equal(t, 3, counter.Count)
+	assert.Equal(t, "counter", counter.Name)
+	var object *torch.Object
+	assert.Nil(t, torch.UnmarshalIValue(output, &object))
+	assert.Equal(t, "__torch__.Counter", object.TypeName())
+}
+
+func TestJitModuleForwardScriptedModuleToEnum(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_enum.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	if !assert.True(t, output.IsEnum()) { return }
+	enum := output.ToEnum()
+	assert.Equal(t, "__torch__.Color", enum.TypeName)
+	assert.Equal(t, "GREEN", enum.Name)
+	assert.Equal(t, 2, enum.Value.ToInt())
+}
+
+func TestJitModuleForwardScriptedModuleFromGenericDict(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_sums_dict_of_int_lists.pt", torch.NewDevice("cpu"))
+	input := torch.NewIValue(map[string][]int{"foo": {1, 2}, "bar": {3}})
+	output := module.Forward([]*torch.IValue{input})
+	assert.Equal(t, 6, output.ToInt())
+}
+
+func TestJitModuleForwardScriptedModuleFromTuple(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_echoes_tuple.pt", torch.NewDevice("cpu"))
+	var tensor *torch.Tensor
+	output := module.Forward([]*torch.IValue{torch.NewTuple(int64(1), "foo", tensor)})
+	assert.Equal(t, []interface{}{1, "foo", nil}, output.ToInterface())
+}
+
 func TestJitModuleForwardScriptedModuleToNone(t *testing.T) {
 	module, _ := jit.Load("../data/module_that_returns_none.pt", torch.NewDevice("cpu"))
 	tensor := torch.NewTensor([][]float32{{1}})
@@ -394,3 +663,80 @@ func TestJitModuleForwardScriptedModuleToDictBoolKeys(t *testing.T) {
 	assert.True(t, value.IsString())
 	assert.Equal(t, "foo", value.ToString())
 }
+
+// MARK: ForwardAsync
+
+func TestJitModuleForwardAsync(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([][]float32{{1}})
+	future := module.ForwardAsync(context.Background(), []*torch.IValue{torch.NewIValue(tensor)})
+	output, err := future.Wait()
+	if !assert.Nil(t, err) { return }
+	assert.True(t, future.IsCompleted())
+	assert.True(t, torch.Equal(output.ToTensor(), tensor))
+}
+
+func TestJitModuleForwardAsyncSelect(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([][]float32{{1}})
+	futures := []*torch.Future{
+		module.ForwardAsync(context.Background(), []*torch.IValue{torch.NewIValue(tensor)}),
+		module.ForwardAsync(context.Background(), []*torch.IValue{torch.NewIValue(tensor)}),
+	}
+	for _, future := range futures {
+		<-future.Done()
+		output, err := future.Wait()
+		assert.Nil(t, err)
+		assert.True(t, torch.Equal(output.ToTensor(), tensor))
+	}
+}
+
+func TestJitModuleForwardAsyncReturnsErrors(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	output, err := module.ForwardAsync(context.Background(), []*torch.IValue{torch.NewIValue("foo")}).Wait()
+	assert.Nil(t, output)
+	assert.NotNil(t, err)
+}
+
+func TestJitModuleForwardAsyncCancelled(t *testing.T) {
+	module, _ := jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([][]float32{{1}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	output, err := module.ForwardAsync(ctx, []*torch.IValue{torch.NewIValue(tensor)}).Wait()
+	assert.Nil(t, output)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestJitModuleForwardAsyncInheritsGradMode(t *testing.T) {
+	module, _ := jit.Load("../data/trace_linear.pt", torch.NewDevice("cpu"))
+	tensor := torch.Rand([]int64{1, 1}, torch.NewTensorOptions())
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	torch.SetGradEnabled(false)
+	defer torch.SetGradEnabled(true)
+	output, err := module.ForwardAsync(context.Background(), []*torch.IValue{torch.NewIValue(tensor)}).Wait()
+	if !assert.Nil(t, err) { return }
+	assert.False(t, output.ToTensor().RequiresGrad())
+}
+
+func TestJitModuleForwardScriptedModuleToFuture(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_future.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1, 2})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	if !assert.True(t, output.IsFuture()) { return }
+	value, err := output.ToFuture().Wait()
+	if !assert.Nil(t, err) { return }
+	assert.True(t, torch.Equal(value.ToTensor(), torch.NewTensor([]float32{-1, -2})))
+}
+
+func TestJitModuleForwardScriptedModuleToFutureWithError(t *testing.T) {
+	module, _ := jit.Load("../data/module_that_returns_failed_future.pt", torch.NewDevice("cpu"))
+	tensor := torch.NewTensor([]float32{1, 2})
+	output := module.Forward([]*torch.IValue{torch.NewIValue(tensor)})
+	value, err := output.ToFuture().Wait()
+	assert.Nil(t, value)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "foo")
+	}
+}
@@ -0,0 +1,175 @@
+// Calls to arbitrary methods of TorchScript modules.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package jit
+
+// #include <stdlib.h>
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// A method of a TorchScript module, e.g., "forward", "encode", or
+// "init_state". Methods share their state with the module that owns them.
+type Method struct {
+	module *JitModule
+	schema *Schema
+}
+
+// Return the method of the module with the given name, or an error if the
+// module does not have the method.
+func (module *JitModule) Method(name string) (*Method, error) {
+	if !module.HasMethod(name) {
+		return nil, fmt.Errorf("Module %s does not have a method named %q", module.TypeName(), name)
+	}
+	schema, err := module.MethodSchema(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Method{module, schema}, nil
+}
+
+// Return true if the module has a method with the given name.
+func (module *JitModule) HasMethod(name string) bool {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_Module_HasMethod(&output, module.Pointer, name_cstring)))
+	runtime.KeepAlive(module)
+	return bool(output)
+}
+
+// Return the name of the method.
+func (method *Method) Name() string {
+	return method.schema.Name
+}
+
+// Return the schema of the method, i.e., the names, types, and default values
+// of its arguments and the types of its return values.
+func (method *Method) Schema() *Schema {
+	return method.schema
+}
+
+// Inputs to a method in the layout expected by the C API.
+type methodInputs struct {
+	inputs []C.IValue
+	kwargNames []*C.char
+	kwargValues []C.IValue
+}
+
+// Convert positional and keyword inputs to C arrays. The caller must call
+// free to release the names of the keyword inputs.
+func newMethodInputs(inputs []*torch.IValue, kwargs map[string]*torch.IValue) *methodInputs {
+	output := &methodInputs{}
+	for _, ivalue := range inputs {
+		output.inputs = append(output.inputs, (C.IValue)(ivalue.Pointer))
+	}
+	for name, ivalue := range kwargs {
+		output.kwargNames = append(output.kwargNames, C.CString(name))
+		output.kwargValues = append(output.kwargValues, (C.IValue)(ivalue.Pointer))
+	}
+	return output
+}
+
+// Release the C strings of the names of the keyword inputs.
+func (inputs *methodInputs) free() {
+	for _, name := range inputs.kwargNames {
+		C.free(unsafe.Pointer(name))
+	}
+}
+
+// Return a pointer to the first positional input, or nil if there are none.
+func (inputs *methodInputs) inputsPointer() *C.IValue {
+	if len(inputs.inputs) == 0 { return nil }
+	return &inputs.inputs[0]
+}
+
+// Return pointers to the first keyword name and value, or nil if there are
+// none.
+func (inputs *methodInputs) kwargsPointers() (**C.char, *C.IValue) {
+	if len(inputs.kwargNames) == 0 { return nil, nil }
+	return &inputs.kwargNames[0], &inputs.kwargValues[0]
+}
+
+// Check positional and keyword inputs against the schema of the method
+// without running it. The error describes missing, unexpected, or mistyped
+// arguments.
+func (method *Method) Validate(inputs []*torch.IValue, kwargs map[string]*torch.IValue) error {
+	name_cstring := C.CString(method.schema.Name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	arrays := newMethodInputs(inputs, kwargs)
+	defer arrays.free()
+	names, values := arrays.kwargsPointers()
+	err := unsafe.Pointer(C.Torch_Jit_Module_CheckMethodInputs(
+		method.module.Pointer,
+		name_cstring,
+		arrays.inputsPointer(),
+		C.int64_t(len(arrays.inputs)),
+		names,
+		values,
+		C.int64_t(len(arrays.kwargNames)),
+	))
+	runtime.KeepAlive(method.module)
+	runtime.KeepAlive(inputs)
+	runtime.KeepAlive(kwargs)
+	if err != nil {
+		return internal.NewTorchError(err)
+	}
+	return nil
+}
+
+// Call the method with positional inputs (excluding self) and keyword inputs
+// and return its output. kwargs may be nil. Arguments that are omitted take
+// their default values. Call returns an error if the inputs do not match the
+// schema of the method or if the method raises an exception.
+func (method *Method) Call(inputs []*torch.IValue, kwargs map[string]*torch.IValue) (*torch.IValue, error) {
+	name_cstring := C.CString(method.schema.Name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	arrays := newMethodInputs(inputs, kwargs)
+	defer arrays.free()
+	names, values := arrays.kwargsPointers()
+	output := &torch.IValue{}
+	err := unsafe.Pointer(C.Torch_Jit_Module_RunMethod(
+		(*C.IValue)(&output.Pointer),
+		method.module.Pointer,
+		name_cstring,
+		arrays.inputsPointer(),
+		C.int64_t(len(arrays.inputs)),
+		names,
+		values,
+		C.int64_t(len(arrays.kwargNames)),
+	))
+	runtime.KeepAlive(method.module)
+	runtime.KeepAlive(inputs)
+	runtime.KeepAlive(kwargs)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	torch.SetIValueFinalizer(output)
+	return output, nil
+}
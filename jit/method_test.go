@@ -0,0 +1,127 @@
+// test cases for method.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+
+package jit_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/jit"
+)
+
+func loadMethods(t *testing.T) *jit.JitModule {
+	module, err := jit.Load("../data/script_methods.pt", torch.NewDevice("cpu"))
+	assert.Nil(t, err)
+	return module
+}
+
+// MARK: HasMethod
+
+func TestJitModuleHasMethod(t *testing.T) {
+	module := loadMethods(t)
+	assert.True(t, module.HasMethod("forward"))
+	assert.True(t, module.HasMethod("encode"))
+	assert.True(t, module.HasMethod("init_state"))
+	assert.False(t, module.HasMethod("decode"))
+}
+
+func TestJitModuleMethodNamesIncludesExportedMethods(t *testing.T) {
+	names := loadMethods(t).MethodNames()
+	assert.Contains(t, names, "forward")
+	assert.Contains(t, names, "encode")
+	assert.Contains(t, names, "init_state")
+}
+
+// MARK: Method
+
+func TestJitModuleMethodReturnsErrorForMissingMethod(t *testing.T) {
+	method, err := loadMethods(t).Method("decode")
+	assert.Nil(t, method)
+	assert.NotNil(t, err)
+}
+
+func TestMethodSchema(t *testing.T) {
+	method, err := loadMethods(t).Method("encode")
+	if !assert.Nil(t, err) { return }
+	assert.Equal(t, "encode", method.Name())
+	schema := method.Schema()
+	if !assert.Equal(t, 3, len(schema.Arguments)) { return }
+	assert.Equal(t, "x", schema.Arguments[1].Name)
+	assert.Equal(t, "offset", schema.Arguments[2].Name)
+	assert.Equal(t, "int", schema.Arguments[2].Type)
+	assert.Equal(t, 1, schema.Arguments[2].Default.ToInt())
+	assert.Equal(t, "encode(Tensor x, int offset=1) -> Tensor", schema.String())
+}
+
+// MARK: Call
+
+func TestMethodCall(t *testing.T) {
+	method, _ := loadMethods(t).Method("encode")
+	x := torch.NewTensor([]float32{1, 2})
+	output, err := method.Call([]*torch.IValue{torch.NewIValue(x)}, nil)
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(torch.NewTensor([]float32{3, 5}), output.ToTensor()))
+}
+
+func TestMethodCallWithKwargs(t *testing.T) {
+	method, _ := loadMethods(t).Method("encode")
+	x := torch.NewTensor([]float32{1, 2})
+	output, err := method.Call([]*torch.IValue{torch.NewIValue(x)}, map[string]*torch.IValue{"offset": torch.NewIValue(0)})
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(torch.NewTensor([]float32{2, 4}), output.ToTensor()))
+	output, err = method.Call(nil, map[string]*torch.IValue{"x": torch.NewIValue(x)})
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(torch.NewTensor([]float32{3, 5}), output.ToTensor()))
+}
+
+func TestMethodCallWithoutInputs(t *testing.T) {
+	method, _ := loadMethods(t).Method("init_state")
+	output, err := method.Call(nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{2}, output.ToTensor().Shape())
+}
+
+func TestMethodCallReturnsErrorOnInvalidInputs(t *testing.T) {
+	method, _ := loadMethods(t).Method("encode")
+	output, err := method.Call(nil, nil)
+	assert.Nil(t, output)
+	assert.NotNil(t, err)
+}
+
+// MARK: Validate
+
+func TestMethodValidate(t *testing.T) {
+	method, _ := loadMethods(t).Method("encode")
+	x := torch.NewIValue(torch.NewTensor([]float32{1, 2}))
+	assert.Nil(t, method.Validate([]*torch.IValue{x}, nil))
+	assert.Nil(t, method.Validate([]*torch.IValue{x}, map[string]*torch.IValue{"offset": torch.NewIValue(2)}))
+	// missing argument
+	assert.NotNil(t, method.Validate(nil, nil))
+	// mistyped argument
+	assert.NotNil(t, method.Validate([]*torch.IValue{torch.NewIValue(1)}, nil))
+	// unexpected keyword argument
+	assert.NotNil(t, method.Validate([]*torch.IValue{x}, map[string]*torch.IValue{"scale": torch.NewIValue(2)}))
+	// too many arguments
+	assert.NotNil(t, method.Validate([]*torch.IValue{x, torch.NewIValue(1), torch.NewIValue(2)}, nil))
+}
@@ -0,0 +1,165 @@
+// Optimization of TorchScript modules for inference.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package jit
+
+// #include <stdlib.h>
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"runtime"
+	"unsafe"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// Convert Go strings to C strings. The caller must free the C strings with
+// freeCStrings.
+func newCStrings(strings []string) []*C.char {
+	output := make([]*C.char, len(strings))
+	for index, str := range strings {
+		output[index] = C.CString(str)
+	}
+	return output
+}
+
+// Free C strings created by newCStrings.
+func freeCStrings(strings []*C.char) {
+	for _, str := range strings {
+		C.free(unsafe.Pointer(str))
+	}
+}
+
+// Return a pointer to the first C string, or nil if there are none.
+func cStringsPointer(strings []*C.char) **C.char {
+	if len(strings) == 0 { return nil }
+	return &strings[0]
+}
+
+// Freeze a module by inlining its parameters, attributes, and submodules
+// into its graphs as constants, which enables optimizations such as constant
+// propagation. The module must be in evaluation mode. Only forward and the
+// attributes and methods named in preservedAttrs, which may be nil, remain
+// accessible on the frozen module. The input module is not modified.
+func Freeze(module *JitModule, preservedAttrs []string) (*JitModule, error) {
+	attrs := newCStrings(preservedAttrs)
+	defer freeCStrings(attrs)
+	output := &JitModule{}
+	err := unsafe.Pointer(C.Torch_Jit_Freeze(
+		&output.Pointer,
+		module.Pointer,
+		cStringsPointer(attrs),
+		C.int64_t(len(attrs)),
+	))
+	runtime.KeepAlive(module)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	output.setFinalizer()
+	return output, nil
+}
+
+// Freeze a module, if it is not already frozen, and apply graph optimizations
+// for inference, e.g., folding convolutions with batch normalization layers
+// and pre-packing weights for the CPU. Methods besides forward that should be
+// optimized are named in otherMethods, which may be nil. The input module is
+// not modified.
+func OptimizeForInference(module *JitModule, otherMethods []string) (*JitModule, error) {
+	methods := newCStrings(otherMethods)
+	defer freeCStrings(methods)
+	output := &JitModule{}
+	err := unsafe.Pointer(C.Torch_Jit_OptimizeForInference(
+		&output.Pointer,
+		module.Pointer,
+		cStringsPointer(methods),
+		C.int64_t(len(methods)),
+	))
+	runtime.KeepAlive(module)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	output.setFinalizer()
+	return output, nil
+}
+
+// Fold batch normalization layers into the weights and biases of preceding
+// convolutions without freezing the module. The module must be in evaluation
+// mode. The input module is not modified.
+func FoldConvBatchNorm(module *JitModule) (*JitModule, error) {
+	output := &JitModule{}
+	err := unsafe.Pointer(C.Torch_Jit_FoldConvBatchNorm(&output.Pointer, module.Pointer))
+	runtime.KeepAlive(module)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	output.setFinalizer()
+	return output, nil
+}
+
+// The kind of shapes that the fuser specializes graphs for.
+type FusionBehavior int32
+const (
+	// Specialize fused kernels to the exact input shapes.
+	FusionStatic FusionBehavior = iota
+	// Compile fused kernels that support varying input shapes.
+	FusionDynamic
+)
+
+// A stage of a fusion strategy: the number of times that graphs are
+// specialized with the given behavior before moving to the next stage.
+type FusionStage struct {
+	Behavior FusionBehavior
+	Depth int64
+}
+
+// Set the fusion strategy of the profiling executor, e.g.,
+// {{FusionStatic, 2}, {FusionDynamic, 10}} (the default) to specialize twice
+// to static shapes and then up to ten times to dynamic shapes before falling
+// back to an unfused graph. The strategy applies to all modules.
+func SetFusionStrategy(strategy []FusionStage) {
+	behaviors := make([]C.int32_t, len(strategy) + 1)
+	depths := make([]C.int64_t, len(strategy) + 1)
+	for index, stage := range strategy {
+		behaviors[index] = C.int32_t(stage.Behavior)
+		depths[index] = C.int64_t(stage.Depth)
+	}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Jit_SetFusionStrategy(
+		&behaviors[0],
+		&depths[0],
+		C.int64_t(len(strategy)),
+	)))
+}
+
+// Enable/disable profiling of tensor shapes by the graph executor and return
+// the previous mode. Disabling profiling avoids the warm-up runs that the
+// executor uses to specialize graphs, at the cost of fusion opportunities.
+// The mode applies to all modules.
+func SetProfilingMode(enabled bool) bool {
+	return bool(C.Torch_Jit_SetProfilingMode(C.bool(enabled)))
+}
+
+// Enable/disable the profiling graph executor, falling back to the legacy
+// executor when disabled, and return the previous mode. The mode applies to
+// all modules.
+func SetProfilingExecutor(enabled bool) bool {
+	return bool(C.Torch_Jit_SetProfilingExecutor(C.bool(enabled)))
+}
@@ -0,0 +1,122 @@
+// test cases for optimize.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+
+package jit_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/jit"
+)
+
+func loadConvBatchNorm(t *testing.T) *jit.JitModule {
+	module, err := jit.Load("../data/script_conv_bn.pt", torch.NewDevice("cpu"))
+	assert.Nil(t, err)
+	return module
+}
+
+func forwardTensor(module *jit.JitModule, tensor *torch.Tensor) *torch.Tensor {
+	return module.Forward([]*torch.IValue{torch.NewIValue(tensor)}).ToTensor()
+}
+
+// MARK: Freeze
+
+func TestFreeze(t *testing.T) {
+	module, _ := jit.Load("../data/script_sequential.pt", torch.NewDevice("cpu"))
+	frozen, err := jit.Freeze(module.Eval(), nil)
+	if !assert.Nil(t, err) { return }
+	assert.NotEqual(t, module.Pointer, frozen.Pointer)
+	// parameters are inlined into the graph as constants
+	assert.Equal(t, 0, len(frozen.NamedParameters()))
+	assert.NotEqual(t, 0, len(module.NamedParameters()))
+	input := torch.Rand([]int64{4, 2}, torch.NewTensorOptions())
+	assert.True(t, forwardTensor(module, input).AllClose(forwardTensor(frozen, input), 1e-5, 1e-6))
+}
+
+func TestFreezePreservesAttributes(t *testing.T) {
+	module, _ := jit.Load("../data/script_methods.pt", torch.NewDevice("cpu"))
+	frozen, err := jit.Freeze(module.Eval(), []string{"encode"})
+	if !assert.Nil(t, err) { return }
+	assert.True(t, frozen.HasMethod("encode"))
+}
+
+func TestFreezeReturnsErrorInTrainingMode(t *testing.T) {
+	module, _ := jit.Load("../data/script_sequential.pt", torch.NewDevice("cpu"))
+	frozen, err := jit.Freeze(module.Train(true), nil)
+	assert.Nil(t, frozen)
+	assert.NotNil(t, err)
+}
+
+// MARK: OptimizeForInference
+
+func TestOptimizeForInference(t *testing.T) {
+	module := loadConvBatchNorm(t)
+	optimized, err := jit.OptimizeForInference(module.Eval(), nil)
+	if !assert.Nil(t, err) { return }
+	input := torch.Rand([]int64{1, 1, 5, 5}, torch.NewTensorOptions())
+	assert.True(t, forwardTensor(module, input).AllClose(forwardTensor(optimized, input), 1e-4, 1e-5))
+}
+
+// MARK: FoldConvBatchNorm
+
+func TestFoldConvBatchNorm(t *testing.T) {
+	module := loadConvBatchNorm(t)
+	folded, err := jit.FoldConvBatchNorm(module.Eval())
+	if !assert.Nil(t, err) { return }
+	graph, err := folded.Graph("forward")
+	if !assert.Nil(t, err) { return }
+	assert.NotContains(t, graph, "batch_norm")
+	input := torch.Rand([]int64{1, 1, 5, 5}, torch.NewTensorOptions())
+	assert.True(t, forwardTensor(module, input).AllClose(forwardTensor(folded, input), 1e-4, 1e-5))
+}
+
+// MARK: SetProfilingMode/SetProfilingExecutor
+
+func TestSetProfilingMode(t *testing.T) {
+	previous := jit.SetProfilingMode(false)
+	defer jit.SetProfilingMode(previous)
+	assert.False(t, jit.SetProfilingMode(true))
+	assert.True(t, jit.SetProfilingMode(false))
+}
+
+func TestSetProfilingExecutor(t *testing.T) {
+	previous := jit.SetProfilingExecutor(false)
+	defer jit.SetProfilingExecutor(previous)
+	assert.False(t, jit.SetProfilingExecutor(true))
+	assert.True(t, jit.SetProfilingExecutor(false))
+}
+
+// MARK: SetFusionStrategy
+
+func TestSetFusionStrategy(t *testing.T) {
+	assert.NotPanics(t, func() {
+		jit.SetFusionStrategy([]jit.FusionStage{{jit.FusionDynamic, 4}})
+	})
+	jit.SetFusionStrategy([]jit.FusionStage{{jit.FusionStatic, 2}, {jit.FusionDynamic, 10}})
+}
+
+func TestSetFusionStrategyEmpty(t *testing.T) {
+	defer jit.SetFusionStrategy([]jit.FusionStage{{jit.FusionStatic, 2}, {jit.FusionDynamic, 10}})
+	assert.NotPanics(t, func() { jit.SetFusionStrategy(nil) })
+}
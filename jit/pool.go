@@ -0,0 +1,189 @@
+// A pool of goroutines for concurrent inference with TorchScript modules.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package jit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"github.com/Kautenja/gotorch"
+)
+
+// The error returned by Pool.Forward after the pool has been closed.
+var ErrPoolClosed = errors.New("jit: pool is closed")
+
+// Options for creating a Pool.
+type PoolOptions struct {
+	// The number of worker goroutines, each locked to its own OS thread.
+	// Defaults to 1.
+	Workers int
+	// The number of intra-op threads used by libtorch. Values less than 1
+	// leave the current setting in place. The setting is process-global, so
+	// creating the pool changes it for every other pool and caller as well.
+	NumThreads int32
+	// The number of requests that may wait for a free worker before Forward
+	// blocks. Defaults to Workers.
+	QueueSize int
+}
+
+// A request for a worker to forward inputs through its module.
+type poolRequest struct {
+	inputs []*torch.IValue
+	response chan poolResponse
+}
+
+// The result of a poolRequest.
+type poolResponse struct {
+	output *torch.IValue
+	err error
+}
+
+// A Pool runs forward passes of a TorchScript module on a fixed set of worker
+// goroutines, each locked to its own OS thread. Pinning workers to threads
+// bounds the number of threads and the thread-local storage that libtorch
+// creates, which would otherwise grow as the Go scheduler moves goroutines
+// that call Forward between threads (see the comments of init in torch.go).
+// Pool.Forward is safe for concurrent use, e.g., directly from HTTP handlers.
+type Pool struct {
+	requests chan poolRequest
+	done chan struct{}
+	closeOnce sync.Once
+	workers sync.WaitGroup
+}
+
+// Create a new Pool whose workers share a single module. The module must not
+// be modified while the pool is in use.
+func NewPool(module *JitModule, options PoolOptions) *Pool {
+	pool, _ := newPool(func(worker int) (*JitModule, error) { return module, nil }, options)
+	return pool
+}
+
+// Create a new Pool whose workers each hold their own replica of a module.
+// load is called once by each worker on its own OS thread. If any call to
+// load fails, the pool is closed and the first error is returned. To make
+// replicas of a module that is already loaded, return module.DeepCopy() from
+// load.
+func NewReplicaPool(load func() (*JitModule, error), options PoolOptions) (*Pool, error) {
+	return newPool(func(worker int) (*JitModule, error) { return load() }, options)
+}
+
+// Create a new Pool and wait for its workers to load their modules.
+func newPool(load func(worker int) (*JitModule, error), options PoolOptions) (*Pool, error) {
+	if options.Workers == 0 {
+		options.Workers = 1
+	}
+	if options.Workers < 0 { panic("Workers should be greater than 0") }
+	if options.QueueSize == 0 {
+		options.QueueSize = options.Workers
+	}
+	if options.QueueSize < 0 { panic("QueueSize should be greater than or equal to 0") }
+	if options.NumThreads > 0 {
+		torch.SetNumThreads(options.NumThreads)
+	}
+	pool := &Pool{
+		requests: make(chan poolRequest, options.QueueSize),
+		done: make(chan struct{}),
+	}
+	ready := make(chan error, options.Workers)
+	for worker := 0; worker < options.Workers; worker++ {
+		pool.workers.Add(1)
+		go pool.work(worker, load, ready)
+	}
+	var err error
+	for worker := 0; worker < options.Workers; worker++ {
+		if workerErr := <-ready; workerErr != nil && err == nil {
+			err = workerErr
+		}
+	}
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// Run a worker that serves requests until the pool is closed.
+func (pool *Pool) work(worker int, load func(int) (*JitModule, error), ready chan<- error) {
+	defer pool.workers.Done()
+	// The thread is never unlocked, so the Go runtime terminates it along
+	// with its libtorch thread-local storage when the worker exits.
+	runtime.LockOSThread()
+	// Gradient mode is thread-local, so disable it on every worker thread.
+	torch.SetGradEnabled(false)
+	module, err := load(worker)
+	ready <- err
+	if err != nil {
+		return
+	}
+	for {
+		select {
+		case <-pool.done:
+			return
+		case request := <-pool.requests:
+			request.response <- forward(module, request.inputs)
+		}
+	}
+}
+
+// Forward inputs through a module and convert panics to errors.
+func forward(module *JitModule, inputs []*torch.IValue) (response poolResponse) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			response.err = fmt.Errorf("%v", recovered)
+		}
+	}()
+	response.output = module.Forward(inputs)
+	return
+}
+
+// Forward inputs through the module on a free worker and return the output.
+// Forward blocks while the request queue is full and returns the context's
+// error if it is cancelled before a worker produces an output.
+func (pool *Pool) Forward(ctx context.Context, inputs []*torch.IValue) (*torch.IValue, error) {
+	request := poolRequest{inputs, make(chan poolResponse, 1)}
+	select {
+	case <-pool.done:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case pool.requests <- request:
+	}
+	select {
+	case <-pool.done:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case response := <-request.response:
+		return response.output, response.err
+	}
+}
+
+// Stop the workers after their current requests and wait for them to exit.
+// Requests that have not started fail with ErrPoolClosed.
+func (pool *Pool) Close() {
+	pool.closeOnce.Do(func() { close(pool.done) })
+	pool.workers.Wait()
+}
@@ -0,0 +1,115 @@
+// test cases for pool.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package jit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/jit"
+)
+
+func loadIdentity() (*jit.JitModule, error) {
+	return jit.Load("../data/trace_identity.pt", torch.NewDevice("cpu"))
+}
+
+func TestNewPoolPanicsOnNegativeWorkers(t *testing.T) {
+	module, _ := loadIdentity()
+	assert.PanicsWithValue(t, "Workers should be greater than 0", func() {
+		jit.NewPool(module, jit.PoolOptions{Workers: -1})
+	})
+}
+
+func TestNewReplicaPoolReturnsLoadError(t *testing.T) {
+	expected := errors.New("failed to load")
+	pool, err := jit.NewReplicaPool(func() (*jit.JitModule, error) {
+		return nil, expected
+	}, jit.PoolOptions{Workers: 2})
+	assert.Nil(t, pool)
+	assert.Equal(t, expected, err)
+}
+
+func TestPoolForwardSharedModule(t *testing.T) {
+	module, _ := loadIdentity()
+	pool := jit.NewPool(module, jit.PoolOptions{Workers: 2, NumThreads: 1})
+	defer pool.Close()
+	tensor := torch.NewTensor([][]float32{{1}})
+	output, err := pool.Forward(context.Background(), []*torch.IValue{torch.NewIValue(tensor)})
+	assert.Nil(t, err)
+	assert.True(t, torch.Equal(output.ToTensor(), tensor))
+}
+
+func TestPoolForwardConcurrentlyWithReplicas(t *testing.T) {
+	pool, err := jit.NewReplicaPool(loadIdentity, jit.PoolOptions{Workers: 4, QueueSize: 1})
+	assert.Nil(t, err)
+	defer pool.Close()
+	var group sync.WaitGroup
+	for index := 0; index < 32; index++ {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			tensor := torch.NewTensor([]float32{float32(index)})
+			output, err := pool.Forward(context.Background(), []*torch.IValue{torch.NewIValue(tensor)})
+			assert.Nil(t, err)
+			assert.True(t, torch.Equal(output.ToTensor(), tensor))
+		}(index)
+	}
+	group.Wait()
+}
+
+func TestPoolForwardReturnsErrorOnInvalidInputs(t *testing.T) {
+	module, _ := loadIdentity()
+	pool := jit.NewPool(module, jit.PoolOptions{})
+	defer pool.Close()
+	output, err := pool.Forward(context.Background(), []*torch.IValue{})
+	assert.Nil(t, output)
+	assert.NotNil(t, err)
+}
+
+func TestPoolForwardReturnsContextError(t *testing.T) {
+	module, _ := loadIdentity()
+	pool := jit.NewPool(module, jit.PoolOptions{})
+	defer pool.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tensor := torch.NewTensor([][]float32{{1}})
+	// A cancelled context may race with a free worker, so only check errors.
+	if _, err := pool.Forward(ctx, []*torch.IValue{torch.NewIValue(tensor)}); err != nil {
+		assert.Equal(t, context.Canceled, err)
+	}
+}
+
+func TestPoolForwardReturnsErrorAfterClose(t *testing.T) {
+	module, _ := loadIdentity()
+	pool := jit.NewPool(module, jit.PoolOptions{})
+	pool.Close()
+	pool.Close()
+	tensor := torch.NewTensor([][]float32{{1}})
+	output, err := pool.Forward(context.Background(), []*torch.IValue{torch.NewIValue(tensor)})
+	assert.Nil(t, output)
+	assert.Equal(t, jit.ErrPoolClosed, err)
+}
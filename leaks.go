@@ -0,0 +1,111 @@
+// Leak detection for native objects.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// A group of live native objects of the same kind (Tensor, IValue,
+// TensorOptions, Device, JitModule, or CompilationUnit) allocated from the
+// same call stack.
+type LiveObjectSite struct {
+	// The kind of the objects, e.g., "Tensor".
+	Kind string
+	// The number of live objects allocated from the site.
+	Count int
+	// The formatted call stack of the allocation site.
+	Stack string
+}
+
+// Enable or disable recording the allocation site of every native object.
+// Leak detection is disabled by default and can also be enabled with the
+// gotorch_debug build tag or the GOTORCH_DEBUG_LEAKS environment variable.
+// Recording call stacks is expensive, so it should not be enabled in
+// production.
+func SetLeakDetection(enabled bool) {
+	internal.SetLeakDetection(enabled)
+}
+
+// Return true if the allocation sites of native objects are being recorded.
+func LeakDetectionEnabled() bool {
+	return internal.LeakDetectionEnabled()
+}
+
+// Return the native objects that have not been freed grouped by allocation
+// site, in descending order of count. Only objects allocated while leak
+// detection was enabled are reported.
+func LiveObjects() []LiveObjectSite {
+	return liveObjectsSince(0)
+}
+
+// Return the live native objects allocated after the given sequence number.
+func liveObjectsSince(sequence uint64) []LiveObjectSite {
+	sites := internal.LiveAllocationSites(sequence)
+	output := make([]LiveObjectSite, len(sites))
+	for index, site := range sites {
+		output[index] = LiveObjectSite{site.Kind, site.Count, site.Stack}
+	}
+	return output
+}
+
+// The subset of testing.TB used by AssertNoLeaks.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Fail the test if body allocates native objects that are still live after it
+// returns and the garbage collector has had a chance to finalize anything
+// unreachable. Leak detection is enabled for the duration of the call. Other
+// goroutines that allocate native objects concurrently will be reported as
+// leaks, so tests that use AssertNoLeaks should not run in parallel.
+func AssertNoLeaks(t TestingT, body func()) {
+	t.Helper()
+	if !LeakDetectionEnabled() {
+		SetLeakDetection(true)
+		defer SetLeakDetection(false)
+	}
+	start := internal.AllocationSequence()
+	body()
+	// Finalizers run on a separate goroutine after a collection, so collect
+	// repeatedly and give them time to run before reporting what remains.
+	var sites []LiveObjectSite
+	for attempt := 0; attempt < 10; attempt++ {
+		runtime.GC()
+		if sites = liveObjectsSince(start); len(sites) == 0 {
+			return
+		}
+		time.Sleep(time.Duration(attempt + 1) * time.Millisecond)
+	}
+	var report strings.Builder
+	for _, site := range sites {
+		fmt.Fprintf(&report, "\n%d %s(s) allocated at:\n%s", site.Count, site.Kind, site.Stack)
+	}
+	t.Errorf("Found native objects that were not freed:%s", report.String())
+}
@@ -0,0 +1,95 @@
+// test cases for leaks.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch_test
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+)
+
+// A TestingT that records failures instead of failing the test.
+type recordingT struct {
+	failures []string
+}
+
+func (t *recordingT) Helper() { }
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+// Return the live objects of the given kind allocated by this file's tests.
+func liveObjectsFromTests(kind string) (count int) {
+	for _, site := range torch.LiveObjects() {
+		if site.Kind == kind && strings.Contains(site.Stack, "leaks_test.go") {
+			count += site.Count
+		}
+	}
+	return
+}
+
+func TestLiveObjectsReportsAllocationSites(t *testing.T) {
+	torch.SetLeakDetection(true)
+	defer torch.SetLeakDetection(false)
+	tensor := torch.Ones([]int64{2}, torch.NewTensorOptions())
+	device := torch.NewDevice("cpu")
+	assert.Equal(t, 1, liveObjectsFromTests("Tensor"))
+	assert.Equal(t, 1, liveObjectsFromTests("Device"))
+	tensor.Free()
+	assert.Equal(t, 0, liveObjectsFromTests("Tensor"))
+	runtime.KeepAlive(device)
+}
+
+func TestLiveObjectsIgnoresObjectsAllocatedWhileDisabled(t *testing.T) {
+	torch.SetLeakDetection(false)
+	tensor := torch.Ones([]int64{2}, torch.NewTensorOptions())
+	assert.Equal(t, 0, liveObjectsFromTests("Tensor"))
+	tensor.Free()
+}
+
+func TestAssertNoLeaksPassesWhenObjectsAreReleased(t *testing.T) {
+	recorder := &recordingT{}
+	torch.AssertNoLeaks(recorder, func() {
+		tensor := torch.Ones([]int64{2}, torch.NewTensorOptions())
+		tensor.Add(tensor, 1).Free()
+	})
+	assert.Empty(t, recorder.failures)
+	assert.False(t, torch.LeakDetectionEnabled())
+}
+
+func TestAssertNoLeaksFailsWhenObjectsRemainLive(t *testing.T) {
+	recorder := &recordingT{}
+	var leaked *torch.Tensor
+	torch.AssertNoLeaks(recorder, func() {
+		leaked = torch.Zeros([]int64{2}, torch.NewTensorOptions())
+	})
+	assert.Len(t, recorder.failures, 1)
+	assert.Contains(t, recorder.failures[0], "1 Tensor(s) allocated at:")
+	assert.Contains(t, recorder.failures[0], "TestAssertNoLeaksFailsWhenObjectsRemainLive")
+	leaked.Free()
+}
@@ -0,0 +1,486 @@
+// Marshalling between Go data and c10::IValue.
+//
+// Copyright (c) 2023 Christian Kauten
+// Copyright (c) 2022 Sensory, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package torch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Embed NamedTuple in a struct to marshal the struct as a TorchScript
+// NamedTuple instead of a Dict[str, T]. The tag of the embedded field is the
+// qualified name of the tuple type, e.g.,
+//
+//	type Detection struct {
+//		torch.NamedTuple `torch:"Detection"`
+//		Boxes *torch.Tensor `torch:"boxes"`
+//		Labels *torch.Tensor `torch:"labels"`
+//		Scores *torch.Tensor `torch:"scores"`
+//	}
+type NamedTuple struct { }
+
+// An error describing an IValue that cannot be unmarshalled into a Go value.
+type UnmarshalTypeError struct {
+	// The TorchScript type of the IValue, e.g., "List[int]".
+	Type string
+	// The type of the Go value that could not be assigned to.
+	GoType reflect.Type
+	// The path to the struct field, map value, or slice element, e.g.,
+	// "[1].boxes", or an empty string for the top-level value.
+	Path string
+}
+
+func (err *UnmarshalTypeError) Error() string {
+	if err.Path == "" {
+		return fmt.Sprintf("torch: cannot unmarshal %s into Go value of type %s", err.Type, err.GoType)
+	}
+	return fmt.Sprintf("torch: cannot unmarshal %s into Go value of type %s at %s", err.Type, err.GoType, err.Path)
+}
+
+var (
+	ivalueType = reflect.TypeOf((*IValue)(nil))
+	tensorType = reflect.TypeOf((*Tensor)(nil))
+	deviceType = reflect.TypeOf((*Device)(nil))
+	objectType = reflect.TypeOf((*Object)(nil))
+	namedTupleType = reflect.TypeOf(NamedTuple{})
+)
+
+// A field of a struct that is marshalled to or from an IValue.
+type structField struct {
+	// The name of the field in the IValue.
+	name string
+	// The index of the field in the struct.
+	index int
+}
+
+// The layout of a struct that is marshalled to or from an IValue.
+type structLayout struct {
+	fields []structField
+	// Whether the struct embeds NamedTuple.
+	namedTuple bool
+	// The qualified name of the NamedTuple type, which may be empty.
+	tupleName string
+}
+
+// Return the layout of a struct type. Fields are named by their torch tag,
+// or by the name of the Go field if the tag is empty. Unexported fields and
+// fields tagged with "-" are skipped.
+func newStructLayout(structType reflect.Type) structLayout {
+	layout := structLayout{}
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		tag := field.Tag.Get("torch")
+		if field.Type == namedTupleType {
+			layout.namedTuple = true
+			layout.tupleName = tag
+			continue
+		}
+		if field.PkgPath != "" || tag == "-" { continue }
+		name := tag
+		if name == "" { name = field.Name }
+		layout.fields = append(layout.fields, structField{name, index})
+	}
+	return layout
+}
+
+// Find the field with the given name, preferring an exact match to a
+// case-insensitive match, or return nil if there is no such field.
+func (layout structLayout) field(name string) *structField {
+	var folded *structField
+	for index := range layout.fields {
+		field := &layout.fields[index]
+		if field.name == name { return field }
+		if folded == nil && strings.EqualFold(field.name, name) { folded = field }
+	}
+	return folded
+}
+
+// ---------------------------------------------------------------------------
+// MARK: Marshal
+// ---------------------------------------------------------------------------
+
+// Convert Go data to an IValue. MarshalIValue supports the types of
+// NewIValue and maps structs to dictionaries or NamedTuples:
+//
+//	struct        Dict[str, T] keyed by the torch tags of the fields
+//	NamedTuple    a NamedTuple with the fields in order (see NamedTuple)
+//	slice, array  a list of the marshalled elements
+//	map           a dictionary of the marshalled keys and values
+//	*T            None if nil, otherwise the marshalled value
+//	*Object       the IValue that holds the object
+//
+// Fields are named by their torch tag, e.g., `torch:"boxes"`, or by the name
+// of the Go field if the tag is empty. Unexported fields and fields tagged
+// with `torch:"-"` are skipped. The values of a struct that is marshalled to
+// a dictionary must share a type; embed NamedTuple in structs with fields of
+// different types.
+func MarshalIValue(v interface{}) (*IValue, error) {
+	return marshalValue(reflect.ValueOf(v))
+}
+
+// Convert a reflected Go value to an IValue.
+func marshalValue(value reflect.Value) (*IValue, error) {
+	if !value.IsValid() { return None(), nil }
+	switch value.Type() {
+	case ivalueType, tensorType, deviceType:
+		return NewIValue(value.Interface()), nil
+	case objectType:
+		if value.IsNil() { return None(), nil }
+		return value.Interface().(*Object).IValue(), nil
+	}
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() { return None(), nil }
+		return marshalValue(value.Elem())
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return NewIValue(value.Interface()), nil
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("torch: cannot marshal %d, which overflows the int64 of a TorchScript int", value.Uint())
+		}
+		return NewIValue(int64(value.Uint())), nil
+	case reflect.Slice, reflect.Array:
+		// Use the typed lists of NewIValue when possible, e.g., List[int]
+		// for []int, so that empty lists have the expected type.
+		switch data := value.Interface().(type) {
+		case []bool, []int, []int32, []int64, []float32, []float64, []complex64, []complex128, []*Tensor:
+			return NewIValue(data), nil
+		}
+		elements := make([]*IValue, value.Len())
+		for index := range elements {
+			element, err := marshalValue(value.Index(index))
+			if err != nil { return nil, err }
+			elements[index] = element
+		}
+		return newList(elements)
+	case reflect.Map:
+		keys := make([]*IValue, 0, value.Len())
+		values := make([]*IValue, 0, value.Len())
+		for _, mapKey := range sortedMapKeys(value) {
+			key, err := marshalValue(mapKey)
+			if err != nil { return nil, err }
+			element, err := marshalValue(value.MapIndex(mapKey))
+			if err != nil { return nil, err }
+			keys = append(keys, key)
+			values = append(values, element)
+		}
+		return newGenericDict(keys, values)
+	case reflect.Struct:
+		return marshalStruct(value)
+	}
+	return nil, fmt.Errorf("torch: cannot marshal Go value of type %s", value.Type())
+}
+
+// Convert a reflected Go struct to a dictionary or a NamedTuple.
+func marshalStruct(value reflect.Value) (*IValue, error) {
+	layout := newStructLayout(value.Type())
+	names := make([]string, len(layout.fields))
+	keys := make([]*IValue, len(layout.fields))
+	values := make([]*IValue, len(layout.fields))
+	for index, field := range layout.fields {
+		element, err := marshalValue(value.Field(field.index))
+		if err != nil { return nil, err }
+		names[index] = field.name
+		keys[index] = NewIValue(field.name)
+		values[index] = element
+	}
+	if layout.namedTuple {
+		return newNamedTuple(layout.tupleName, names, values)
+	}
+	ivalue, err := newGenericDict(keys, values)
+	if err != nil {
+		return nil, fmt.Errorf("torch: cannot marshal %s as a dictionary, embed torch.NamedTuple to marshal it as a NamedTuple: %w", value.Type(), err)
+	}
+	return ivalue, nil
+}
+
+// ---------------------------------------------------------------------------
+// MARK: Unmarshal
+// ---------------------------------------------------------------------------
+
+// Convert an IValue to Go data and store the result in the value pointed to
+// by v. UnmarshalIValue is the inverse of MarshalIValue:
+//
+//	None                        nil pointers, slices, maps, and interfaces
+//	bool, int, float, complex   the matching Go kinds (int converts to float)
+//	str                         string
+//	Tensor                      *Tensor
+//	List, Tuple                 slices and arrays of the unmarshalled elements
+//	Dict                        maps of the unmarshalled keys and values
+//	Dict[str, T]                structs, matching keys to the torch tags of
+//	                            the fields (case-insensitive if not exact)
+//	NamedTuple                  structs, matching field names
+//	Tuple                       structs, assigning the elements in order
+//	Object                      *Object, or structs, matching attributes
+//	anything                    *IValue, which receives the IValue itself,
+//	                            or interface{}, which receives ToInterface()
+//
+// Pointers are allocated as necessary. Dictionary keys without a matching
+// field are ignored and fields without a matching key are left unchanged.
+// UnmarshalIValue returns an *UnmarshalTypeError if the IValue cannot be
+// stored in the Go value.
+func UnmarshalIValue(ivalue *IValue, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("torch: UnmarshalIValue requires a non-nil pointer")
+	}
+	return unmarshalValue(ivalue, value.Elem(), "")
+}
+
+// Store an IValue in a reflected Go value. path describes the location of
+// the value for error messages.
+func unmarshalValue(ivalue *IValue, value reflect.Value, path string) error {
+	typeError := func() error {
+		return &UnmarshalTypeError{ivalue.TypeName(), value.Type(), path}
+	}
+	switch value.Type() {
+	case ivalueType:
+		value.Set(reflect.ValueOf(ivalue))
+		return nil
+	case tensorType:
+		if ivalue.IsNil() {
+			value.Set(reflect.Zero(tensorType))
+		} else if ivalue.IsTensor() {
+			value.Set(reflect.ValueOf(ivalue.ToTensor()))
+		} else {
+			return typeError()
+		}
+		return nil
+	case deviceType:
+		if ivalue.IsNil() {
+			value.Set(reflect.Zero(deviceType))
+		} else if ivalue.IsDevice() {
+			value.Set(reflect.ValueOf(ivalue.ToDevice()))
+		} else {
+			return typeError()
+		}
+		return nil
+	case objectType:
+		if ivalue.IsNil() {
+			value.Set(reflect.Zero(objectType))
+		} else if ivalue.IsObject() {
+			value.Set(reflect.ValueOf(ivalue.ToObject()))
+		} else {
+			return typeError()
+		}
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Ptr:
+		if ivalue.IsNil() {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return unmarshalValue(ivalue, value.Elem(), path)
+	case reflect.Interface:
+		if value.NumMethod() != 0 { return typeError() }
+		if data := ivalue.ToInterface(); data != nil {
+			value.Set(reflect.ValueOf(data))
+		} else {
+			value.Set(reflect.Zero(value.Type()))
+		}
+		return nil
+	case reflect.Bool:
+		if !ivalue.IsBool() { return typeError() }
+		value.SetBool(ivalue.ToBool())
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !ivalue.IsInt() { return typeError() }
+		data := int64(ivalue.ToInt())
+		if value.OverflowInt(data) { return typeError() }
+		value.SetInt(data)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !ivalue.IsInt() { return typeError() }
+		data := ivalue.ToInt()
+		if data < 0 || value.OverflowUint(uint64(data)) { return typeError() }
+		value.SetUint(uint64(data))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if ivalue.IsDouble() {
+			value.SetFloat(ivalue.ToDouble())
+		} else if ivalue.IsInt() {
+			value.SetFloat(float64(ivalue.ToInt()))
+		} else {
+			return typeError()
+		}
+		return nil
+	case reflect.Complex64, reflect.Complex128:
+		if ivalue.IsComplexDouble() {
+			value.SetComplex(ivalue.ToComplexDouble())
+		} else if ivalue.IsDouble() {
+			value.SetComplex(complex(ivalue.ToDouble(), 0))
+		} else if ivalue.IsInt() {
+			value.SetComplex(complex(float64(ivalue.ToInt()), 0))
+		} else {
+			return typeError()
+		}
+		return nil
+	case reflect.String:
+		if !ivalue.IsString() { return typeError() }
+		value.SetString(ivalue.ToString())
+		return nil
+	case reflect.Slice:
+		if ivalue.IsNil() {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		// Copy typed lists in a single call when possible.
+		switch {
+		case value.Type() == reflect.TypeOf([]bool{}) && ivalue.IsBoolList():
+			value.Set(reflect.ValueOf(ivalue.ToBoolList()))
+			return nil
+		case value.Type() == reflect.TypeOf([]int{}) && ivalue.IsIntList():
+			value.Set(reflect.ValueOf(ivalue.ToIntList()))
+			return nil
+		case value.Type() == reflect.TypeOf([]float64{}) && ivalue.IsDoubleList():
+			value.Set(reflect.ValueOf(ivalue.ToDoubleList()))
+			return nil
+		case value.Type() == reflect.TypeOf([]*Tensor{}) && ivalue.IsTensorList():
+			value.Set(reflect.ValueOf(ivalue.ToTensorList()))
+			return nil
+		}
+		elements, ok := sequence(ivalue)
+		if !ok { return typeError() }
+		slice := reflect.MakeSlice(value.Type(), len(elements), len(elements))
+		for index, element := range elements {
+			if err := unmarshalValue(element, slice.Index(index), fmt.Sprintf("%s[%d]", path, index)); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	case reflect.Array:
+		elements, ok := sequence(ivalue)
+		if !ok || len(elements) != value.Len() { return typeError() }
+		for index, element := range elements {
+			if err := unmarshalValue(element, value.Index(index), fmt.Sprintf("%s[%d]", path, index)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if ivalue.IsNil() {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		if !ivalue.IsGenericDict() { return typeError() }
+		output := reflect.MakeMapWithSize(value.Type(), int(ivalue.LengthDict()))
+		keyType := value.Type().Key()
+		for key, element := range ivalue.ToGenericDict() {
+			keyValue := reflect.ValueOf(key)
+			if !convertibleKey(keyValue.Type(), keyType) { return typeError() }
+			elementValue := reflect.New(value.Type().Elem()).Elem()
+			if err := unmarshalValue(element, elementValue, fmt.Sprintf("%s[%v]", path, key)); err != nil {
+				return err
+			}
+			output.SetMapIndex(keyValue.Convert(keyType), elementValue)
+		}
+		value.Set(output)
+		return nil
+	case reflect.Struct:
+		return unmarshalStruct(ivalue, value, path, typeError)
+	}
+	return typeError()
+}
+
+// Store a dictionary, tuple, or object in a reflected Go struct.
+func unmarshalStruct(ivalue *IValue, value reflect.Value, path string, typeError func() error) error {
+	layout := newStructLayout(value.Type())
+	fieldPath := func(field structField) string {
+		if path == "" { return field.name }
+		return path + "." + field.name
+	}
+	switch {
+	case ivalue.IsGenericDict():
+		for key, element := range ivalue.ToGenericDict() {
+			name, ok := key.(string)
+			if !ok { return typeError() }
+			field := layout.field(name)
+			if field == nil { continue }
+			if err := unmarshalValue(element, value.Field(field.index), fieldPath(*field)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ivalue.IsTuple():
+		elements := ivalue.ToTuple()
+		names := ivalue.TupleFieldNames()
+		if names == nil {
+			// Assign the elements of unnamed tuples in order.
+			if len(elements) != len(layout.fields) { return typeError() }
+			for index, field := range layout.fields {
+				if err := unmarshalValue(elements[index], value.Field(field.index), fieldPath(field)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for index, name := range names {
+			field := layout.field(name)
+			if field == nil { continue }
+			if err := unmarshalValue(elements[index], value.Field(field.index), fieldPath(*field)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ivalue.IsObject() && !ivalue.IsCustomClass() && !ivalue.IsModule():
+		object := ivalue.ToObject()
+		for _, name := range object.AttrNames() {
+			field := layout.field(name)
+			if field == nil { continue }
+			if err := unmarshalValue(object.Attr(name), value.Field(field.index), fieldPath(*field)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return typeError()
+}
+
+// Return true if a dictionary key of the given type can be converted to the
+// key type of a map. Unlike reflect.Type.ConvertibleTo, integers are not
+// convertible to strings.
+func convertibleKey(from, to reflect.Type) bool {
+	if from.Kind() == reflect.String || to.Kind() == reflect.String {
+		return from.Kind() == to.Kind()
+	}
+	return from.ConvertibleTo(to)
+}
+
+// Return the elements of a list or tuple.
+func sequence(ivalue *IValue) ([]*IValue, bool) {
+	if ivalue.IsList() { return ivalue.ToList(), true }
+	if ivalue.IsTuple() { return ivalue.ToTuple(), true }
+	return nil, false
+}
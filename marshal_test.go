@@ -0,0 +1,252 @@
+// test cases for marshal.go
+//
+// Copyright (c) 2023 Christian Kauten
+// Copyright (c) 2022 Sensory, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package torch_test
+
+import (
+	"math"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+)
+
+type detection struct {
+	Boxes *torch.Tensor `torch:"boxes"`
+	Labels *torch.Tensor `torch:"labels"`
+	Scores *torch.Tensor `torch:"scores"`
+	ignored int
+	Skipped *torch.Tensor `torch:"-"`
+}
+
+type point struct {
+	torch.NamedTuple `torch:"Point"`
+	X float64 `torch:"x"`
+	Y float64 `torch:"y"`
+	Label string `torch:"label"`
+}
+
+// MARK: MarshalIValue
+
+func TestMarshalIValueScalars(t *testing.T) {
+	ivalue, err := torch.MarshalIValue(7)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, ivalue.ToInt())
+	ivalue, err = torch.MarshalIValue("foo")
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", ivalue.ToString())
+}
+
+func TestMarshalIValueUint(t *testing.T) {
+	ivalue, err := torch.MarshalIValue(uint64(7))
+	assert.Nil(t, err)
+	assert.Equal(t, 7, ivalue.ToInt())
+	_, err = torch.MarshalIValue(uint64(math.MaxUint64))
+	assert.NotNil(t, err)
+}
+
+func TestMarshalIValueNilPointer(t *testing.T) {
+	var value *int
+	ivalue, err := torch.MarshalIValue(value)
+	assert.Nil(t, err)
+	assert.True(t, ivalue.IsNil())
+}
+
+func TestMarshalIValueTensorsWithNil(t *testing.T) {
+	ivalue, err := torch.MarshalIValue([]*torch.Tensor{torch.NewTensor([]float32{1}), nil})
+	assert.Nil(t, err)
+	assert.True(t, ivalue.IsOptionalTensorList())
+}
+
+func TestMarshalIValueStructToDict(t *testing.T) {
+	boxes := torch.NewTensor([][]float32{{1, 2, 3, 4}})
+	ivalue, err := torch.MarshalIValue(detection{Boxes: boxes, Labels: torch.NewTensor([]int64{1}), Scores: torch.NewTensor([]float32{0.5})})
+	if !assert.Nil(t, err) { return }
+	assert.True(t, ivalue.IsGenericDict())
+	assert.Equal(t, "Dict[str, Tensor]", ivalue.TypeName())
+	dict := ivalue.ToGenericDict()
+	assert.Equal(t, 3, len(dict))
+	assert.True(t, dict["boxes"].ToTensor().Equal(boxes))
+}
+
+func TestMarshalIValueStructWithMixedTypesReturnsError(t *testing.T) {
+	type mixed struct {
+		Count int
+		Name string
+	}
+	ivalue, err := torch.MarshalIValue(mixed{1, "foo"})
+	assert.Nil(t, ivalue)
+	assert.NotNil(t, err)
+}
+
+func TestMarshalIValueNamedTuple(t *testing.T) {
+	ivalue, err := torch.MarshalIValue(point{X: 1, Y: 2, Label: "foo"})
+	if !assert.Nil(t, err) { return }
+	assert.True(t, ivalue.IsTuple())
+	assert.Equal(t, []string{"x", "y", "label"}, ivalue.TupleFieldNames())
+	assert.Equal(t, []interface{}{1.0, 2.0, "foo"}, ivalue.ToInterface())
+}
+
+func TestMarshalIValueSliceOfStructs(t *testing.T) {
+	ivalue, err := torch.MarshalIValue([]point{{X: 1}, {X: 2}})
+	if !assert.Nil(t, err) { return }
+	assert.True(t, ivalue.IsList())
+	assert.Equal(t, int64(2), ivalue.LengthList())
+}
+
+func TestMarshalIValueMap(t *testing.T) {
+	ivalue, err := torch.MarshalIValue(map[string][]int{"foo": {1, 2}})
+	if !assert.Nil(t, err) { return }
+	assert.Equal(t, "Dict[str, List[int]]", ivalue.TypeName())
+}
+
+func TestMarshalIValueUnsupportedType(t *testing.T) {
+	ivalue, err := torch.MarshalIValue(make(chan int))
+	assert.Nil(t, ivalue)
+	assert.NotNil(t, err)
+}
+
+// MARK: UnmarshalIValue
+
+func TestUnmarshalIValueRequiresPointer(t *testing.T) {
+	var value int
+	assert.NotNil(t, torch.UnmarshalIValue(torch.NewIValue(1), value))
+	assert.NotNil(t, torch.UnmarshalIValue(torch.NewIValue(1), nil))
+}
+
+func TestUnmarshalIValueScalars(t *testing.T) {
+	var integer int32
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue(7), &integer))
+	assert.Equal(t, int32(7), integer)
+	var float float32
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue(7), &float))
+	assert.Equal(t, float32(7), float)
+	var str string
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue("foo"), &str))
+	assert.Equal(t, "foo", str)
+}
+
+func TestUnmarshalIValueTypeError(t *testing.T) {
+	var value string
+	err := torch.UnmarshalIValue(torch.NewIValue(7), &value)
+	if !assert.NotNil(t, err) { return }
+	typeError, ok := err.(*torch.UnmarshalTypeError)
+	if !assert.True(t, ok) { return }
+	assert.Equal(t, "int", typeError.Type)
+	assert.Equal(t, "torch: cannot unmarshal int into Go value of type string", err.Error())
+}
+
+func TestUnmarshalIValueOverflow(t *testing.T) {
+	var value int8
+	assert.NotNil(t, torch.UnmarshalIValue(torch.NewIValue(1000), &value))
+	var unsigned uint
+	assert.NotNil(t, torch.UnmarshalIValue(torch.NewIValue(-1), &unsigned))
+}
+
+func TestUnmarshalIValueDictToStruct(t *testing.T) {
+	boxes := torch.NewTensor([][]float32{{1, 2, 3, 4}})
+	ivalue := torch.NewIValue(map[string]*torch.Tensor{"boxes": boxes, "extra": boxes})
+	var output detection
+	assert.Nil(t, torch.UnmarshalIValue(ivalue, &output))
+	assert.True(t, output.Boxes.Equal(boxes))
+	assert.Nil(t, output.Labels)
+	assert.Nil(t, output.Skipped)
+}
+
+func TestUnmarshalIValueDictMatchesFieldNamesCaseInsensitively(t *testing.T) {
+	var output struct { Count int }
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue(map[string]int{"count": 3}), &output))
+	assert.Equal(t, 3, output.Count)
+}
+
+func TestUnmarshalIValueTupleToStruct(t *testing.T) {
+	var output struct {
+		Losses map[string]*torch.Tensor
+		Detections []detection
+	}
+	boxes := torch.NewTensor([][]float32{{1, 2, 3, 4}})
+	detections := torch.NewIValue([]*torch.IValue{torch.NewIValue(map[string]*torch.Tensor{"boxes": boxes})})
+	ivalue := torch.NewTuple(map[string]*torch.Tensor{}, detections)
+	if !assert.Nil(t, torch.UnmarshalIValue(ivalue, &output)) { return }
+	assert.Equal(t, 0, len(output.Losses))
+	assert.Equal(t, 1, len(output.Detections))
+	assert.True(t, output.Detections[0].Boxes.Equal(boxes))
+}
+
+func TestUnmarshalIValueTupleToStructWithWrongLength(t *testing.T) {
+	var output struct { A, B int }
+	assert.NotNil(t, torch.UnmarshalIValue(torch.NewTuple(1), &output))
+}
+
+func TestUnmarshalIValueNamedTupleRoundTrip(t *testing.T) {
+	ivalue, _ := torch.MarshalIValue(point{X: 1, Y: 2, Label: "foo"})
+	var output point
+	assert.Nil(t, torch.UnmarshalIValue(ivalue, &output))
+	assert.Equal(t, point{X: 1, Y: 2, Label: "foo"}, output)
+}
+
+func TestUnmarshalIValueErrorPath(t *testing.T) {
+	ivalue := torch.NewIValue([]*torch.IValue{torch.NewIValue(map[string]int{"boxes": 1})})
+	var output []detection
+	err := torch.UnmarshalIValue(ivalue, &output)
+	if !assert.NotNil(t, err) { return }
+	assert.Equal(t, "[0].boxes", err.(*torch.UnmarshalTypeError).Path)
+}
+
+func TestUnmarshalIValueSlicesAndArrays(t *testing.T) {
+	var slice []int
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue([]int{1, 2}), &slice))
+	assert.Equal(t, []int{1, 2}, slice)
+	var array [2]float32
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewTuple(1.5, 2), &array))
+	assert.Equal(t, [2]float32{1.5, 2}, array)
+	var nested [][]string
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue([][]string{{"a"}, {"b", "c"}}), &nested))
+	assert.Equal(t, [][]string{{"a"}, {"b", "c"}}, nested)
+}
+
+func TestUnmarshalIValueMap(t *testing.T) {
+	var output map[int]string
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue(map[int]string{1: "foo"}), &output))
+	assert.Equal(t, map[int]string{1: "foo"}, output)
+	// integer keys do not convert to strings
+	var strings map[string]string
+	assert.NotNil(t, torch.UnmarshalIValue(torch.NewIValue(map[int]string{1: "foo"}), &strings))
+}
+
+func TestUnmarshalIValueOptional(t *testing.T) {
+	value := new(int)
+	assert.Nil(t, torch.UnmarshalIValue(torch.None(), &value))
+	assert.Nil(t, value)
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue(3), &value))
+	assert.Equal(t, 3, *value)
+}
+
+func TestUnmarshalIValueInterfaceAndIValue(t *testing.T) {
+	var data interface{}
+	assert.Nil(t, torch.UnmarshalIValue(torch.NewIValue([]int{1}), &data))
+	assert.Equal(t, []int{1}, data)
+	ivalue := torch.NewIValue(1)
+	var output *torch.IValue
+	assert.Nil(t, torch.UnmarshalIValue(ivalue, &output))
+	assert.Equal(t, ivalue, output)
+}
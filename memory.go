@@ -0,0 +1,123 @@
+// Go bindings for tracking libtorch CPU memory.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch
+
+// #cgo CPPFLAGS: -I/usr/local/include -I/usr/local/include/cgotorch
+// #cgo LDFLAGS: -L/usr/local/lib -lc10 -ltorch_cpu -ltorch -lcgotorch
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"unsafe"
+	"runtime"
+	"sync/atomic"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// Statistics about the native memory held by libtorch. The Go garbage
+// collector only sees the small Go structs that wrap libtorch objects, so
+// these statistics are the only view of the memory that those structs pin.
+type MemoryStatistics struct {
+	// The number of bytes currently allocated on the CPU by libtorch.
+	LiveBytes int64
+	// The maximum number of bytes allocated on the CPU at once.
+	PeakBytes int64
+	// The number of CPU allocations currently live. Views and tensors that
+	// share storage share a single allocation.
+	LiveAllocations int64
+	// The number of Tensor handles that have not been freed.
+	LiveTensors int64
+}
+
+var (
+	// The number of Tensor handles that have not been freed.
+	liveTensors int64
+	// The live bytes above which a garbage collection is triggered, or 0 to
+	// disable triggering garbage collection.
+	gcThreshold int64
+	// The live bytes above which the next garbage collection is triggered.
+	gcTrigger int64
+)
+
+func init() {
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Memory_InstallTrackingAllocator()))
+}
+
+// Return statistics about the CPU memory held by libtorch.
+func MemoryStats() MemoryStatistics {
+	var stats MemoryStatistics
+	C.Torch_Memory_Stats(
+		(*C.int64_t)(unsafe.Pointer(&stats.LiveBytes)),
+		(*C.int64_t)(unsafe.Pointer(&stats.PeakBytes)),
+		(*C.int64_t)(unsafe.Pointer(&stats.LiveAllocations)),
+	)
+	stats.LiveTensors = atomic.LoadInt64(&liveTensors)
+	return stats
+}
+
+// Reset the peak bytes statistic to the number of bytes currently live.
+func ResetPeakMemoryStats() {
+	C.Torch_Memory_ResetPeakStats()
+}
+
+// Set the number of live CPU bytes held by libtorch above which creating a
+// tensor triggers a garbage collection so that finalizers can release tensors
+// that are no longer reachable. To avoid collecting continuously while memory
+// is legitimately in use, the next collection is not triggered until live
+// bytes double, or fall back below the threshold and cross it again. A
+// threshold of 0 disables triggering garbage collection, which is the default.
+func SetGCThreshold(bytes int64) {
+	if bytes < 0 { panic("bytes should be greater than or equal to 0") }
+	atomic.StoreInt64(&gcThreshold, bytes)
+	atomic.StoreInt64(&gcTrigger, bytes)
+}
+
+// Return the number of live CPU bytes above which garbage collection is
+// triggered, or 0 if it is disabled.
+func GCThreshold() int64 {
+	return atomic.LoadInt64(&gcThreshold)
+}
+
+// Trigger a garbage collection in the background if the live bytes held by
+// libtorch have crossed the threshold set by SetGCThreshold.
+func maybeCollectGarbage() {
+	threshold := atomic.LoadInt64(&gcThreshold)
+	if threshold == 0 {
+		return
+	}
+	live := int64(C.Torch_Memory_LiveBytes())
+	trigger := atomic.LoadInt64(&gcTrigger)
+	if live < threshold {
+		// Memory was released, so re-arm the trigger at the threshold.
+		if trigger != threshold {
+			atomic.CompareAndSwapInt64(&gcTrigger, trigger, threshold)
+		}
+		return
+	}
+	if live < trigger || !atomic.CompareAndSwapInt64(&gcTrigger, trigger, 2 * live) {
+		return
+	}
+	go runtime.GC()
+}
@@ -0,0 +1,93 @@
+// test cases for memory.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch_test
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+)
+
+func TestMemoryStatsTracksLiveBytes(t *testing.T) {
+	before := torch.MemoryStats()
+	tensor := torch.Zeros([]int64{1024, 1024}, torch.NewTensorOptions().Dtype(torch.Float))
+	during := torch.MemoryStats()
+	assert.GreaterOrEqual(t, during.LiveBytes - before.LiveBytes, int64(4 * 1024 * 1024))
+	assert.GreaterOrEqual(t, during.PeakBytes, during.LiveBytes)
+	assert.Greater(t, during.LiveAllocations, before.LiveAllocations)
+	tensor.Free()
+	after := torch.MemoryStats()
+	assert.Less(t, after.LiveBytes, during.LiveBytes)
+	assert.GreaterOrEqual(t, after.PeakBytes, during.LiveBytes)
+	torch.ResetPeakMemoryStats()
+	assert.Equal(t, torch.MemoryStats().LiveBytes, torch.MemoryStats().PeakBytes)
+}
+
+// Disable automatic garbage collection for the rest of a test and return the
+// number of live tensors once the finalizers of unreachable tensors from
+// earlier tests have stopped freeing them.
+func settleLiveTensors(t *testing.T) int64 {
+	percent := debug.SetGCPercent(-1)
+	t.Cleanup(func() { debug.SetGCPercent(percent) })
+	live := int64(-1)
+	for live != torch.MemoryStats().LiveTensors {
+		live = torch.MemoryStats().LiveTensors
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	return live
+}
+
+func TestMemoryStatsTracksLiveTensors(t *testing.T) {
+	before := settleLiveTensors(t)
+	tensor := torch.Ones([]int64{2}, torch.NewTensorOptions())
+	view := tensor.View(2, 1)
+	assert.Equal(t, before + 2, torch.MemoryStats().LiveTensors)
+	tensor.Free()
+	view.Free()
+	assert.Equal(t, before, torch.MemoryStats().LiveTensors)
+}
+
+func TestSetGCThreshold(t *testing.T) {
+	assert.PanicsWithValue(t, "bytes should be greater than or equal to 0", func() {
+		torch.SetGCThreshold(-1)
+	})
+	torch.SetGCThreshold(1024)
+	defer torch.SetGCThreshold(0)
+	assert.Equal(t, int64(1024), torch.GCThreshold())
+	// Crossing the threshold triggers a collection without disrupting use.
+	settleLiveTensors(t)
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	collections := stats.NumGC
+	tensor := torch.Zeros([]int64{1024}, torch.NewTensorOptions().Dtype(torch.Float))
+	assert.Equal(t, []int64{1024}, tensor.Shape())
+	assert.Eventually(t, func() bool {
+		runtime.ReadMemStats(&stats)
+		return stats.NumGC > collections
+	}, time.Second, time.Millisecond)
+}
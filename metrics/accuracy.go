@@ -0,0 +1,90 @@
+// Top-k classification accuracy.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics
+
+import (
+	"sync"
+	"github.com/Kautenja/gotorch"
+)
+
+// An accumulator for the fraction of samples whose target class is among the
+// k highest scoring classes.
+type TopKAccuracy struct {
+	// The number of top scoring classes that are considered a hit.
+	K int64
+	mutex sync.Mutex
+	correct, total int64
+}
+
+// Create a new TopKAccuracy accumulator for the given k.
+func NewTopKAccuracy(k int64) *TopKAccuracy {
+	if k <= 0 { panic("k should be greater than 0") }
+	return &TopKAccuracy{K: k}
+}
+
+// Update the accumulator with (N, C) class scores and (N,) target labels.
+func (metric *TopKAccuracy) Update(scores, target *torch.Tensor) {
+	checkDim("scores", scores, 2)
+	checkDim("target", target, 1)
+	checkLength(scores, target)
+	var correct int64
+	if scores.Numel() > 0 {
+		k := metric.K
+		if classes := scores.Shape()[1]; k > classes {
+			k = classes
+		}
+		indices := scores.TopK(k, 1, true, false).Indices
+		hits := indices.Eq(target.CastTo(torch.Long).Unsqueeze(1)).AnyByDim(1, false)
+		correct = hits.CastTo(torch.Long).Sum().Item().(int64)
+	}
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.correct += correct
+	metric.total += target.Shape()[0]
+}
+
+// Merge the state of another accumulator into this one.
+func (metric *TopKAccuracy) Merge(other *TopKAccuracy) {
+	other.mutex.Lock()
+	correct, total := other.correct, other.total
+	other.mutex.Unlock()
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.correct += correct
+	metric.total += total
+}
+
+// Compute the top-k accuracy over all samples seen so far.
+func (metric *TopKAccuracy) Compute() float64 {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	return safeDivide(float64(metric.correct), float64(metric.total))
+}
+
+// Reset the accumulator to its initial state.
+func (metric *TopKAccuracy) Reset() {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.correct, metric.total = 0, 0
+}
@@ -0,0 +1,77 @@
+// test cases for accuracy.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics_test
+
+import (
+	"sync"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/metrics"
+)
+
+func TestNewTopKAccuracyPanicsOnInvalidK(t *testing.T) {
+	assert.PanicsWithValue(t, "k should be greater than 0", func() {
+		metrics.NewTopKAccuracy(0)
+	})
+}
+
+func TestTopKAccuracyPanicsOnSizeMismatch(t *testing.T) {
+	metric := metrics.NewTopKAccuracy(1)
+	assert.PanicsWithValue(t, "Size mismatch between predictions (1) and target (2)", func() {
+		metric.Update(torch.NewTensor([][]float32{{0.1, 0.9}}), torch.NewTensor([]int64{0, 1}))
+	})
+}
+
+func TestTopKAccuracy(t *testing.T) {
+	scores := torch.NewTensor([][]float32{{0.1, 0.5, 0.4}, {0.7, 0.2, 0.1}})
+	target := torch.NewTensor([]int64{2, 1})
+	top1 := metrics.NewTopKAccuracy(1)
+	top2 := metrics.NewTopKAccuracy(2)
+	top1.Update(scores, target)
+	top2.Update(scores, target)
+	assert.Equal(t, 0.0, top1.Compute())
+	assert.Equal(t, 1.0, top2.Compute())
+	top1.Update(torch.NewTensor([][]float32{{0.0, 0.0, 1.0}}), torch.NewTensor([]int64{2}))
+	assert.InDelta(t, 1.0 / 3.0, top1.Compute(), 1e-9)
+	top1.Reset()
+	assert.Equal(t, 0.0, top1.Compute())
+}
+
+func TestTopKAccuracyMergesAcrossGoroutines(t *testing.T) {
+	total := metrics.NewTopKAccuracy(1)
+	var group sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		group.Add(1)
+		go func(worker int) {
+			defer group.Done()
+			metric := metrics.NewTopKAccuracy(1)
+			label := int64(worker % 2)
+			metric.Update(torch.NewTensor([][]float32{{1, 0}}), torch.NewTensor([]int64{label}))
+			total.Merge(metric)
+		}(worker)
+	}
+	group.Wait()
+	assert.Equal(t, 0.5, total.Compute())
+}
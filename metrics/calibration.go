@@ -0,0 +1,149 @@
+// Calibration error of probabilistic classifiers.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"github.com/Kautenja/gotorch"
+)
+
+// An accumulator for the calibration error of a classifier, i.e., the gap
+// between the confidence of its top prediction and its accuracy. Samples are
+// grouped into equal-width confidence bins over [0, 1].
+type CalibrationError struct {
+	// The number of equal-width confidence bins.
+	NumBins int64
+	mutex sync.Mutex
+	counts []int64
+	confidences []float64
+	accuracies []float64
+}
+
+// Create a new CalibrationError accumulator with the given number of bins.
+func NewCalibrationError(numBins int64) *CalibrationError {
+	if numBins <= 0 { panic("numBins should be greater than 0") }
+	metric := &CalibrationError{NumBins: numBins}
+	metric.Reset()
+	return metric
+}
+
+// Update the accumulator with (N, C) class probabilities and (N,) target
+// labels.
+func (metric *CalibrationError) Update(probabilities, target *torch.Tensor) {
+	checkDim("probabilities", probabilities, 2)
+	checkDim("target", target, 1)
+	checkLength(probabilities, target)
+	if probabilities.Numel() == 0 {
+		return
+	}
+	top := probabilities.MaxByDim(1, false)
+	confidences := toFloat64s(top.Values)
+	hits := toInt64s(top.Indices.Eq(target.CastTo(torch.Long)))
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	for index, confidence := range confidences {
+		bin := int64(confidence * float64(metric.NumBins))
+		if bin >= metric.NumBins {
+			bin = metric.NumBins - 1
+		} else if bin < 0 {
+			bin = 0
+		}
+		metric.counts[bin]++
+		metric.confidences[bin] += confidence
+		metric.accuracies[bin] += float64(hits[index])
+	}
+}
+
+// Merge the state of another accumulator into this one.
+func (metric *CalibrationError) Merge(other *CalibrationError) {
+	if other.NumBins != metric.NumBins {
+		panic(fmt.Sprintf("Cannot merge calibration errors with %d and %d bins", metric.NumBins, other.NumBins))
+	}
+	other.mutex.Lock()
+	counts := append([]int64{}, other.counts...)
+	confidences := append([]float64{}, other.confidences...)
+	accuracies := append([]float64{}, other.accuracies...)
+	other.mutex.Unlock()
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	for bin := range counts {
+		metric.counts[bin] += counts[bin]
+		metric.confidences[bin] += confidences[bin]
+		metric.accuracies[bin] += accuracies[bin]
+	}
+}
+
+// Return the absolute gap between confidence and accuracy of each bin and
+// the fraction of samples in each bin.
+func (metric *CalibrationError) gaps() (gaps, weights []float64) {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	var total int64
+	for _, count := range metric.counts {
+		total += count
+	}
+	gaps = make([]float64, metric.NumBins)
+	weights = make([]float64, metric.NumBins)
+	for bin, count := range metric.counts {
+		if count == 0 {
+			continue
+		}
+		gaps[bin] = math.Abs(metric.accuracies[bin] - metric.confidences[bin]) / float64(count)
+		weights[bin] = float64(count) / float64(total)
+	}
+	return
+}
+
+// Compute the expected calibration error (ECE), the mean gap between
+// confidence and accuracy weighted by the number of samples in each bin.
+func (metric *CalibrationError) Compute() float64 {
+	gaps, weights := metric.gaps()
+	var value float64
+	for bin := range gaps {
+		value += gaps[bin] * weights[bin]
+	}
+	return value
+}
+
+// Compute the maximum calibration error (MCE), the largest gap between
+// confidence and accuracy over all non-empty bins.
+func (metric *CalibrationError) Max() float64 {
+	gaps, _ := metric.gaps()
+	var value float64
+	for _, gap := range gaps {
+		value = math.Max(value, gap)
+	}
+	return value
+}
+
+// Reset the accumulator to its initial state.
+func (metric *CalibrationError) Reset() {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.counts = make([]int64, metric.NumBins)
+	metric.confidences = make([]float64, metric.NumBins)
+	metric.accuracies = make([]float64, metric.NumBins)
+}
@@ -0,0 +1,48 @@
+// test cases for calibration.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/metrics"
+)
+
+func TestCalibrationError(t *testing.T) {
+	metric := metrics.NewCalibrationError(10)
+	metric.Update(torch.NewTensor([][]float32{{0.9, 0.1}, {0.6, 0.4}}), torch.NewTensor([]int64{0, 1}))
+	assert.InDelta(t, 0.35, metric.Compute(), 1e-6)
+	assert.InDelta(t, 0.6, metric.Max(), 1e-6)
+	metric.Merge(metric)
+	assert.InDelta(t, 0.35, metric.Compute(), 1e-6)
+	metric.Reset()
+	assert.Equal(t, 0.0, metric.Compute())
+}
+
+func TestCalibrationErrorOfPerfectConfidenceIsZero(t *testing.T) {
+	metric := metrics.NewCalibrationError(15)
+	metric.Update(torch.NewTensor([][]float32{{1, 0}, {0, 1}}), torch.NewTensor([]int64{0, 1}))
+	assert.Equal(t, 0.0, metric.Compute())
+}
@@ -0,0 +1,195 @@
+// Confusion matrix with precision, recall, and F1 scores.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"github.com/Kautenja/gotorch"
+)
+
+// The method for reducing per-class scores to a single value.
+type Average int64
+const (
+	// Compute the score from the total true positives, false positives, and
+	// false negatives over all classes.
+	AverageMicro Average = iota
+	// Compute the score for each class and take the unweighted mean.
+	AverageMacro
+)
+
+// An accumulator for the counts of (target, prediction) pairs over a fixed
+// set of classes.
+type ConfusionMatrix struct {
+	// The number of classes in the classification problem.
+	NumClasses int64
+	mutex sync.Mutex
+	// The counts in row-major (target, prediction) order.
+	counts []int64
+}
+
+// Create a new ConfusionMatrix for the given number of classes.
+func NewConfusionMatrix(numClasses int64) *ConfusionMatrix {
+	if numClasses <= 0 { panic("numClasses should be greater than 0") }
+	return &ConfusionMatrix{NumClasses: numClasses, counts: make([]int64, numClasses * numClasses)}
+}
+
+// Update the accumulator with predictions and (N,) target labels. Predictions
+// are either (N,) predicted labels or (N, C) class scores.
+func (metric *ConfusionMatrix) Update(predictions, target *torch.Tensor) {
+	checkDim("predictions", predictions, 1, 2)
+	checkDim("target", target, 1)
+	checkLength(predictions, target)
+	if predictions.Dim() == 2 && predictions.Numel() > 0 {
+		predictions = predictions.ArgmaxByDim(1, false)
+	}
+	predicted := toInt64s(predictions)
+	actual := toInt64s(target)
+	for index := range actual {
+		for _, label := range []int64{predicted[index], actual[index]} {
+			if label < 0 || label >= metric.NumClasses {
+				panic(fmt.Sprintf("Label %d is out of range for %d classes", label, metric.NumClasses))
+			}
+		}
+	}
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	for index := range actual {
+		metric.counts[actual[index] * metric.NumClasses + predicted[index]]++
+	}
+}
+
+// Merge the state of another accumulator into this one.
+func (metric *ConfusionMatrix) Merge(other *ConfusionMatrix) {
+	if other.NumClasses != metric.NumClasses {
+		panic(fmt.Sprintf("Cannot merge confusion matrices with %d and %d classes", metric.NumClasses, other.NumClasses))
+	}
+	other.mutex.Lock()
+	counts := append([]int64{}, other.counts...)
+	other.mutex.Unlock()
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	for index, count := range counts {
+		metric.counts[index] += count
+	}
+}
+
+// Return the (C, C) matrix of counts where rows are targets and columns are
+// predictions.
+func (metric *ConfusionMatrix) Compute() *torch.Tensor {
+	metric.mutex.Lock()
+	counts := append([]int64{}, metric.counts...)
+	metric.mutex.Unlock()
+	return torch.NewTensor(counts).View(metric.NumClasses, metric.NumClasses)
+}
+
+// Reset the accumulator to its initial state.
+func (metric *ConfusionMatrix) Reset() {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.counts = make([]int64, metric.NumClasses * metric.NumClasses)
+}
+
+// Return the true positive, false positive, and false negative counts of each
+// class.
+func (metric *ConfusionMatrix) outcomes() (truePositives, falsePositives, falseNegatives []float64) {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	classes := metric.NumClasses
+	truePositives = make([]float64, classes)
+	falsePositives = make([]float64, classes)
+	falseNegatives = make([]float64, classes)
+	for actual := int64(0); actual < classes; actual++ {
+		for predicted := int64(0); predicted < classes; predicted++ {
+			count := float64(metric.counts[actual * classes + predicted])
+			if actual == predicted {
+				truePositives[actual] += count
+			} else {
+				falseNegatives[actual] += count
+				falsePositives[predicted] += count
+			}
+		}
+	}
+	return
+}
+
+// Reduce per-class outcomes to a single score using the given average.
+func reduceScores(
+	average Average,
+	truePositives, falsePositives, falseNegatives []float64,
+	score func(tp, fp, fn float64) float64,
+) float64 {
+	switch average {
+	case AverageMicro:
+		var tp, fp, fn float64
+		for class := range truePositives {
+			tp += truePositives[class]
+			fp += falsePositives[class]
+			fn += falseNegatives[class]
+		}
+		return score(tp, fp, fn)
+	case AverageMacro:
+		scores := make([]float64, len(truePositives))
+		for class := range truePositives {
+			scores[class] = score(truePositives[class], falsePositives[class], falseNegatives[class])
+		}
+		return mean(scores)
+	default:
+		panic(fmt.Sprintf("Unrecognized average %d", average))
+	}
+}
+
+// Compute the fraction of samples that were classified correctly.
+func (metric *ConfusionMatrix) Accuracy() float64 {
+	truePositives, falsePositives, falseNegatives := metric.outcomes()
+	return reduceScores(AverageMicro, truePositives, falsePositives, falseNegatives, func(tp, fp, fn float64) float64 {
+		return safeDivide(tp, tp + fn)
+	})
+}
+
+// Compute the precision tp / (tp + fp) using the given average. Classes that
+// were never predicted have a precision of 0.
+func (metric *ConfusionMatrix) Precision(average Average) float64 {
+	truePositives, falsePositives, falseNegatives := metric.outcomes()
+	return reduceScores(average, truePositives, falsePositives, falseNegatives, func(tp, fp, fn float64) float64 {
+		return safeDivide(tp, tp + fp)
+	})
+}
+
+// Compute the recall tp / (tp + fn) using the given average. Classes that
+// never appeared in the targets have a recall of 0.
+func (metric *ConfusionMatrix) Recall(average Average) float64 {
+	truePositives, falsePositives, falseNegatives := metric.outcomes()
+	return reduceScores(average, truePositives, falsePositives, falseNegatives, func(tp, fp, fn float64) float64 {
+		return safeDivide(tp, tp + fn)
+	})
+}
+
+// Compute the F1 score 2tp / (2tp + fp + fn) using the given average.
+func (metric *ConfusionMatrix) F1(average Average) float64 {
+	truePositives, falsePositives, falseNegatives := metric.outcomes()
+	return reduceScores(average, truePositives, falsePositives, falseNegatives, func(tp, fp, fn float64) float64 {
+		return safeDivide(2 * tp, 2 * tp + fp + fn)
+	})
+}
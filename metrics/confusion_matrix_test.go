@@ -0,0 +1,79 @@
+// test cases for confusion_matrix.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/metrics"
+)
+
+func makeConfusionMatrix() *metrics.ConfusionMatrix {
+	metric := metrics.NewConfusionMatrix(3)
+	metric.Update(torch.NewTensor([]int64{0, 1, 1, 2}), torch.NewTensor([]int64{0, 1, 2, 2}))
+	return metric
+}
+
+func TestConfusionMatrixPanicsOnOutOfRangeLabel(t *testing.T) {
+	metric := metrics.NewConfusionMatrix(2)
+	assert.PanicsWithValue(t, "Label 2 is out of range for 2 classes", func() {
+		metric.Update(torch.NewTensor([]int64{2}), torch.NewTensor([]int64{0}))
+	})
+}
+
+func TestConfusionMatrixCompute(t *testing.T) {
+	metric := makeConfusionMatrix()
+	expected := torch.NewTensor([][]int64{{1, 0, 0}, {0, 1, 0}, {0, 1, 1}})
+	assert.True(t, metric.Compute().Equal(expected))
+}
+
+func TestConfusionMatrixAcceptsScores(t *testing.T) {
+	metric := metrics.NewConfusionMatrix(2)
+	metric.Update(torch.NewTensor([][]float32{{0.9, 0.1}, {0.3, 0.7}}), torch.NewTensor([]int64{1, 1}))
+	assert.True(t, metric.Compute().Equal(torch.NewTensor([][]int64{{0, 0}, {1, 1}})))
+}
+
+func TestConfusionMatrixScores(t *testing.T) {
+	metric := makeConfusionMatrix()
+	assert.Equal(t, 0.75, metric.Accuracy())
+	assert.Equal(t, 0.75, metric.Precision(metrics.AverageMicro))
+	assert.Equal(t, 0.75, metric.Recall(metrics.AverageMicro))
+	assert.Equal(t, 0.75, metric.F1(metrics.AverageMicro))
+	assert.InDelta(t, 2.5 / 3.0, metric.Precision(metrics.AverageMacro), 1e-9)
+	assert.InDelta(t, 2.5 / 3.0, metric.Recall(metrics.AverageMacro), 1e-9)
+	assert.InDelta(t, 7.0 / 9.0, metric.F1(metrics.AverageMacro), 1e-9)
+}
+
+func TestConfusionMatrixMerge(t *testing.T) {
+	metric := makeConfusionMatrix()
+	metric.Merge(makeConfusionMatrix())
+	expected := torch.NewTensor([][]int64{{2, 0, 0}, {0, 2, 0}, {0, 2, 2}})
+	assert.True(t, metric.Compute().Equal(expected))
+	assert.PanicsWithValue(t, "Cannot merge confusion matrices with 3 and 2 classes", func() {
+		metric.Merge(metrics.NewConfusionMatrix(2))
+	})
+	metric.Reset()
+	assert.True(t, metric.Compute().Equal(torch.ZerosLike(expected)))
+}
@@ -0,0 +1,253 @@
+// COCO-style mean average precision for object detection.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"github.com/Kautenja/gotorch"
+	ops "github.com/Kautenja/gotorch/vision/ops"
+)
+
+// The IoU thresholds of the COCO mAP@[.5:.95] metric.
+var COCOIoUThresholds = []float64{0.5, 0.55, 0.6, 0.65, 0.7, 0.75, 0.8, 0.85, 0.9, 0.95}
+
+// A scored detection and whether it matched a ground truth box at each IoU
+// threshold.
+type detectionMatch struct {
+	score float64
+	matched []bool
+}
+
+// An accumulator for COCO-style mean average precision of object detections.
+// Detections are matched greedily in descending order of score to the unmatched
+// ground truth box of the same class with the highest IoU. Average precision
+// is interpolated at 101 recall points and averaged over classes and IoU
+// thresholds.
+type MeanAveragePrecision struct {
+	// The IoU thresholds at which detections are considered true positives.
+	IoUThresholds []float64
+	// The maximum number of detections per image and class to consider.
+	MaxDetections int
+	mutex sync.Mutex
+	// The detections and number of ground truth boxes of each class.
+	detections map[int64][]detectionMatch
+	groundTruths map[int64]int64
+}
+
+// Create a new MeanAveragePrecision accumulator with COCO's default IoU
+// thresholds of [.5:.05:.95] and 100 detections per image.
+func NewMeanAveragePrecision() *MeanAveragePrecision {
+	return NewMeanAveragePrecisionWithThresholds(COCOIoUThresholds, 100)
+}
+
+// Create a new MeanAveragePrecision accumulator with the given IoU thresholds
+// and maximum number of detections per image and class.
+func NewMeanAveragePrecisionWithThresholds(iouThresholds []float64, maxDetections int) *MeanAveragePrecision {
+	if len(iouThresholds) == 0 { panic("iouThresholds should not be empty") }
+	if maxDetections <= 0 { panic("maxDetections should be greater than 0") }
+	return &MeanAveragePrecision{
+		IoUThresholds: append([]float64{}, iouThresholds...),
+		MaxDetections: maxDetections,
+		detections: make(map[int64][]detectionMatch),
+		groundTruths: make(map[int64]int64),
+	}
+}
+
+// Update the accumulator with the detections and ground truth of a single
+// image. boxes are (N, 4) predicted boxes in (xmin, ymin, xmax, ymax) format
+// with (N,) scores and (N,) labels. targetBoxes are (M, 4) ground truth boxes
+// in the same format with (M,) targetLabels.
+func (metric *MeanAveragePrecision) Update(boxes, scores, labels, targetBoxes, targetLabels *torch.Tensor) {
+	checkDim("boxes", boxes, 2)
+	checkDim("scores", scores, 1)
+	checkDim("labels", labels, 1)
+	checkDim("targetBoxes", targetBoxes, 2)
+	checkDim("targetLabels", targetLabels, 1)
+	checkLength(boxes, scores)
+	checkLength(boxes, labels)
+	checkLength(targetBoxes, targetLabels)
+	predictedScores := toFloat64s(scores)
+	predictedLabels := toInt64s(labels)
+	actualLabels := toInt64s(targetLabels)
+	// Compute the (N, M) IoU matrix between all detections and ground truths.
+	var ious []float64
+	if len(predictedLabels) > 0 && len(actualLabels) > 0 {
+		ious = toFloat64s(ops.BoxIoU(boxes, targetBoxes))
+	}
+	// Group the detections and ground truths of the image by class.
+	detectionsByClass := make(map[int64][]int)
+	for index, label := range predictedLabels {
+		detectionsByClass[label] = append(detectionsByClass[label], index)
+	}
+	groundTruthsByClass := make(map[int64][]int)
+	for index, label := range actualLabels {
+		groundTruthsByClass[label] = append(groundTruthsByClass[label], index)
+	}
+	matches := make(map[int64][]detectionMatch)
+	for class, detections := range detectionsByClass {
+		sort.SliceStable(detections, func(i, j int) bool {
+			return predictedScores[detections[i]] > predictedScores[detections[j]]
+		})
+		if len(detections) > metric.MaxDetections {
+			detections = detections[:metric.MaxDetections]
+		}
+		groundTruths := groundTruthsByClass[class]
+		records := make([]detectionMatch, len(detections))
+		for index, detection := range detections {
+			records[index] = detectionMatch{predictedScores[detection], make([]bool, len(metric.IoUThresholds))}
+		}
+		for thresholdIndex, threshold := range metric.IoUThresholds {
+			taken := make([]bool, len(groundTruths))
+			for index, detection := range detections {
+				best := -1
+				bestIoU := threshold
+				for position, groundTruth := range groundTruths {
+					iou := ious[detection * len(actualLabels) + groundTruth]
+					if !taken[position] && iou >= bestIoU {
+						best, bestIoU = position, iou
+					}
+				}
+				if best >= 0 {
+					taken[best] = true
+					records[index].matched[thresholdIndex] = true
+				}
+			}
+		}
+		matches[class] = records
+	}
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	for class, records := range matches {
+		metric.detections[class] = append(metric.detections[class], records...)
+	}
+	for class, groundTruths := range groundTruthsByClass {
+		metric.groundTruths[class] += int64(len(groundTruths))
+	}
+}
+
+// Return true if two lists of IoU thresholds have the same values in the same
+// order.
+func equalThresholds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if a[index] != b[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge the state of another accumulator into this one. Merge panics if the
+// accumulators have different IoU thresholds.
+func (metric *MeanAveragePrecision) Merge(other *MeanAveragePrecision) {
+	if !equalThresholds(metric.IoUThresholds, other.IoUThresholds) {
+		panic(fmt.Sprintf("Cannot merge mean average precisions with IoU thresholds %v and %v", metric.IoUThresholds, other.IoUThresholds))
+	}
+	other.mutex.Lock()
+	detections := make(map[int64][]detectionMatch)
+	for class, records := range other.detections {
+		detections[class] = append([]detectionMatch{}, records...)
+	}
+	groundTruths := make(map[int64]int64)
+	for class, count := range other.groundTruths {
+		groundTruths[class] = count
+	}
+	other.mutex.Unlock()
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	for class, records := range detections {
+		metric.detections[class] = append(metric.detections[class], records...)
+	}
+	for class, count := range groundTruths {
+		metric.groundTruths[class] += count
+	}
+}
+
+// Compute the average precision of a set of detections at one IoU threshold
+// using 101-point interpolation over recall.
+func interpolatedAveragePrecision(records []detectionMatch, threshold int, groundTruths int64) float64 {
+	precisions := make([]float64, len(records))
+	recalls := make([]float64, len(records))
+	var truePositives float64
+	for index, record := range records {
+		if record.matched[threshold] {
+			truePositives++
+		}
+		precisions[index] = truePositives / float64(index + 1)
+		recalls[index] = truePositives / float64(groundTruths)
+	}
+	// Make precision monotonically decreasing from right to left.
+	for index := len(precisions) - 2; index >= 0; index-- {
+		if precisions[index] < precisions[index + 1] {
+			precisions[index] = precisions[index + 1]
+		}
+	}
+	var sum float64
+	for point := 0; point <= 100; point++ {
+		recall := float64(point) / 100
+		index := sort.SearchFloat64s(recalls, recall)
+		if index < len(precisions) {
+			sum += precisions[index]
+		}
+	}
+	return sum / 101
+}
+
+// Compute the mean average precision over classes at each IoU threshold.
+// Classes without ground truth boxes are ignored.
+func (metric *MeanAveragePrecision) ComputeByThreshold() []float64 {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	averages := make([]float64, len(metric.IoUThresholds))
+	for threshold := range metric.IoUThresholds {
+		var precisions []float64
+		for class, groundTruths := range metric.groundTruths {
+			if groundTruths == 0 {
+				continue
+			}
+			records := append([]detectionMatch{}, metric.detections[class]...)
+			sort.SliceStable(records, func(i, j int) bool { return records[i].score > records[j].score })
+			precisions = append(precisions, interpolatedAveragePrecision(records, threshold, groundTruths))
+		}
+		averages[threshold] = mean(precisions)
+	}
+	return averages
+}
+
+// Compute the mean average precision over all classes and IoU thresholds.
+func (metric *MeanAveragePrecision) Compute() float64 {
+	return mean(metric.ComputeByThreshold())
+}
+
+// Reset the accumulator to its initial state.
+func (metric *MeanAveragePrecision) Reset() {
+	metric.mutex.Lock()
+	defer metric.mutex.Unlock()
+	metric.detections = make(map[int64][]detectionMatch)
+	metric.groundTruths = make(map[int64]int64)
+}
@@ -0,0 +1,87 @@
+// test cases for mean_average_precision.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/metrics"
+)
+
+var (
+	targetBoxes = torch.NewTensor([][]float32{{0, 0, 10, 10}})
+	targetLabels = torch.NewTensor([]int64{1})
+)
+
+func TestMeanAveragePrecisionOfPerfectDetectionIsOne(t *testing.T) {
+	metric := metrics.NewMeanAveragePrecision()
+	boxes := torch.NewTensor([][]float32{{0, 0, 10, 10}})
+	metric.Update(boxes, torch.NewTensor([]float32{0.9}), torch.NewTensor([]int64{1}), targetBoxes, targetLabels)
+	assert.InDelta(t, 1.0, metric.Compute(), 1e-9)
+}
+
+func TestMeanAveragePrecisionAveragesOverIoUThresholds(t *testing.T) {
+	metric := metrics.NewMeanAveragePrecision()
+	// An IoU of 0.68 is a hit at the thresholds 0.5, 0.55, 0.6, and 0.65.
+	boxes := torch.NewTensor([][]float32{{0, 0, 10, 6.8}})
+	metric.Update(boxes, torch.NewTensor([]float32{0.9}), torch.NewTensor([]int64{1}), targetBoxes, targetLabels)
+	byThreshold := metric.ComputeByThreshold()
+	assert.InDelta(t, 1.0, byThreshold[0], 1e-9)
+	assert.InDelta(t, 0.0, byThreshold[9], 1e-9)
+	assert.InDelta(t, 0.4, metric.Compute(), 1e-6)
+}
+
+func TestMeanAveragePrecisionPenalizesHighScoringFalsePositives(t *testing.T) {
+	metric := metrics.NewMeanAveragePrecision()
+	boxes := torch.NewTensor([][]float32{{0, 0, 10, 10}, {50, 50, 60, 60}, {0, 0, 10, 10}})
+	scores := torch.NewTensor([]float32{0.5, 0.9, 0.8})
+	// The detection of class 2 does not match the ground truth of class 1.
+	labels := torch.NewTensor([]int64{1, 1, 2})
+	metric.Update(boxes, scores, labels, targetBoxes, targetLabels)
+	assert.InDelta(t, 0.5, metric.Compute(), 1e-9)
+}
+
+func TestMeanAveragePrecisionMergeAndReset(t *testing.T) {
+	metric := metrics.NewMeanAveragePrecisionWithThresholds([]float64{0.5}, 100)
+	other := metrics.NewMeanAveragePrecisionWithThresholds([]float64{0.5}, 100)
+	// The first image has a missed ground truth box.
+	boxes := torch.Zeros([]int64{0, 4}, torch.NewTensorOptions().Dtype(torch.Float))
+	scores := torch.Zeros([]int64{0}, torch.NewTensorOptions().Dtype(torch.Float))
+	labels := torch.Zeros([]int64{0}, torch.NewTensorOptions().Dtype(torch.Long))
+	metric.Update(boxes, scores, labels, targetBoxes, targetLabels)
+	other.Update(targetBoxes, torch.NewTensor([]float32{0.9}), targetLabels, targetBoxes, targetLabels)
+	metric.Merge(other)
+	assert.InDelta(t, 51.0 / 101.0, metric.Compute(), 1e-9)
+	metric.Reset()
+	assert.Equal(t, 0.0, metric.Compute())
+}
+
+func TestMeanAveragePrecisionMergePanicsOnDifferentThresholds(t *testing.T) {
+	metric := metrics.NewMeanAveragePrecisionWithThresholds([]float64{0.5, 0.75}, 100)
+	other := metrics.NewMeanAveragePrecisionWithThresholds([]float64{0.5, 0.9}, 100)
+	assert.PanicsWithValue(t, "Cannot merge mean average precisions with IoU thresholds [0.5 0.75] and [0.5 0.9]", func() { metric.Merge(other) })
+	other = metrics.NewMeanAveragePrecisionWithThresholds([]float64{0.5}, 100)
+	assert.Panics(t, func() { metric.Merge(other) })
+}
@@ -0,0 +1,87 @@
+// Streaming evaluation metrics for classification and detection models.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package metrics provides streaming accumulators for evaluating models. Each
+// accumulator is updated batch-by-batch with *torch.Tensor predictions and
+// targets and computes its final value on demand. Accumulators are safe for
+// concurrent use and can be merged, so parallel evaluation can give each
+// goroutine its own accumulator and combine them once all batches are scored.
+package metrics
+
+import (
+	"fmt"
+	"github.com/Kautenja/gotorch"
+)
+
+// Copy a tensor of any shape into a flat slice of float64 values.
+func toFloat64s(tensor *torch.Tensor) []float64 {
+	if tensor.Numel() == 0 {
+		return []float64{}
+	}
+	return tensor.CastTo(torch.Double).ToSlice().([]float64)
+}
+
+// Copy a tensor of any shape into a flat slice of int64 values.
+func toInt64s(tensor *torch.Tensor) []int64 {
+	if tensor.Numel() == 0 {
+		return []int64{}
+	}
+	return tensor.CastTo(torch.Long).ToSlice().([]int64)
+}
+
+// Panic if the tensor does not have the expected number of dimensions.
+func checkDim(name string, tensor *torch.Tensor, dims ...int64) {
+	for _, dim := range dims {
+		if tensor.Dim() == dim {
+			return
+		}
+	}
+	panic(fmt.Sprintf("Expected %s to have %v dimensions, but received tensor with shape %v", name, dims, tensor.Shape()))
+}
+
+// Panic if two tensors do not describe the same number of samples.
+func checkLength(predictions, target *torch.Tensor) {
+	if predictions.Shape()[0] != target.Shape()[0] {
+		panic(fmt.Sprintf("Size mismatch between predictions (%d) and target (%d)", predictions.Shape()[0], target.Shape()[0]))
+	}
+}
+
+// Return the mean of a slice of values, or 0 if the slice is empty.
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// Return numerator / denominator, or 0 if the denominator is 0.
+func safeDivide(numerator, denominator float64) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
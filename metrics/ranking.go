@@ -0,0 +1,198 @@
+// Area under the ROC and precision-recall curves for binary classifiers.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"github.com/Kautenja/gotorch"
+)
+
+// The scores and binary labels of every sample seen by a ranking metric.
+// Ranking metrics cannot be computed from fixed-size summaries, so the
+// accumulator keeps one score and label per sample.
+type binaryScores struct {
+	mutex sync.Mutex
+	scores []float64
+	labels []bool
+}
+
+// Append (N,) scores and (N,) binary targets to the accumulator.
+func (state *binaryScores) update(scores, target *torch.Tensor) {
+	checkDim("scores", scores, 1)
+	checkDim("target", target, 1)
+	checkLength(scores, target)
+	values := toFloat64s(scores)
+	labels := toInt64s(target)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	for index := range values {
+		state.scores = append(state.scores, values[index])
+		state.labels = append(state.labels, labels[index] != 0)
+	}
+}
+
+// Append the samples of another accumulator to this one.
+func (state *binaryScores) merge(other *binaryScores) {
+	other.mutex.Lock()
+	scores := append([]float64{}, other.scores...)
+	labels := append([]bool{}, other.labels...)
+	other.mutex.Unlock()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.scores = append(state.scores, scores...)
+	state.labels = append(state.labels, labels...)
+}
+
+// Remove all samples from the accumulator.
+func (state *binaryScores) reset() {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.scores, state.labels = nil, nil
+}
+
+// Return the cumulative true and false positive counts at each distinct score
+// threshold in descending order of score.
+func (state *binaryScores) curve() (truePositives, falsePositives []float64) {
+	state.mutex.Lock()
+	order := make([]int, len(state.scores))
+	for index := range order {
+		order[index] = index
+	}
+	scores := state.scores
+	labels := state.labels
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+	var tp, fp float64
+	for position, index := range order {
+		if labels[index] {
+			tp++
+		} else {
+			fp++
+		}
+		// Samples with tied scores share a single threshold.
+		if position + 1 < len(order) && scores[order[position + 1]] == scores[index] {
+			continue
+		}
+		truePositives = append(truePositives, tp)
+		falsePositives = append(falsePositives, fp)
+	}
+	state.mutex.Unlock()
+	return
+}
+
+// An accumulator for the area under the receiver operating characteristic
+// curve (ROC-AUC) of a binary classifier.
+type AUROC struct {
+	state binaryScores
+}
+
+// Create a new AUROC accumulator.
+func NewAUROC() *AUROC {
+	return &AUROC{}
+}
+
+// Update the accumulator with (N,) scores for the positive class and (N,)
+// binary targets where non-zero values are positive.
+func (metric *AUROC) Update(scores, target *torch.Tensor) {
+	metric.state.update(scores, target)
+}
+
+// Merge the state of another accumulator into this one.
+func (metric *AUROC) Merge(other *AUROC) {
+	metric.state.merge(&other.state)
+}
+
+// Compute the ROC-AUC using the trapezoidal rule. The result is NaN if the
+// samples seen so far are all positive or all negative.
+func (metric *AUROC) Compute() float64 {
+	truePositives, falsePositives := metric.state.curve()
+	if len(truePositives) == 0 {
+		return math.NaN()
+	}
+	positives := truePositives[len(truePositives) - 1]
+	negatives := falsePositives[len(falsePositives) - 1]
+	if positives == 0 || negatives == 0 {
+		return math.NaN()
+	}
+	var area, previousTPR, previousFPR float64
+	for index := range truePositives {
+		tpr := truePositives[index] / positives
+		fpr := falsePositives[index] / negatives
+		area += (fpr - previousFPR) * (tpr + previousTPR) / 2
+		previousTPR, previousFPR = tpr, fpr
+	}
+	return area
+}
+
+// Reset the accumulator to its initial state.
+func (metric *AUROC) Reset() {
+	metric.state.reset()
+}
+
+// An accumulator for the area under the precision-recall curve (PR-AUC) of a
+// binary classifier, summarized as average precision.
+type AveragePrecision struct {
+	state binaryScores
+}
+
+// Create a new AveragePrecision accumulator.
+func NewAveragePrecision() *AveragePrecision {
+	return &AveragePrecision{}
+}
+
+// Update the accumulator with (N,) scores for the positive class and (N,)
+// binary targets where non-zero values are positive.
+func (metric *AveragePrecision) Update(scores, target *torch.Tensor) {
+	metric.state.update(scores, target)
+}
+
+// Merge the state of another accumulator into this one.
+func (metric *AveragePrecision) Merge(other *AveragePrecision) {
+	metric.state.merge(&other.state)
+}
+
+// Compute the average precision as the sum of precisions at each threshold
+// weighted by the increase in recall from the previous threshold. The result
+// is NaN if no positive samples have been seen.
+func (metric *AveragePrecision) Compute() float64 {
+	truePositives, falsePositives := metric.state.curve()
+	if len(truePositives) == 0 || truePositives[len(truePositives) - 1] == 0 {
+		return math.NaN()
+	}
+	positives := truePositives[len(truePositives) - 1]
+	var area, previousRecall float64
+	for index := range truePositives {
+		recall := truePositives[index] / positives
+		precision := truePositives[index] / (truePositives[index] + falsePositives[index])
+		area += (recall - previousRecall) * precision
+		previousRecall = recall
+	}
+	return area
+}
+
+// Reset the accumulator to its initial state.
+func (metric *AveragePrecision) Reset() {
+	metric.state.reset()
+}
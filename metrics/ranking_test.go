@@ -0,0 +1,58 @@
+// test cases for ranking.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package metrics_test
+
+import (
+	"math"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	"github.com/Kautenja/gotorch/metrics"
+)
+
+func TestAUROC(t *testing.T) {
+	metric := metrics.NewAUROC()
+	assert.True(t, math.IsNaN(metric.Compute()))
+	metric.Update(torch.NewTensor([]float32{0.1, 0.4}), torch.NewTensor([]int64{0, 0}))
+	assert.True(t, math.IsNaN(metric.Compute()))
+	other := metrics.NewAUROC()
+	other.Update(torch.NewTensor([]float32{0.35, 0.8}), torch.NewTensor([]int64{1, 1}))
+	metric.Merge(other)
+	assert.InDelta(t, 0.75, metric.Compute(), 1e-9)
+	metric.Reset()
+	assert.True(t, math.IsNaN(metric.Compute()))
+}
+
+func TestAUROCHandlesTiedScores(t *testing.T) {
+	metric := metrics.NewAUROC()
+	metric.Update(torch.NewTensor([]float32{0.5, 0.5}), torch.NewTensor([]bool{true, false}))
+	assert.InDelta(t, 0.5, metric.Compute(), 1e-9)
+}
+
+func TestAveragePrecision(t *testing.T) {
+	metric := metrics.NewAveragePrecision()
+	assert.True(t, math.IsNaN(metric.Compute()))
+	metric.Update(torch.NewTensor([]float32{0.1, 0.4, 0.35, 0.8}), torch.NewTensor([]int64{0, 0, 1, 1}))
+	assert.InDelta(t, 0.5 + 0.5 * 2.0 / 3.0, metric.Compute(), 1e-9)
+}
@@ -38,15 +38,6 @@ import (
 	"github.com/Kautenja/gotorch/internal"
 )
 
-// Free the C-allocated heap memory associated with the given tensor.
-func freeTensor(tensor *torch.Tensor) {
-	if tensor.Pointer == nil {
-		panic("Attempting to free a tensor that has already been freed!")
-	}
-	C.Torch_Tensor_Close((C.Tensor)(tensor.Pointer))
-	tensor.Pointer = nil
-}
-
 // MARK: torch::nn::functional::adaptive_avg_pool1d
 // MARK: torch::nn::functional::adaptive_avg_pool2d
 // MARK: torch::nn::functional::adaptive_avg_pool3d
@@ -135,7 +126,7 @@ func InterpolateSize(
 		C.bool(antialias),
 	)))
 	runtime.KeepAlive(input)
-	runtime.SetFinalizer(output, freeTensor)
+	torch.SetTensorFinalizer(output)
 	return
 }
 
@@ -164,7 +155,7 @@ func InterpolateScale(
 		C.bool(antialias),
 	)))
 	runtime.KeepAlive(input)
-	runtime.SetFinalizer(output, freeTensor)
+	torch.SetTensorFinalizer(output)
 	return
 }
 
@@ -175,7 +166,7 @@ func InterpolateScale(
 // func LeakyRelu(tensor *torch.Tensor, negativeSlope float64) (output *torch.Tensor) {
 // 	output = &torch.Tensor{}
 // 	internal.PanicOnCException(unsafe.Pointer(C.LeakyRelu((C.Tensor)(tensor.Pointer), C.double(negativeSlope), (*C.Tensor)(&output.Pointer))))
-// 	runtime.SetFinalizer(output, freeTensor)
+// 	torch.SetTensorFinalizer(output)
 // 	return
 // }
 
@@ -225,7 +216,7 @@ func Normalize(
 		C.double(eps),
 	)))
 	runtime.KeepAlive(input)
-	runtime.SetFinalizer(output, freeTensor)
+	torch.SetTensorFinalizer(output)
 	return
 }
 
@@ -263,7 +254,7 @@ func Pad(
 		C.double(value_),
 	)))
 	runtime.KeepAlive(input)
-	runtime.SetFinalizer(output, freeTensor)
+	torch.SetTensorFinalizer(output)
 	return
 }
 
@@ -283,7 +274,7 @@ func Relu(input *torch.Tensor, inplace bool) (output *torch.Tensor) {
 		C.bool(inplace),
 	)))
 	runtime.KeepAlive(input)
-	runtime.SetFinalizer(output, freeTensor)
+	torch.SetTensorFinalizer(output)
 	return
 }
 
@@ -304,7 +295,7 @@ func Softmax(input *torch.Tensor, dim int64) (output *torch.Tensor) {
 		C.int64_t(dim),
 	)))
 	runtime.KeepAlive(input)
-	runtime.SetFinalizer(output, freeTensor)
+	torch.SetTensorFinalizer(output)
 	return
 }
 
@@ -0,0 +1,523 @@
+// Readers and writers for the NumPy .npy and .npz file formats.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// The magic string at the start of every .npy file.
+const npyMagic = "\x93NUMPY"
+
+// The alignment of the data in .npy files written by NumPy, in bytes.
+const npyAlignment = 64
+
+// The NumPy element types of tensor data-types as array-protocol type strings
+// without a byte order, i.e., a kind and a size in bytes. ComplexHalf,
+// BFloat16, and the quantized data-types have no NumPy equivalent.
+var npyTypes = map[Dtype]string{
+	Bool:          "b1",
+	Byte:          "u1",
+	Char:          "i1",
+	Short:         "i2",
+	Int:           "i4",
+	Long:          "i8",
+	Half:          "f2",
+	Float:         "f4",
+	Double:        "f8",
+	ComplexFloat:  "c8",
+	ComplexDouble: "c16",
+}
+
+// The byte order of tensor data in memory, i.e., of the host, as a NumPy byte
+// order character: '<' for little-endian or '>' for big-endian.
+var npyNativeByteOrder = func() byte {
+	value := uint16(1)
+	if *(*byte)(unsafe.Pointer(&value)) == 1 {
+		return '<'
+	}
+	return '>'
+}()
+
+// The header of a .npy file that describes the array that follows it.
+type npyHeader struct {
+	// The array-protocol type string of the elements, e.g., "<f4".
+	descr string
+	// Whether the data is in column-major (Fortran) order.
+	fortranOrder bool
+	// The shape of the array, which is empty for scalars.
+	shape []int64
+}
+
+// Return the tensor data-type, item size in bytes, and whether the data must
+// be byte-swapped for an array-protocol type string, e.g., "<f4".
+func parseNpyDescr(descr string) (dtype Dtype, itemSize int, swap bool, err error) {
+	if len(descr) < 3 {
+		return Invalid, 0, false, fmt.Errorf("Unsupported NumPy dtype %q", descr)
+	}
+	order, kind := descr[0], descr[1:]
+	itemSize, err = strconv.Atoi(kind[1:])
+	if err != nil {
+		return Invalid, 0, false, fmt.Errorf("Unsupported NumPy dtype %q", descr)
+	}
+	switch order {
+	case '<', '>':
+		// The order of single bytes is irrelevant.
+		swap = order != npyNativeByteOrder && itemSize > 1
+	case '|', '=':
+	default:
+		return Invalid, 0, false, fmt.Errorf("Unsupported NumPy dtype %q", descr)
+	}
+	for candidate, npyType := range npyTypes {
+		if npyType == kind {
+			return candidate, itemSize, swap, nil
+		}
+	}
+	return Invalid, 0, false, fmt.Errorf("Unsupported NumPy dtype %q", descr)
+}
+
+// Reverse the order of the bytes of each element of data in-place. Complex
+// elements have each of their components reversed separately.
+func npySwapBytes(data []byte, dtype Dtype, itemSize int) {
+	if dtype == ComplexFloat || dtype == ComplexDouble {
+		itemSize /= 2
+	}
+	for start := 0; start < len(data); start += itemSize {
+		item := data[start:start + itemSize]
+		for left, right := 0, itemSize - 1; left < right; left, right = left + 1, right - 1 {
+			item[left], item[right] = item[right], item[left]
+		}
+	}
+}
+
+// A parser for the Python dictionary literal in the header of a .npy file,
+// e.g., "{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3), }".
+type npyHeaderParser struct {
+	text string
+	position int
+}
+
+// Parse the header dictionary of a .npy file.
+func parseNpyHeader(text string) (header npyHeader, err error) {
+	parser := &npyHeaderParser{text: text}
+	found := map[string]bool{}
+	if err = parser.expect('{'); err != nil {
+		return
+	}
+	for !parser.accept('}') {
+		var key string
+		if key, err = parser.parseString(); err != nil {
+			return
+		}
+		if err = parser.expect(':'); err != nil {
+			return
+		}
+		switch key {
+		case "descr":
+			if parser.accept('[') {
+				return header, errors.New("Structured NumPy dtypes are not supported")
+			}
+			header.descr, err = parser.parseString()
+		case "fortran_order":
+			header.fortranOrder, err = parser.parseBool()
+		case "shape":
+			header.shape, err = parser.parseTuple()
+		default:
+			err = fmt.Errorf("Unexpected key %q in NumPy header", key)
+		}
+		if err != nil {
+			return
+		}
+		found[key] = true
+		// Entries are separated by commas with an optional trailing comma.
+		if !parser.accept(',') {
+			if err = parser.expect('}'); err != nil {
+				return
+			}
+			break
+		}
+	}
+	for _, key := range []string{"descr", "fortran_order", "shape"} {
+		if !found[key] {
+			return header, fmt.Errorf("Missing key %q in NumPy header", key)
+		}
+	}
+	return
+}
+
+// Skip the whitespace before the next token.
+func (parser *npyHeaderParser) skipSpace() {
+	for parser.position < len(parser.text) && strings.IndexByte(" \t\r\n", parser.text[parser.position]) >= 0 {
+		parser.position++
+	}
+}
+
+// Consume the next token if it is the given character and return whether it
+// was consumed.
+func (parser *npyHeaderParser) accept(token byte) bool {
+	parser.skipSpace()
+	if parser.position < len(parser.text) && parser.text[parser.position] == token {
+		parser.position++
+		return true
+	}
+	return false
+}
+
+// Consume the next token, which must be the given character.
+func (parser *npyHeaderParser) expect(token byte) error {
+	if !parser.accept(token) {
+		return fmt.Errorf("Expected %q at offset %d of NumPy header %q", token, parser.position, parser.text)
+	}
+	return nil
+}
+
+// Parse a quoted Python string without escape sequences, e.g., '<f4'.
+func (parser *npyHeaderParser) parseString() (string, error) {
+	parser.skipSpace()
+	if parser.position >= len(parser.text) || (parser.text[parser.position] != '\'' && parser.text[parser.position] != '"') {
+		return "", fmt.Errorf("Expected string at offset %d of NumPy header %q", parser.position, parser.text)
+	}
+	quote := parser.text[parser.position]
+	end := strings.IndexByte(parser.text[parser.position + 1:], quote)
+	if end < 0 {
+		return "", fmt.Errorf("Unterminated string in NumPy header %q", parser.text)
+	}
+	value := parser.text[parser.position + 1:parser.position + 1 + end]
+	parser.position += end + 2
+	return value, nil
+}
+
+// Parse a Python boolean, i.e., True or False.
+func (parser *npyHeaderParser) parseBool() (bool, error) {
+	parser.skipSpace()
+	for _, literal := range []string{"True", "False"} {
+		if strings.HasPrefix(parser.text[parser.position:], literal) {
+			parser.position += len(literal)
+			return literal == "True", nil
+		}
+	}
+	return false, fmt.Errorf("Expected boolean at offset %d of NumPy header %q", parser.position, parser.text)
+}
+
+// Parse a Python tuple of non-negative integers, e.g., (2, 3), (3,), or ().
+// Integers may have the "L" suffix of long integers from Python 2.
+func (parser *npyHeaderParser) parseTuple() ([]int64, error) {
+	if err := parser.expect('('); err != nil {
+		return nil, err
+	}
+	values := []int64{}
+	for !parser.accept(')') {
+		parser.skipSpace()
+		start := parser.position
+		for parser.position < len(parser.text) && parser.text[parser.position] >= '0' && parser.text[parser.position] <= '9' {
+			parser.position++
+		}
+		value, err := strconv.ParseInt(parser.text[start:parser.position], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Expected integer at offset %d of NumPy header %q", start, parser.text)
+		}
+		parser.accept('L')
+		values = append(values, value)
+		if !parser.accept(',') {
+			if err := parser.expect(')'); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return values, nil
+}
+
+// Read the header of a .npy file, which follows the magic string, the format
+// version, and the length of the header.
+func readNpyHeader(reader io.Reader) (npyHeader, error) {
+	prefix := make([]byte, len(npyMagic) + 2)
+	if _, err := io.ReadFull(reader, prefix); err != nil {
+		return npyHeader{}, fmt.Errorf("Failed to read NumPy magic string: %w", err)
+	}
+	if string(prefix[:len(npyMagic)]) != npyMagic {
+		return npyHeader{}, errors.New("Invalid NumPy magic string")
+	}
+	var length int
+	switch major := prefix[len(npyMagic)]; major {
+	case 1:
+		var length16 uint16
+		if err := binary.Read(reader, binary.LittleEndian, &length16); err != nil {
+			return npyHeader{}, fmt.Errorf("Failed to read NumPy header length: %w", err)
+		}
+		length = int(length16)
+	case 2, 3:
+		var length32 uint32
+		if err := binary.Read(reader, binary.LittleEndian, &length32); err != nil {
+			return npyHeader{}, fmt.Errorf("Failed to read NumPy header length: %w", err)
+		}
+		length = int(length32)
+	default:
+		return npyHeader{}, fmt.Errorf("Unsupported NumPy format version %d.%d", major, prefix[len(npyMagic) + 1])
+	}
+	text := make([]byte, length)
+	if _, err := io.ReadFull(reader, text); err != nil {
+		return npyHeader{}, fmt.Errorf("Failed to read NumPy header: %w", err)
+	}
+	return parseNpyHeader(string(text))
+}
+
+// Return the number of elements of an array with the given shape, or an error
+// if the size of the array in bytes overflows an int64. Empty arrays are
+// checked too, as libtorch computes the strides of every dimension.
+func npyNumel(shape []int64, itemSize int) (int64, error) {
+	numel, extent := int64(1), int64(itemSize)
+	for _, size := range shape {
+		numel *= size
+		if size == 0 {
+			continue
+		}
+		if extent > math.MaxInt64 / size {
+			return 0, fmt.Errorf("NumPy shape %v overflows the size of a tensor", shape)
+		}
+		extent *= size
+	}
+	return numel, nil
+}
+
+// Read a tensor from an array in the NumPy .npy format, as written by
+// numpy.save. The tensor has the data-type of the array (see WriteNpy) and is
+// contiguous. Arrays in column-major (Fortran) order and arrays in the
+// opposite byte order of the host are converted as they are read. The data is
+// buffered as it is read, so a file shorter than its header claims is an
+// error rather than a large allocation.
+func ReadNpy(reader io.Reader) (*Tensor, error) {
+	return readNpy(reader, math.MaxInt64)
+}
+
+// Read a tensor from an array in the NumPy .npy format whose data is at most
+// maxDataSize bytes (see ReadNpy.)
+func readNpy(reader io.Reader, maxDataSize int64) (*Tensor, error) {
+	header, err := readNpyHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	dtype, itemSize, swap, err := parseNpyDescr(header.descr)
+	if err != nil {
+		return nil, err
+	}
+	numel, err := npyNumel(header.shape, itemSize)
+	if err != nil {
+		return nil, err
+	}
+	if numel == 0 {
+		return Empty(header.shape, NewTensorOptions().Dtype(dtype)), nil
+	}
+	dataSize := numel * int64(itemSize)
+	if dataSize > maxDataSize {
+		return nil, fmt.Errorf("Expected at most %d bytes of NumPy data but the header claims %d", maxDataSize, dataSize)
+	}
+	var buffer bytes.Buffer
+	if read, err := io.CopyN(&buffer, reader, dataSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("Failed to read NumPy data after %d of %d bytes: %w", read, dataSize, err)
+	}
+	data := buffer.Bytes()
+	if swap {
+		npySwapBytes(data, dtype, itemSize)
+	}
+	// Column-major data is the transpose of row-major data with the reversed
+	// shape, which is read as such and then permuted back.
+	shape := make([]int64, len(header.shape))
+	copy(shape, header.shape)
+	if header.fortranOrder {
+		for left, right := 0, len(shape) - 1; left < right; left, right = left + 1, right - 1 {
+			shape[left], shape[right] = shape[right], shape[left]
+		}
+	}
+	if len(shape) == 0 {
+		return NewTensorFromBlob(unsafe.Pointer(&data[0]), dtype, []int64{1}).Squeeze(), nil
+	}
+	tensor := NewTensorFromBlob(unsafe.Pointer(&data[0]), dtype, shape)
+	if header.fortranOrder && len(shape) > 1 {
+		dims := make([]int64, len(shape))
+		for dim := range dims {
+			dims[dim] = int64(len(shape) - 1 - dim)
+		}
+		tensor = tensor.Permute(dims...).Contiguous()
+	}
+	return tensor, nil
+}
+
+// Return the header of a .npy file for a tensor with the given array-protocol
+// type string and shape, including the magic string, format version, and
+// header length. The header is padded so that the data is aligned like it is
+// in files written by NumPy.
+func formatNpyHeader(descr string, shape []int64) []byte {
+	sizes := make([]string, len(shape))
+	for index, size := range shape {
+		sizes[index] = strconv.FormatInt(size, 10)
+	}
+	tuple := strings.Join(sizes, ", ")
+	// Tuples of one element have a trailing comma in Python.
+	if len(shape) == 1 {
+		tuple += ","
+	}
+	dict := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, tuple)
+	// Version 1.0 stores the length of the header in 2 bytes and version 2.0
+	// in 4 bytes for longer headers.
+	version, lengthSize := byte(1), 2
+	if paddedNpyHeaderLength(len(dict), lengthSize) > 1 << 16 - 1 {
+		version, lengthSize = 2, 4
+	}
+	length := paddedNpyHeaderLength(len(dict), lengthSize)
+	var output bytes.Buffer
+	output.WriteString(npyMagic)
+	output.Write([]byte{version, 0})
+	if version == 1 {
+		binary.Write(&output, binary.LittleEndian, uint16(length))
+	} else {
+		binary.Write(&output, binary.LittleEndian, uint32(length))
+	}
+	output.WriteString(dict)
+	output.WriteString(strings.Repeat(" ", length - len(dict) - 1))
+	output.WriteString("\n")
+	return output.Bytes()
+}
+
+// Return the length of a header dictionary of the given length after it is
+// padded with spaces and a newline to align the data that follows it.
+func paddedNpyHeaderLength(length, lengthSize int) int {
+	prefix := len(npyMagic) + 2 + lengthSize
+	total := prefix + length + 1
+	total += (npyAlignment - total % npyAlignment) % npyAlignment
+	return total - prefix
+}
+
+// Write a tensor as an array in the NumPy .npy format, which numpy.load can
+// read. The array has the NumPy equivalent of the data-type of the tensor,
+// e.g., float32 for Float or bool for Bool, in the byte order of the host and
+// in row-major order. Tensors on other devices are copied to the CPU first.
+// ComplexHalf, BFloat16, and quantized tensors have no NumPy equivalent and
+// cannot be written.
+func WriteNpy(writer io.Writer, tensor *Tensor) error {
+	dtype := tensor.Dtype()
+	npyType, ok := npyTypes[dtype]
+	if !ok {
+		return fmt.Errorf("Tensors of dtype %v have no NumPy equivalent", dtype)
+	}
+	order := npyNativeByteOrder
+	if npyType[1:] == "1" {
+		order = '|'
+	}
+	itemSize, _ := strconv.Atoi(npyType[1:])
+	if !tensor.IsCPU() {
+		tensor = tensor.CopyTo(NewDevice("cpu"))
+	}
+	tensor = tensor.Contiguous()
+	shape := tensor.Shape()
+	if _, err := writer.Write(formatNpyHeader(string(order) + npyType, shape)); err != nil {
+		return err
+	}
+	numel := tensor.Numel()
+	if numel == 0 {
+		return nil
+	}
+	data := unsafe.Slice((*byte)(tensor.DataPtr()), numel * int64(itemSize))
+	_, err := writer.Write(data)
+	runtime.KeepAlive(tensor)
+	return err
+}
+
+// Read the tensors of an archive of arrays in the NumPy .npz format, as written
+// by numpy.savez or numpy.savez_compressed, from a reader of the given size.
+// The tensors are keyed by the names of their arrays, i.e., the names of the
+// files in the archive without the ".npy" extension.
+func ReadNpz(reader io.ReaderAt, size int64) (map[string]*Tensor, error) {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read NumPy archive: %w", err)
+	}
+	tensors := make(map[string]*Tensor, len(archive.File))
+	for _, file := range archive.File {
+		name := strings.TrimSuffix(file.Name, ".npy")
+		tensor, err := readNpzFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read array %q: %w", name, err)
+		}
+		tensors[name] = tensor
+	}
+	return tensors, nil
+}
+
+// Read a tensor from a .npy file in a .npz archive. The data of the array
+// cannot be larger than the uncompressed size of the file.
+func readNpzFile(file *zip.File) (*Tensor, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	maxDataSize := int64(math.MaxInt64)
+	if file.UncompressedSize64 < math.MaxInt64 {
+		maxDataSize = int64(file.UncompressedSize64)
+	}
+	return readNpy(reader, maxDataSize)
+}
+
+// Write tensors as an archive of arrays in the NumPy .npz format, which
+// numpy.load can read. Each tensor is written to a file in the archive named
+// by its key with the ".npy" extension, in order of the keys. When compressed
+// is true the files are compressed with DEFLATE like numpy.savez_compressed,
+// otherwise they are stored like numpy.savez.
+func WriteNpz(writer io.Writer, tensors map[string]*Tensor, compressed bool) error {
+	names := make([]string, 0, len(tensors))
+	for name := range tensors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	method := zip.Store
+	if compressed {
+		method = zip.Deflate
+	}
+	archive := zip.NewWriter(writer)
+	for _, name := range names {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: method})
+		if err != nil {
+			return err
+		}
+		if err := WriteNpy(file, tensors[name]); err != nil {
+			return fmt.Errorf("Failed to write array %q: %w", name, err)
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,381 @@
+// test cases for numpy.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+)
+
+// Create the contents of a .npy file with the given format version, header
+// dictionary, and data, like numpy.save would write them.
+func npyFile(version byte, header string, data []byte) []byte {
+	var output bytes.Buffer
+	output.WriteString("\x93NUMPY")
+	output.Write([]byte{version, 0})
+	header += "\n"
+	if version == 1 {
+		binary.Write(&output, binary.LittleEndian, uint16(len(header)))
+	} else {
+		binary.Write(&output, binary.LittleEndian, uint32(len(header)))
+	}
+	output.WriteString(header)
+	output.Write(data)
+	return output.Bytes()
+}
+
+// Return the little-endian bytes of values.
+func littleEndian(values interface{}) []byte {
+	var output bytes.Buffer
+	binary.Write(&output, binary.LittleEndian, values)
+	return output.Bytes()
+}
+
+// MARK: WriteNpy
+
+func TestWriteNpy(t *testing.T) {
+	var output bytes.Buffer
+	tensor := torch.NewTensor([][]float32{{1, 2, 3}, {4, 5, 6}})
+	if !assert.NoError(t, torch.WriteNpy(&output, tensor)) { return }
+	header := "\x93NUMPY\x01\x00v\x00{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3), }"
+	assert.Equal(t, header, output.String()[:len(header)])
+	// The data is aligned to 64 bytes after a newline.
+	assert.Equal(t, byte('\n'), output.Bytes()[127])
+	assert.Equal(t, littleEndian([]float32{1, 2, 3, 4, 5, 6}), output.Bytes()[128:])
+}
+
+func TestWriteNpyShapes(t *testing.T) {
+	for shape, tensor := range map[string]*torch.Tensor{
+		"(3,)": torch.NewTensor([]int64{1, 2, 3}),
+		"()": torch.NewTensor([]int64{1}).Squeeze(),
+		"(2, 0)": torch.Empty([]int64{2, 0}, torch.NewTensorOptions()),
+	} {
+		var output bytes.Buffer
+		if !assert.NoError(t, torch.WriteNpy(&output, tensor)) { return }
+		assert.Contains(t, output.String(), "'shape': " + shape + ", }")
+		assert.Equal(t, 128 + int(tensor.Numel()) * 8, output.Len())
+	}
+}
+
+func TestWriteNpyOfSingleByteDtypes(t *testing.T) {
+	var output bytes.Buffer
+	if !assert.NoError(t, torch.WriteNpy(&output, torch.NewTensor([]bool{true, false}))) { return }
+	assert.Contains(t, output.String(), "'descr': '|b1'")
+	assert.Equal(t, []byte{1, 0}, output.Bytes()[output.Len() - 2:])
+}
+
+func TestWriteNpyOfNonContiguousTensor(t *testing.T) {
+	var output bytes.Buffer
+	tensor := torch.NewTensor([][]int32{{1, 2, 3}, {4, 5, 6}}).Transpose(0, 1)
+	if !assert.NoError(t, torch.WriteNpy(&output, tensor)) { return }
+	assert.Contains(t, output.String(), "'shape': (3, 2), }")
+	assert.Equal(t, littleEndian([]int32{1, 4, 2, 5, 3, 6}), output.Bytes()[output.Len() - 24:])
+}
+
+func TestWriteNpyErrorsOnDtypeWithoutNumPyEquivalent(t *testing.T) {
+	var output bytes.Buffer
+	tensor := torch.NewTensor([]float32{1}).CastTo(torch.BFloat16)
+	assert.EqualError(t, torch.WriteNpy(&output, tensor), "Tensors of dtype 15 have no NumPy equivalent")
+	assert.Equal(t, 0, output.Len())
+}
+
+// MARK: ReadNpy
+
+func TestReadNpy(t *testing.T) {
+	data := npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 2), }", littleEndian([]float64{1, 2, 3, 4}))
+	tensor, err := torch.ReadNpy(bytes.NewReader(data))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, torch.Double, tensor.Dtype())
+	assert.Equal(t, [][]float64{{1, 2}, {3, 4}}, tensor.ToNestedSlice())
+}
+
+func TestReadNpyRoundTrip(t *testing.T) {
+	for _, tensor := range []*torch.Tensor{
+		torch.NewTensor([]bool{true, false, true}),
+		torch.NewTensor([][]uint8{{1, 2}, {3, 255}}),
+		torch.NewTensor([]int8{-1, 2}),
+		torch.NewTensor([]int16{-300, 2}),
+		torch.NewTensor([]int32{-70000, 2}),
+		torch.NewTensor([][][]int64{{{1 << 40}, {2}}}),
+		torch.NewTensor([]float32{0.5, -1}).CastTo(torch.Half),
+		torch.NewTensor([][]float32{{0.5, -1}, {1e30, 0}}),
+		torch.NewTensor([]float64{0.5, -1e300}),
+		torch.NewTensor([]complex64{complex(1, 2), complex(-3, 4)}),
+		torch.NewTensor([]complex128{complex(1, 2), complex(-3, 4)}),
+		torch.NewTensor([]float32{7}).Squeeze(),
+		torch.Empty([]int64{2, 0, 3}, torch.NewTensorOptions()),
+	} {
+		var output bytes.Buffer
+		if !assert.NoError(t, torch.WriteNpy(&output, tensor)) { return }
+		input, err := torch.ReadNpy(&output)
+		if !assert.NoError(t, err) { return }
+		assert.Equal(t, tensor.Dtype(), input.Dtype())
+		assert.Equal(t, tensor.Shape(), input.Shape())
+		assert.True(t, input.Equal(tensor), "Expected %v got %v", tensor, input)
+	}
+}
+
+func TestReadNpyOfBigEndianData(t *testing.T) {
+	var data bytes.Buffer
+	binary.Write(&data, binary.BigEndian, []int32{1, 258, -2})
+	tensor, err := torch.ReadNpy(bytes.NewReader(npyFile(1, "{'descr': '>i4', 'fortran_order': False, 'shape': (3,), }", data.Bytes())))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int32{1, 258, -2}, tensor.ToSlice())
+}
+
+func TestReadNpyOfBigEndianComplexData(t *testing.T) {
+	var data bytes.Buffer
+	binary.Write(&data, binary.BigEndian, []float32{1, 2, -3, 4})
+	tensor, err := torch.ReadNpy(bytes.NewReader(npyFile(1, "{'descr': '>c8', 'fortran_order': False, 'shape': (2,), }", data.Bytes())))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []complex64{complex(1, 2), complex(-3, 4)}, tensor.ToSlice())
+}
+
+func TestReadNpyOfFortranOrderData(t *testing.T) {
+	data := npyFile(1, "{'descr': '<i2', 'fortran_order': True, 'shape': (2, 3), }", littleEndian([]int16{1, 4, 2, 5, 3, 6}))
+	tensor, err := torch.ReadNpy(bytes.NewReader(data))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]int16{{1, 2, 3}, {4, 5, 6}}, tensor.ToNestedSlice())
+	assert.True(t, tensor.IsContiguous())
+}
+
+func TestReadNpyOfFortranOrder3DData(t *testing.T) {
+	// np.asfortranarray(np.arange(8).reshape(2, 2, 2)) in memory order.
+	data := npyFile(1, "{'descr': '<i8', 'fortran_order': True, 'shape': (2, 2, 2), }", littleEndian([]int64{0, 4, 2, 6, 1, 5, 3, 7}))
+	tensor, err := torch.ReadNpy(bytes.NewReader(data))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][][]int64{{{0, 1}, {2, 3}}, {{4, 5}, {6, 7}}}, tensor.ToNestedSlice())
+}
+
+func TestReadNpyOfVersion2AndPython2Headers(t *testing.T) {
+	data := npyFile(2, "{'descr': '<u1', 'fortran_order': False, 'shape': (1L, 2L), }", []byte{3, 4})
+	tensor, err := torch.ReadNpy(bytes.NewReader(data))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]uint8{{3, 4}}, tensor.ToNestedSlice())
+}
+
+func TestReadNpyOfScalar(t *testing.T) {
+	data := npyFile(1, "{'descr': '<f4', 'fortran_order': False, 'shape': (), }", littleEndian([]float32{2.5}))
+	tensor, err := torch.ReadNpy(bytes.NewReader(data))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{}, tensor.Shape())
+	assert.Equal(t, float32(2.5), tensor.Item())
+}
+
+func TestReadNpyErrors(t *testing.T) {
+	_, err := torch.ReadNpy(bytes.NewReader([]byte("\x93NUMPZ\x01\x00")))
+	assert.EqualError(t, err, "Invalid NumPy magic string")
+	_, err = torch.ReadNpy(bytes.NewReader(npyFile(1, "{'descr': '<U3', 'fortran_order': False, 'shape': (1,), }", nil)))
+	assert.EqualError(t, err, "Unsupported NumPy dtype \"<U3\"")
+	_, err = torch.ReadNpy(bytes.NewReader(npyFile(1, "{'descr': [('a', '<f4')], 'fortran_order': False, 'shape': (1,), }", nil)))
+	assert.EqualError(t, err, "Structured NumPy dtypes are not supported")
+	_, err = torch.ReadNpy(bytes.NewReader(npyFile(1, "{'descr': '<f4', 'shape': (1,), }", nil)))
+	assert.EqualError(t, err, "Missing key \"fortran_order\" in NumPy header")
+	_, err = torch.ReadNpy(bytes.NewReader(npyFile(1, "{'descr': '<f4', 'fortran_order': False, 'shape': (2,), }", []byte{0, 0, 0, 0})))
+	assert.EqualError(t, err, "Failed to read NumPy data after 4 of 8 bytes: unexpected EOF")
+}
+
+func TestReadNpyErrorsOnShortDataOfHugeShape(t *testing.T) {
+	// The header claims 8 TiB of data that the file does not have.
+	data := npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (1099511627776,), }", littleEndian([]float64{1, 2}))
+	_, err := torch.ReadNpy(bytes.NewReader(data))
+	assert.EqualError(t, err, "Failed to read NumPy data after 16 of 8796093022208 bytes: unexpected EOF")
+}
+
+func TestReadNpyErrorsOnOverflowingShape(t *testing.T) {
+	for _, shape := range []string{
+		"(4611686018427387904, 4)",
+		"(1152921504606846976,)",
+		"(0, 4611686018427387904, 4)",
+		"(3037000500, 3037000500, 0)",
+	} {
+		data := npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': " + shape + ", }", nil)
+		_, err := torch.ReadNpy(bytes.NewReader(data))
+		assert.ErrorContains(t, err, "overflows the size of a tensor", shape)
+	}
+}
+
+// MARK: WriteNpz
+
+func TestWriteNpz(t *testing.T) {
+	tensors := map[string]*torch.Tensor{
+		"b": torch.NewTensor([]int64{1, 2}),
+		"a": torch.NewTensor([][]float32{{1, 2}, {3, 4}}),
+	}
+	for _, compressed := range []bool{false, true} {
+		var output bytes.Buffer
+		if !assert.NoError(t, torch.WriteNpz(&output, tensors, compressed)) { return }
+		archive, err := zip.NewReader(bytes.NewReader(output.Bytes()), int64(output.Len()))
+		if !assert.NoError(t, err) { return }
+		if !assert.Len(t, archive.File, 2) { return }
+		assert.Equal(t, "a.npy", archive.File[0].Name)
+		assert.Equal(t, "b.npy", archive.File[1].Name)
+		method := zip.Store
+		if compressed {
+			method = zip.Deflate
+		}
+		assert.Equal(t, method, archive.File[0].Method)
+	}
+}
+
+// MARK: ReadNpz
+
+func TestReadNpzRoundTrip(t *testing.T) {
+	tensors := map[string]*torch.Tensor{
+		"embeddings": torch.Rand([]int64{4, 8}, torch.NewTensorOptions()),
+		"labels": torch.NewTensor([]int64{0, 1, 1, 0}),
+		"mask": torch.NewTensor([]bool{true, false, true, true}),
+	}
+	for _, compressed := range []bool{false, true} {
+		var output bytes.Buffer
+		if !assert.NoError(t, torch.WriteNpz(&output, tensors, compressed)) { return }
+		inputs, err := torch.ReadNpz(bytes.NewReader(output.Bytes()), int64(output.Len()))
+		if !assert.NoError(t, err) { return }
+		if !assert.Len(t, inputs, len(tensors)) { return }
+		for name, tensor := range tensors {
+			assert.True(t, inputs[name].Equal(tensor), "Expected %v got %v for %s", tensor, inputs[name], name)
+		}
+	}
+}
+
+func TestReadNpzOfCompressedArchive(t *testing.T) {
+	// An archive like numpy.savez_compressed(file, x=x) writes.
+	var output bytes.Buffer
+	archive := zip.NewWriter(&output)
+	file, err := archive.CreateHeader(&zip.FileHeader{Name: "x.npy", Method: zip.Deflate})
+	if !assert.NoError(t, err) { return }
+	file.Write(npyFile(1, "{'descr': '<i4', 'fortran_order': False, 'shape': (3,), }", littleEndian([]int32{1, 2, 3})))
+	if !assert.NoError(t, archive.Close()) { return }
+	tensors, err := torch.ReadNpz(bytes.NewReader(output.Bytes()), int64(output.Len()))
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int32{1, 2, 3}, tensors["x"].ToSlice())
+}
+
+func TestReadNpzErrors(t *testing.T) {
+	_, err := torch.ReadNpz(bytes.NewReader([]byte("not a zip")), 9)
+	assert.Error(t, err)
+	var output bytes.Buffer
+	archive := zip.NewWriter(&output)
+	file, _ := archive.Create("x.npy")
+	file.Write([]byte("not a npy file"))
+	archive.Close()
+	_, err = torch.ReadNpz(bytes.NewReader(output.Bytes()), int64(output.Len()))
+	assert.EqualError(t, err, "Failed to read array \"x\": Invalid NumPy magic string")
+}
+
+func TestReadNpzErrorsOnDataLargerThanFile(t *testing.T) {
+	var output bytes.Buffer
+	archive := zip.NewWriter(&output)
+	file, _ := archive.CreateHeader(&zip.FileHeader{Name: "x.npy", Method: zip.Deflate})
+	file.Write(npyFile(1, "{'descr': '<f8', 'fortran_order': False, 'shape': (1099511627776,), }", littleEndian([]float64{1})))
+	archive.Close()
+	_, err := torch.ReadNpz(bytes.NewReader(output.Bytes()), int64(output.Len()))
+	assert.EqualError(t, err, "Failed to read array \"x\": Expected at most 88 bytes of NumPy data but the header claims 8796093022208")
+}
+
+// MARK: NumPy fixtures
+
+// Read a .npy file written by scripts/numpy_save_test_cases.py.
+func readNpyFixture(name string) (*torch.Tensor, error) {
+	file, err := os.Open("data/" + name + ".npy")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return torch.ReadNpy(file)
+}
+
+func TestReadNpyOfNumPyFiles(t *testing.T) {
+	matrix := torch.NewTensor([][]float64{{0, 1, 2}, {3, 4, 5}})
+	for name, dtype := range map[string]torch.Dtype{
+		"npy_u1":  torch.Byte,
+		"npy_i1":  torch.Char,
+		"npy_i2":  torch.Short,
+		"npy_i4":  torch.Int,
+		"npy_i8":  torch.Long,
+		"npy_f2":  torch.Half,
+		"npy_f4":  torch.Float,
+		"npy_f8":  torch.Double,
+		"npy_c8":  torch.ComplexFloat,
+		"npy_c16": torch.ComplexDouble,
+	} {
+		tensor, err := readNpyFixture(name)
+		if !assert.NoError(t, err, name) { continue }
+		assert.Equal(t, dtype, tensor.Dtype(), name)
+		expected := matrix.CastTo(dtype)
+		assert.True(t, tensor.Equal(expected), "Expected %v got %v for %s", expected, tensor, name)
+	}
+	tensor, err := readNpyFixture("npy_b1")
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]bool{{false, true, false}, {true, false, true}}, tensor.ToNestedSlice())
+}
+
+func TestReadNpyOfNumPyFortranOrderFiles(t *testing.T) {
+	tensor, err := readNpyFixture("npy_fortran_order")
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]float32{{0, 1, 2}, {3, 4, 5}}, tensor.ToNestedSlice())
+	tensor, err = readNpyFixture("npy_fortran_order_3d")
+	if !assert.NoError(t, err) { return }
+	expected := torch.Arange(0, 24, 1, torch.NewTensorOptions().Dtype(torch.Long)).View(2, 3, 4)
+	assert.True(t, tensor.Equal(expected), "Expected %v got %v", expected, tensor)
+	assert.True(t, tensor.IsContiguous())
+}
+
+func TestReadNpyOfNumPyBigEndianFiles(t *testing.T) {
+	tensor, err := readNpyFixture("npy_big_endian_f8")
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]float64{{0, 1, 2}, {3, 4, 5}}, tensor.ToNestedSlice())
+	tensor, err = readNpyFixture("npy_big_endian_c16")
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, [][]complex128{{0, complex(1, 1), complex(2, 2)}, {complex(3, 3), complex(4, 4), complex(5, 5)}}, tensor.ToNestedSlice())
+}
+
+func TestReadNpyOfNumPyScalarAndEmptyFiles(t *testing.T) {
+	tensor, err := readNpyFixture("npy_scalar")
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{}, tensor.Shape())
+	assert.Equal(t, float32(2.5), tensor.Item())
+	tensor, err = readNpyFixture("npy_empty")
+	if !assert.NoError(t, err) { return }
+	assert.Equal(t, []int64{2, 0, 3}, tensor.Shape())
+	assert.Equal(t, torch.Float, tensor.Dtype())
+}
+
+func TestReadNpzOfNumPyFiles(t *testing.T) {
+	for _, name := range []string{"npz_savez", "npz_savez_compressed"} {
+		data, err := os.ReadFile("data/" + name + ".npz")
+		if !assert.NoError(t, err) { return }
+		tensors, err := torch.ReadNpz(bytes.NewReader(data), int64(len(data)))
+		if !assert.NoError(t, err, name) { return }
+		if !assert.Len(t, tensors, 3, name) { return }
+		assert.Equal(t, [][]float32{{0, 1, 2}, {3, 4, 5}}, tensors["matrix"].ToNestedSlice())
+		assert.Equal(t, []int64{0, 1, 1, 0}, tensors["labels"].ToSlice())
+		assert.Equal(t, []bool{true, false, true, true}, tensors["mask"].ToSlice())
+	}
+}
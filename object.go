@@ -0,0 +1,212 @@
+// Go bindings for TorchScript objects and enums held by IValues.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch
+
+// #cgo CPPFLAGS: -I/usr/local/include -I/usr/local/include/cgotorch
+// #cgo LDFLAGS: -L/usr/local/lib -lc10 -ltorch_cpu -ltorch -lcgotorch
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// An instance of a TorchScript class, e.g., an object of a class decorated
+// with @torch.jit.script or a custom class registered with torch::class_.
+// Objects are references: changes made with SetAttr or by methods are
+// visible to every IValue that holds the same object.
+type Object struct {
+	ivalue *IValue
+}
+
+// Convert the IValue to a TorchScript object. Custom classes and modules are
+// objects too, although the attributes of custom classes are not accessible.
+// ToObject panics if the IValue does not hold an object.
+func (ivalue *IValue) ToObject() *Object {
+	if !ivalue.IsObject() {
+		panic(fmt.Sprintf("Expected IValue to hold an object but found %s", ivalue.TypeName()))
+	}
+	return &Object{ivalue}
+}
+
+// Return the IValue that holds the object, e.g., to pass the object as an
+// input to a method.
+func (object *Object) IValue() *IValue {
+	return object.ivalue
+}
+
+// Return the qualified name of the class of the object, e.g.,
+// "__torch__.Tokenizer".
+func (object *Object) TypeName() string {
+	var output *C.char
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_TypeName(&output, object.ivalue.Pointer)))
+	runtime.KeepAlive(object)
+	defer C.free(unsafe.Pointer(output))
+	return C.GoString(output)
+}
+
+// Return the names of the attributes of the object in the order that they
+// are declared.
+func (object *Object) AttrNames() []string {
+	var length C.int64_t
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_NumAttrs(&length, object.ivalue.Pointer)))
+	if length == 0 { return []string{} }
+	names := make([]*C.char, length)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_AttrNames(&names[0], length, object.ivalue.Pointer)))
+	runtime.KeepAlive(object)
+	return goStrings(names)
+}
+
+// Return true if the object has an attribute with the given name.
+func (object *Object) HasAttr(name string) bool {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_HasAttr(&output, object.ivalue.Pointer, name_cstring)))
+	runtime.KeepAlive(object)
+	return bool(output)
+}
+
+// Return the value of the attribute with the given name. Attr panics if the
+// object does not have the attribute.
+func (object *Object) Attr(name string) *IValue {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	output := &IValue{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_Attr(&output.Pointer, object.ivalue.Pointer, name_cstring)))
+	runtime.KeepAlive(object)
+	SetIValueFinalizer(output)
+	return output
+}
+
+// Set the value of an existing attribute of the object. The value is
+// converted with NewIValue. SetAttr panics if the object does not have the
+// attribute or if the type of the value does not match the type of the
+// attribute.
+func (object *Object) SetAttr(name string, value interface{}) *Object {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	ivalue := NewIValue(value)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_SetAttr(object.ivalue.Pointer, name_cstring, ivalue.Pointer)))
+	runtime.KeepAlive(object)
+	runtime.KeepAlive(ivalue)
+	return object
+}
+
+// Return the names of the methods of the object.
+func (object *Object) MethodNames() []string {
+	var length C.int64_t
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_NumMethods(&length, object.ivalue.Pointer)))
+	if length == 0 { return []string{} }
+	names := make([]*C.char, length)
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_MethodNames(&names[0], length, object.ivalue.Pointer)))
+	runtime.KeepAlive(object)
+	return goStrings(names)
+}
+
+// Return true if the object has a method with the given name.
+func (object *Object) HasMethod(name string) bool {
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Object_HasMethod(&output, object.ivalue.Pointer, name_cstring)))
+	runtime.KeepAlive(object)
+	return bool(output)
+}
+
+// Call the method with the given name and return its output. The arguments
+// are converted with NewIValue, e.g.,
+//
+//	ids, err := tokenizer.CallMethod("encode", "hello world")
+//
+// CallMethod returns an error if the object does not have the method, if the
+// arguments do not match its schema, or if it raises an exception.
+func (object *Object) CallMethod(name string, args ...interface{}) (*IValue, error) {
+	if !object.HasMethod(name) {
+		return nil, fmt.Errorf("Object of type %s does not have a method named %q", object.TypeName(), name)
+	}
+	name_cstring := C.CString(name)
+	defer C.free(unsafe.Pointer(name_cstring))
+	inputs := make([]*IValue, len(args))
+	for index, arg := range args {
+		inputs[index] = NewIValue(arg)
+	}
+	pointers := newCIValues(inputs)
+	output := &IValue{}
+	err := unsafe.Pointer(C.Torch_Object_RunMethod(
+		&output.Pointer,
+		object.ivalue.Pointer,
+		name_cstring,
+		&pointers[0],
+		C.int64_t(len(inputs)),
+	))
+	runtime.KeepAlive(object)
+	runtime.KeepAlive(inputs)
+	if err != nil {
+		return nil, internal.NewTorchError(err)
+	}
+	SetIValueFinalizer(output)
+	return output, nil
+}
+
+// Convert C strings to Go strings and free the C strings.
+func goStrings(cstrings []*C.char) []string {
+	output := make([]string, len(cstrings))
+	for index, cstring := range cstrings {
+		output[index] = C.GoString(cstring)
+		C.free(unsafe.Pointer(cstring))
+	}
+	return output
+}
+
+// ---------------------------------------------------------------------------
+// MARK: Enums
+// ---------------------------------------------------------------------------
+
+// A member of a TorchScript enum, i.e., a subclass of enum.Enum.
+type Enum struct {
+	// The qualified name of the enum class, e.g., "__torch__.Color".
+	TypeName string
+	// The name of the member, e.g., "RED".
+	Name string
+	// The value of the member, which is an int, float, or str.
+	Value *IValue
+}
+
+// Convert the IValue to a member of a TorchScript enum. ToEnum panics if the
+// IValue does not hold an enum.
+func (ivalue *IValue) ToEnum() *Enum {
+	var typeName, name *C.char
+	value := &IValue{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_IValue_ToEnum(&typeName, &name, &value.Pointer, ivalue.Pointer)))
+	runtime.KeepAlive(ivalue)
+	defer C.free(unsafe.Pointer(typeName))
+	defer C.free(unsafe.Pointer(name))
+	SetIValueFinalizer(value)
+	return &Enum{C.GoString(typeName), C.GoString(name), value}
+}
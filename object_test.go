@@ -0,0 +1,55 @@
+// test cases for object.go
+//
+// Copyright (c) 2023 Christian Kauten
+// Copyright (c) 2022 Sensory, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package torch_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+)
+
+// Objects and enums are created by TorchScript, see the scripted module test
+// cases in the jit package.
+
+func TestIValueToObjectPanicsForNonObject(t *testing.T) {
+	assert.Panics(t, func() { torch.NewIValue(1).ToObject() })
+}
+
+func TestIValueToEnumPanicsForNonEnum(t *testing.T) {
+	assert.Panics(t, func() { torch.NewIValue("GREEN").ToEnum() })
+}
+
+func TestUnmarshalIValueNoneToObject(t *testing.T) {
+	var object *torch.Object
+	assert.Nil(t, torch.UnmarshalIValue(torch.None(), &object))
+	assert.Nil(t, object)
+	assert.NotNil(t, torch.UnmarshalIValue(torch.NewIValue(1), &object))
+}
+
+func TestMarshalIValueNilObject(t *testing.T) {
+	var object *torch.Object
+	ivalue, err := torch.MarshalIValue(object)
+	assert.Nil(t, err)
+	assert.True(t, ivalue.IsNil())
+}
@@ -0,0 +1,172 @@
+// Scope-based deterministic memory management for tensors.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch
+
+// #cgo CPPFLAGS: -I/usr/local/include -I/usr/local/include/cgotorch
+// #cgo LDFLAGS: -L/usr/local/lib -lc10 -ltorch_cpu -ltorch -lcgotorch
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// A Scope tracks the tensors created by a goroutine so they can be freed
+// deterministically when the scope ends instead of when the garbage collector
+// finalizes them. Scopes are created with WithScope and WithScopeResult.
+type Scope struct {
+	// The enclosing scope of the same goroutine, if any.
+	parent *Scope
+	// The ID of the scope stored in the thread-local state of libcgotorch.
+	id int64
+	mutex sync.Mutex
+	tensors []*Tensor
+	kept map[*Tensor]bool
+}
+
+// The active scopes by ID, the last ID handed out, and the number of active
+// scopes. A goroutine is locked to its OS thread while it has an active scope,
+// so the thread-local scope ID identifies the goroutine without parsing its
+// stack. The count allows tensor creation to skip the lookup entirely when no
+// scope is in use.
+var (
+	scopes sync.Map
+	lastScopeID int64
+	activeScopes int64
+)
+
+// Return the active scope of the calling goroutine, if any. A stale
+// thread-local ID without an active scope, e.g., on a reused locked thread, is
+// treated as no scope.
+func currentScope() *Scope {
+	id := int64(C.Torch_Scope_ThreadScope())
+	if id == 0 {
+		return nil
+	}
+	value, ok := scopes.Load(id)
+	if !ok {
+		return nil
+	}
+	scope, ok := value.(*Scope)
+	if !ok {
+		return nil
+	}
+	return scope
+}
+
+// Attach the garbage collection finalizer to a newly created tensor, count it
+// in MemoryStats, record its allocation site for leak detection, and track it
+// in the active scope of the calling goroutine, if any. Every function that
+// creates a Tensor should call this once the tensor's Pointer is set.
+func SetTensorFinalizer(tensor *Tensor) {
+	runtime.SetFinalizer(tensor, (*Tensor).free)
+	internal.TrackObject("Tensor", unsafe.Pointer(tensor))
+	atomic.AddInt64(&liveTensors, 1)
+	maybeCollectGarbage()
+	if atomic.LoadInt64(&activeScopes) == 0 {
+		return
+	}
+	if scope := currentScope(); scope != nil {
+		scope.Track(tensor)
+	}
+}
+
+// Open a new scope for the calling goroutine nested in its active scope. The
+// goroutine stays locked to its OS thread until the scope exits.
+func enterScope() *Scope {
+	runtime.LockOSThread()
+	scope := &Scope{id: atomic.AddInt64(&lastScopeID, 1), kept: make(map[*Tensor]bool)}
+	scope.parent = currentScope()
+	scopes.Store(scope.id, scope)
+	C.Torch_Scope_SetThreadScope(C.int64_t(scope.id))
+	atomic.AddInt64(&activeScopes, 1)
+	return scope
+}
+
+// Close the scope, freeing every tracked tensor that was not kept. Kept
+// tensors are handed to the enclosing scope, if any.
+func (scope *Scope) exit() {
+	if scope.parent == nil {
+		C.Torch_Scope_SetThreadScope(0)
+	} else {
+		C.Torch_Scope_SetThreadScope(C.int64_t(scope.parent.id))
+	}
+	scopes.Delete(scope.id)
+	atomic.AddInt64(&activeScopes, -1)
+	runtime.UnlockOSThread()
+	scope.mutex.Lock()
+	tensors, kept := scope.tensors, scope.kept
+	scope.tensors, scope.kept = nil, make(map[*Tensor]bool)
+	scope.mutex.Unlock()
+	for _, tensor := range tensors {
+		if !kept[tensor] {
+			tensor.Free()
+		} else if scope.parent != nil {
+			scope.parent.Track(tensor)
+		}
+	}
+}
+
+// Track a tensor in the scope so that it is freed when the scope ends unless
+// it is kept. Tensors created by the scope's goroutine are tracked
+// automatically; Track is for tensors created elsewhere, e.g., by goroutines
+// started inside the scope.
+func (scope *Scope) Track(tensor *Tensor) {
+	scope.mutex.Lock()
+	defer scope.mutex.Unlock()
+	scope.tensors = append(scope.tensors, tensor)
+}
+
+// Keep tensors alive past the end of the scope. Kept tensors are tracked by
+// the enclosing scope, if any, or otherwise left to the garbage collector.
+func (scope *Scope) Keep(tensors ...*Tensor) {
+	scope.mutex.Lock()
+	defer scope.mutex.Unlock()
+	for _, tensor := range tensors {
+		scope.kept[tensor] = true
+	}
+}
+
+// Run body in a new scope and free every tensor created by the calling
+// goroutine inside of it, except those kept with Scope.Keep, once body
+// returns or panics. Tensors created by other goroutines are not tracked
+// unless they are added with Scope.Track. Scopes may be nested. The calling
+// goroutine is locked to its OS thread while body runs.
+func WithScope(body func(scope *Scope)) {
+	scope := enterScope()
+	defer scope.exit()
+	body(scope)
+}
+
+// Run body in a new scope like WithScope and keep the tensor it returns.
+func WithScopeResult(body func(scope *Scope) *Tensor) (output *Tensor) {
+	WithScope(func(scope *Scope) {
+		output = body(scope)
+		scope.Keep(output)
+	})
+	return
+}
@@ -0,0 +1,114 @@
+// test cases for scope.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch_test
+
+import (
+	"sync"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+)
+
+func TestWithScopeFreesTensorsCreatedInside(t *testing.T) {
+	var a, b *torch.Tensor
+	torch.WithScope(func(scope *torch.Scope) {
+		a = torch.Ones([]int64{2, 2}, torch.NewTensorOptions())
+		b = a.Add(a, 1)
+		assert.NotNil(t, b.Pointer)
+	})
+	assert.Nil(t, a.Pointer)
+	assert.Nil(t, b.Pointer)
+}
+
+func TestWithScopeDoesNotFreeTensorsCreatedOutside(t *testing.T) {
+	tensor := torch.Ones([]int64{2}, torch.NewTensorOptions())
+	torch.WithScope(func(scope *torch.Scope) {
+		tensor.Add(tensor, 1)
+	})
+	assert.NotNil(t, tensor.Pointer)
+}
+
+func TestWithScopeKeepsTensors(t *testing.T) {
+	var kept, freed *torch.Tensor
+	torch.WithScope(func(scope *torch.Scope) {
+		kept = torch.Ones([]int64{2}, torch.NewTensorOptions())
+		freed = torch.Zeros([]int64{2}, torch.NewTensorOptions())
+		scope.Keep(kept)
+	})
+	assert.NotNil(t, kept.Pointer)
+	assert.Nil(t, freed.Pointer)
+	assert.Equal(t, []float32{1, 1}, kept.ToSlice())
+}
+
+func TestWithScopeResultKeepsReturnedTensor(t *testing.T) {
+	var intermediate *torch.Tensor
+	output := torch.WithScopeResult(func(scope *torch.Scope) *torch.Tensor {
+		intermediate = torch.Ones([]int64{2}, torch.NewTensorOptions())
+		return intermediate.Add(intermediate, 1)
+	})
+	assert.Nil(t, intermediate.Pointer)
+	assert.Equal(t, []float32{2, 2}, output.ToSlice())
+}
+
+func TestWithScopeHandsKeptTensorsToEnclosingScope(t *testing.T) {
+	var inner *torch.Tensor
+	torch.WithScope(func(outer *torch.Scope) {
+		inner = torch.WithScopeResult(func(scope *torch.Scope) *torch.Tensor {
+			return torch.Ones([]int64{2}, torch.NewTensorOptions())
+		})
+		assert.NotNil(t, inner.Pointer)
+	})
+	assert.Nil(t, inner.Pointer)
+}
+
+func TestWithScopeFreesTensorsOnPanic(t *testing.T) {
+	var tensor *torch.Tensor
+	assert.Panics(t, func() {
+		torch.WithScope(func(scope *torch.Scope) {
+			tensor = torch.Ones([]int64{2}, torch.NewTensorOptions())
+			panic("failure")
+		})
+	})
+	assert.Nil(t, tensor.Pointer)
+}
+
+func TestWithScopeIgnoresOtherGoroutines(t *testing.T) {
+	var tracked, untracked *torch.Tensor
+	torch.WithScope(func(scope *torch.Scope) {
+		var group sync.WaitGroup
+		group.Add(2)
+		go func() {
+			defer group.Done()
+			untracked = torch.Ones([]int64{2}, torch.NewTensorOptions())
+		}()
+		go func() {
+			defer group.Done()
+			tracked = torch.Ones([]int64{2}, torch.NewTensorOptions())
+			scope.Track(tracked)
+		}()
+		group.Wait()
+	})
+	assert.NotNil(t, untracked.Pointer)
+	assert.Nil(t, tracked.Pointer)
+}
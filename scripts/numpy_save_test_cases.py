@@ -0,0 +1,45 @@
+#!/usr/bin/env python
+import os
+import numpy as np
+
+
+PACKAGE = os.path.dirname(os.path.abspath(__file__))
+DATA = os.path.join(os.path.split(PACKAGE)[0], 'data')
+
+
+def save(name, array):
+    output_path = os.path.join(DATA, f'{name}.npy')
+    np.save(output_path, array)
+    print(f"saved array to {output_path}")
+
+
+def savez(name, function, **arrays):
+    output_path = os.path.join(DATA, f'{name}.npz')
+    function(output_path, **arrays)
+    print(f"saved archive to {output_path}")
+
+
+# An array of each dtype that has a tensor equivalent.
+matrix = np.arange(6).reshape(2, 3)
+for dtype in ['u1', 'i1', 'i2', 'i4', 'i8', 'f2', 'f4', 'f8', 'c8', 'c16']:
+    save(f'npy_{dtype}', matrix.astype(dtype))
+save('npy_b1', matrix % 2 == 1)
+
+# Arrays in column-major (Fortran) order and in big-endian byte order.
+save('npy_fortran_order', np.asfortranarray(matrix.astype('f4')))
+save('npy_fortran_order_3d', np.asfortranarray(np.arange(24).reshape(2, 3, 4)))
+save('npy_big_endian_f8', matrix.astype('>f8'))
+save('npy_big_endian_c16', (matrix + 1j * matrix).astype('>c16'))
+
+# Arrays of a single element and without elements.
+save('npy_scalar', np.array(2.5, dtype='f4'))
+save('npy_empty', np.zeros((2, 0, 3), dtype='f4'))
+
+# Archives of arrays, stored and compressed.
+arrays = {
+    'matrix': matrix.astype('f4'),
+    'labels': np.array([0, 1, 1, 0]),
+    'mask': np.array([True, False, True, True]),
+}
+savez('npz_savez', np.savez, **arrays)
+savez('npz_savez_compressed', np.savez_compressed, **arrays)
@@ -0,0 +1,15 @@
+#!/usr/bin/env python
+import os
+import torch
+from torch import nn
+
+
+PACKAGE = os.path.dirname(os.path.abspath(__file__))
+DATA = os.path.join(os.path.split(PACKAGE)[0], 'data')
+
+
+model = nn.Sequential(nn.Conv2d(1, 2, 3), nn.BatchNorm2d(2), nn.ReLU()).eval()
+model = torch.jit.script(model)
+output_path = os.path.join(DATA, 'script_conv_bn.pt')
+model.save(output_path)
+print(f"saved model to {output_path}")
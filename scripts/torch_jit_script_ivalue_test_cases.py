@@ -1,5 +1,6 @@
 #!/usr/bin/env python
-from typing import List, Tuple, Dict
+from typing import List, Tuple, Dict, Optional, NamedTuple
+from enum import Enum
 import os
 import torch
 from torch import nn
@@ -91,3 +92,99 @@ script(module_that_returns_dict_bool_key)
 #     def forward(self, _: torch.Tensor) -> Dict[torch.Tensor, str]:
 #         return {}
 # script(module_that_returns_dict_tensor_key)
+
+class module_that_returns_int_list(nn.Module):
+    def forward(self, _: torch.Tensor) -> List[int]:
+        return [1 << 40, -3]
+script(module_that_returns_int_list)
+
+class module_that_returns_float_list(nn.Module):
+    def forward(self, _: torch.Tensor) -> List[float]:
+        return [1.5, -2.5]
+script(module_that_returns_float_list)
+
+class module_that_returns_bool_list(nn.Module):
+    def forward(self, _: torch.Tensor) -> List[bool]:
+        return [True, False]
+script(module_that_returns_bool_list)
+
+class module_that_returns_complex_list(nn.Module):
+    def forward(self, _: torch.Tensor) -> List[complex]:
+        return [complex(1, 2), complex(3, -4)]
+script(module_that_returns_complex_list)
+
+class module_that_returns_optional_tensor_list(nn.Module):
+    def forward(self, x: torch.Tensor) -> List[Optional[torch.Tensor]]:
+        return [x, None]
+script(module_that_returns_optional_tensor_list)
+
+class module_that_returns_nested(nn.Module):
+    def forward(self, x: torch.Tensor) -> Dict[str, List[Tuple[int, torch.Tensor]]]:
+        return {"foo": [(1, x), (2, x + 1)], "bar": []}
+script(module_that_returns_nested)
+
+class module_that_sums_dict_of_int_lists(nn.Module):
+    def forward(self, x: Dict[str, List[int]]) -> int:
+        total = 0
+        for values in x.values():
+            for value in values:
+                total += value
+        return total
+script(module_that_sums_dict_of_int_lists)
+
+class module_that_echoes_tuple(nn.Module):
+    def forward(self, x: Tuple[int, str, Optional[torch.Tensor]]) -> Tuple[int, str, Optional[torch.Tensor]]:
+        return x
+script(module_that_echoes_tuple)
+
+class Detection(NamedTuple):
+    boxes: torch.Tensor
+    label: int
+    score: float
+
+class module_that_returns_named_tuple(nn.Module):
+    def forward(self, x: torch.Tensor) -> List[Detection]:
+        return [Detection(x, 1, 0.5), Detection(x + 1, 2, 0.25)]
+script(module_that_returns_named_tuple)
+
+@torch.jit.script
+class Counter:
+    def __init__(self, start: int):
+        self.count = start
+        self.name = "counter"
+
+    def increment(self, step: int) -> int:
+        self.count += step
+        return self.count
+
+class module_that_returns_object(nn.Module):
+    def forward(self, x: torch.Tensor) -> Counter:
+        return Counter(x.numel())
+script(module_that_returns_object)
+
+class Color(Enum):
+    RED = 1
+    GREEN = 2
+
+class module_that_returns_enum(nn.Module):
+    def forward(self, x: torch.Tensor) -> Color:
+        return Color.GREEN
+script(module_that_returns_enum)
+
+def negate(x: torch.Tensor) -> torch.Tensor:
+    return -x
+
+class module_that_returns_future(nn.Module):
+    def forward(self, x: torch.Tensor) -> torch.jit.Future[torch.Tensor]:
+        return torch.jit.fork(negate, x)
+script(module_that_returns_future)
+
+def fail(x: torch.Tensor) -> torch.Tensor:
+    if bool(x.sum() > 0):
+        raise RuntimeError("foo")
+    return x
+
+class module_that_returns_failed_future(nn.Module):
+    def forward(self, x: torch.Tensor) -> torch.jit.Future[torch.Tensor]:
+        return torch.jit.fork(fail, x)
+script(module_that_returns_failed_future)
@@ -0,0 +1,29 @@
+#!/usr/bin/env python
+import os
+import torch
+from torch import nn
+
+
+PACKAGE = os.path.dirname(os.path.abspath(__file__))
+DATA = os.path.join(os.path.split(PACKAGE)[0], 'data')
+
+
+class Methods(nn.Module):
+    """A module with exported methods besides forward."""
+
+    def forward(self, x: torch.Tensor) -> torch.Tensor:
+        return self.encode(x)
+
+    @torch.jit.export
+    def encode(self, x: torch.Tensor, offset: int = 1) -> torch.Tensor:
+        return 2 * x + offset
+
+    @torch.jit.export
+    def init_state(self) -> torch.Tensor:
+        return torch.zeros(2)
+
+
+model = torch.jit.script(Methods().eval())
+output_path = os.path.join(DATA, 'script_methods.pt')
+model.save(output_path)
+print(f"saved model to {output_path}")
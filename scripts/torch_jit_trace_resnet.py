@@ -6,10 +6,13 @@ import torchvision as tv
 
 PACKAGE = os.path.dirname(os.path.abspath(__file__))
 DATA = os.path.join(os.path.split(PACKAGE)[0], 'data')
+LABELS = os.path.join(PACKAGE, 'notebooks', 'imagenet-labels.txt')
 
 
 model = tv.models.resnet18(pretrained=True).eval()
 model = torch.jit.trace(model, example_inputs=torch.rand(1, 3, 224, 224))
 output_path = os.path.join(DATA, 'trace_resnet18.pt')
-model.save(output_path)
+# Store the class names in the archive for cmd/imagenet_inference.
+with open(LABELS) as labels:
+    model.save(output_path, _extra_files={'labels.txt': labels.read()})
 print(f"saved model to {output_path}")
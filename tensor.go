@@ -30,12 +30,16 @@ package torch
 // #include <stdio.h>
 // #include <stdlib.h>
 // #include "cgotorch/cgotorch.h"
+// extern void goTorchCallDeleter(uintptr_t);
 import "C"
 import (
 	"fmt"
 	"unsafe"
 	"reflect"
 	"runtime"
+	"runtime/cgo"
+	"sync"
+	"sync/atomic"
 	"github.com/Kautenja/gotorch/internal"
 )
 
@@ -51,6 +55,30 @@ func (tensor *Tensor) free() {
 	}
 	C.Torch_Tensor_Close(tensor.Pointer)  // TODO: rename to Torch_Tensor_Free
 	tensor.Pointer = nil
+	atomic.AddInt64(&liveTensors, -1)
+	internal.UntrackObject(unsafe.Pointer(tensor))
+}
+
+// Free the tensor from memory immediately instead of waiting for the garbage
+// collector to finalize it. Freeing releases this handle's reference to the
+// underlying libtorch tensor, so views and other tensors that share storage
+// with it remain valid. The tensor must not be used after it is freed. It is
+// safe to call Free more than once.
+func (tensor *Tensor) Free() {
+	// Remove the finalizer first so the garbage collector cannot free the
+	// tensor a second time.
+	runtime.SetFinalizer(tensor, nil)
+	if tensor.Pointer != nil {
+		tensor.free()
+	}
+	runtime.KeepAlive(tensor)
+}
+
+// Close the tensor by freeing it from memory. Close implements io.Closer and
+// always returns nil.
+func (tensor *Tensor) Close() error {
+	tensor.Free()
+	return nil
 }
 
 // Create a tensor view that wraps around existing contiguous memory pointed to
@@ -66,7 +94,40 @@ func TensorFromBlob(data unsafe.Pointer, dtype Dtype, sizes []int64) (output *Te
 		(*C.int64_t)(unsafe.Pointer(&sizes[0])),
 		C.int64_t(len(sizes)),
 	)))
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
+	return
+}
+
+// Create a tensor view that wraps around existing contiguous memory like
+// TensorFromBlob and call deleter when libtorch frees the storage of the
+// tensor, i.e., once the tensor and every view of it are freed. The deleter
+// may be called on any goroutine and must not use the tensor. data must not
+// point to Go memory, which cgo forbids C++ from retaining, but to memory
+// allocated by C, e.g., with C.malloc and a deleter that frees it, or by
+// libtorch, e.g., the DataPtr of another tensor and the release function of
+// its RetainStorage as the deleter.
+func TensorFromBlobWithDeleter(data unsafe.Pointer, dtype Dtype, sizes []int64, deleter func()) (output *Tensor) {
+	// An empty size creates a 0-dimensional tensor.
+	var sizesPointer *C.int64_t
+	if len(sizes) > 0 { sizesPointer = (*C.int64_t)(unsafe.Pointer(&sizes[0])) }
+	output = &Tensor{}
+	handle := cgo.NewHandle(deleter)
+	err := C.Torch_FromBlobWithDeleter(
+		&output.Pointer,
+		data,
+		C.int8_t(dtype),
+		sizesPointer,
+		C.int64_t(len(sizes)),
+		C.TensorDeleter(C.goTorchCallDeleter),
+		C.uintptr_t(handle),
+	)
+	if err != nil {
+		// libtorch does not call the deleter if the tensor is not created.
+		handle.Delete()
+		internal.PanicOnCException(unsafe.Pointer(err))
+	}
+	runtime.KeepAlive(sizes)
+	SetTensorFinalizer(output)
 	return
 }
 
@@ -84,22 +145,35 @@ func NewTensorFromBlob(data unsafe.Pointer, dtype Dtype, sizes []int64) (output
 		C.int64_t(len(sizes)),
 	)))
 	runtime.KeepAlive(data)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return
 }
 
-// Create a new tensor from a Go slice.
+// Create a new tensor from a Go slice or array, which may be nested, e.g.,
+// [][]float32 or [3][224][224]float32. The elements are copied. The data-type
+// of the tensor follows from the element type (see GetDtypeOfType), e.g.,
+// []int creates a Long tensor, []float16.Float16 creates a Half tensor from
+// the bit patterns of the elements, and []BFloat16Bits creates a BFloat16
+// tensor. NewTensor panics if the nested
+// slices are jagged, i.e., if slices in the same dimension differ in length.
 func NewTensor(data interface{}) *Tensor {
-	// Ensure that the input data is a slice.
-	if reflect.TypeOf(data).Kind() != reflect.Slice {
-		panic(fmt.Sprintf("Expected slice but got data of type %v", reflect.TypeOf(data).Kind()))
+	// Ensure that the input data is a slice or array.
+	if kind := reflect.TypeOf(data).Kind(); kind != reflect.Slice && kind != reflect.Array {
+		panic(fmt.Sprintf("Expected slice or array but got data of type %v", kind))
 	}
 	// Reflect information about the tensor size and data type.
 	sizes, kind := internal.GetSizesAndKindOfSlice(data)
-	dtype := GetDtypeOfKind(kind)
+	elementType := reflect.TypeOf(data)
+	for elementType.Kind() == reflect.Slice || elementType.Kind() == reflect.Array {
+		elementType = elementType.Elem()
+	}
+	dtype := GetDtypeOfType(elementType)
 	if dtype == Invalid {
 		panic(fmt.Sprintf("Unrecognized dtype kind %v", kind))
 	}
+	if err := internal.CheckSliceSizes(data, sizes); err != nil {
+		panic(err.Error())
+	}
 	// Convert the data a 1-dimensional buffer
 	flat_data := internal.FlattenSlice(data, kind)
 	header := (*reflect.SliceHeader)(unsafe.Pointer(&flat_data))
@@ -141,6 +215,31 @@ func (tensor *Tensor) ToBytes() []byte {
 	return output
 }
 
+// Retain a reference to the storage of the tensor that keeps the memory
+// addressed by DataPtr and ToBytesUnsafe valid until the returned function is
+// called, even if the tensor is freed or garbage collected before then. This
+// ties the lifetime of the tensor data to Go values that alias it, e.g., by
+// calling release from their finalizers. release may be called more than
+// once and from any goroutine.
+func (tensor *Tensor) RetainStorage() (release func()) {
+	var reference C.Tensor
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_ShallowCopy(&reference, tensor.Pointer)))
+	runtime.KeepAlive(tensor)
+	var once sync.Once
+	return func() {
+		once.Do(func() { C.Torch_Tensor_Close(reference) })
+	}
+}
+
+// Return true if the tensor data is stored in CPU memory, i.e., if its data
+// can be addressed by Go code.
+func (tensor *Tensor) IsCPU() bool {
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_IsCPU(&output, tensor.Pointer)))
+	runtime.KeepAlive(tensor)
+	return bool(output)
+}
+
 // Create a clone of an existing tensor.
 func (tensor *Tensor) Clone() (output *Tensor) {
 	output = &Tensor{}
@@ -149,7 +248,7 @@ func (tensor *Tensor) Clone() (output *Tensor) {
 		tensor.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return
 }
 
@@ -186,7 +285,7 @@ func Load(path string) (*Tensor, error) {
 	if err != nil {
 		return nil, internal.NewTorchError(err)
 	}
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output, nil
 }
 
@@ -213,7 +312,7 @@ func Decode(buffer []byte) (*Tensor, error) {
 	if err != nil {
 		return nil, internal.NewTorchError(err)
 	}
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output, nil
 }
 
@@ -250,6 +349,119 @@ func (tensor *Tensor) Shape() []int64 {
 	return shape
 }
 
+// Return the strides of the tensor, i.e., the number of elements to skip in
+// the underlying storage to step by one along each dimension.
+func (tensor *Tensor) Strides() []int64 {
+	strides := make([]int64, tensor.Dim())
+	if len(strides) == 0 {
+		return strides
+	}
+	internal.PanicOnCException(unsafe.Pointer(
+		C.Torch_Tensor_Strides((*C.int64_t)(unsafe.Pointer(&strides[0])), tensor.Pointer),
+	))
+	runtime.KeepAlive(tensor)
+	return strides
+}
+
+// Return the offset of the first element of the tensor in its underlying
+// storage as a number of elements.
+func (tensor *Tensor) StorageOffset() int64 {
+	var offset int64
+	internal.PanicOnCException(unsafe.Pointer(
+		C.Torch_Tensor_StorageOffset((*C.int64_t)(&offset), tensor.Pointer),
+	))
+	runtime.KeepAlive(tensor)
+	return offset
+}
+
+// Return the address of the first element of the tensor. The pointer is only
+// valid while the tensor, or another tensor that shares its storage, is alive
+// and the elements it addresses are laid out according to Strides.
+func (tensor *Tensor) DataPtr() unsafe.Pointer {
+	var data unsafe.Pointer
+	internal.PanicOnCException(unsafe.Pointer(
+		C.Torch_Tensor_DataPtr(&data, tensor.Pointer),
+	))
+	runtime.KeepAlive(tensor)
+	return data
+}
+
+// Return true if the tensor shares its underlying storage with another, e.g.,
+// because one is a view of the other produced by View, Permute, or Slice.
+func (tensor *Tensor) IsSameStorage(other *Tensor) bool {
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(
+		C.Torch_Tensor_IsSameStorage(&output, tensor.Pointer, other.Pointer),
+	))
+	runtime.KeepAlive(tensor)
+	runtime.KeepAlive(other)
+	return bool(output)
+}
+
+// Return the layout of the tensor data in memory.
+func (tensor *Tensor) Layout() Layout {
+	var layout Layout
+	internal.PanicOnCException(unsafe.Pointer(
+		C.Torch_Tensor_Layout((*C.int8_t)(unsafe.Pointer(&layout)), tensor.Pointer),
+	))
+	runtime.KeepAlive(tensor)
+	return layout
+}
+
+// Return the memory format that best describes the strides of the tensor,
+// e.g., ChannelsLast for a 4-dimensional tensor permuted from NHWC to NCHW.
+func (tensor *Tensor) MemoryFormat() MemoryFormat {
+	var memoryFormat MemoryFormat
+	internal.PanicOnCException(unsafe.Pointer(
+		C.Torch_Tensor_SuggestMemoryFormat((*C.int8_t)(unsafe.Pointer(&memoryFormat)), tensor.Pointer),
+	))
+	runtime.KeepAlive(tensor)
+	return memoryFormat
+}
+
+// Return the memory format from the optional arguments of a method, which
+// defaults to ContiguousFormat.
+func optionalMemoryFormat(memoryFormat []MemoryFormat) MemoryFormat {
+	switch len(memoryFormat) {
+	case 0:
+		return ContiguousFormat
+	case 1:
+		return memoryFormat[0]
+	default:
+		panic("Expected 0-1 memory formats as input")
+	}
+}
+
+// Return true if the tensor is contiguous in memory, i.e., its elements are
+// laid out without gaps in the order of its dimensions. When a memory format
+// is given, e.g., ChannelsLast, contiguity is checked in that order instead.
+func (tensor *Tensor) IsContiguous(memoryFormat ...MemoryFormat) bool {
+	var output C.bool
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_IsContiguous(
+		&output,
+		tensor.Pointer,
+		C.int8_t(optionalMemoryFormat(memoryFormat)),
+	)))
+	runtime.KeepAlive(tensor)
+	return bool(output)
+}
+
+// Return a tensor that is contiguous in memory. When a memory format is given,
+// e.g., ChannelsLast, the tensor is contiguous in that order instead. The
+// output shares storage with the input if it is already contiguous, otherwise
+// the data is copied.
+func (tensor *Tensor) Contiguous(memoryFormat ...MemoryFormat) *Tensor {
+	output := &Tensor{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_Contiguous(
+		&output.Pointer,
+		tensor.Pointer,
+		C.int8_t(optionalMemoryFormat(memoryFormat)),
+	)))
+	runtime.KeepAlive(tensor)
+	SetTensorFinalizer(output)
+	return output
+}
+
 // Create a new tensor with an updated view of the underlying data.
 func (tensor *Tensor) View(shape ...int64) *Tensor {
 	output := &Tensor{}
@@ -260,7 +472,7 @@ func (tensor *Tensor) View(shape ...int64) *Tensor {
 		C.int64_t(len(shape)),
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -275,7 +487,7 @@ func (tensor *Tensor) ViewAs(other *Tensor) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -289,7 +501,7 @@ func (tensor *Tensor) Reshape(shape ...int64) *Tensor {
 		C.int64_t(len(shape)),
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -308,7 +520,7 @@ func (tensor *Tensor) ReshapeAs(other *Tensor) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -322,7 +534,7 @@ func (tensor *Tensor) Expand(shape ...int64) *Tensor {
 		C.int64_t(len(shape)),
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -336,7 +548,24 @@ func (tensor *Tensor) ExpandAs(other *Tensor) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(other)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
+	return output
+}
+
+// Return a view of all the slices of the given size along a dimension, with
+// step elements between the start of consecutive slices. The slices are
+// indexed by the dimension and their elements by a new last dimension.
+func (tensor *Tensor) Unfold(dimension, size, step int64) *Tensor {
+	output := &Tensor{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_Unfold(
+		&output.Pointer,
+		tensor.Pointer,
+		C.int64_t(dimension),
+		C.int64_t(size),
+		C.int64_t(step),
+	)))
+	runtime.KeepAlive(tensor)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -363,7 +592,7 @@ func (tensor *Tensor) CastTo(dtype Dtype) *Tensor {
 		C.int8_t(dtype),
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -377,7 +606,7 @@ func (tensor *Tensor) CopyTo(device *Device) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(device)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -392,7 +621,7 @@ func (tensor *Tensor) To(device *Device, dtype Dtype) *Tensor {
 	)))
 	runtime.KeepAlive(tensor)
 	runtime.KeepAlive(device)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -407,7 +636,7 @@ func (tensor *Tensor) To(device *Device, dtype Dtype) *Tensor {
 // 		tensor.Pointer,
 // 	)))
 // 	runtime.KeepAlive(tensor)
-// 	runtime.SetFinalizer(output, (*Tensor).free)
+// 	SetTensorFinalizer(output)
 // 	return output
 // }
 
@@ -436,7 +665,7 @@ func (tensor *Tensor) Grad() *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_Grad(&output.Pointer, tensor.Pointer)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -445,7 +674,7 @@ func (tensor *Tensor) Detach() *Tensor {
 	output := &Tensor{}
 	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_Detach(&output.Pointer, tensor.Pointer)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
@@ -458,7 +687,7 @@ func (tensor *Tensor) Index(index *Tensor) *Tensor {
 		index.Pointer,
 	)))
 	runtime.KeepAlive(tensor)
-	runtime.SetFinalizer(output, (*Tensor).free)
+	SetTensorFinalizer(output)
 	return output
 }
 
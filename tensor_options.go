@@ -59,6 +59,7 @@ func NewTensorOptions() (options *TensorOptions) {
 // functional interface here at the Go layer, but this is not possible ATM.
 func (options *TensorOptions) withFinalizerSet() *TensorOptions {
 	runtime.SetFinalizer(options, (*TensorOptions).free)
+	internal.TrackObject("TensorOptions", unsafe.Pointer(options))
 	return options
 }
 
@@ -69,6 +70,7 @@ func (options *TensorOptions) free() {
 	}
 	C.Torch_TensorOptions_Free(options.Pointer)
 	options.Pointer = nil
+	internal.UntrackObject(unsafe.Pointer(options))
 }
 
 // Create a new TensorOptions with the given data type.
@@ -83,50 +85,55 @@ func (options *TensorOptions) Dtype(value Dtype) *TensorOptions {
 	return output.withFinalizerSet()
 }
 
-// // TODO:
-// enum class Layout : int8_t {
-// 	Strided,
-// 	Sparse,
-// 	SparseCsr,
-// 	Mkldnn,
-// 	SparseCsc,
-// 	SparseBsr,
-// 	SparseBsc,
-// 	NumOptions
-// };
+// An enumeration of the layouts of tensor data in memory. These codes are
+// one-to-one with c10::Layout in torch 1.11.
+type Layout int8
+const (
+	Strided Layout = iota
+	Sparse
+	SparseCsr
+	Mkldnn
+)
 
-// // Create a new TensorOptions with the given data layout.
-// func (options *TensorOptions) Layout(value Layout) *TensorOptions {
-// 	output := &TensorOptions{}
-// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_TensorOptions_Layout(
-// 		&output.Pointer,
-// 		options.Pointer,
-// 		C.int8_t(value),
-// 	)))
-// 	runtime.KeepAlive(options)
-// 	return output.withFinalizerSet()
-// }
+// Create a new TensorOptions with the given data layout.
+func (options *TensorOptions) Layout(value Layout) *TensorOptions {
+	output := &TensorOptions{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_TensorOptions_Layout(
+		&output.Pointer,
+		options.Pointer,
+		C.int8_t(value),
+	)))
+	runtime.KeepAlive(options)
+	return output.withFinalizerSet()
+}
 
-// // TODO:
-// enum class MemoryFormat : int8_t {
-// 	Contiguous,
-// 	Preserve,
-// 	ChannelsLast,
-// 	ChannelsLast3d,
-// 	NumOptions
-// };
+// An enumeration of the orders of the dimensions of strided tensors in memory.
+// These codes are one-to-one with c10::MemoryFormat in torch.
+type MemoryFormat int8
+const (
+	// The dimensions are stored in order, e.g., NCHW for images.
+	ContiguousFormat MemoryFormat = iota
+	// The memory format of the input is preserved by an operation.
+	PreserveFormat
+	// The channels of 4-dimensional tensors are stored last, i.e., NHWC.
+	// Convolutions on the CPU are typically faster in this format.
+	ChannelsLast
+	// The channels of 5-dimensional tensors are stored last, i.e., NDHWC.
+	ChannelsLast3d
+)
 
-// // Create a new TensorOptions with the given memory format.
-// func (options *TensorOptions) MemoryFormat(value MemoryFormat) *TensorOptions {
-// 	output := &TensorOptions{}
-// 	internal.PanicOnCException(unsafe.Pointer(C.Torch_TensorOptions_MemoryFormat(
-// 		&output.Pointer,
-// 		options.Pointer,
-// 		C.int8_t(value),
-// 	)))
-// 	runtime.KeepAlive(options)
-// 	return output.withFinalizerSet()
-// }
+// Create a new TensorOptions with the given memory format. The memory format
+// is honored by Empty, e.g., to allocate channels-last images.
+func (options *TensorOptions) MemoryFormat(value MemoryFormat) *TensorOptions {
+	output := &TensorOptions{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_TensorOptions_MemoryFormat(
+		&output.Pointer,
+		options.Pointer,
+		C.int8_t(value),
+	)))
+	runtime.KeepAlive(options)
+	return output.withFinalizerSet()
+}
 
 // Create a new TensorOptions with the given compute device.
 func (options *TensorOptions) Device(device Device) *TensorOptions {
@@ -67,6 +67,58 @@ func Test_Torch_TensorFromBlob_PanicsOnInvalidSize(t *testing.T) {
 	})
 }
 
+// MARK: TensorFromBlobWithDeleter
+
+func Test_Torch_TensorFromBlobWithDeleter(t *testing.T) {
+	source := torch.NewTensor([]float32{1, 2, 3})
+	release := source.RetainStorage()
+	deleted := false
+	tensor := torch.TensorFromBlobWithDeleter(source.DataPtr(), torch.Float, []int64{3}, func() {
+		release()
+		deleted = true
+	})
+	assert.Equal(t, []float32{1, 2, 3}, tensor.ToSlice())
+	source.Copy_(torch.NewTensor([]float32{4, 2, 3}))
+	assert.Equal(t, []float32{4, 2, 3}, tensor.ToSlice())
+	tensor.Free()
+	assert.True(t, deleted)
+}
+
+func Test_Torch_TensorFromBlobWithDeleter_WaitsForViews(t *testing.T) {
+	source := torch.NewTensor([]float32{1, 2, 3})
+	release := source.RetainStorage()
+	deleted := false
+	tensor := torch.TensorFromBlobWithDeleter(source.DataPtr(), torch.Float, []int64{3}, func() {
+		release()
+		deleted = true
+	})
+	source.Free()
+	view := tensor.Slice(0, 1, 3, 1)
+	tensor.Free()
+	assert.False(t, deleted)
+	assert.Equal(t, []float32{2, 3}, view.ToSlice())
+	view.Free()
+	assert.True(t, deleted)
+}
+
+func Test_Torch_TensorFromBlobWithDeleter_Scalar(t *testing.T) {
+	source := torch.NewTensor([]float32{2})
+	release := source.RetainStorage()
+	tensor := torch.TensorFromBlobWithDeleter(source.DataPtr(), torch.Float, []int64{}, release)
+	assert.Equal(t, []int64{}, tensor.Shape())
+	assert.Equal(t, float32(2), tensor.Item().(float32))
+	runtime.KeepAlive(source)
+}
+
+func Test_Torch_TensorFromBlobWithDeleter_PanicsOnInvalidSize(t *testing.T) {
+	data := [1]float32{1.0}
+	deleted := false
+	assert.PanicsWithValue(t, "Trying to create tensor with negative dimension -1: [-1]", func() {
+		torch.TensorFromBlobWithDeleter(unsafe.Pointer(&data), torch.Float, []int64{-1}, func() { deleted = true })
+	})
+	assert.False(t, deleted)
+}
+
 // MARK: NewTensorFromBlob
 
 func Test_Torch_NewTensorFromBlob(t *testing.T) {
@@ -93,16 +145,11 @@ func Test_Torch_NewTensorFromBlob_PanicsOnInvalidSize(t *testing.T) {
 
 // MARK: NewTensor
 
-// func Test_Torch_NewTensor_WithEmptyData(t *testing.T) {
-//     tensor := torch.NewTensor([]float32{})
-// }
-
-// TODO: this test makes invalid assumptions and sometimes fails
-// func Test_Torch_NewTensor_WithRaggedData(t *testing.T) {
-//     tensor := torch.NewTensor([][]float32{{0, 1}, {1}, {}})
-//     expected := torch.NewTensor([][]float32{{0, 1}, {1, 0}, {0, 0}})
-//     assert.True(t, tensor.AllClose(expected, 1e-8, 1e-5), "Got %v, expected %v", tensor, expected)
-// }
+func Test_Torch_NewTensor_WithEmptyData(t *testing.T) {
+	tensor := torch.NewTensor([]float32{})
+	assert.Equal(t, []int64{0}, tensor.Shape())
+	assert.Equal(t, []float32{}, tensor.ToSlice())
+}
 
 func Test_Torch_NewTensor_WithComplex64DataType(t *testing.T) {
 	assert.NotPanics(t, func() {
@@ -117,7 +164,7 @@ func Test_Torch_NewTensor_WithComplex128DataType(t *testing.T) {
 }
 
 func Test_Torch_NewTensor_PanicsOnNonSliceInput(t *testing.T) {
-	assert.PanicsWithValue(t, "Expected slice but got data of type string", func() {
+	assert.PanicsWithValue(t, "Expected slice or array but got data of type string", func() {
 		_ = torch.NewTensor("foo")
 	})
 }
@@ -128,9 +175,50 @@ func Test_Torch_NewTensor_PanicsOnStringDataType(t *testing.T) {
 	})
 }
 
-func Test_Torch_NewTensor_PanicsOnIntDataType(t *testing.T) {
-	assert.PanicsWithValue(t, "Unrecognized dtype kind int", func() {
-		_ = torch.NewTensor([]int{})
+func Test_Torch_NewTensor_WithIntDataType(t *testing.T) {
+	tensor := torch.NewTensor([]int{1 << 40, -1})
+	assert.Equal(t, torch.Long, tensor.Dtype())
+	assert.Equal(t, []int64{1 << 40, -1}, tensor.ToSlice())
+}
+
+func Test_Torch_NewTensor_WithFloat16DataType(t *testing.T) {
+	tensor := torch.NewTensor([]float16.Float16{float16.Fromfloat32(1), float16.Fromfloat32(-0.5)})
+	assert.Equal(t, torch.Half, tensor.Dtype())
+	assert.Equal(t, []float32{1, -0.5}, tensor.CastTo(torch.Float).ToSlice())
+}
+
+func Test_Torch_NewTensor_WithArray(t *testing.T) {
+	tensor := torch.NewTensor([2][3]float32{{1, 2, 3}, {4, 5, 6}})
+	assert.Equal(t, []int64{2, 3}, tensor.Shape())
+	assert.Equal(t, []float32{1, 2, 3, 4, 5, 6}, tensor.ToSlice())
+}
+
+func Test_Torch_NewTensor_WithLargeArray(t *testing.T) {
+	var data [3][224][224]float32
+	data[2][223][223] = 1
+	tensor := torch.NewTensor(data)
+	assert.Equal(t, []int64{3, 224, 224}, tensor.Shape())
+	assert.Equal(t, float32(1), tensor.Sum().Item())
+}
+
+func Test_Torch_NewTensor_WithSliceOfArrays(t *testing.T) {
+	tensor := torch.NewTensor([][2]int32{{1, 2}, {3, 4}, {5, 6}})
+	assert.Equal(t, []int64{3, 2}, tensor.Shape())
+	assert.Equal(t, []int32{1, 2, 3, 4, 5, 6}, tensor.ToSlice())
+}
+
+func Test_Torch_NewTensor_WithEmptyNestedSlice(t *testing.T) {
+	tensor := torch.NewTensor([][]float32{})
+	assert.Equal(t, torch.Float, tensor.Dtype())
+	assert.Equal(t, []int64{0, 0}, tensor.Shape())
+}
+
+func Test_Torch_NewTensor_PanicsOnJaggedData(t *testing.T) {
+	assert.PanicsWithValue(t, "Expected slice of length 2 at [1] but found length 1", func() {
+		_ = torch.NewTensor([][]float32{{0, 1}, {1}, {}})
+	})
+	assert.PanicsWithValue(t, "Expected slice of length 1 at [0][1] but found length 0", func() {
+		_ = torch.NewTensor([][][]int64{{{1}, {}}})
 	})
 }
 
@@ -152,6 +240,48 @@ func Test_Torch_NewTensor_PanicsOnUIntPtrDataType(t *testing.T) {
 	})
 }
 
+// MARK: Free
+
+func Test_Torch_Tensor_Free(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	tensor.Free()
+	assert.Nil(t, tensor.Pointer)
+}
+
+func Test_Torch_Tensor_Free_IsSafeToCallTwice(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	tensor.Free()
+	assert.NotPanics(t, func() { tensor.Free() })
+	assert.Nil(t, tensor.Close())
+}
+
+func Test_Torch_Tensor_Free_DoesNotInvalidateViews(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	view := tensor.View(3, 1)
+	tensor.Free()
+	assert.Equal(t, []float32{1, 2, 3}, view.ToSlice())
+}
+
+// MARK: RetainStorage
+
+func Test_Torch_Tensor_RetainStorage(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	release := tensor.RetainStorage()
+	data := unsafe.Slice((*float32)(tensor.DataPtr()), 3)
+	tensor.Free()
+	runtime.GC()
+	assert.Equal(t, []float32{1, 2, 3}, data)
+	release()
+	assert.NotPanics(t, release)
+}
+
+// MARK: IsCPU
+
+func Test_Torch_Tensor_IsCPU(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1, 2, 3})
+	assert.True(t, tensor.IsCPU())
+}
+
 // MARK: ToBytes
 
 func TestTensorToBytesScalarFloat32(t *testing.T) {
@@ -731,6 +861,147 @@ func Test_Tensor_ExpandAs(t *testing.T) {
 	assert.Equal(t, g, c.String())
 }
 
+// MARK: Strides/StorageOffset
+
+func Test_Tensor_Strides(t *testing.T) {
+	tensor := torch.Zeros([]int64{2, 3, 4}, torch.NewTensorOptions())
+	assert.Equal(t, []int64{12, 4, 1}, tensor.Strides())
+	assert.Equal(t, []int64{1, 4, 12}, tensor.Permute(2, 1, 0).Strides())
+}
+
+func Test_Tensor_StridesOfScalar(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1}).Squeeze()
+	assert.Equal(t, []int64{}, tensor.Strides())
+}
+
+func Test_Tensor_StorageOffset(t *testing.T) {
+	tensor := torch.Arange(0, 6, 1, torch.NewTensorOptions()).View(2, 3)
+	assert.Equal(t, int64(0), tensor.StorageOffset())
+	assert.Equal(t, int64(3), tensor.Slice(0, 1, 2, 1).StorageOffset())
+	assert.Equal(t, int64(1), tensor.Slice(1, 1, 3, 1).StorageOffset())
+}
+
+// MARK: DataPtr/IsSameStorage
+
+func Test_Tensor_DataPtr(t *testing.T) {
+	data := []float32{1, 2, 3, 4}
+	tensor := torch.TensorFromBlob(unsafe.Pointer(&data[0]), torch.Float, []int64{4})
+	assert.Equal(t, unsafe.Pointer(&data[0]), tensor.DataPtr())
+	assert.Equal(t, unsafe.Pointer(&data[2]), tensor.Slice(0, 2, 4, 1).DataPtr())
+}
+
+func Test_Tensor_IsSameStorage(t *testing.T) {
+	tensor := torch.Zeros([]int64{2, 3}, torch.NewTensorOptions())
+	assert.True(t, tensor.IsSameStorage(tensor))
+	assert.True(t, tensor.IsSameStorage(tensor.View(6)))
+	assert.True(t, tensor.IsSameStorage(tensor.Permute(1, 0)))
+	assert.True(t, tensor.IsSameStorage(tensor.Slice(1, 1, 3, 1)))
+	assert.False(t, tensor.IsSameStorage(tensor.Clone()))
+	assert.False(t, tensor.IsSameStorage(torch.Zeros([]int64{2, 3}, torch.NewTensorOptions())))
+}
+
+// MARK: Layout
+
+func Test_Tensor_Layout(t *testing.T) {
+	tensor := torch.Zeros([]int64{2, 3}, torch.NewTensorOptions().Layout(torch.Strided))
+	assert.Equal(t, torch.Strided, tensor.Layout())
+}
+
+// MARK: Contiguous
+
+func Test_Tensor_IsContiguous(t *testing.T) {
+	tensor := torch.Zeros([]int64{2, 3}, torch.NewTensorOptions())
+	assert.True(t, tensor.IsContiguous())
+	assert.False(t, tensor.Transpose(0, 1).IsContiguous())
+	assert.False(t, tensor.Slice(1, 0, 3, 2).IsContiguous())
+	assert.True(t, tensor.Slice(0, 1, 2, 1).IsContiguous())
+}
+
+func Test_Tensor_IsContiguousPanicsOnMultipleMemoryFormats(t *testing.T) {
+	tensor := torch.Zeros([]int64{2, 3}, torch.NewTensorOptions())
+	assert.PanicsWithValue(t, "Expected 0-1 memory formats as input", func() {
+		tensor.IsContiguous(torch.ContiguousFormat, torch.ChannelsLast)
+	})
+}
+
+func Test_Tensor_Contiguous(t *testing.T) {
+	tensor := torch.NewTensor([][]float32{{1, 2, 3}, {4, 5, 6}})
+	// A contiguous tensor is returned as is.
+	assert.True(t, tensor.IsSameStorage(tensor.Contiguous()))
+	// A non-contiguous tensor is copied.
+	transposed := tensor.Transpose(0, 1)
+	contiguous := transposed.Contiguous()
+	assert.True(t, contiguous.IsContiguous())
+	assert.False(t, contiguous.IsSameStorage(tensor))
+	assert.Equal(t, []int64{2, 1}, contiguous.Strides())
+	assert.True(t, contiguous.Equal(transposed))
+}
+
+func Test_Tensor_ContiguousChannelsLast(t *testing.T) {
+	tensor := torch.Rand([]int64{2, 3, 4, 5}, torch.NewTensorOptions())
+	assert.Equal(t, torch.ContiguousFormat, tensor.MemoryFormat())
+	assert.False(t, tensor.IsContiguous(torch.ChannelsLast))
+	output := tensor.Contiguous(torch.ChannelsLast)
+	assert.Equal(t, []int64{2, 3, 4, 5}, output.Shape())
+	assert.Equal(t, []int64{60, 1, 15, 3}, output.Strides())
+	assert.True(t, output.IsContiguous(torch.ChannelsLast))
+	assert.False(t, output.IsContiguous())
+	assert.Equal(t, torch.ChannelsLast, output.MemoryFormat())
+	assert.True(t, output.Equal(tensor))
+}
+
+func Test_Tensor_MemoryFormatOfPermutedTensor(t *testing.T) {
+	// An NHWC tensor permuted to NCHW is channels-last without a copy.
+	tensor := torch.Rand([]int64{2, 4, 5, 3}, torch.NewTensorOptions())
+	output := tensor.Permute(0, 3, 1, 2)
+	assert.True(t, output.IsContiguous(torch.ChannelsLast))
+	assert.Equal(t, torch.ChannelsLast, output.MemoryFormat())
+	assert.True(t, output.IsSameStorage(output.Contiguous(torch.ChannelsLast)))
+}
+
+func Test_Tensor_EmptyWithChannelsLastMemoryFormat(t *testing.T) {
+	options := torch.NewTensorOptions().MemoryFormat(torch.ChannelsLast)
+	tensor := torch.Empty([]int64{2, 3, 4, 5}, options)
+	assert.True(t, tensor.IsContiguous(torch.ChannelsLast))
+	assert.Equal(t, []int64{60, 1, 15, 3}, tensor.Strides())
+}
+
+// MARK: Unfold
+
+// >>> x = torch.arange(1., 8)
+// >>> x.unfold(0, 2, 2)
+// tensor([[ 1.,  2.],
+//         [ 3.,  4.],
+//         [ 5.,  6.]])
+func Test_Tensor_Unfold(t *testing.T) {
+	tensor := torch.Arange(1, 8, 1, torch.NewTensorOptions())
+	output := tensor.Unfold(0, 2, 2)
+	expected := torch.NewTensor([][]float32{{1, 2}, {3, 4}, {5, 6}})
+	assert.True(t, output.Equal(expected), "Expected %v got %v", expected, output)
+	assert.True(t, output.IsSameStorage(tensor))
+}
+
+// >>> x = torch.arange(1., 8)
+// >>> x.unfold(0, 3, 1)
+// tensor([[1., 2., 3.],
+//         [2., 3., 4.],
+//         [3., 4., 5.],
+//         [4., 5., 6.],
+//         [5., 6., 7.]])
+func Test_Tensor_UnfoldOverlapping(t *testing.T) {
+	tensor := torch.Arange(1, 8, 1, torch.NewTensorOptions())
+	output := tensor.Unfold(0, 3, 1)
+	assert.Equal(t, []int64{5, 3}, output.Shape())
+	assert.Equal(t, []int64{1, 1}, output.Strides())
+	expected := torch.NewTensor([][]float32{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}, {4, 5, 6}, {5, 6, 7}})
+	assert.True(t, output.Equal(expected), "Expected %v got %v", expected, output)
+}
+
+func Test_Tensor_UnfoldPanicsOnInvalidSize(t *testing.T) {
+	tensor := torch.Arange(1, 4, 1, torch.NewTensorOptions())
+	assert.Panics(t, func() { tensor.Unfold(0, 4, 1) })
+}
+
 // MARK: SetData
 
 // Set data should in-place overwrite the tensor data. For blobs this
@@ -0,0 +1,162 @@
+// Generic tensors with Go element types checked against their data-type.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package torch
+
+// #cgo CPPFLAGS: -I/usr/local/include -I/usr/local/include/cgotorch
+// #cgo LDFLAGS: -L/usr/local/lib -lc10 -ltorch_cpu -ltorch -lcgotorch
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include "cgotorch/cgotorch.h"
+import "C"
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"unsafe"
+	"github.com/Kautenja/gotorch/internal"
+)
+
+// The Go types that have a native torch data-type (see DtypeOf).
+type Number interface {
+	~uint8 | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64 | ~complex64 | ~complex128
+}
+
+// Return the data-type of tensors with elements of type T, e.g., Float for
+// float32.
+func DtypeOf[T Number]() Dtype {
+	var zero T
+	return GetDtypeOfKind(reflect.TypeOf(zero).Kind())
+}
+
+// A TypedTensor is a tensor whose data-type is known to match the Go type T,
+// so that its elements can be read and written without type assertions. The
+// embedded Tensor provides the untyped API.
+type TypedTensor[T Number] struct {
+	*Tensor
+}
+
+// Wrap a tensor as a TypedTensor. NewTypedTensor returns an error if the
+// data-type of the tensor does not match T. The tensor is shared, not copied.
+func NewTypedTensor[T Number](tensor *Tensor) (*TypedTensor[T], error) {
+	if dtype := tensor.Dtype(); dtype != DtypeOf[T]() {
+		var zero T
+		return nil, fmt.Errorf("Expected tensor of dtype %v for %T but found dtype %v", DtypeOf[T](), zero, dtype)
+	}
+	return &TypedTensor[T]{tensor}, nil
+}
+
+// Create a new tensor with the given shape that copies the data of a slice,
+// e.g., FromSlice([]float32{1, 2, 3, 4}, 2, 2). The shape defaults to the
+// length of the slice. FromSlice panics if the shape does not match the
+// length of the slice.
+func FromSlice[T Number](data []T, shape ...int64) *TypedTensor[T] {
+	if len(shape) == 0 { shape = []int64{int64(len(data))} }
+	numel := int64(1)
+	for _, size := range shape { numel *= size }
+	if numel != int64(len(data)) {
+		panic(fmt.Sprintf("Expected %d elements for shape %v but found %d", numel, shape, len(data)))
+	}
+	// Allocate one extra element so that the pointer is valid for empty data.
+	buffer := make([]T, len(data) + 1)
+	copy(buffer, data)
+	tensor := NewTensorFromBlob(unsafe.Pointer(&buffer[0]), DtypeOf[T](), shape)
+	runtime.KeepAlive(buffer)
+	return &TypedTensor[T]{tensor}
+}
+
+// Copy the contents of a tensor into a slice of the same length. The tensor
+// may be non-contiguous or on any device.
+func copyToSlice[T Number](output []T, tensor *Tensor) {
+	if len(output) == 0 { return }
+	blob := TensorFromBlob(unsafe.Pointer(&output[0]), DtypeOf[T](), []int64{int64(len(output))})
+	blob.Copy_(tensor.Reshape(-1))
+	runtime.KeepAlive(output)
+	runtime.KeepAlive(blob)
+}
+
+// Return a copy of the elements of the tensor in row-major order as a
+// 1-dimensional slice.
+func (tensor *TypedTensor[T]) Data() []T {
+	output := make([]T, tensor.Numel())
+	copyToSlice(output, tensor.Tensor)
+	return output
+}
+
+// Return the value of a tensor with one element. Item panics if the tensor
+// has more than one element.
+func (tensor *TypedTensor[T]) Item() T {
+	if numel := tensor.Numel(); numel != 1 {
+		panic(fmt.Sprintf("Expected tensor with 1 element but found %d elements", numel))
+	}
+	output := make([]T, 1)
+	copyToSlice(output, tensor.Tensor)
+	return output[0]
+}
+
+// Check that an index addresses a single element of the tensor and return a
+// copy of the index with an extra element so that it is never empty.
+func (tensor *TypedTensor[T]) checkIndex(index []int64) []int64 {
+	if dim := tensor.Dim(); int64(len(index)) != dim {
+		panic(fmt.Sprintf("Expected %d indices for tensor of shape %v but found %d", dim, tensor.Shape(), len(index)))
+	}
+	indices := make([]int64, len(index) + 1)
+	copy(indices, index)
+	return indices
+}
+
+// Return the element at the given index, e.g., At(1, 2) for the element in
+// the second row and third column of a matrix. Negative indices count from
+// the end of their dimension. At panics if the number of indices does not
+// match the number of dimensions or if an index is out of range.
+func (tensor *TypedTensor[T]) At(index ...int64) T {
+	indices := tensor.checkIndex(index)
+	element := &Tensor{}
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_IndexAt(
+		&element.Pointer,
+		tensor.Pointer,
+		(*C.int64_t)(unsafe.Pointer(&indices[0])),
+		C.int64_t(len(index)),
+	)))
+	runtime.KeepAlive(tensor)
+	SetTensorFinalizer(element)
+	output := make([]T, 1)
+	copyToSlice(output, element)
+	return output[0]
+}
+
+// Set the element at the given index to a value in-place. Negative indices
+// count from the end of their dimension. Set panics if the number of indices
+// does not match the number of dimensions or if an index is out of range.
+func (tensor *TypedTensor[T]) Set(value T, index ...int64) {
+	indices := tensor.checkIndex(index)
+	element := FromSlice([]T{value})
+	internal.PanicOnCException(unsafe.Pointer(C.Torch_Tensor_IndexPutAt(
+		tensor.Pointer,
+		(*C.int64_t)(unsafe.Pointer(&indices[0])),
+		C.int64_t(len(index)),
+		element.Pointer,
+	)))
+	runtime.KeepAlive(tensor)
+	runtime.KeepAlive(element)
+}
@@ -0,0 +1,167 @@
+// test cases for typed_tensor.go
+//
+// Copyright (c) 2023 Christian Kauten
+// Copyright (c) 2022 Sensory, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package torch_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+)
+
+// MARK: DtypeOf
+
+func TestDtypeOf(t *testing.T) {
+	assert.Equal(t, torch.Byte, torch.DtypeOf[uint8]())
+	assert.Equal(t, torch.Char, torch.DtypeOf[int8]())
+	assert.Equal(t, torch.Short, torch.DtypeOf[int16]())
+	assert.Equal(t, torch.Int, torch.DtypeOf[int32]())
+	assert.Equal(t, torch.Long, torch.DtypeOf[int64]())
+	assert.Equal(t, torch.Float, torch.DtypeOf[float32]())
+	assert.Equal(t, torch.Double, torch.DtypeOf[float64]())
+	assert.Equal(t, torch.ComplexFloat, torch.DtypeOf[complex64]())
+	assert.Equal(t, torch.ComplexDouble, torch.DtypeOf[complex128]())
+}
+
+func TestDtypeOfNamedType(t *testing.T) {
+	type label int64
+	assert.Equal(t, torch.Long, torch.DtypeOf[label]())
+}
+
+// MARK: NewTypedTensor
+
+func TestNewTypedTensor(t *testing.T) {
+	tensor := torch.NewTensor([]float32{1, 2})
+	typed, err := torch.NewTypedTensor[float32](tensor)
+	assert.Nil(t, err)
+	assert.Equal(t, tensor, typed.Tensor)
+}
+
+func TestNewTypedTensorWithWrongDtype(t *testing.T) {
+	typed, err := torch.NewTypedTensor[float64](torch.NewTensor([]float32{1, 2}))
+	assert.Nil(t, typed)
+	assert.NotNil(t, err)
+}
+
+// MARK: FromSlice
+
+func TestFromSlice(t *testing.T) {
+	tensor := torch.FromSlice([]int64{1, 2, 3})
+	assert.Equal(t, torch.Long, tensor.Dtype())
+	assert.Equal(t, []int64{3}, tensor.Shape())
+	assert.Equal(t, []int64{1, 2, 3}, tensor.Data())
+}
+
+func TestFromSliceWithShape(t *testing.T) {
+	tensor := torch.FromSlice([]float64{1, 2, 3, 4, 5, 6}, 2, 3)
+	assert.Equal(t, []int64{2, 3}, tensor.Shape())
+	assert.Equal(t, []float64{1, 2, 3, 4, 5, 6}, tensor.Data())
+}
+
+func TestFromSliceCopiesData(t *testing.T) {
+	data := []float32{1, 2}
+	tensor := torch.FromSlice(data)
+	data[0] = 3
+	assert.Equal(t, []float32{1, 2}, tensor.Data())
+}
+
+func TestFromSliceEmpty(t *testing.T) {
+	tensor := torch.FromSlice([]float32{})
+	assert.Equal(t, []int64{0}, tensor.Shape())
+	assert.Equal(t, []float32{}, tensor.Data())
+}
+
+func TestFromSliceComplex(t *testing.T) {
+	tensor := torch.FromSlice([]complex64{1 + 2i, 3 - 4i})
+	assert.Equal(t, torch.ComplexFloat, tensor.Dtype())
+	assert.Equal(t, []complex64{1 + 2i, 3 - 4i}, tensor.Data())
+}
+
+func TestFromSliceWithWrongShapePanics(t *testing.T) {
+	assert.PanicsWithValue(t, "Expected 6 elements for shape [2 3] but found 4", func() {
+		torch.FromSlice([]float32{1, 2, 3, 4}, 2, 3)
+	})
+}
+
+// MARK: Data
+
+func TestTypedTensorDataOfNonContiguousTensor(t *testing.T) {
+	tensor := torch.FromSlice([]int32{1, 2, 3, 4, 5, 6}, 2, 3)
+	transposed, _ := torch.NewTypedTensor[int32](tensor.Transpose(0, 1))
+	assert.Equal(t, []int32{1, 4, 2, 5, 3, 6}, transposed.Data())
+}
+
+// MARK: Item
+
+func TestTypedTensorItem(t *testing.T) {
+	tensor, _ := torch.NewTypedTensor[float32](torch.NewTensor([]float32{1, 2, 3}).Sum())
+	assert.Equal(t, float32(6), tensor.Item())
+}
+
+func TestTypedTensorItemPanicsForManyElements(t *testing.T) {
+	tensor := torch.FromSlice([]float32{1, 2})
+	assert.PanicsWithValue(t, "Expected tensor with 1 element but found 2 elements", func() {
+		tensor.Item()
+	})
+}
+
+// MARK: At/Set
+
+func TestTypedTensorAt(t *testing.T) {
+	tensor := torch.FromSlice([]uint8{1, 2, 3, 4, 5, 6}, 2, 3)
+	assert.Equal(t, uint8(1), tensor.At(0, 0))
+	assert.Equal(t, uint8(6), tensor.At(1, 2))
+	assert.Equal(t, uint8(5), tensor.At(-1, -2))
+}
+
+func TestTypedTensorAtPanicsForWrongNumberOfIndices(t *testing.T) {
+	tensor := torch.FromSlice([]float32{1, 2, 3, 4}, 2, 2)
+	assert.Panics(t, func() { tensor.At(0) })
+	assert.Panics(t, func() { tensor.At(0, 0, 0) })
+}
+
+func TestTypedTensorAtPanicsForIndexOutOfRange(t *testing.T) {
+	tensor := torch.FromSlice([]float32{1, 2, 3, 4}, 2, 2)
+	assert.Panics(t, func() { tensor.At(2, 0) })
+}
+
+func TestTypedTensorSet(t *testing.T) {
+	tensor := torch.FromSlice([]int64{1, 2, 3, 4}, 2, 2)
+	tensor.Set(1 << 40, 1, 0)
+	tensor.Set(-1, -1, -1)
+	assert.Equal(t, []int64{1, 2, 1 << 40, -1}, tensor.Data())
+}
+
+func TestTypedTensorSetDoesNotModifyIndex(t *testing.T) {
+	tensor := torch.FromSlice([]float64{1, 2, 3, 4}, 2, 2)
+	backing := []int64{0, 0, 7}
+	tensor.Set(5, backing[:2]...)
+	assert.Equal(t, []int64{0, 0, 7}, backing)
+	assert.Equal(t, float64(5), tensor.At(0, 0))
+}
+
+func TestTypedTensorSetScalar(t *testing.T) {
+	tensor, _ := torch.NewTypedTensor[float32](torch.NewTensor([]float32{1}).Sum())
+	tensor.Set(2)
+	assert.Equal(t, float32(2), tensor.At())
+}
@@ -0,0 +1,167 @@
+// GoTorch port of torch.utils.data.DataLoader
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package utils_data
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"github.com/Kautenja/gotorch"
+)
+
+// A function that merges a slice of examples into a single batched example.
+type CollateFunc func(examples []Example) (Example, error)
+
+// Collate examples by stacking the data and targets along a new leading batch
+// dimension. All examples in the batch must have the same data and target
+// shapes.
+func StackCollate(examples []Example) (batch Example, err error) {
+	if len(examples) == 0 {
+		return Example{}, errors.New("Cannot collate an empty batch of examples")
+	}
+	// Stack panics on mismatched shapes, surface that as an error instead.
+	defer func() {
+		if r := recover(); r != nil {
+			batch = Example{}
+			err = fmt.Errorf("Failed to stack examples: %v", r)
+		}
+	}()
+	data := make([]*torch.Tensor, len(examples))
+	target := make([]*torch.Tensor, len(examples))
+	for index, example := range examples {
+		data[index] = example.Data
+		target[index] = example.Target
+	}
+	return Example{Data: torch.Stack(data, 0), Target: torch.Stack(target, 0)}, nil
+}
+
+// A loader that iterates over a dataset in (optionally shuffled) batches.
+type DataLoader struct {
+	// The dataset to load examples from.
+	Dataset IDataset
+	// The number of examples per batch.
+	BatchSize int64
+	// Whether to visit the examples in a random order on each iteration.
+	Shuffle bool
+	// Whether to drop the last batch if it is smaller than BatchSize.
+	DropLast bool
+	// The function used to merge examples into batches.
+	Collate CollateFunc
+}
+
+// Create a new DataLoader that stacks examples into batches of given size.
+func NewDataLoader(dataset IDataset, batchSize int64, shuffle bool) *DataLoader {
+	if batchSize <= 0 { panic("batchSize should be greater than 0") }
+	return &DataLoader{
+		Dataset:   dataset,
+		BatchSize: batchSize,
+		Shuffle:   shuffle,
+		Collate:   StackCollate,
+	}
+}
+
+// Return the number of batches produced by one pass over the dataset.
+func (loader *DataLoader) Len() int64 {
+	length := loader.Dataset.Len()
+	if loader.DropLast {
+		return length / loader.BatchSize
+	}
+	return (length + loader.BatchSize - 1) / loader.BatchSize
+}
+
+// Create an iterator for a single pass over the dataset.
+func (loader *DataLoader) Iter() *DataLoaderIterator {
+	indices := make([]int64, loader.Dataset.Len())
+	for index := range indices {
+		indices[index] = int64(index)
+	}
+	if loader.Shuffle {
+		rand.Shuffle(len(indices), func(i, j int) {
+			indices[i], indices[j] = indices[j], indices[i]
+		})
+	}
+	return &DataLoaderIterator{loader: loader, indices: indices}
+}
+
+// An iterator over the batches of a DataLoader. Usage follows that of a
+// bufio.Scanner, i.e.,
+//
+// ```
+// iterator := loader.Iter()
+// for iterator.Next() {
+//     batch := iterator.Batch()
+//     // process the batch
+// }
+// if err := iterator.Err(); err != nil {
+//     // handle the error
+// }
+// ```
+type DataLoaderIterator struct {
+	loader   *DataLoader
+	indices  []int64
+	position int
+	batch    Example
+	err      error
+}
+
+// Advance to the next batch. Return false when the dataset is exhausted or an
+// error occurs.
+func (iterator *DataLoaderIterator) Next() bool {
+	if iterator.err != nil { return false }
+	remaining := len(iterator.indices) - iterator.position
+	size := int(iterator.loader.BatchSize)
+	if remaining <= 0 || (iterator.loader.DropLast && remaining < size) {
+		return false
+	}
+	if remaining < size {
+		size = remaining
+	}
+	examples := make([]Example, size)
+	for offset := range examples {
+		index := iterator.indices[iterator.position + offset]
+		example, err := iterator.loader.Dataset.Get(index)
+		if err != nil {
+			iterator.err = err
+			return false
+		}
+		examples[offset] = example
+	}
+	iterator.position += size
+	collate := iterator.loader.Collate
+	if collate == nil {
+		collate = StackCollate
+	}
+	iterator.batch, iterator.err = collate(examples)
+	return iterator.err == nil
+}
+
+// Return the most recent batch produced by Next.
+func (iterator *DataLoaderIterator) Batch() Example {
+	return iterator.batch
+}
+
+// Return the first error encountered during iteration, if any.
+func (iterator *DataLoaderIterator) Err() error {
+	return iterator.err
+}
@@ -0,0 +1,103 @@
+// test cases for data_loader.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package utils_data_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	data "github.com/Kautenja/gotorch/utils/data"
+)
+
+func newRangeDataset(length int64) *data.TensorDataset {
+	x := torch.Arange(0, float32(length), 1, torch.NewTensorOptions().Dtype(torch.Long))
+	return data.NewTensorDataset(x.Unsqueeze(1), x)
+}
+
+func TestNewDataLoaderPanicsOnInvalidBatchSize(t *testing.T) {
+	assert.PanicsWithValue(t, "batchSize should be greater than 0", func() {
+		data.NewDataLoader(newRangeDataset(4), 0, false)
+	})
+}
+
+func TestDataLoaderLen(t *testing.T) {
+	loader := data.NewDataLoader(newRangeDataset(5), 2, false)
+	assert.Equal(t, int64(3), loader.Len())
+	loader.DropLast = true
+	assert.Equal(t, int64(2), loader.Len())
+}
+
+func TestDataLoaderIteratesInOrder(t *testing.T) {
+	loader := data.NewDataLoader(newRangeDataset(5), 2, false)
+	iterator := loader.Iter()
+	expected := [][]int64{{0, 1}, {2, 3}, {4}}
+	count := 0
+	for iterator.Next() {
+		batch := iterator.Batch()
+		assert.Equal(t, []int64{int64(len(expected[count])), 1}, batch.Data.Shape())
+		assert.Equal(t, expected[count], batch.Target.ToSlice())
+		count++
+	}
+	assert.Nil(t, iterator.Err())
+	assert.Equal(t, 3, count)
+}
+
+func TestDataLoaderDropLast(t *testing.T) {
+	loader := data.NewDataLoader(newRangeDataset(5), 2, false)
+	loader.DropLast = true
+	iterator := loader.Iter()
+	count := 0
+	for iterator.Next() {
+		assert.Equal(t, []int64{2}, iterator.Batch().Target.Shape())
+		count++
+	}
+	assert.Nil(t, iterator.Err())
+	assert.Equal(t, 2, count)
+}
+
+func TestDataLoaderShuffleVisitsEveryExample(t *testing.T) {
+	loader := data.NewDataLoader(newRangeDataset(10), 3, true)
+	iterator := loader.Iter()
+	seen := map[int64]bool{}
+	for iterator.Next() {
+		for _, index := range iterator.Batch().Target.ToSlice().([]int64) {
+			seen[index] = true
+		}
+	}
+	assert.Nil(t, iterator.Err())
+	assert.Equal(t, 10, len(seen))
+}
+
+func TestStackCollateReturnsErrorOnEmptyBatch(t *testing.T) {
+	_, err := data.StackCollate([]data.Example{})
+	assert.NotNil(t, err)
+}
+
+func TestStackCollateReturnsErrorOnShapeMismatch(t *testing.T) {
+	_, err := data.StackCollate([]data.Example{
+		{Data: torch.NewTensor([]float32{1, 2}), Target: torch.NewTensor([]int64{0})},
+		{Data: torch.NewTensor([]float32{1, 2, 3}), Target: torch.NewTensor([]int64{0})},
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,77 @@
+// GoTorch port of torch.utils.data.Dataset
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package utils_data
+
+import (
+	"fmt"
+	"github.com/Kautenja/gotorch"
+)
+
+// A single example from a dataset as a pair of data and target tensors.
+type Example struct {
+	Data   *torch.Tensor
+	Target *torch.Tensor
+}
+
+// An abstract dataset that maps integer indices in [0, Len()) to examples.
+type IDataset interface {
+	Len() int64
+	Get(index int64) (Example, error)
+}
+
+// A dataset wrapping a data tensor and a target tensor that share the same
+// size along the first dimension.
+type TensorDataset struct {
+	data, target *torch.Tensor
+}
+
+// Create a new TensorDataset from data and target tensors. Examples are
+// indexed along the first dimension of each tensor.
+func NewTensorDataset(data, target *torch.Tensor) *TensorDataset {
+	dataShape := data.Shape()
+	targetShape := target.Shape()
+	if len(dataShape) == 0 || len(targetShape) == 0 {
+		panic("TensorDataset requires tensors with 1 or more dimensions")
+	}
+	if dataShape[0] != targetShape[0] {
+		panic(fmt.Sprintf("Size mismatch between data (%d) and target (%d) tensors", dataShape[0], targetShape[0]))
+	}
+	return &TensorDataset{data, target}
+}
+
+// Return the number of examples in the dataset.
+func (dataset *TensorDataset) Len() int64 {
+	return dataset.data.Shape()[0]
+}
+
+// Return the example at the given index.
+func (dataset *TensorDataset) Get(index int64) (Example, error) {
+	if index < 0 || index >= dataset.Len() {
+		return Example{}, fmt.Errorf("Index %d is out of range for dataset of length %d", index, dataset.Len())
+	}
+	return Example{
+		Data:   dataset.data.Slice(0, index, index + 1, 1).Squeeze(0),
+		Target: dataset.target.Slice(0, index, index + 1, 1).Squeeze(0),
+	}, nil
+}
@@ -0,0 +1,62 @@
+// test cases for dataset.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package utils_data_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	data "github.com/Kautenja/gotorch/utils/data"
+)
+
+func TestNewTensorDatasetPanicsOnSizeMismatch(t *testing.T) {
+	x := torch.NewTensor([][]float32{{1, 2}, {3, 4}, {5, 6}})
+	y := torch.NewTensor([]int64{0, 1})
+	assert.PanicsWithValue(t, "Size mismatch between data (3) and target (2) tensors", func() {
+		data.NewTensorDataset(x, y)
+	})
+}
+
+func TestTensorDatasetLen(t *testing.T) {
+	x := torch.NewTensor([][]float32{{1, 2}, {3, 4}, {5, 6}})
+	y := torch.NewTensor([]int64{0, 1, 2})
+	assert.Equal(t, int64(3), data.NewTensorDataset(x, y).Len())
+}
+
+func TestTensorDatasetGet(t *testing.T) {
+	x := torch.NewTensor([][]float32{{1, 2}, {3, 4}, {5, 6}})
+	y := torch.NewTensor([]int64{0, 1, 2})
+	example, err := data.NewTensorDataset(x, y).Get(1)
+	assert.Nil(t, err)
+	assert.True(t, example.Data.Equal(torch.NewTensor([]float32{3, 4})))
+	assert.Equal(t, int64(1), example.Target.Item().(int64))
+}
+
+func TestTensorDatasetGetReturnsErrorOnOutOfRangeIndex(t *testing.T) {
+	x := torch.NewTensor([][]float32{{1, 2}, {3, 4}, {5, 6}})
+	y := torch.NewTensor([]int64{0, 1, 2})
+	_, err := data.NewTensorDataset(x, y).Get(3)
+	assert.NotNil(t, err)
+	assert.Equal(t, "Index 3 is out of range for dataset of length 3", err.Error())
+}
@@ -0,0 +1,234 @@
+// Image datasets annotated by CSV or JSON Lines files.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"github.com/Kautenja/gotorch"
+	data "github.com/Kautenja/gotorch/utils/data"
+	transforms "github.com/Kautenja/gotorch/vision/transforms"
+)
+
+// An image path and its float target tensor.
+type AnnotatedImageSample struct {
+	Path   string
+	Target *torch.Tensor
+}
+
+// A dataset of images paired with arbitrary float targets, e.g., regression
+// values or bounding boxes for detection.
+type AnnotatedImageDataset struct {
+	// The image paths and targets in the dataset.
+	Samples []AnnotatedImageSample
+	// The optional transform to apply to each image. Note that the transform
+	// is not applied to the target, i.e., boxes are not resized with images.
+	transform transforms.ITransformer
+}
+
+// Create a new AnnotatedImageDataset from a CSV file where each row has an
+// image path in the first column followed by one or more float targets, e.g.,
+//
+// ```
+// image,age,weight
+// images/0001.jpg,32,70.5
+// images/0002.jpg,45,81.0
+// ```
+//
+// Relative image paths are resolved against root. If hasHeader is true, the
+// first row is skipped. The target for each row is a float tensor of shape
+// (K,) where K is the number of target columns. The transform may be nil.
+func AnnotatedImagesFromCSV(
+	path, root string,
+	hasHeader bool,
+	transform transforms.ITransformer,
+) (*AnnotatedImageDataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	dataset := &AnnotatedImageDataset{transform: transform}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if hasHeader && line == 1 {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected an image path and at least 1 target", path, line)
+		}
+		values := make([]float32, len(record) - 1)
+		for index, field := range record[1:] {
+			value, err := strconv.ParseFloat(strings.TrimSpace(field), 32)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+			}
+			values[index] = float32(value)
+		}
+		dataset.Samples = append(dataset.Samples, AnnotatedImageSample{
+			Path:   resolvePath(root, strings.TrimSpace(record[0])),
+			Target: torch.NewTensor(values),
+		})
+	}
+	return dataset, nil
+}
+
+// Create a new AnnotatedImageDataset from a JSON Lines file where each line is
+// an object with an "image" path and a numeric "target" that may be a number
+// or a (non-jagged) nested array, e.g., for object detection
+//
+// ```
+// {"image": "images/0001.jpg", "target": [[10, 20, 50, 60, 1], [5, 5, 9, 9, 3]]}
+// {"image": "images/0002.jpg", "target": []}
+// ```
+//
+// Relative image paths are resolved against root. The target for each line is
+// a float tensor with the shape of the nested array. The transform may be nil.
+func AnnotatedImagesFromJSONL(
+	path, root string,
+	transform transforms.ITransformer,
+) (*AnnotatedImageDataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64 * 1024), 64 * 1024 * 1024)
+	dataset := &AnnotatedImageDataset{transform: transform}
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record struct {
+			Image  string      `json:"image"`
+			Target interface{} `json:"target"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if record.Image == "" {
+			return nil, fmt.Errorf("%s:%d: missing \"image\" field", path, line)
+		}
+		var values []float32
+		shape, err := flattenJSONArray(record.Target, &values)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		dataset.Samples = append(dataset.Samples, AnnotatedImageSample{
+			Path:   resolvePath(root, record.Image),
+			Target: newTensorWithShape(values, shape),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return dataset, nil
+}
+
+// Return the number of images in the dataset.
+func (dataset *AnnotatedImageDataset) Len() int64 {
+	return int64(len(dataset.Samples))
+}
+
+// Load the image at the given index as a (C, H, W) float tensor with its
+// float target tensor.
+func (dataset *AnnotatedImageDataset) Get(index int64) (data.Example, error) {
+	if index < 0 || index >= dataset.Len() {
+		return data.Example{}, fmt.Errorf("Index %d is out of range for dataset of length %d", index, dataset.Len())
+	}
+	sample := dataset.Samples[index]
+	tensor, err := loadImage(sample.Path, dataset.transform)
+	if err != nil {
+		return data.Example{}, err
+	}
+	return data.Example{Data: tensor, Target: sample.Target}, nil
+}
+
+// Join a relative path onto root, absolute paths are returned as-is.
+func resolvePath(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
+
+// Flatten a decoded JSON number, boolean, or nested array of them into values
+// and return the shape of the array.
+func flattenJSONArray(value interface{}, values *[]float32) ([]int64, error) {
+	switch typed := value.(type) {
+	case float64:
+		*values = append(*values, float32(typed))
+		return []int64{}, nil
+	case bool:
+		if typed {
+			*values = append(*values, 1)
+		} else {
+			*values = append(*values, 0)
+		}
+		return []int64{}, nil
+	case []interface{}:
+		var shape []int64
+		for index, element := range typed {
+			elementShape, err := flattenJSONArray(element, values)
+			if err != nil {
+				return nil, err
+			}
+			if index == 0 {
+				shape = elementShape
+			} else if fmt.Sprint(shape) != fmt.Sprint(elementShape) {
+				return nil, errors.New("target must not be a jagged array")
+			}
+		}
+		return append([]int64{int64(len(typed))}, shape...), nil
+	default:
+		return nil, fmt.Errorf("target must be a number or an array, but found %T", value)
+	}
+}
+
+// Create a float tensor with the given shape from flat values.
+func newTensorWithShape(values []float32, shape []int64) *torch.Tensor {
+	if len(values) == 0 {
+		return torch.Zeros(shape, torch.NewTensorOptions().Dtype(torch.Float))
+	}
+	tensor := torch.NewTensor(values)
+	if len(shape) == 0 {
+		return tensor.Squeeze()
+	}
+	return tensor.Reshape(shape...)
+}
@@ -0,0 +1,124 @@
+// test cases for annotated_images.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	datasets "github.com/Kautenja/gotorch/vision/datasets"
+)
+
+// Write the contents to a file in the root directory and return its path.
+func writeAnnotations(t *testing.T, root, name, contents string) string {
+	path := filepath.Join(root, name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// MARK: AnnotatedImagesFromCSV
+
+func TestAnnotatedImagesFromCSVReturnsErrorOnMissingFile(t *testing.T) {
+	dataset, err := datasets.AnnotatedImagesFromCSV(filepath.Join(t.TempDir(), "missing.csv"), "", false, nil)
+	assert.Nil(t, dataset)
+	assert.NotNil(t, err)
+}
+
+func TestAnnotatedImagesFromCSV(t *testing.T) {
+	root := t.TempDir()
+	writePNG(t, filepath.Join(root, "images", "0.png"), 4, 3)
+	writePNG(t, filepath.Join(root, "images", "1.png"), 4, 3)
+	path := writeAnnotations(t, root, "labels.csv", "image,x,y\nimages/0.png,1.5,2\nimages/1.png, 3,4.25\n")
+	dataset, err := datasets.AnnotatedImagesFromCSV(path, root, true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), dataset.Len())
+	assert.Equal(t, filepath.Join(root, "images", "1.png"), dataset.Samples[1].Path)
+	example, err := dataset.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{3, 3, 4}, example.Data.Shape())
+	assert.True(t, example.Target.Equal(torch.NewTensor([]float32{3, 4.25})))
+}
+
+func TestAnnotatedImagesFromCSVReturnsErrorOnMissingTarget(t *testing.T) {
+	root := t.TempDir()
+	path := writeAnnotations(t, root, "labels.csv", "images/0.png\n")
+	_, err := datasets.AnnotatedImagesFromCSV(path, root, false, nil)
+	assert.NotNil(t, err)
+}
+
+func TestAnnotatedImagesFromCSVReturnsErrorOnNonNumericTarget(t *testing.T) {
+	root := t.TempDir()
+	path := writeAnnotations(t, root, "labels.csv", "images/0.png,foo\n")
+	_, err := datasets.AnnotatedImagesFromCSV(path, root, false, nil)
+	assert.NotNil(t, err)
+}
+
+// MARK: AnnotatedImagesFromJSONL
+
+func TestAnnotatedImagesFromJSONL(t *testing.T) {
+	root := t.TempDir()
+	writePNG(t, filepath.Join(root, "0.png"), 4, 3)
+	path := writeAnnotations(t, root, "labels.jsonl",
+		`{"image": "0.png", "target": [[10, 20, 50, 60, 1], [5, 5, 9, 9, 3]]}` + "\n" +
+		"\n" +
+		`{"image": "0.png", "target": []}` + "\n" +
+		`{"image": "0.png", "target": 0.5}` + "\n")
+	dataset, err := datasets.AnnotatedImagesFromJSONL(path, root, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), dataset.Len())
+	example, err := dataset.Get(0)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{3, 3, 4}, example.Data.Shape())
+	expected := torch.NewTensor([][]float32{{10, 20, 50, 60, 1}, {5, 5, 9, 9, 3}})
+	assert.True(t, example.Target.Equal(expected))
+	assert.Equal(t, []int64{0}, dataset.Samples[1].Target.Shape())
+	assert.Equal(t, []int64{}, dataset.Samples[2].Target.Shape())
+	assert.Equal(t, float32(0.5), dataset.Samples[2].Target.Item().(float32))
+}
+
+func TestAnnotatedImagesFromJSONLReturnsErrorOnJaggedTarget(t *testing.T) {
+	root := t.TempDir()
+	path := writeAnnotations(t, root, "labels.jsonl", `{"image": "0.png", "target": [[1, 2], [3]]}`)
+	_, err := datasets.AnnotatedImagesFromJSONL(path, root, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "jagged")
+}
+
+func TestAnnotatedImagesFromJSONLReturnsErrorOnMissingImage(t *testing.T) {
+	root := t.TempDir()
+	path := writeAnnotations(t, root, "labels.jsonl", `{"target": 1}`)
+	_, err := datasets.AnnotatedImagesFromJSONL(path, root, nil)
+	assert.NotNil(t, err)
+}
+
+func TestAnnotatedImagesFromJSONLReturnsErrorOnStringTarget(t *testing.T) {
+	root := t.TempDir()
+	path := writeAnnotations(t, root, "labels.jsonl", `{"image": "0.png", "target": "foo"}`)
+	_, err := datasets.AnnotatedImagesFromJSONL(path, root, nil)
+	assert.NotNil(t, err)
+}
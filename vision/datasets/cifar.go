@@ -0,0 +1,156 @@
+// GoTorch port of torchvision.datasets.CIFAR10 and CIFAR100.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"unsafe"
+	"github.com/Kautenja/gotorch"
+	data "github.com/Kautenja/gotorch/utils/data"
+	transforms "github.com/Kautenja/gotorch/vision/transforms"
+)
+
+// The number of bytes in a CIFAR image (3 channels of 32x32 pixels.)
+const cifarImageBytes = 3 * 32 * 32
+
+// A dataset of CIFAR-10 or CIFAR-100 images.
+type CIFARDataset struct {
+	// The (N, 3, 32, 32) byte tensor of images.
+	Images *torch.Tensor
+	// The (N,) long tensor of labels (the fine labels for CIFAR-100.)
+	Labels *torch.Tensor
+	// The (N,) long tensor of coarse labels for CIFAR-100, nil for CIFAR-10.
+	CoarseLabels *torch.Tensor
+	// The optional transform to apply to each image.
+	transform transforms.ITransformer
+}
+
+// Create a new CIFARDataset from the CIFAR-10 binary batches in root, i.e.,
+// "data_batch_1.bin" through "data_batch_5.bin" for the training split and
+// "test_batch.bin" for the test split. The transform may be nil.
+func CIFAR10(root string, train bool, transform transforms.ITransformer) (*CIFARDataset, error) {
+	files := []string{"test_batch.bin"}
+	if train {
+		files = []string{
+			"data_batch_1.bin",
+			"data_batch_2.bin",
+			"data_batch_3.bin",
+			"data_batch_4.bin",
+			"data_batch_5.bin",
+		}
+	}
+	var images, labels []byte
+	for _, file := range files {
+		fileImages, fileLabels, err := readCIFARBatch(filepath.Join(root, file), 1)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, fileImages...)
+		labels = append(labels, fileLabels...)
+	}
+	return newCIFARDataset(images, labels, nil, transform), nil
+}
+
+// Create a new CIFARDataset from the CIFAR-100 binary batches in root, i.e.,
+// "train.bin" for the training split and "test.bin" for the test split. The
+// transform may be nil.
+func CIFAR100(root string, train bool, transform transforms.ITransformer) (*CIFARDataset, error) {
+	file := "test.bin"
+	if train {
+		file = "train.bin"
+	}
+	images, labels, err := readCIFARBatch(filepath.Join(root, file), 2)
+	if err != nil {
+		return nil, err
+	}
+	// Each record stores the coarse label followed by the fine label.
+	coarse := make([]byte, len(labels) / 2)
+	fine := make([]byte, len(labels) / 2)
+	for index := range fine {
+		coarse[index] = labels[2 * index]
+		fine[index] = labels[2 * index + 1]
+	}
+	return newCIFARDataset(images, fine, coarse, transform), nil
+}
+
+// Read the images and labels from a CIFAR binary batch where each record has
+// numLabels label bytes followed by the image bytes in CHW order.
+func readCIFARBatch(path string, numLabels int) (images, labels []byte, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	buffer, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	recordBytes := numLabels + cifarImageBytes
+	if len(buffer) % recordBytes != 0 {
+		return nil, nil, fmt.Errorf("Size of %s (%d bytes) is not a multiple of the record size (%d bytes)", path, len(buffer), recordBytes)
+	}
+	for offset := 0; offset < len(buffer); offset += recordBytes {
+		labels = append(labels, buffer[offset:offset + numLabels]...)
+		images = append(images, buffer[offset + numLabels:offset + recordBytes]...)
+	}
+	return images, labels, nil
+}
+
+// Create a new CIFARDataset from raw image and label bytes.
+func newCIFARDataset(images, labels, coarseLabels []byte, transform transforms.ITransformer) *CIFARDataset {
+	dataset := &CIFARDataset{transform: transform}
+	count := int64(len(labels))
+	if count == 0 {
+		dataset.Images = torch.Zeros([]int64{0, 3, 32, 32}, torch.NewTensorOptions().Dtype(torch.Byte))
+		dataset.Labels = torch.Zeros([]int64{0}, torch.NewTensorOptions().Dtype(torch.Long))
+		return dataset
+	}
+	dataset.Images = torch.NewTensorFromBlob(unsafe.Pointer(&images[0]), torch.Byte, []int64{count, 3, 32, 32})
+	dataset.Labels = torch.NewTensor(labels).CastTo(torch.Long)
+	if coarseLabels != nil {
+		dataset.CoarseLabels = torch.NewTensor(coarseLabels).CastTo(torch.Long)
+	}
+	return dataset
+}
+
+// Return the number of images in the dataset.
+func (dataset *CIFARDataset) Len() int64 {
+	return dataset.Labels.Shape()[0]
+}
+
+// Return the image at the given index as a (3, 32, 32) float tensor in [0, 1]
+// with its label as a scalar long tensor.
+func (dataset *CIFARDataset) Get(index int64) (data.Example, error) {
+	if index < 0 || index >= dataset.Len() {
+		return data.Example{}, fmt.Errorf("Index %d is out of range for dataset of length %d", index, dataset.Len())
+	}
+	image := byteImageToFloat(dataset.Images.Slice(0, index, index + 1, 1).Squeeze(0))
+	return data.Example{
+		Data:   applyTransform(image, dataset.transform),
+		Target: dataset.Labels.Slice(0, index, index + 1, 1).Squeeze(),
+	}, nil
+}
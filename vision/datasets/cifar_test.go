@@ -0,0 +1,92 @@
+// test cases for cifar.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	datasets "github.com/Kautenja/gotorch/vision/datasets"
+)
+
+// Create a CIFAR binary record with the given labels and a constant image.
+func makeCIFARRecord(labels []byte, pixel byte) []byte {
+	record := append([]byte{}, labels...)
+	for index := 0; index < 3 * 32 * 32; index++ {
+		record = append(record, pixel)
+	}
+	return record
+}
+
+func writeCIFARFile(t *testing.T, path string, contents []byte) {
+	if err := os.WriteFile(path, contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCIFAR10ReturnsErrorOnMissingFiles(t *testing.T) {
+	dataset, err := datasets.CIFAR10(t.TempDir(), false, nil)
+	assert.Nil(t, dataset)
+	assert.NotNil(t, err)
+}
+
+func TestCIFAR10ReturnsErrorOnTruncatedFile(t *testing.T) {
+	root := t.TempDir()
+	writeCIFARFile(t, filepath.Join(root, "test_batch.bin"), makeCIFARRecord([]byte{1}, 0)[:100])
+	_, err := datasets.CIFAR10(root, false, nil)
+	assert.NotNil(t, err)
+}
+
+func TestCIFAR10TrainSplit(t *testing.T) {
+	root := t.TempDir()
+	for batch := 1; batch <= 5; batch++ {
+		contents := makeCIFARRecord([]byte{byte(batch)}, 255)
+		writeCIFARFile(t, filepath.Join(root, fmt.Sprintf("data_batch_%d.bin", batch)), contents)
+	}
+	dataset, err := datasets.CIFAR10(root, true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), dataset.Len())
+	assert.Nil(t, dataset.CoarseLabels)
+	example, err := dataset.Get(4)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{3, 32, 32}, example.Data.Shape())
+	assert.Equal(t, float32(1), example.Data.Mean().Item().(float32))
+	assert.Equal(t, int64(5), example.Target.Item().(int64))
+}
+
+func TestCIFAR100TestSplit(t *testing.T) {
+	root := t.TempDir()
+	contents := append(makeCIFARRecord([]byte{3, 42}, 0), makeCIFARRecord([]byte{4, 17}, 0)...)
+	writeCIFARFile(t, filepath.Join(root, "test.bin"), contents)
+	dataset, err := datasets.CIFAR100(root, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), dataset.Len())
+	assert.Equal(t, []int64{42, 17}, dataset.Labels.ToSlice())
+	assert.Equal(t, []int64{3, 4}, dataset.CoarseLabels.ToSlice())
+	example, err := dataset.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(17), example.Target.Item().(int64))
+}
@@ -0,0 +1,303 @@
+// GoTorch port of torchvision.datasets.CocoDetection.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"unsafe"
+	"github.com/Kautenja/gotorch"
+	data "github.com/Kautenja/gotorch/utils/data"
+	transforms "github.com/Kautenja/gotorch/vision/transforms"
+)
+
+// An image entry from a COCO annotation file.
+type COCOImage struct {
+	ID       int64  `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int64  `json:"width"`
+	Height   int64  `json:"height"`
+}
+
+// A category entry from a COCO annotation file.
+type COCOCategory struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Supercategory string `json:"supercategory"`
+}
+
+// An object instance annotation from a COCO annotation file. Boxes are in
+// (x, y, width, height) format. The segmentation is either a list of polygons
+// or a run-length encoding (RLE) and can be decoded using DecodeCOCOSegmentation.
+type COCOAnnotation struct {
+	ID           int64           `json:"id"`
+	ImageID      int64           `json:"image_id"`
+	CategoryID   int64           `json:"category_id"`
+	BBox         [4]float64      `json:"bbox"`
+	Area         float64         `json:"area"`
+	IsCrowd      int64           `json:"iscrowd"`
+	Segmentation json.RawMessage `json:"segmentation"`
+}
+
+// The structured targets for the objects in a COCO image.
+type COCOTarget struct {
+	// The (N, 4) float tensor of boxes in (xmin, ymin, xmax, ymax) format.
+	Boxes *torch.Tensor
+	// The (N,) long tensor of category IDs.
+	Labels *torch.Tensor
+	// The (N,) bool tensor of crowd flags.
+	IsCrowd *torch.Tensor
+	// The (N, H, W) byte tensor of binary instance masks.
+	Masks *torch.Tensor
+}
+
+// A dataset of images and object instance annotations in the COCO format.
+type COCODataset struct {
+	// The images in the dataset in the order of the annotation file.
+	Images []COCOImage
+	// The object categories in the dataset.
+	Categories []COCOCategory
+	// The object annotations of each image keyed by image ID.
+	Annotations map[int64][]COCOAnnotation
+	// The directory containing the image files.
+	root string
+	// The optional transform to apply to each image.
+	transform transforms.ITransformer
+}
+
+// Create a new COCODataset from the images in root and a COCO instances
+// annotation file, e.g., "annotations/instances_val2017.json". The transform
+// may be nil.
+func COCODetection(root, annotationFile string, transform transforms.ITransformer) (*COCODataset, error) {
+	file, err := os.Open(annotationFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var contents struct {
+		Images      []COCOImage      `json:"images"`
+		Annotations []COCOAnnotation `json:"annotations"`
+		Categories  []COCOCategory   `json:"categories"`
+	}
+	if err := json.NewDecoder(file).Decode(&contents); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %v", annotationFile, err)
+	}
+	dataset := &COCODataset{
+		Images:      contents.Images,
+		Categories:  contents.Categories,
+		Annotations: map[int64][]COCOAnnotation{},
+		root:        root,
+		transform:   transform,
+	}
+	for _, annotation := range contents.Annotations {
+		dataset.Annotations[annotation.ImageID] = append(dataset.Annotations[annotation.ImageID], annotation)
+	}
+	return dataset, nil
+}
+
+// Return the number of images in the dataset.
+func (dataset *COCODataset) Len() int64 {
+	return int64(len(dataset.Images))
+}
+
+// Load the image at the given index as a (C, H, W) float tensor with an
+// (N, 5) float target of (xmin, ymin, xmax, ymax, category) rows.
+func (dataset *COCODataset) Get(index int64) (data.Example, error) {
+	if index < 0 || index >= dataset.Len() {
+		return data.Example{}, fmt.Errorf("Index %d is out of range for dataset of length %d", index, dataset.Len())
+	}
+	tensor, err := loadImage(resolvePath(dataset.root, dataset.Images[index].FileName), dataset.transform)
+	if err != nil {
+		return data.Example{}, err
+	}
+	annotations := dataset.Annotations[dataset.Images[index].ID]
+	values := make([]float32, 0, 5 * len(annotations))
+	for _, annotation := range annotations {
+		xmin, ymin, xmax, ymax := cocoBoxToCorners(annotation.BBox)
+		values = append(values, xmin, ymin, xmax, ymax, float32(annotation.CategoryID))
+	}
+	return data.Example{Data: tensor, Target: newTensorWithShape(values, []int64{int64(len(annotations)), 5})}, nil
+}
+
+// Return the boxes, labels, crowd flags, and decoded instance masks of the
+// image at the given index.
+func (dataset *COCODataset) GetTarget(index int64) (COCOTarget, error) {
+	if index < 0 || index >= dataset.Len() {
+		return COCOTarget{}, fmt.Errorf("Index %d is out of range for dataset of length %d", index, dataset.Len())
+	}
+	image := dataset.Images[index]
+	annotations := dataset.Annotations[image.ID]
+	count := int64(len(annotations))
+	boxes := make([]float32, 0, 4 * count)
+	labels := make([]int64, count)
+	isCrowd := make([]bool, count)
+	masks := make([]byte, 0, count * image.Height * image.Width)
+	for index, annotation := range annotations {
+		xmin, ymin, xmax, ymax := cocoBoxToCorners(annotation.BBox)
+		boxes = append(boxes, xmin, ymin, xmax, ymax)
+		labels[index] = annotation.CategoryID
+		isCrowd[index] = annotation.IsCrowd != 0
+		mask, err := DecodeCOCOSegmentation(annotation.Segmentation, image.Height, image.Width)
+		if err != nil {
+			return COCOTarget{}, fmt.Errorf("Failed to decode segmentation of annotation %d: %v", annotation.ID, err)
+		}
+		masks = append(masks, mask...)
+	}
+	if count == 0 {
+		return COCOTarget{
+			Boxes:   torch.Zeros([]int64{0, 4}, torch.NewTensorOptions().Dtype(torch.Float)),
+			Labels:  torch.Zeros([]int64{0}, torch.NewTensorOptions().Dtype(torch.Long)),
+			IsCrowd: torch.Zeros([]int64{0}, torch.NewTensorOptions().Dtype(torch.Bool)),
+			Masks:   torch.Zeros([]int64{0, image.Height, image.Width}, torch.NewTensorOptions().Dtype(torch.Byte)),
+		}, nil
+	}
+	target := COCOTarget{
+		Boxes:   torch.NewTensor(boxes).Reshape(count, 4),
+		Labels:  torch.NewTensor(labels),
+		IsCrowd: torch.NewTensor(isCrowd),
+	}
+	if len(masks) == 0 {
+		target.Masks = torch.Zeros([]int64{count, image.Height, image.Width}, torch.NewTensorOptions().Dtype(torch.Byte))
+	} else {
+		target.Masks = torch.NewTensorFromBlob(unsafe.Pointer(&masks[0]), torch.Byte, []int64{count, image.Height, image.Width})
+	}
+	return target, nil
+}
+
+// Convert a COCO (x, y, width, height) box to (xmin, ymin, xmax, ymax).
+func cocoBoxToCorners(box [4]float64) (xmin, ymin, xmax, ymax float32) {
+	return float32(box[0]), float32(box[1]), float32(box[0] + box[2]), float32(box[1] + box[3])
+}
+
+// Decode a COCO segmentation into a row-major binary mask of height * width
+// bytes. The segmentation may be a list of polygons in [x0, y0, x1, y1, ...]
+// format, an uncompressed RLE with integer counts, or a compressed RLE with
+// string counts. Polygons are rasterized by sampling pixel centers, which may
+// differ from pycocotools by a pixel along the polygon boundary.
+func DecodeCOCOSegmentation(segmentation json.RawMessage, height, width int64) ([]byte, error) {
+	mask := make([]byte, height * width)
+	if len(segmentation) == 0 || string(segmentation) == "null" {
+		return mask, nil
+	}
+	// Attempt to decode the segmentation as a list of polygons.
+	var polygons [][]float64
+	if err := json.Unmarshal(segmentation, &polygons); err == nil {
+		for _, polygon := range polygons {
+			if len(polygon) < 6 || len(polygon) % 2 != 0 {
+				return nil, fmt.Errorf("Polygon must have 3 or more (x, y) points but has %d values", len(polygon))
+			}
+			rasterizePolygon(mask, polygon, height, width)
+		}
+		return mask, nil
+	}
+	// Otherwise, decode the segmentation as an RLE with list or string counts.
+	var rle struct {
+		Counts json.RawMessage `json:"counts"`
+		Size   []int64         `json:"size"`
+	}
+	if err := json.Unmarshal(segmentation, &rle); err != nil {
+		return nil, errors.New("Segmentation must be a list of polygons or an RLE")
+	}
+	if len(rle.Size) != 2 || rle.Size[0] != height || rle.Size[1] != width {
+		return nil, fmt.Errorf("RLE size %v does not match the image size [%d %d]", rle.Size, height, width)
+	}
+	var counts []int64
+	if err := json.Unmarshal(rle.Counts, &counts); err != nil {
+		var compressed string
+		if err := json.Unmarshal(rle.Counts, &compressed); err != nil {
+			return nil, errors.New("RLE counts must be a list of integers or a string")
+		}
+		counts = decodeRLEString(compressed)
+	}
+	// RLE counts alternate between runs of 0s and 1s in column-major order.
+	position := int64(0)
+	for index, count := range counts {
+		if count < 0 || position + count > height * width {
+			return nil, errors.New("RLE counts exceed the size of the mask")
+		}
+		if index % 2 == 1 {
+			for offset := position; offset < position + count; offset++ {
+				mask[(offset % height) * width + offset / height] = 1
+			}
+		}
+		position += count
+	}
+	return mask, nil
+}
+
+// Decode the compressed string counts of an RLE, following rleFrString from
+// pycocotools. Each count is a variable length sequence of 5-bit chunks
+// offset by 48, and counts after the second are stored as deltas.
+func decodeRLEString(encoded string) []int64 {
+	var counts []int64
+	for position := 0; position < len(encoded); {
+		var value int64
+		shift := uint(0)
+		for more := true; more && position < len(encoded); shift += 5 {
+			chunk := int64(encoded[position]) - 48
+			value |= (chunk & 0x1f) << shift
+			more = chunk & 0x20 != 0
+			position++
+			if !more && chunk & 0x10 != 0 {
+				value |= -1 << (shift + 5)
+			}
+		}
+		if len(counts) > 2 {
+			value += counts[len(counts) - 2]
+		}
+		counts = append(counts, value)
+	}
+	return counts
+}
+
+// Fill the pixels whose centers lie inside the polygon using the even-odd rule.
+func rasterizePolygon(mask []byte, polygon []float64, height, width int64) {
+	points := len(polygon) / 2
+	for y := int64(0); y < height; y++ {
+		center := float64(y) + 0.5
+		// Find the x-coordinates where the polygon edges cross this row.
+		var crossings []float64
+		for index := 0; index < points; index++ {
+			x0, y0 := polygon[2 * index], polygon[2 * index + 1]
+			next := (index + 1) % points
+			x1, y1 := polygon[2 * next], polygon[2 * next + 1]
+			if (y0 <= center && y1 > center) || (y1 <= center && y0 > center) {
+				crossings = append(crossings, x0 + (center - y0) / (y1 - y0) * (x1 - x0))
+			}
+		}
+		sort.Float64s(crossings)
+		// Fill the pixels between each pair of crossings.
+		for index := 0; index + 1 < len(crossings); index += 2 {
+			start := int64(math.Max(0, math.Ceil(crossings[index] - 0.5)))
+			stop := int64(math.Min(float64(width), math.Ceil(crossings[index + 1] - 0.5)))
+			for x := start; x < stop; x++ {
+				mask[y * width + x] = 1
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// test cases for coco.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	datasets "github.com/Kautenja/gotorch/vision/datasets"
+)
+
+const cocoAnnotations = `{
+	"images": [
+		{"id": 7, "file_name": "0.png", "width": 4, "height": 3},
+		{"id": 9, "file_name": "1.png", "width": 4, "height": 3}
+	],
+	"annotations": [
+		{"id": 1, "image_id": 7, "category_id": 18, "bbox": [0, 0, 2, 2], "area": 4, "iscrowd": 0,
+		 "segmentation": [[0, 0, 2, 0, 2, 2, 0, 2]]},
+		{"id": 2, "image_id": 7, "category_id": 1, "bbox": [2, 1, 2, 2], "area": 2, "iscrowd": 1,
+		 "segmentation": {"counts": [7, 2, 1, 2], "size": [3, 4]}}
+	],
+	"categories": [{"id": 1, "name": "person"}, {"id": 18, "name": "dog"}]
+}`
+
+func makeCOCO(t *testing.T) (string, string) {
+	root := t.TempDir()
+	writePNG(t, filepath.Join(root, "0.png"), 4, 3)
+	writePNG(t, filepath.Join(root, "1.png"), 4, 3)
+	return root, writeAnnotations(t, root, "instances.json", cocoAnnotations)
+}
+
+func TestCOCODetectionReturnsErrorOnInvalidJSON(t *testing.T) {
+	root := t.TempDir()
+	path := writeAnnotations(t, root, "instances.json", "{")
+	dataset, err := datasets.COCODetection(root, path, nil)
+	assert.Nil(t, dataset)
+	assert.NotNil(t, err)
+}
+
+func TestCOCODetectionParsesAnnotations(t *testing.T) {
+	root, path := makeCOCO(t)
+	dataset, err := datasets.COCODetection(root, path, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), dataset.Len())
+	assert.Equal(t, "dog", dataset.Categories[1].Name)
+	assert.Equal(t, 2, len(dataset.Annotations[7]))
+	assert.Equal(t, 0, len(dataset.Annotations[9]))
+}
+
+func TestCOCODetectionGet(t *testing.T) {
+	root, path := makeCOCO(t)
+	dataset, _ := datasets.COCODetection(root, path, nil)
+	example, err := dataset.Get(0)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{3, 3, 4}, example.Data.Shape())
+	expected := torch.NewTensor([][]float32{{0, 0, 2, 2, 18}, {2, 1, 4, 3, 1}})
+	assert.True(t, example.Target.Equal(expected))
+	// Images without annotations have an empty (0, 5) target.
+	example, err = dataset.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{0, 5}, example.Target.Shape())
+}
+
+func TestCOCODetectionGetTarget(t *testing.T) {
+	root, path := makeCOCO(t)
+	dataset, _ := datasets.COCODetection(root, path, nil)
+	target, err := dataset.GetTarget(0)
+	assert.Nil(t, err)
+	assert.True(t, target.Boxes.Equal(torch.NewTensor([][]float32{{0, 0, 2, 2}, {2, 1, 4, 3}})))
+	assert.Equal(t, []int64{18, 1}, target.Labels.ToSlice())
+	assert.Equal(t, []bool{false, true}, target.IsCrowd.ToSlice())
+	expected := torch.NewTensor([][][]uint8{
+		{{1, 1, 0, 0}, {1, 1, 0, 0}, {0, 0, 0, 0}},
+		{{0, 0, 0, 0}, {0, 0, 1, 1}, {0, 0, 1, 1}},
+	})
+	assert.True(t, target.Masks.Equal(expected))
+	// Images without annotations have empty targets.
+	target, err = dataset.GetTarget(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{0, 4}, target.Boxes.Shape())
+	assert.Equal(t, []int64{0, 3, 4}, target.Masks.Shape())
+}
+
+// MARK: DecodeCOCOSegmentation
+
+func TestDecodeCOCOSegmentationPolygon(t *testing.T) {
+	mask, err := datasets.DecodeCOCOSegmentation(json.RawMessage(`[[0, 0, 4, 0, 4, 2, 0, 2]]`), 3, 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{1, 1, 1, 1, 0, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0}, mask)
+}
+
+func TestDecodeCOCOSegmentationUncompressedRLE(t *testing.T) {
+	mask, err := datasets.DecodeCOCOSegmentation(json.RawMessage(`{"counts": [1, 2, 3, 3], "size": [3, 3]}`), 3, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 1, 1, 0, 1, 1, 0, 1}, mask)
+}
+
+func TestDecodeCOCOSegmentationCompressedRLE(t *testing.T) {
+	// "1231" is the pycocotools string encoding of the counts [1, 2, 3, 3].
+	mask, err := datasets.DecodeCOCOSegmentation(json.RawMessage(`{"counts": "1231", "size": [3, 3]}`), 3, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 1, 1, 0, 1, 1, 0, 1}, mask)
+}
+
+func TestDecodeCOCOSegmentationReturnsErrorOnSizeMismatch(t *testing.T) {
+	_, err := datasets.DecodeCOCOSegmentation(json.RawMessage(`{"counts": [9], "size": [3, 3]}`), 4, 4)
+	assert.NotNil(t, err)
+}
+
+func TestDecodeCOCOSegmentationReturnsErrorOnOverflowingCounts(t *testing.T) {
+	_, err := datasets.DecodeCOCOSegmentation(json.RawMessage(`{"counts": [5, 5], "size": [3, 3]}`), 3, 3)
+	assert.NotNil(t, err)
+}
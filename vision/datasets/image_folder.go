@@ -0,0 +1,175 @@
+// GoTorch port of torchvision.datasets.ImageFolder
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"github.com/Kautenja/gotorch"
+	data "github.com/Kautenja/gotorch/utils/data"
+	transforms "github.com/Kautenja/gotorch/vision/transforms"
+	T "github.com/Kautenja/gotorch/vision/transforms/functional"
+)
+
+// The file extensions that can be decoded by the Go standard image library.
+var ImageExtensions = []string{".jpg", ".jpeg", ".png", ".gif"}
+
+// An image path and its integer class label.
+type ImageFolderSample struct {
+	Path  string
+	Label int64
+}
+
+// A dataset of images arranged in class sub-directories, i.e.,
+//
+// ```
+// root/dog/xxx.png
+// root/dog/xxy.png
+// root/cat/123.png
+// root/cat/nsdf3.png
+// ```
+type ImageFolderDataset struct {
+	// The sorted names of the class sub-directories.
+	Classes []string
+	// The integer label for each class name.
+	ClassToIndex map[string]int64
+	// The image paths and class labels in the dataset.
+	Samples []ImageFolderSample
+	// The optional transform to apply to each image.
+	transform transforms.ITransformer
+}
+
+// Create a new ImageFolderDataset by scanning the class sub-directories of
+// root for files with one of the given (case-insensitive) extensions. When
+// extensions is nil, ImageExtensions is used. The transform may be nil.
+func ImageFolder(
+	root string,
+	extensions []string,
+	transform transforms.ITransformer,
+) (*ImageFolderDataset, error) {
+	if extensions == nil {
+		extensions = ImageExtensions
+	}
+	// Find the class sub-directories in sorted order.
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	dataset := &ImageFolderDataset{ClassToIndex: map[string]int64{}, transform: transform}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dataset.Classes = append(dataset.Classes, entry.Name())
+		}
+	}
+	if len(dataset.Classes) == 0 {
+		return nil, fmt.Errorf("Couldn't find any class folder in %s", root)
+	}
+	sort.Strings(dataset.Classes)
+	// Walk each class sub-directory for files with a matching extension.
+	for label, class := range dataset.Classes {
+		dataset.ClassToIndex[class] = int64(label)
+		err := filepath.WalkDir(filepath.Join(root, class), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && hasExtension(path, extensions) {
+				dataset.Samples = append(dataset.Samples, ImageFolderSample{path, int64(label)})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(dataset.Samples) == 0 {
+		return nil, fmt.Errorf("Found no valid file for the classes %v in %s", dataset.Classes, root)
+	}
+	return dataset, nil
+}
+
+// Return the number of images in the dataset.
+func (dataset *ImageFolderDataset) Len() int64 {
+	return int64(len(dataset.Samples))
+}
+
+// Load the image at the given index as a (C, H, W) float tensor with its
+// class label as a scalar long tensor.
+func (dataset *ImageFolderDataset) Get(index int64) (data.Example, error) {
+	if index < 0 || index >= dataset.Len() {
+		return data.Example{}, fmt.Errorf("Index %d is out of range for dataset of length %d", index, dataset.Len())
+	}
+	sample := dataset.Samples[index]
+	tensor, err := loadImage(sample.Path, dataset.transform)
+	if err != nil {
+		return data.Example{}, err
+	}
+	return data.Example{
+		Data:   tensor,
+		Target: torch.NewTensor([]int64{sample.Label}).Squeeze(),
+	}, nil
+}
+
+// Return true if the path ends with one of the given extensions, ignoring case.
+func hasExtension(path string, extensions []string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+	for _, candidate := range extensions {
+		if extension == strings.ToLower(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode the image at the given path into a (C, H, W) float tensor and apply
+// the optional transform.
+func loadImage(path string, transform transforms.ITransformer) (*torch.Tensor, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	frame, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode image %s: %v", path, err)
+	}
+	return applyTransform(T.ToTensor(frame), transform), nil
+}
+
+// Apply an optional transform to a (C, H, W) image tensor. The vision
+// transforms operate on batched (N, C, H, W) inputs, so the transform is
+// applied to a batch of one image that is squeezed back to (C, H, W).
+func applyTransform(tensor *torch.Tensor, transform transforms.ITransformer) *torch.Tensor {
+	if transform == nil {
+		return tensor
+	}
+	return transform.Forward(tensor.Unsqueeze(0)).Squeeze(0)
+}
@@ -0,0 +1,141 @@
+// test cases for image_folder.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets_test
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	data "github.com/Kautenja/gotorch/utils/data"
+	datasets "github.com/Kautenja/gotorch/vision/datasets"
+	transforms "github.com/Kautenja/gotorch/vision/transforms"
+)
+
+// Write a solid gray PNG image with given size to the path.
+func writePNG(t *testing.T, path string, width, height int) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	frame := image.NewGray(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			frame.Set(x, y, color.Gray{255})
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Create an image folder with two classes in a temporary directory.
+func makeImageFolder(t *testing.T) string {
+	root := t.TempDir()
+	writePNG(t, filepath.Join(root, "dog", "0.png"), 4, 3)
+	writePNG(t, filepath.Join(root, "dog", "nested", "1.PNG"), 4, 3)
+	writePNG(t, filepath.Join(root, "cat", "0.png"), 4, 3)
+	if err := os.WriteFile(filepath.Join(root, "cat", "notes.txt"), []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestImageFolderReturnsErrorOnMissingRoot(t *testing.T) {
+	dataset, err := datasets.ImageFolder(filepath.Join(t.TempDir(), "missing"), nil, nil)
+	assert.Nil(t, dataset)
+	assert.NotNil(t, err)
+}
+
+func TestImageFolderReturnsErrorOnRootWithoutClasses(t *testing.T) {
+	dataset, err := datasets.ImageFolder(t.TempDir(), nil, nil)
+	assert.Nil(t, dataset)
+	assert.NotNil(t, err)
+}
+
+func TestImageFolderFindsClassesAndSamples(t *testing.T) {
+	root := makeImageFolder(t)
+	dataset, err := datasets.ImageFolder(root, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cat", "dog"}, dataset.Classes)
+	assert.Equal(t, map[string]int64{"cat": 0, "dog": 1}, dataset.ClassToIndex)
+	assert.Equal(t, int64(3), dataset.Len())
+	assert.Equal(t, []datasets.ImageFolderSample{
+		{filepath.Join(root, "cat", "0.png"), 0},
+		{filepath.Join(root, "dog", "0.png"), 1},
+		{filepath.Join(root, "dog", "nested", "1.PNG"), 1},
+	}, dataset.Samples)
+}
+
+func TestImageFolderFiltersExtensions(t *testing.T) {
+	dataset, err := datasets.ImageFolder(makeImageFolder(t), []string{".txt"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), dataset.Len())
+}
+
+func TestImageFolderGet(t *testing.T) {
+	dataset, err := datasets.ImageFolder(makeImageFolder(t), nil, nil)
+	assert.Nil(t, err)
+	example, err := dataset.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{3, 3, 4}, example.Data.Shape())
+	assert.True(t, example.Data.Equal(torch.OnesLike(example.Data)))
+	assert.Equal(t, int64(1), example.Target.Item().(int64))
+}
+
+func TestImageFolderGetAppliesTransform(t *testing.T) {
+	dataset, err := datasets.ImageFolder(makeImageFolder(t), nil, transforms.CenterCrop(1, 2))
+	assert.Nil(t, err)
+	example, err := dataset.Get(0)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{3, 1, 2}, example.Data.Shape())
+}
+
+func TestImageFolderGetReturnsErrorOnUndecodableImage(t *testing.T) {
+	dataset, err := datasets.ImageFolder(makeImageFolder(t), []string{".txt"}, nil)
+	assert.Nil(t, err)
+	_, err = dataset.Get(0)
+	assert.NotNil(t, err)
+}
+
+func TestImageFolderWithDataLoader(t *testing.T) {
+	dataset, err := datasets.ImageFolder(makeImageFolder(t), nil, nil)
+	assert.Nil(t, err)
+	iterator := data.NewDataLoader(dataset, 2, false).Iter()
+	assert.True(t, iterator.Next())
+	assert.Equal(t, []int64{2, 3, 3, 4}, iterator.Batch().Data.Shape())
+	assert.Equal(t, []int64{0, 1}, iterator.Batch().Target.ToSlice())
+	assert.True(t, iterator.Next())
+	assert.Equal(t, []int64{1, 3, 3, 4}, iterator.Batch().Data.Shape())
+	assert.False(t, iterator.Next())
+	assert.Nil(t, iterator.Err())
+}
@@ -0,0 +1,223 @@
+// GoTorch port of torchvision.datasets.MNIST and IDX file parsing.
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"unsafe"
+	"github.com/Kautenja/gotorch"
+	data "github.com/Kautenja/gotorch/utils/data"
+	transforms "github.com/Kautenja/gotorch/vision/transforms"
+)
+
+// Read a tensor from an IDX formatted stream, i.e., the format of the MNIST
+// and Fashion-MNIST image and label files. Multi-byte values are stored in
+// big-endian order and are converted to the native byte order.
+func ReadIDX(reader io.Reader) (*torch.Tensor, error) {
+	// The magic number is two zero bytes, a type code, and a dimension count.
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(reader, magic); err != nil {
+		return nil, err
+	}
+	if magic[0] != 0 || magic[1] != 0 {
+		return nil, errors.New("Invalid IDX magic number")
+	}
+	if magic[3] == 0 {
+		return nil, errors.New("IDX data must have at least 1 dimension")
+	}
+	var dtype torch.Dtype
+	switch magic[2] {
+	case 0x08: dtype = torch.Byte
+	case 0x09: dtype = torch.Char
+	case 0x0B: dtype = torch.Short
+	case 0x0C: dtype = torch.Int
+	case 0x0D: dtype = torch.Float
+	case 0x0E: dtype = torch.Double
+	default:
+		return nil, fmt.Errorf("Unrecognized IDX type code 0x%02X", magic[2])
+	}
+	shape := make([]int64, magic[3])
+	numel, extent := int64(1), dtype.NumBytes()
+	for index := range shape {
+		var size uint32
+		if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return nil, err
+		}
+		shape[index] = int64(size)
+		numel *= int64(size)
+		// Empty data is checked too, as libtorch computes the strides of
+		// every dimension.
+		if size == 0 {
+			continue
+		}
+		if extent > math.MaxInt64 / int64(size) {
+			return nil, fmt.Errorf("IDX shape %v overflows the size of a tensor", shape[:index + 1])
+		}
+		extent *= int64(size)
+	}
+	if numel == 0 {
+		return torch.Zeros(shape, torch.NewTensorOptions().Dtype(dtype)), nil
+	}
+	// Buffer the payload as it is read, so that a stream shorter than its
+	// header claims is an error rather than a large allocation.
+	var data bytes.Buffer
+	if read, err := io.CopyN(&data, reader, numel * dtype.NumBytes()); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("Failed to read IDX data after %d of %d bytes: %w", read, numel * dtype.NumBytes(), err)
+	}
+	payload := data.Bytes()
+	// Convert the big-endian payload to a native-endian buffer of the dtype.
+	var buffer unsafe.Pointer
+	switch dtype {
+	case torch.Byte, torch.Char:
+		buffer = unsafe.Pointer(&payload[0])
+	case torch.Short:
+		values := make([]int16, numel)
+		for index := range values {
+			values[index] = int16(binary.BigEndian.Uint16(payload[2 * index:]))
+		}
+		buffer = unsafe.Pointer(&values[0])
+	case torch.Int:
+		values := make([]int32, numel)
+		for index := range values {
+			values[index] = int32(binary.BigEndian.Uint32(payload[4 * index:]))
+		}
+		buffer = unsafe.Pointer(&values[0])
+	case torch.Float:
+		values := make([]float32, numel)
+		for index := range values {
+			values[index] = math.Float32frombits(binary.BigEndian.Uint32(payload[4 * index:]))
+		}
+		buffer = unsafe.Pointer(&values[0])
+	case torch.Double:
+		values := make([]float64, numel)
+		for index := range values {
+			values[index] = math.Float64frombits(binary.BigEndian.Uint64(payload[8 * index:]))
+		}
+		buffer = unsafe.Pointer(&values[0])
+	}
+	return torch.NewTensorFromBlob(buffer, dtype, shape), nil
+}
+
+// Read a tensor from an IDX file on the file-system. If the path does not
+// exist, the gzip compressed "<path>.gz" is read instead. Gzip compressed
+// files are detected by their magic number and decompressed transparently.
+func ReadIDXFile(path string) (*torch.Tensor, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(path + ".gz")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := maybeGzipReader(bufio.NewReader(file))
+	if err != nil {
+		return nil, err
+	}
+	return ReadIDX(reader)
+}
+
+// Wrap the reader with a gzip decompressor if the stream is gzip compressed.
+func maybeGzipReader(reader *bufio.Reader) (io.Reader, error) {
+	magic, err := reader.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(reader)
+	}
+	return reader, nil
+}
+
+// A dataset of the MNIST (or Fashion-MNIST) handwritten digits.
+type MNISTDataset struct {
+	// The (N, H, W) byte tensor of images.
+	Images *torch.Tensor
+	// The (N,) long tensor of labels.
+	Labels *torch.Tensor
+	// The optional transform to apply to each image.
+	transform transforms.ITransformer
+}
+
+// Create a new MNISTDataset from the IDX files in root. The training split
+// reads "train-images-idx3-ubyte" and "train-labels-idx1-ubyte", the test
+// split reads "t10k-images-idx3-ubyte" and "t10k-labels-idx1-ubyte". Each
+// file may be gzip compressed with a ".gz" suffix. Fashion-MNIST uses the same
+// file names and format. The transform may be nil.
+func MNIST(root string, train bool, transform transforms.ITransformer) (*MNISTDataset, error) {
+	prefix := "t10k"
+	if train {
+		prefix = "train"
+	}
+	images, err := ReadIDXFile(filepath.Join(root, prefix + "-images-idx3-ubyte"))
+	if err != nil {
+		return nil, err
+	}
+	labels, err := ReadIDXFile(filepath.Join(root, prefix + "-labels-idx1-ubyte"))
+	if err != nil {
+		return nil, err
+	}
+	imagesShape := images.Shape()
+	labelsShape := labels.Shape()
+	if len(imagesShape) != 3 || images.Dtype() != torch.Byte {
+		return nil, fmt.Errorf("Expected (N, H, W) byte images but found %v tensor with shape %v", images.Dtype(), imagesShape)
+	}
+	if len(labelsShape) != 1 || labelsShape[0] != imagesShape[0] {
+		return nil, fmt.Errorf("Expected (%d,) labels but found tensor with shape %v", imagesShape[0], labelsShape)
+	}
+	return &MNISTDataset{images, labels.CastTo(torch.Long), transform}, nil
+}
+
+// Return the number of images in the dataset.
+func (dataset *MNISTDataset) Len() int64 {
+	return dataset.Images.Shape()[0]
+}
+
+// Return the image at the given index as a (1, H, W) float tensor in [0, 1]
+// with its label as a scalar long tensor.
+func (dataset *MNISTDataset) Get(index int64) (data.Example, error) {
+	if index < 0 || index >= dataset.Len() {
+		return data.Example{}, fmt.Errorf("Index %d is out of range for dataset of length %d", index, dataset.Len())
+	}
+	image := byteImageToFloat(dataset.Images.Slice(0, index, index + 1, 1))
+	return data.Example{
+		Data:   applyTransform(image, dataset.transform),
+		Target: dataset.Labels.Slice(0, index, index + 1, 1).Squeeze(),
+	}, nil
+}
+
+// Convert a byte image tensor in [0, 255] to a float tensor in [0, 1].
+func byteImageToFloat(tensor *torch.Tensor) *torch.Tensor {
+	tensor = tensor.CastTo(torch.Float)
+	return tensor.Div(torch.FullLike(tensor, 255.0))
+}
@@ -0,0 +1,159 @@
+// test cases for mnist.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_datasets_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	datasets "github.com/Kautenja/gotorch/vision/datasets"
+)
+
+// Create an IDX byte stream with the given type code, shape, and payload.
+func makeIDX(typeCode byte, shape []uint32, payload []byte) []byte {
+	buffer := []byte{0, 0, typeCode, byte(len(shape))}
+	for _, size := range shape {
+		buffer = append(buffer, byte(size >> 24), byte(size >> 16), byte(size >> 8), byte(size))
+	}
+	return append(buffer, payload...)
+}
+
+// Write a small MNIST split with 2 images of size 2x3 to root.
+func makeMNIST(t *testing.T, root, prefix string, compress bool) {
+	images := makeIDX(0x08, []uint32{2, 2, 3}, []byte{0, 255, 0, 0, 0, 0, 255, 255, 255, 255, 255, 255})
+	labels := makeIDX(0x08, []uint32{2}, []byte{7, 3})
+	files := map[string][]byte{
+		prefix + "-images-idx3-ubyte": images,
+		prefix + "-labels-idx1-ubyte": labels,
+	}
+	for name, contents := range files {
+		path := filepath.Join(root, name)
+		if compress {
+			var buffer bytes.Buffer
+			writer := gzip.NewWriter(&buffer)
+			writer.Write(contents)
+			writer.Close()
+			contents = buffer.Bytes()
+			path += ".gz"
+		}
+		if err := os.WriteFile(path, contents, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// MARK: ReadIDX
+
+func TestReadIDXReturnsErrorOnInvalidMagicNumber(t *testing.T) {
+	_, err := datasets.ReadIDX(bytes.NewReader([]byte{1, 0, 0x08, 1, 0, 0, 0, 0}))
+	assert.NotNil(t, err)
+}
+
+func TestReadIDXReturnsErrorOnInvalidTypeCode(t *testing.T) {
+	_, err := datasets.ReadIDX(bytes.NewReader(makeIDX(0x0A, []uint32{1}, []byte{0})))
+	assert.NotNil(t, err)
+	assert.Equal(t, "Unrecognized IDX type code 0x0A", err.Error())
+}
+
+func TestReadIDXReturnsErrorOnTruncatedPayload(t *testing.T) {
+	_, err := datasets.ReadIDX(bytes.NewReader(makeIDX(0x08, []uint32{4}, []byte{0, 1})))
+	assert.NotNil(t, err)
+}
+
+func TestReadIDXReturnsErrorOnTruncatedPayloadOfHugeShape(t *testing.T) {
+	// The header claims 8 TiB of data that the stream does not have.
+	_, err := datasets.ReadIDX(bytes.NewReader(makeIDX(0x0E, []uint32{1 << 20, 1 << 20}, []byte{0, 1})))
+	assert.EqualError(t, err, "Failed to read IDX data after 2 of 8796093022208 bytes: unexpected EOF")
+}
+
+func TestReadIDXReturnsErrorOnOverflowingShape(t *testing.T) {
+	_, err := datasets.ReadIDX(bytes.NewReader(makeIDX(0x08, []uint32{1 << 31, 1 << 31, 1 << 31}, nil)))
+	assert.EqualError(t, err, "IDX shape [2147483648 2147483648 2147483648] overflows the size of a tensor")
+	_, err = datasets.ReadIDX(bytes.NewReader(makeIDX(0x08, []uint32{0, 1 << 31, 1 << 31, 1 << 31}, nil)))
+	assert.EqualError(t, err, "IDX shape [0 2147483648 2147483648 2147483648] overflows the size of a tensor")
+}
+
+func TestReadIDXBytes(t *testing.T) {
+	tensor, err := datasets.ReadIDX(bytes.NewReader(makeIDX(0x08, []uint32{2, 2}, []byte{1, 2, 3, 4})))
+	assert.Nil(t, err)
+	assert.Equal(t, torch.Byte, tensor.Dtype())
+	assert.True(t, tensor.Equal(torch.NewTensor([][]uint8{{1, 2}, {3, 4}})))
+}
+
+func TestReadIDXBigEndianShorts(t *testing.T) {
+	tensor, err := datasets.ReadIDX(bytes.NewReader(makeIDX(0x0B, []uint32{2}, []byte{0x01, 0x02, 0xFF, 0xFE})))
+	assert.Nil(t, err)
+	assert.Equal(t, torch.Short, tensor.Dtype())
+	assert.Equal(t, []int16{258, -2}, tensor.ToSlice())
+}
+
+func TestReadIDXBigEndianFloats(t *testing.T) {
+	tensor, err := datasets.ReadIDX(bytes.NewReader(makeIDX(0x0D, []uint32{1}, []byte{0x3F, 0xC0, 0x00, 0x00})))
+	assert.Nil(t, err)
+	assert.Equal(t, torch.Float, tensor.Dtype())
+	assert.Equal(t, []float32{1.5}, tensor.ToSlice())
+}
+
+// MARK: MNIST
+
+func TestMNISTReturnsErrorOnMissingFiles(t *testing.T) {
+	dataset, err := datasets.MNIST(t.TempDir(), true, nil)
+	assert.Nil(t, dataset)
+	assert.NotNil(t, err)
+}
+
+func TestMNISTTrainSplit(t *testing.T) {
+	root := t.TempDir()
+	makeMNIST(t, root, "train", false)
+	dataset, err := datasets.MNIST(root, true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), dataset.Len())
+	example, err := dataset.Get(0)
+	assert.Nil(t, err)
+	assert.True(t, example.Data.Equal(torch.NewTensor([][][]float32{{{0, 1, 0}, {0, 0, 0}}})))
+	assert.Equal(t, int64(7), example.Target.Item().(int64))
+}
+
+func TestMNISTTestSplitWithGzip(t *testing.T) {
+	root := t.TempDir()
+	makeMNIST(t, root, "t10k", true)
+	dataset, err := datasets.MNIST(root, false, nil)
+	assert.Nil(t, err)
+	example, err := dataset.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, example.Data.Shape())
+	assert.Equal(t, int64(3), example.Target.Item().(int64))
+}
+
+func TestMNISTGetReturnsErrorOnOutOfRangeIndex(t *testing.T) {
+	root := t.TempDir()
+	makeMNIST(t, root, "train", false)
+	dataset, _ := datasets.MNIST(root, true, nil)
+	_, err := dataset.Get(2)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,57 @@
+// GoTorch port of torchvision.ops.box_iou
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_ops
+
+import (
+	"fmt"
+	"github.com/Kautenja/gotorch"
+)
+
+// Compute the intersection-over-union (Jaccard index) between two sets of
+// boxes. boxes1 is expected to be shaped as (N, 4) and boxes2 as (M, 4), both
+// in (xmin, ymin, xmax, ymax) format with 0 <= xmin < xmax and
+// 0 <= ymin < ymax. The output is an (N, M) matrix containing the pairwise
+// IoU values for every element in boxes1 and boxes2.
+func BoxIoU(boxes1, boxes2 *torch.Tensor) *torch.Tensor {
+	for _, boxes := range []*torch.Tensor{boxes1, boxes2} {
+		shape := boxes.Shape()
+		if len(shape) != 2 || shape[1] != 4 {
+			panic(fmt.Sprintf("Expected inputs to be in (N, 4) format, but received tensor with shape %v", shape))
+		}
+	}
+	boxes1 = boxes1.CastTo(torch.Float)
+	boxes2 = boxes2.CastTo(torch.Float)
+	// Calculate the area of each box as (N, 1) and (1, M) for broadcasting.
+	area1 := BoxArea(boxes1)
+	area2 := BoxArea(boxes2).Transpose(0, 1)
+	// Find the top-left and bottom-right corners of the (N, M, 2) intersections.
+	topLeft := boxes1.Slice(1, 0, 2, 1).Unsqueeze(1).Maximum(boxes2.Slice(1, 0, 2, 1).Unsqueeze(0))
+	bottomRight := boxes1.Slice(1, 2, 4, 1).Unsqueeze(1).Minimum(boxes2.Slice(1, 2, 4, 1).Unsqueeze(0))
+	// Disjoint boxes have negative extents that need to be clipped to zero.
+	extent := bottomRight.Sub(topLeft, 1.0)
+	extent = extent.Maximum(torch.ZerosLike(extent))
+	intersection := extent.Slice(2, 0, 1, 1).Mul(extent.Slice(2, 1, 2, 1)).Squeeze(2)
+	union := area1.Add(area2, 1.0).Sub_(intersection, 1.0)
+	return intersection.Div(union)
+}
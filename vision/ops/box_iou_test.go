@@ -0,0 +1,59 @@
+// test cases for box_iou.go
+//
+// Copyright (c) 2023 Christian Kauten
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXTERNRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package vision_ops_test
+
+import (
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/Kautenja/gotorch"
+	ops "github.com/Kautenja/gotorch/vision/ops"
+)
+
+func TestBoxIoUPanicsOnNonBBoxInputs(t *testing.T) {
+	boxes1 := torch.NewTensor([][]int64{{0, 0, 0}})
+	boxes2 := torch.NewTensor([][]int64{{0, 0, 10, 10}})
+	message := "Expected inputs to be in (N, 4) format, but received tensor with shape [1 3]"
+	assert.PanicsWithValue(t, message, func() {
+		ops.BoxIoU(boxes1, boxes2)
+	})
+	assert.PanicsWithValue(t, message, func() {
+		ops.BoxIoU(boxes2, boxes1)
+	})
+}
+
+func TestBoxIoUOfIdenticalBoxesIsOne(t *testing.T) {
+	boxes := torch.NewTensor([][]int64{{0, 0, 10, 10}})
+	iou := ops.BoxIoU(boxes, boxes)
+	assert.Equal(t, []int64{1, 1}, iou.Shape())
+	assert.Equal(t, float32(1.0), iou.Item())
+}
+
+func TestBoxIoUComputesPairwiseIoU(t *testing.T) {
+	boxes1 := torch.NewTensor([][]float32{{0, 0, 10, 10}, {0, 0, 5, 5}})
+	boxes2 := torch.NewTensor([][]float32{{5, 5, 15, 15}, {0, 0, 10, 5}, {20, 20, 30, 30}})
+	iou := ops.BoxIoU(boxes1, boxes2)
+	assert.Equal(t, []int64{2, 3}, iou.Shape())
+	expected := torch.NewTensor([][]float32{{25.0 / 175.0, 0.5, 0}, {0, 0.5, 0}})
+	assert.True(t, iou.AllClose(expected, 1e-5, 1e-8))
+}